@@ -1,9 +1,12 @@
 package system
 
 import (
+	"fmt"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	common "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	request "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -76,6 +79,9 @@ func (a *AutoCodeHistoryApi) RollBack(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
+	if submitApproval(c, system.ApprovalActionRollBackAutoCode, info, fmt.Sprintf("回滚自动生成代码 ID:%d", info.ID)) {
+		return
+	}
 	err = autoCodeHistoryService.RollBack(c.Request.Context(), info)
 	if err != nil {
 		response.FailWithMessage(err.Error(), c)
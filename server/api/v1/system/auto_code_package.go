@@ -98,3 +98,37 @@ func (a *AutoCodePackageApi) Templates(c *gin.Context) {
 	}
 	response.OkWithDetailed(data, "获取成功", c)
 }
+
+// PreviewPack
+// @Tags      AutoCodePackage
+// @Summary   预览并校验自定义模板包
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.TemplatePackPreview                                        true  "模板包名称, 预览使用的包名和结构"
+// @Success   200   {object}  response.Response{data=systemRes.TemplatePackPreview,msg=string}  "预览结果"
+// @Router    /autoCode/previewPack [post]
+func (a *AutoCodePackageApi) PreviewPack(c *gin.Context) {
+	var info request.TemplatePackPreview
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(info, utils.TemplatePackVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if strings.Contains(info.Template, "\\") || strings.Contains(info.Template, "/") || strings.Contains(info.Template, "..") {
+		response.FailWithMessage("模板名不合法", c)
+		return
+	}
+	data, err := autoCodePackageService.PreviewPack(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("预览失败!", zap.Error(err))
+		response.FailWithMessage("预览失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(data, "预览成功", c)
+}
@@ -117,3 +117,84 @@ func (a *AutoCodePluginApi) InitAPI(c *gin.Context) {
 	}
 	response.OkWithMessage("文件变更成功", c)
 }
+
+// InstallPackage
+// @Tags      AutoCodePlugin
+// @Summary   安装插件包 校验plugin.json后复制代码、注册插件、执行迁移并创建菜单和api
+// @Security  ApiKeyAuth
+// @accept    multipart/form-data
+// @Produce   application/json
+// @Param     plug  formData  file                                                          true  "插件包"
+// @Success   200   {object}  response.Response{data=systemRes.PluginSteps,msg=string}  "安装插件成功"
+// @Router    /autoCode/installPluginPackage [post]
+func (a *AutoCodePluginApi) InstallPackage(c *gin.Context) {
+	header, err := c.FormFile("plug")
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	result, err := autoCodePluginService.InstallPackage(c.Request.Context(), header)
+	if err != nil {
+		global.GVA_LOG.Error("安装插件失败!", zap.Error(err))
+		response.FailWithMessage("安装插件失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(result, "安装插件成功", c)
+}
+
+// GetInstalledPlugins
+// @Tags      AutoCodePlugin
+// @Summary   获取通过插件包安装的插件
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200   {object}  response.Response{data=[]system.SysPlugin,msg=string}  "获取成功"
+// @Router    /autoCode/getInstalledPlugins [get]
+func (a *AutoCodePluginApi) GetInstalledPlugins(c *gin.Context) {
+	list, err := autoCodePluginService.GetInstalledPlugins(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// GetPluginStatus
+// @Tags      AutoCodePlugin
+// @Summary   获取v2插件运行状态
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200   {object}  response.Response{data=[]plugin.Status,msg=string}  "获取成功"
+// @Router    /autoCode/getPluginStatus [get]
+func (a *AutoCodePluginApi) GetPluginStatus(c *gin.Context) {
+	response.OkWithDetailed(autoCodePluginService.GetPluginStatus(c.Request.Context()), "获取成功", c)
+}
+
+// Uninstall
+// @Tags      AutoCodePlugin
+// @Summary   卸载通过插件包安装的插件 dryRun为true时只列出将要执行的操作
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.PluginUninstall                                     true  "插件名, 是否预演"
+// @Success   200   {object}  response.Response{data=systemRes.PluginSteps,msg=string}  "卸载插件成功"
+// @Router    /autoCode/uninstallPlugin [post]
+func (a *AutoCodePluginApi) Uninstall(c *gin.Context) {
+	var info request.PluginUninstall
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	result, err := autoCodePluginService.Uninstall(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("卸载插件失败!", zap.Error(err))
+		response.FailWithMessage("卸载插件失败:"+err.Error(), c)
+		return
+	}
+	if info.DryRun {
+		response.OkWithDetailed(result, "预演成功", c)
+		return
+	}
+	response.OkWithDetailed(result, "卸载插件成功", c)
+}
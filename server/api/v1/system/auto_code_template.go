@@ -82,6 +82,101 @@ func (a *AutoCodeTemplateApi) Create(c *gin.Context) {
 	}
 }
 
+// Update
+// @Tags      AutoCodeTemplate
+// @Summary   修改已生成结构的字段 合并模型和查询结构体, 生成字段变更的迁移SQL
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.AutoCodeUpdate                                       true  "修改后的自动代码, 是否只预览"
+// @Success   200   {object}  response.Response{data=systemRes.AutoCodeUpdate,msg=string}  "修改已生成结构的字段"
+// @Router    /autoCode/updateTemp [post]
+func (a *AutoCodeTemplateApi) Update(c *gin.Context) {
+	var info request.AutoCodeUpdate
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(info.AutoCode, utils.AutoCodeVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = info.Pretreatment()
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	result, err := autoCodeTemplateService.Update(c.Request.Context(), info.AutoCode, info.DryRun)
+	if err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	if info.DryRun {
+		response.OkWithDetailed(result, "预览成功", c)
+		return
+	}
+	response.OkWithDetailed(result, "更新成功", c)
+}
+
+// GetOpenAPISchemas
+// @Tags      AutoCodeTemplate
+// @Summary   获取OpenAPI文档中的Schema
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     path  query     string                                                             true  "文档路径 相对于项目目录"
+// @Success   200   {object}  response.Response{data=[]systemRes.OpenAPISchema,msg=string}  "获取OpenAPI文档中的Schema"
+// @Router    /autoCode/getOpenAPISchemas [get]
+func (a *AutoCodeTemplateApi) GetOpenAPISchemas(c *gin.Context) {
+	schemas, err := autoCodeTemplateService.OpenAPISchemas(c.Query("path"))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(schemas, "获取成功", c)
+}
+
+// OpenAPI
+// @Tags      AutoCodeTemplate
+// @Summary   从OpenAPI文档的Schema转换代码生成配置, 可直接预览或生成代码
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.AutoCodeOpenAPI                                        true  "文档路径, Schema, 包, 模式"
+// @Success   200   {object}  response.Response{data=systemRes.AutoCodeOpenAPI,msg=string}  "转换结果"
+// @Router    /autoCode/openAPI [post]
+func (a *AutoCodeTemplateApi) OpenAPI(c *gin.Context) {
+	var req request.AutoCodeOpenAPI
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.AutoCodeOpenAPIVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	result, err := autoCodeTemplateService.OpenAPI(c.Request.Context(), req)
+	if err != nil {
+		global.GVA_LOG.Error("转换失败!", zap.Error(err))
+		response.FailWithMessage("转换失败:"+err.Error(), c)
+		return
+	}
+	switch req.Mode {
+	case "create":
+		response.OkWithDetailed(result, "创建成功", c)
+	case "preview":
+		response.OkWithDetailed(result, "预览成功", c)
+	default:
+		response.OkWithDetailed(result, "转换成功", c)
+	}
+}
+
 // AddFunc
 // @Tags      AddFunc
 // @Summary   增加方法
@@ -16,12 +16,23 @@ type ApiGroup struct {
 	OperationRecordApi
 	DictionaryDetailApi
 	AuthorityBtnApi
+	AuthorityFieldApi
 	SysExportTemplateApi
 	AutoCodePluginApi
 	AutoCodePackageApi
 	AutoCodeHistoryApi
 	AutoCodeTemplateApi
 	SysParamsApi
+	LdapApi
+	ApiTokenApi
+	DataScopeApi
+	DepartmentApi
+	AuthorityGrantApi
+	RbacApi
+	TenantApi
+	ApprovalApi
+	NotificationApi
+	WebhookApi
 }
 
 var (
@@ -35,6 +46,7 @@ var (
 	authorityService        = service.ServiceGroupApp.SystemServiceGroup.AuthorityService
 	dictionaryService       = service.ServiceGroupApp.SystemServiceGroup.DictionaryService
 	authorityBtnService     = service.ServiceGroupApp.SystemServiceGroup.AuthorityBtnService
+	authorityFieldService   = service.ServiceGroupApp.SystemServiceGroup.AuthorityFieldService
 	systemConfigService     = service.ServiceGroupApp.SystemServiceGroup.SystemConfigService
 	sysParamsService        = service.ServiceGroupApp.SystemServiceGroup.SysParamsService
 	operationRecordService  = service.ServiceGroupApp.SystemServiceGroup.OperationRecordService
@@ -44,4 +56,16 @@ var (
 	autoCodePackageService  = service.ServiceGroupApp.SystemServiceGroup.AutoCodePackage
 	autoCodeHistoryService  = service.ServiceGroupApp.SystemServiceGroup.AutoCodeHistory
 	autoCodeTemplateService = service.ServiceGroupApp.SystemServiceGroup.AutoCodeTemplate
+	oidcService             = service.ServiceGroupApp.SystemServiceGroup.OidcService
+	ldapService             = service.ServiceGroupApp.SystemServiceGroup.LdapService
+	apiTokenService         = service.ServiceGroupApp.SystemServiceGroup.ApiTokenService
+	passwordResetService    = service.ServiceGroupApp.SystemServiceGroup.PasswordResetService
+	dataScopeService        = service.ServiceGroupApp.SystemServiceGroup.DataScopeService
+	departmentService       = service.ServiceGroupApp.SystemServiceGroup.DepartmentService
+	authorityGrantService   = service.ServiceGroupApp.SystemServiceGroup.AuthorityGrantService
+	rbacService             = service.ServiceGroupApp.SystemServiceGroup.RbacService
+	tenantService           = service.ServiceGroupApp.SystemServiceGroup.TenantService
+	approvalService         = service.ServiceGroupApp.SystemServiceGroup.ApprovalService
+	notificationService     = service.ServiceGroupApp.SystemServiceGroup.NotificationService
+	webhookService          = service.ServiceGroupApp.SystemServiceGroup.WebhookService
 )
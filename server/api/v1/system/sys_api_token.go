@@ -0,0 +1,161 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ApiTokenApi struct{}
+
+// CreateApiToken
+// @Tags      ApiToken
+// @Summary   创建个人访问令牌
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.CreateApiToken                                      true  "令牌名称, 权限范围, 过期时间"
+// @Success   200   {object}  response.Response{data=systemRes.ApiTokenResponse,msg=string}  "返回令牌明文 仅展示一次"
+// @Router    /apiToken/createApiToken [post]
+func (a *ApiTokenApi) CreateApiToken(c *gin.Context) {
+	var req systemReq.CreateApiToken
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	a.createApiToken(c, utils.GetUserID(c), req)
+}
+
+// AdminCreateApiToken
+// @Tags      ApiToken
+// @Summary   管理员为指定用户创建访问令牌
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.CreateApiToken                                      true  "所属用户, 令牌名称, 权限范围, 过期时间"
+// @Success   200   {object}  response.Response{data=systemRes.ApiTokenResponse,msg=string}  "返回令牌明文 仅展示一次"
+// @Router    /apiToken/adminCreateApiToken [post]
+func (a *ApiTokenApi) AdminCreateApiToken(c *gin.Context) {
+	var req systemReq.CreateApiToken
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if req.UserId == 0 {
+		response.FailWithMessage("请选择令牌所属用户", c)
+		return
+	}
+	a.createApiToken(c, req.UserId, req)
+}
+
+func (a *ApiTokenApi) createApiToken(c *gin.Context, userId uint, req systemReq.CreateApiToken) {
+	token, apiToken, err := apiTokenService.CreateApiToken(c.Request.Context(), userId, req)
+	if err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.ApiTokenResponse{Token: token, ApiToken: apiToken}, "创建成功,请妥善保存令牌,关闭后将无法再次查看", c)
+}
+
+// GetApiTokenList
+// @Tags      ApiToken
+// @Summary   分页获取当前用户的访问令牌
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.ApiTokenSearch                                true  "页码, 每页大小, 名称"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取访问令牌,返回包括列表,总数,页码,每页数量"
+// @Router    /apiToken/getApiTokenList [get]
+func (a *ApiTokenApi) GetApiTokenList(c *gin.Context) {
+	var search systemReq.ApiTokenSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	search.UserId = utils.GetUserID(c)
+	a.getApiTokenList(c, search)
+}
+
+// AdminGetApiTokenList
+// @Tags      ApiToken
+// @Summary   管理员分页获取全部访问令牌
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.ApiTokenSearch                                true  "页码, 每页大小, 所属用户, 名称"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取访问令牌,返回包括列表,总数,页码,每页数量"
+// @Router    /apiToken/adminGetApiTokenList [get]
+func (a *ApiTokenApi) AdminGetApiTokenList(c *gin.Context) {
+	var search systemReq.ApiTokenSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	a.getApiTokenList(c, search)
+}
+
+func (a *ApiTokenApi) getApiTokenList(c *gin.Context, search systemReq.ApiTokenSearch) {
+	list, total, err := apiTokenService.GetApiTokenList(c.Request.Context(), search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// RevokeApiToken
+// @Tags      ApiToken
+// @Summary   吊销当前用户的访问令牌
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                 true  "令牌ID"
+// @Success   200   {object}  response.Response{msg=string}  "吊销访问令牌"
+// @Router    /apiToken/revokeApiToken [post]
+func (a *ApiTokenApi) RevokeApiToken(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	a.revokeApiToken(c, req.Uint(), utils.GetUserID(c))
+}
+
+// AdminRevokeApiToken
+// @Tags      ApiToken
+// @Summary   管理员吊销任意访问令牌
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                 true  "令牌ID"
+// @Success   200   {object}  response.Response{msg=string}  "吊销访问令牌"
+// @Router    /apiToken/adminRevokeApiToken [post]
+func (a *ApiTokenApi) AdminRevokeApiToken(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	a.revokeApiToken(c, req.Uint(), 0)
+}
+
+func (a *ApiTokenApi) revokeApiToken(c *gin.Context, id, userId uint) {
+	if err := apiTokenService.RevokeApiToken(c.Request.Context(), id, userId); err != nil {
+		global.GVA_LOG.Error("吊销失败!", zap.Error(err))
+		response.FailWithMessage("吊销失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("吊销成功", c)
+}
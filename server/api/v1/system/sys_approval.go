@@ -0,0 +1,352 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ApprovalApi struct{}
+
+// submitApproval 操作配置了审批流程时提交审批单并响应请求, 返回true表示已处理 调用方不应再执行原操作
+func submitApproval(c *gin.Context, action string, payload interface{}, summary string) bool {
+	req, guarded, err := approvalService.Submit(c.Request.Context(), action, payload, summary)
+	if err != nil {
+		global.GVA_LOG.Error("提交审批失败!", zap.Error(err))
+		response.FailWithMessage("提交审批失败:"+err.Error(), c)
+		return true
+	}
+	if guarded {
+		req.Payload = nil
+		response.OkWithDetailed(req, "操作需要审批, 已提交审批单", c)
+	}
+	return guarded
+}
+
+// GetApprovalActions
+// @Tags      Approval
+// @Summary   获取可配置审批流程的操作
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]systemRes.ApprovalAction,msg=string}  "获取可配置审批流程的操作"
+// @Router    /approval/getActions [get]
+func (a *ApprovalApi) GetApprovalActions(c *gin.Context) {
+	response.OkWithDetailed(approvalService.GetActions(), "获取成功", c)
+}
+
+// CreateApprovalFlow
+// @Tags      Approval
+// @Summary   创建审批流程
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysApprovalFlow                                     true  "流程名称, 受控操作, 是否启用, 审批步骤"
+// @Success   200   {object}  response.Response{data=system.SysApprovalFlow,msg=string}  "创建审批流程"
+// @Router    /approval/createFlow [post]
+func (a *ApprovalApi) CreateApprovalFlow(c *gin.Context) {
+	var flow system.SysApprovalFlow
+	if err := c.ShouldBindJSON(&flow); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	flow.ID = 0
+	if err := approvalService.CreateFlow(&flow); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(flow, "创建成功", c)
+}
+
+// UpdateApprovalFlow
+// @Tags      Approval
+// @Summary   更新审批流程
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysApprovalFlow         true  "流程ID, 流程名称, 受控操作, 是否启用, 审批步骤"
+// @Success   200   {object}  response.Response{msg=string}  "更新审批流程"
+// @Router    /approval/updateFlow [put]
+func (a *ApprovalApi) UpdateApprovalFlow(c *gin.Context) {
+	var flow system.SysApprovalFlow
+	if err := c.ShouldBindJSON(&flow); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := approvalService.UpdateFlow(flow); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteApprovalFlow
+// @Tags      Approval
+// @Summary   删除审批流程
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "流程ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除审批流程"
+// @Router    /approval/deleteFlow [delete]
+func (a *ApprovalApi) DeleteApprovalFlow(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := approvalService.DeleteFlow(req.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// FindApprovalFlow
+// @Tags      Approval
+// @Summary   获取审批流程
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     request.GetById                                            true  "流程ID"
+// @Success   200   {object}  response.Response{data=system.SysApprovalFlow,msg=string}  "获取审批流程"
+// @Router    /approval/findFlow [get]
+func (a *ApprovalApi) FindApprovalFlow(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	flow, err := approvalService.GetFlow(req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(flow, "获取成功", c)
+}
+
+// GetApprovalFlowList
+// @Tags      Approval
+// @Summary   分页获取审批流程列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.ApprovalFlowSearch                            true  "页码, 每页大小, 受控操作, 流程名称"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取审批流程列表,返回包括列表,总数,页码,每页数量"
+// @Router    /approval/getFlowList [get]
+func (a *ApprovalApi) GetApprovalFlowList(c *gin.Context) {
+	var search systemReq.ApprovalFlowSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := approvalService.GetFlowList(search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// ApproveRequest
+// @Tags      Approval
+// @Summary   同意审批 最后一步通过后执行原操作
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.ApprovalAct                                         true  "审批单ID, 审批意见"
+// @Success   200   {object}  response.Response{data=system.SysApprovalRequest,msg=string}  "同意审批"
+// @Router    /approval/approve [post]
+func (a *ApprovalApi) ApproveRequest(c *gin.Context) {
+	var act systemReq.ApprovalAct
+	if err := c.ShouldBindJSON(&act); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	req, err := approvalService.Approve(c.Request.Context(), act)
+	if err != nil {
+		global.GVA_LOG.Error("审批失败!", zap.Error(err))
+		response.FailWithMessage("审批失败:"+err.Error(), c)
+		return
+	}
+	switch req.Status {
+	case system.ApprovalExecuted:
+		response.OkWithMessage("审批通过, 操作已执行", c)
+	case system.ApprovalFailed:
+		response.FailWithMessage("审批通过, 操作执行失败:"+req.Result, c)
+	case system.ApprovalApproved, system.ApprovalExecuting:
+		response.OkWithMessage("审批通过, 操作执行中", c)
+	default:
+		response.OkWithMessage("审批成功", c)
+	}
+}
+
+// RejectRequest
+// @Tags      Approval
+// @Summary   驳回审批
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.ApprovalAct          true  "审批单ID, 驳回原因"
+// @Success   200   {object}  response.Response{msg=string}  "驳回审批"
+// @Router    /approval/reject [post]
+func (a *ApprovalApi) RejectRequest(c *gin.Context) {
+	var act systemReq.ApprovalAct
+	if err := c.ShouldBindJSON(&act); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := approvalService.Reject(c.Request.Context(), act); err != nil {
+		global.GVA_LOG.Error("驳回失败!", zap.Error(err))
+		response.FailWithMessage("驳回失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("已驳回", c)
+}
+
+// CancelRequest
+// @Tags      Approval
+// @Summary   撤回自己提交的审批单
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "审批单ID"
+// @Success   200   {object}  response.Response{msg=string}  "撤回审批单"
+// @Router    /approval/cancel [post]
+func (a *ApprovalApi) CancelRequest(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := approvalService.Cancel(c.Request.Context(), req.Uint()); err != nil {
+		global.GVA_LOG.Error("撤回失败!", zap.Error(err))
+		response.FailWithMessage("撤回失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("撤回成功", c)
+}
+
+// FindApprovalRequest
+// @Tags      Approval
+// @Summary   获取审批单详情及审批记录
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     request.GetById                                               true  "审批单ID"
+// @Success   200   {object}  response.Response{data=system.SysApprovalRequest,msg=string}  "获取审批单详情"
+// @Router    /approval/findRequest [get]
+func (a *ApprovalApi) FindApprovalRequest(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	detail, err := approvalService.GetRequest(c.Request.Context(), req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(detail, "获取成功", c)
+}
+
+// GetApprovalRequestList
+// @Tags      Approval
+// @Summary   分页获取审批单列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.ApprovalRequestSearch                         true  "页码, 每页大小, 受控操作, 状态, 申请人"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取审批单列表,返回包括列表,总数,页码,每页数量"
+// @Router    /approval/getRequestList [get]
+func (a *ApprovalApi) GetApprovalRequestList(c *gin.Context) {
+	var search systemReq.ApprovalRequestSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := approvalService.GetRequestList(c.Request.Context(), search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// GetMyApprovalRequests
+// @Tags      Approval
+// @Summary   分页获取我提交的审批单
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.ApprovalRequestSearch                         true  "页码, 每页大小, 受控操作, 状态"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取我提交的审批单,返回包括列表,总数,页码,每页数量"
+// @Router    /approval/getMyRequests [get]
+func (a *ApprovalApi) GetMyApprovalRequests(c *gin.Context) {
+	var search systemReq.ApprovalRequestSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := approvalService.GetMyRequests(c.Request.Context(), search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// GetMyApprovalTasks
+// @Tags      Approval
+// @Summary   分页获取待我审批的审批单
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     request.PageInfo                                        true  "页码, 每页大小"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取待我审批的审批单,返回包括列表,总数,页码,每页数量"
+// @Router    /approval/getMyTasks [get]
+func (a *ApprovalApi) GetMyApprovalTasks(c *gin.Context) {
+	var pageInfo request.PageInfo
+	if err := c.ShouldBindQuery(&pageInfo); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := approvalService.GetMyTasks(c.Request.Context(), pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
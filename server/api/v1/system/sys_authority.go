@@ -1,10 +1,13 @@
 package system
 
 import (
+	"errors"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
 
 	"github.com/gin-gonic/gin"
@@ -39,18 +42,21 @@ func (a *AuthorityApi) CreateAuthority(c *gin.Context) {
 	if *authority.ParentId == 0 && global.GVA_CONFIG.System.UseStrictAuth {
 		authority.ParentId = utils.Pointer(utils.GetUserAuthorityId(c))
 	}
+	if global.GVA_CONFIG.Tenant.Enable && !global.GVA_CONFIG.Tenant.IsSuperAuthority(utils.GetUserAuthorityId(c)) {
+		// 非超级管理员创建的角色归属当前租户
+		authority.TenantId = utils.GetTenantId(c)
+	}
 
 	if authBack, err = authorityService.CreateAuthority(authority); err != nil {
+		if errors.Is(err, systemService.ErrCasbinRefresh) {
+			global.GVA_LOG.Error("创建成功，权限刷新失败。", zap.Error(err))
+			response.FailWithMessage("创建成功，权限刷新失败。"+err.Error(), c)
+			return
+		}
 		global.GVA_LOG.Error("创建失败!", zap.Error(err))
 		response.FailWithMessage("创建失败"+err.Error(), c)
 		return
 	}
-	err = casbinService.FreshCasbin()
-	if err != nil {
-		global.GVA_LOG.Error("创建成功，权限刷新失败。", zap.Error(err))
-		response.FailWithMessage("创建成功，权限刷新失败。"+err.Error(), c)
-		return
-	}
 	response.OkWithDetailed(systemRes.SysAuthorityResponse{Authority: authBack}, "创建成功", c)
 }
 
@@ -81,6 +87,9 @@ func (a *AuthorityApi) CopyAuthority(c *gin.Context) {
 		return
 	}
 	adminAuthorityID := utils.GetUserAuthorityId(c)
+	if global.GVA_CONFIG.Tenant.Enable && !global.GVA_CONFIG.Tenant.IsSuperAuthority(adminAuthorityID) {
+		copyInfo.Authority.TenantId = utils.GetTenantId(c)
+	}
 	authBack, err := authorityService.CopyAuthority(adminAuthorityID, copyInfo)
 	if err != nil {
 		global.GVA_LOG.Error("拷贝失败!", zap.Error(err))
@@ -112,11 +121,15 @@ func (a *AuthorityApi) DeleteAuthority(c *gin.Context) {
 	}
 	// 删除角色之前需要判断是否有用户正在使用此角色
 	if err = authorityService.DeleteAuthority(&authority); err != nil {
+		if errors.Is(err, systemService.ErrCasbinRefresh) {
+			global.GVA_LOG.Error("删除成功，权限刷新失败。", zap.Error(err))
+			response.FailWithMessage("删除成功，权限刷新失败。"+err.Error(), c)
+			return
+		}
 		global.GVA_LOG.Error("删除失败!", zap.Error(err))
 		response.FailWithMessage("删除失败"+err.Error(), c)
 		return
 	}
-	_ = casbinService.FreshCasbin()
 	response.OkWithMessage("删除成功", c)
 }
 
@@ -141,8 +154,17 @@ func (a *AuthorityApi) UpdateAuthority(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
+	if global.GVA_CONFIG.Tenant.Enable && !global.GVA_CONFIG.Tenant.IsSuperAuthority(utils.GetUserAuthorityId(c)) {
+		// 只有超级管理员可以变更角色所属租户
+		auth.TenantId = 0
+	}
 	authority, err := authorityService.UpdateAuthority(auth)
 	if err != nil {
+		if errors.Is(err, systemService.ErrCasbinRefresh) {
+			global.GVA_LOG.Error("更新成功，权限刷新失败。", zap.Error(err))
+			response.FailWithMessage("更新成功，权限刷新失败。"+err.Error(), c)
+			return
+		}
 		global.GVA_LOG.Error("更新失败!", zap.Error(err))
 		response.FailWithMessage("更新失败"+err.Error(), c)
 		return
@@ -0,0 +1,62 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AuthorityFieldApi struct{}
+
+// GetAuthorityField
+// @Tags      AuthorityField
+// @Summary   获取角色字段权限
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.SysAuthorityFieldReq                                  true  "角色id, 接口路径或导出表名"
+// @Success   200   {object}  response.Response{data=[]system.SysAuthorityField,msg=string}  "返回列表成功"
+// @Router    /authorityField/getAuthorityField [post]
+func (a *AuthorityFieldApi) GetAuthorityField(c *gin.Context) {
+	var req request.SysAuthorityFieldReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, err := authorityFieldService.GetAuthorityField(utils.GetUserAuthorityId(c), req)
+	if err != nil {
+		global.GVA_LOG.Error("查询失败!", zap.Error(err))
+		response.FailWithMessage("查询失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "查询成功", c)
+}
+
+// SetAuthorityField
+// @Tags      AuthorityField
+// @Summary   设置角色字段权限
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.SysAuthorityFieldReq     true  "角色id, 接口路径或导出表名, 字段规则"
+// @Success   200   {object}  response.Response{msg=string}  "设置成功"
+// @Router    /authorityField/setAuthorityField [post]
+func (a *AuthorityFieldApi) SetAuthorityField(c *gin.Context) {
+	var req request.SysAuthorityFieldReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = authorityFieldService.SetAuthorityField(utils.GetUserAuthorityId(c), req)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
@@ -1,8 +1,11 @@
 package system
 
 import (
+	"fmt"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
@@ -33,6 +36,9 @@ func (cas *CasbinApi) UpdateCasbin(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
+	if submitApproval(c, system.ApprovalActionUpdateCasbin, cmr, fmt.Sprintf("更新角色 %d 的api权限, 共%d条", cmr.AuthorityId, len(cmr.CasbinInfos))) {
+		return
+	}
 	adminAuthorityID := utils.GetUserAuthorityId(c)
 	err = casbinService.UpdateCasbin(adminAuthorityID, cmr.AuthorityId, cmr.CasbinInfos)
 	if err != nil {
@@ -67,3 +73,75 @@ func (cas *CasbinApi) GetPolicyPathByAuthorityId(c *gin.Context) {
 	paths := casbinService.GetPolicyPathByAuthorityId(casbin.AuthorityId)
 	response.OkWithDetailed(systemRes.PolicyPathResponse{Paths: paths}, "获取成功", c)
 }
+
+// SimulateCasbin
+// @Tags      Casbin
+// @Summary   模拟权限判定,解释请求被拒绝的原因
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.CasbinSimulate                                               true  "用户id或角色id, 请求路径, 请求方法"
+// @Success   200   {object}  response.Response{data=systemRes.CasbinSimulateResponse,msg=string}  "返回各角色的判定结果和命中的策略"
+// @Router    /casbin/simulateCasbin [post]
+func (cas *CasbinApi) SimulateCasbin(c *gin.Context) {
+	var req request.CasbinSimulate
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	res, err := casbinService.SimulateCasbin(req)
+	if err != nil {
+		global.GVA_LOG.Error("模拟失败!", zap.Error(err))
+		response.FailWithMessage("模拟失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(res, "获取成功", c)
+}
+
+// DiffCasbin
+// @Tags      Casbin
+// @Summary   预览更新角色api权限的差异
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.CasbinInReceive                                          true  "权限id, 权限模型列表"
+// @Success   200   {object}  response.Response{data=systemRes.CasbinDiffResponse,msg=string}  "返回将新增和移除的权限"
+// @Router    /casbin/diffCasbin [post]
+func (cas *CasbinApi) DiffCasbin(c *gin.Context) {
+	var cmr request.CasbinInReceive
+	err := c.ShouldBindJSON(&cmr)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(cmr, utils.AuthorityIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	res, err := casbinService.DiffCasbin(utils.GetUserAuthorityId(c), cmr.AuthorityId, cmr.CasbinInfos)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(res, "获取成功", c)
+}
+
+// MigrateRoleInheritance
+// @Tags      Casbin
+// @Summary   同步角色继承关系并清理子角色中与上级重复的策略
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=map[string]int,msg=string}  "返回删除的重复策略数量"
+// @Router    /casbin/migrateRoleInheritance [post]
+func (cas *CasbinApi) MigrateRoleInheritance(c *gin.Context) {
+	removed, err := casbinService.MigrateRoleInheritance()
+	if err != nil {
+		global.GVA_LOG.Error("迁移失败!", zap.Error(err))
+		response.FailWithMessage("迁移失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(gin.H{"removed": removed}, "迁移成功", c)
+}
@@ -0,0 +1,87 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DataScopeApi struct{}
+
+// SetDataScope
+// @Tags      DataScope
+// @Summary   设置角色在某张表上的行级数据范围
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysDataScope            true  "角色ID, 表名(*为全部表), 数据范围, 创建者字段"
+// @Success   200   {object}  response.Response{msg=string}  "设置数据范围"
+// @Router    /dataScope/setDataScope [post]
+func (d *DataScopeApi) SetDataScope(c *gin.Context) {
+	var rule system.SysDataScope
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := utils.Verify(rule, utils.AuthorityIdVerify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := dataScopeService.SetDataScope(utils.GetUserAuthorityId(c), rule); err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// DeleteDataScope
+// @Tags      DataScope
+// @Summary   删除行级数据范围规则
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "规则ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除数据范围"
+// @Router    /dataScope/deleteDataScope [post]
+func (d *DataScopeApi) DeleteDataScope(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := dataScopeService.DeleteDataScope(utils.GetUserAuthorityId(c), req.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// GetDataScopes
+// @Tags      DataScope
+// @Summary   获取角色的行级数据范围规则
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  query     systemReq.GetDataScopes                                true  "角色ID"
+// @Success   200   {object}  response.Response{data=[]system.SysDataScope,msg=string}  "获取数据范围规则"
+// @Router    /dataScope/getDataScopes [get]
+func (d *DataScopeApi) GetDataScopes(c *gin.Context) {
+	var req systemReq.GetDataScopes
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, err := dataScopeService.GetDataScopes(utils.GetUserAuthorityId(c), req.AuthorityId)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
@@ -0,0 +1,250 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DepartmentApi struct{}
+
+// CreateDepartment
+// @Tags      Department
+// @Summary   创建部门
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysDepartment                                true  "父部门ID, 部门名称, 排序"
+// @Success   200   {object}  response.Response{data=system.SysDepartment,msg=string}  "创建部门"
+// @Router    /department/createDepartment [post]
+func (d *DepartmentApi) CreateDepartment(c *gin.Context) {
+	var dept system.SysDepartment
+	if err := c.ShouldBindJSON(&dept); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if dept.Name == "" {
+		response.FailWithMessage("部门名称不能为空", c)
+		return
+	}
+	if err := departmentService.CreateDepartment(&dept); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(dept, "创建成功", c)
+}
+
+// UpdateDepartment
+// @Tags      Department
+// @Summary   更新部门名称和排序
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysDepartment           true  "部门ID, 部门名称, 排序"
+// @Success   200   {object}  response.Response{msg=string}  "更新部门"
+// @Router    /department/updateDepartment [put]
+func (d *DepartmentApi) UpdateDepartment(c *gin.Context) {
+	var dept system.SysDepartment
+	if err := c.ShouldBindJSON(&dept); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if dept.Name == "" {
+		response.FailWithMessage("部门名称不能为空", c)
+		return
+	}
+	if err := departmentService.UpdateDepartment(dept); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteDepartment
+// @Tags      Department
+// @Summary   删除部门
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "部门ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除部门"
+// @Router    /department/deleteDepartment [delete]
+func (d *DepartmentApi) DeleteDepartment(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := departmentService.DeleteDepartment(req.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// MoveDepartment
+// @Tags      Department
+// @Summary   移动部门
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.MoveDepartment       true  "部门ID, 新的父部门ID"
+// @Success   200   {object}  response.Response{msg=string}  "移动部门"
+// @Router    /department/moveDepartment [post]
+func (d *DepartmentApi) MoveDepartment(c *gin.Context) {
+	var req systemReq.MoveDepartment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := departmentService.MoveDepartment(req); err != nil {
+		global.GVA_LOG.Error("移动失败!", zap.Error(err))
+		response.FailWithMessage("移动失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("移动成功", c)
+}
+
+// MergeDepartment
+// @Tags      Department
+// @Summary   合并部门
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.MergeDepartment      true  "源部门ID, 目标部门ID"
+// @Success   200   {object}  response.Response{msg=string}  "合并部门"
+// @Router    /department/mergeDepartment [post]
+func (d *DepartmentApi) MergeDepartment(c *gin.Context) {
+	var req systemReq.MergeDepartment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := departmentService.MergeDepartment(req); err != nil {
+		global.GVA_LOG.Error("合并失败!", zap.Error(err))
+		response.FailWithMessage("合并失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("合并成功", c)
+}
+
+// GetDepartmentTree
+// @Tags      Department
+// @Summary   获取部门树
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]system.SysDepartment,msg=string}  "获取部门树 包含各部门负责人"
+// @Router    /department/getDepartmentTree [get]
+func (d *DepartmentApi) GetDepartmentTree(c *gin.Context) {
+	list, err := departmentService.GetDepartmentTree(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// GetDepartmentUsers
+// @Tags      Department
+// @Summary   分页获取部门成员
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  query     systemReq.DepartmentUserSearch                        true  "部门ID, 页码, 每页大小"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取部门成员"
+// @Router    /department/getDepartmentUsers [get]
+func (d *DepartmentApi) GetDepartmentUsers(c *gin.Context) {
+	var req systemReq.DepartmentUserSearch
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := departmentService.GetDepartmentUsers(c.Request.Context(), req)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, "获取成功", c)
+}
+
+// GetUserDepartments
+// @Tags      Department
+// @Summary   获取用户所属部门
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  query     request.GetById                                              true  "用户ID"
+// @Success   200   {object}  response.Response{data=[]system.SysUserDepartment,msg=string}  "获取用户所属部门"
+// @Router    /department/getUserDepartments [get]
+func (d *DepartmentApi) GetUserDepartments(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, err := departmentService.GetUserDepartments(req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// SetUserDepartments
+// @Tags      Department
+// @Summary   设置用户的主部门和兼职部门
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.SetUserDepartments   true  "用户ID, 主部门ID, 兼职部门ID"
+// @Success   200   {object}  response.Response{msg=string}  "设置用户部门"
+// @Router    /department/setUserDepartments [post]
+func (d *DepartmentApi) SetUserDepartments(c *gin.Context) {
+	var req systemReq.SetUserDepartments
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := departmentService.SetUserDepartments(c.Request.Context(), req); err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// SetDepartmentLeaders
+// @Tags      Department
+// @Summary   设置部门负责人
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.SetDepartmentLeaders  true  "部门ID, 负责人用户ID"
+// @Success   200   {object}  response.Response{msg=string}   "设置部门负责人"
+// @Router    /department/setDepartmentLeaders [post]
+func (d *DepartmentApi) SetDepartmentLeaders(c *gin.Context) {
+	var req systemReq.SetDepartmentLeaders
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := departmentService.SetDepartmentLeaders(c.Request.Context(), req); err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
@@ -1,7 +1,10 @@
 package system
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"sync"
@@ -236,6 +239,7 @@ func (sysExportTemplateApi *SysExportTemplateApi) ExportExcel(c *gin.Context) {
 	exportParams := map[string]interface{}{
 		"templateID":  templateID,
 		"queryParams": queryParams,
+		"claims":      utils.GetUserInfo(c), // 下载链接不经过鉴权 导出时按申请人的数据权限过滤
 	}
 
 	// 参数保留记录完成鉴权
@@ -286,6 +290,10 @@ func (sysExportTemplateApi *SysExportTemplateApi) ExportExcelByToken(c *gin.Cont
 	// 获取导出参数
 	templateID := exportParams["templateID"].(string)
 	queryParams := exportParams["queryParams"].(url.Values)
+	ctx := c.Request.Context()
+	if claims, ok := exportParams["claims"].(*systemReq.CustomClaims); ok && claims != nil {
+		ctx = utils.ContextWithClaims(ctx, claims)
+	}
 
 	// 清理一次性token
 	tokenMutex.Lock()
@@ -294,7 +302,7 @@ func (sysExportTemplateApi *SysExportTemplateApi) ExportExcelByToken(c *gin.Cont
 	tokenMutex.Unlock()
 
 	// 导出
-	if file, name, err := sysExportTemplateService.ExportExcel(templateID, queryParams); err != nil {
+	if file, name, err := sysExportTemplateService.ExportExcel(ctx, templateID, queryParams); err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
 	} else {
@@ -419,10 +427,30 @@ func (sysExportTemplateApi *SysExportTemplateApi) ImportExcel(c *gin.Context) {
 		response.FailWithMessage("文件获取失败", c)
 		return
 	}
-	if err := sysExportTemplateService.ImportExcel(templateID, file); err != nil {
+	data, err := readFormFile(file)
+	if err != nil {
+		global.GVA_LOG.Error("文件读取失败!", zap.Error(err))
+		response.FailWithMessage("文件读取失败", c)
+		return
+	}
+	payload := systemReq.ApprovalImportExcel{TemplateID: templateID, FileName: file.Filename, File: data}
+	if submitApproval(c, system.ApprovalActionImportExcel, payload, fmt.Sprintf("使用模板 %s 导入表格 %s", templateID, file.Filename)) {
+		return
+	}
+	if err := sysExportTemplateService.ImportExcelData(templateID, bytes.NewReader(data)); err != nil {
 		global.GVA_LOG.Error(err.Error(), zap.Error(err))
 		response.FailWithMessage(err.Error(), c)
 	} else {
 		response.OkWithMessage("导入成功", c)
 	}
 }
+
+// readFormFile 读取上传文件内容 需要审批时随审批单保存
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return io.ReadAll(src)
+}
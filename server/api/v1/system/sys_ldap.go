@@ -0,0 +1,44 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type LdapApi struct{}
+
+// SyncPreview
+// @Tags      Ldap
+// @Summary   预览LDAP同步差异
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=systemRes.LdapSyncDiff,msg=string}  "返回将要新建、更新、冻结的用户"
+// @Router    /ldap/syncPreview [get]
+func (l *LdapApi) SyncPreview(c *gin.Context) {
+	diff, err := ldapService.Sync(true)
+	if err != nil {
+		global.GVA_LOG.Error("获取同步差异失败!", zap.Error(err))
+		response.FailWithMessage("获取同步差异失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(diff, "获取成功", c)
+}
+
+// Sync
+// @Tags      Ldap
+// @Summary   立即执行LDAP同步
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=systemRes.LdapSyncDiff,msg=string}  "返回已执行的变更"
+// @Router    /ldap/sync [post]
+func (l *LdapApi) Sync(c *gin.Context) {
+	diff, err := ldapService.Sync(false)
+	if err != nil {
+		global.GVA_LOG.Error("同步失败!", zap.Error(err))
+		response.FailWithMessage("同步失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(diff, "同步成功", c)
+}
@@ -0,0 +1,329 @@
+package system
+
+import (
+	"io"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// notificationHeartbeat SSE心跳间隔 防止代理因连接空闲断开
+const notificationHeartbeat = 30 * time.Second
+
+type NotificationApi struct{}
+
+// Stream
+// @Tags      Notification
+// @Summary   订阅实时站内信(SSE) 连接建立后先推送unread事件, 新站内信推送notification事件
+// @Security  ApiKeyAuth
+// @Produce   text/event-stream
+// @Success   200  {object}  system.SysNotification  "新站内信"
+// @Router    /notification/stream [get]
+func (n *NotificationApi) Stream(c *gin.Context) {
+	claims := utils.GetUserInfo(c)
+	if claims == nil {
+		response.NoAuth("未登录或非法访问", c)
+		return
+	}
+	userId := claims.BaseClaims.ID
+	authorized := n.streamAuthorized(c, claims)
+	ch, cancel := notificationService.Subscribe(userId)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	if unread, err := notificationService.GetUnread(userId); err == nil {
+		c.SSEvent("unread", unread)
+		c.Writer.Flush()
+	}
+
+	ticker := time.NewTicker(notificationHeartbeat)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case msg := <-ch:
+			c.SSEvent("notification", msg)
+		case <-ticker.C:
+			// 长连接不再经过 JWTAuth 心跳时重新校验 jwt到期、退出登录或会话被吊销后关闭连接
+			if !authorized() {
+				c.SSEvent("logout", "登录已失效,请重新登录")
+				return false
+			}
+			c.SSEvent("ping", time.Now().Unix())
+		}
+		return true
+	})
+}
+
+// streamAuthorized 返回校验建立连接时的登录状态是否仍然有效的函数 个人访问令牌不受jwt吊销影响
+func (n *NotificationApi) streamAuthorized(c *gin.Context, claims *systemReq.CustomClaims) func() bool {
+	if _, ok := c.Get("apiTokenId"); ok {
+		return func() bool { return true }
+	}
+	token := utils.GetToken(c)
+	return func() bool {
+		if claims.ExpiresAt != nil && !claims.ExpiresAt.After(time.Now()) {
+			return false
+		}
+		return !jwtService.IsBlacklist(token) && !jwtService.IsRevoked(claims)
+	}
+}
+
+// GetMyNotifications
+// @Tags      Notification
+// @Summary   分页获取我的站内信
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.NotificationSearch                            true  "页码, 每页大小, 分类, 只看未读"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取我的站内信,返回包括列表,总数,页码,每页数量"
+// @Router    /notification/getMyNotifications [get]
+func (n *NotificationApi) GetMyNotifications(c *gin.Context) {
+	var search systemReq.NotificationSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := notificationService.GetMyNotifications(utils.GetUserID(c), search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// GetUnread
+// @Tags      Notification
+// @Summary   获取未读站内信数量
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=systemRes.NotificationUnread,msg=string}  "获取未读站内信数量"
+// @Router    /notification/getUnread [get]
+func (n *NotificationApi) GetUnread(c *gin.Context) {
+	unread, err := notificationService.GetUnread(utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(unread, "获取成功", c)
+}
+
+// MarkRead
+// @Tags      Notification
+// @Summary   标记站内信已读
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.NotificationRead     true  "站内信ID列表, 全部已读, 分类"
+// @Success   200   {object}  response.Response{msg=string}  "标记站内信已读"
+// @Router    /notification/markRead [post]
+func (n *NotificationApi) MarkRead(c *gin.Context) {
+	var req systemReq.NotificationRead
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := notificationService.MarkRead(utils.GetUserID(c), req); err != nil {
+		global.GVA_LOG.Error("操作失败!", zap.Error(err))
+		response.FailWithMessage("操作失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("操作成功", c)
+}
+
+// DeleteMyNotifications
+// @Tags      Notification
+// @Summary   删除我的站内信
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.NotificationRead     true  "站内信ID列表, 全部, 分类"
+// @Success   200   {object}  response.Response{msg=string}  "删除我的站内信"
+// @Router    /notification/deleteMyNotifications [delete]
+func (n *NotificationApi) DeleteMyNotifications(c *gin.Context) {
+	var req systemReq.NotificationRead
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := notificationService.DeleteMyNotifications(utils.GetUserID(c), req); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// SendNotification
+// @Tags      Notification
+// @Summary   发送站内信 指定模板时按模板渲染, 否则直接使用标题和内容
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.NotificationSend     true  "接收人, 模板编码, 模板数据, 分类, 标题, 内容, 跳转地址"
+// @Success   200   {object}  response.Response{msg=string}  "发送站内信"
+// @Router    /notification/send [post]
+func (n *NotificationApi) SendNotification(c *gin.Context) {
+	var req systemReq.NotificationSend
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	var err error
+	if req.TemplateCode != "" {
+		err = notificationService.SendToUsers(req.UserIds, req.TemplateCode, req.Data)
+	} else {
+		err = notificationService.Push(req.UserIds, system.SysNotification{
+			Category: req.Category,
+			Title:    req.Title,
+			Content:  req.Content,
+			Link:     req.Link,
+		})
+	}
+	if err != nil {
+		global.GVA_LOG.Error("发送失败!", zap.Error(err))
+		response.FailWithMessage("发送失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("发送成功", c)
+}
+
+// CreateNotificationTemplate
+// @Tags      Notification
+// @Summary   创建站内信模板
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysNotificationTemplate                                     true  "模板编码, 名称, 分类, 标题, 内容, 跳转地址"
+// @Success   200   {object}  response.Response{data=system.SysNotificationTemplate,msg=string}  "创建站内信模板"
+// @Router    /notification/createTemplate [post]
+func (n *NotificationApi) CreateNotificationTemplate(c *gin.Context) {
+	var tpl system.SysNotificationTemplate
+	if err := c.ShouldBindJSON(&tpl); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := notificationService.CreateTemplate(&tpl); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(tpl, "创建成功", c)
+}
+
+// UpdateNotificationTemplate
+// @Tags      Notification
+// @Summary   更新站内信模板
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysNotificationTemplate  true  "模板ID, 名称, 分类, 标题, 内容, 跳转地址"
+// @Success   200   {object}  response.Response{msg=string}   "更新站内信模板"
+// @Router    /notification/updateTemplate [put]
+func (n *NotificationApi) UpdateNotificationTemplate(c *gin.Context) {
+	var tpl system.SysNotificationTemplate
+	if err := c.ShouldBindJSON(&tpl); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := notificationService.UpdateTemplate(tpl); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteNotificationTemplate
+// @Tags      Notification
+// @Summary   删除站内信模板
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "模板ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除站内信模板"
+// @Router    /notification/deleteTemplate [delete]
+func (n *NotificationApi) DeleteNotificationTemplate(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := notificationService.DeleteTemplate(req.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// FindNotificationTemplate
+// @Tags      Notification
+// @Summary   获取站内信模板
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     request.GetById                                                    true  "模板ID"
+// @Success   200   {object}  response.Response{data=system.SysNotificationTemplate,msg=string}  "获取站内信模板"
+// @Router    /notification/findTemplate [get]
+func (n *NotificationApi) FindNotificationTemplate(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	tpl, err := notificationService.GetTemplate(req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(tpl, "获取成功", c)
+}
+
+// GetNotificationTemplateList
+// @Tags      Notification
+// @Summary   分页获取站内信模板列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.NotificationTemplateSearch                    true  "页码, 每页大小, 模板编码, 名称, 分类"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取站内信模板列表,返回包括列表,总数,页码,每页数量"
+// @Router    /notification/getTemplateList [get]
+func (n *NotificationApi) GetNotificationTemplateList(c *gin.Context) {
+	var search systemReq.NotificationTemplateSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := notificationService.GetTemplateList(search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
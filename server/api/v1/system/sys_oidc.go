@@ -0,0 +1,61 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OidcAuthorize
+// @Tags     Base
+// @Summary  获取OIDC单点登录授权地址
+// @Produce  application/json
+// @Success  200  {object}  response.Response{data=systemRes.OidcAuthorizeResponse,msg=string}  "返回身份提供方授权地址"
+// @Router   /base/oidc/authorize [get]
+func (b *BaseApi) OidcAuthorize(c *gin.Context) {
+	authURL, err := oidcService.AuthCodeURL(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取授权地址失败!", zap.Error(err))
+		response.FailWithMessage("获取授权地址失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.OidcAuthorizeResponse{AuthURL: authURL}, "获取成功", c)
+}
+
+// OidcCallback
+// @Tags     Base
+// @Summary  OIDC单点登录回调
+// @Produce  application/json
+// @Param    data  query     systemReq.OidcCallback                                      true  "授权码, state"
+// @Success  200   {object}  response.Response{data=systemRes.LoginResponse,msg=string}  "返回包括用户信息,token,过期时间"
+// @Router   /base/oidc/callback [get]
+func (b *BaseApi) OidcCallback(c *gin.Context) {
+	var req systemReq.OidcCallback
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if req.Error != "" {
+		response.FailWithMessage("单点登录失败:"+req.Error+" "+req.ErrorDescription, c)
+		return
+	}
+	if req.Code == "" || req.State == "" {
+		response.FailWithMessage("缺少授权码或state", c)
+		return
+	}
+	user, err := oidcService.Login(c.Request.Context(), req.State, req.Code)
+	if err != nil {
+		global.GVA_LOG.Error("单点登录失败!", zap.Error(err))
+		response.FailWithMessage("单点登录失败:"+err.Error(), c)
+		return
+	}
+	if user.Enable != 1 {
+		global.GVA_LOG.Error("登陆失败! 用户被禁止登录!")
+		response.FailWithMessage("用户被禁止登录", c)
+		return
+	}
+	b.TokenNext(c, *user)
+}
@@ -0,0 +1,69 @@
+package system
+
+import (
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ForgotPassword
+// @Tags     Base
+// @Summary  申请找回密码 向账号绑定的邮箱发送重置链接
+// @accept   application/json
+// @Produce  application/json
+// @Param    data  body      systemReq.ForgotPassword       true  "用户名或邮箱"
+// @Success  200   {object}  response.Response{msg=string}  "无论账号是否存在均返回成功"
+// @Router   /base/forgotPassword [post]
+func (b *BaseApi) ForgotPassword(c *gin.Context) {
+	var req systemReq.ForgotPassword
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := utils.Verify(req, utils.ForgotPasswordVerify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err := passwordResetService.SendResetLink(req.Account, c.ClientIP())
+	if errors.Is(err, systemService.ErrPasswordResetDisabled) || errors.Is(err, systemService.ErrPasswordResetLimited) {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err != nil {
+		// 不向调用方暴露内部错误 避免借此判断账号是否存在
+		global.GVA_LOG.Error("申请找回密码失败!", zap.Error(err))
+	}
+	response.OkWithMessage("如果该账号存在且已绑定邮箱,重置链接已发送,请查收邮件", c)
+}
+
+// ResetPasswordByToken
+// @Tags     Base
+// @Summary  通过邮件中的链接重置密码
+// @accept   application/json
+// @Produce  application/json
+// @Param    data  body      systemReq.ResetPasswordByToken  true  "令牌, 新密码"
+// @Success  200   {object}  response.Response{msg=string}   "重置密码 成功后需重新登录"
+// @Router   /base/resetPassword [post]
+func (b *BaseApi) ResetPasswordByToken(c *gin.Context) {
+	var req systemReq.ResetPasswordByToken
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := utils.Verify(req, utils.ResetByTokenVerify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := passwordResetService.ResetPasswordByToken(req.Token, req.NewPassword); err != nil {
+		global.GVA_LOG.Error("重置密码失败!", zap.Error(err))
+		response.FailWithMessage("重置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("密码已重置,请使用新密码登录", c)
+}
@@ -0,0 +1,120 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type RbacApi struct{}
+
+// ExportRbac
+// @Tags      Rbac
+// @Summary   导出角色、菜单、按钮、api 与 casbin 策略配置包
+// @Security  ApiKeyAuth
+// @Produce   application/octet-stream
+// @Param     format  query     string  false  "json(默认) 或 yaml"
+// @Success   200     {file}    file    "配置包文件"
+// @Router    /rbac/exportRbac [get]
+func (r *RbacApi) ExportRbac(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "yaml" {
+		response.FailWithMessage("format 只支持 json 或 yaml", c)
+		return
+	}
+	bundle, err := rbacService.ExportRbac()
+	if err != nil {
+		global.GVA_LOG.Error("导出失败!", zap.Error(err))
+		response.FailWithMessage("导出失败:"+err.Error(), c)
+		return
+	}
+	data, err := rbacService.EncodeRbacBundle(bundle, format)
+	if err != nil {
+		global.GVA_LOG.Error("导出失败!", zap.Error(err))
+		response.FailWithMessage("导出失败:"+err.Error(), c)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=rbac-%s.%s", time.Now().Format("20060102150405"), format))
+	c.Data(200, "application/octet-stream", data)
+}
+
+// PreviewRbacImport
+// @Tags      Rbac
+// @Summary   预览导入配置包与当前环境的差异
+// @Security  ApiKeyAuth
+// @accept    multipart/form-data
+// @Produce   application/json
+// @Param     file      formData  file    true   "json 或 yaml 配置包"
+// @Param     strategy  formData  string  false  "冲突处理策略 skip|overwrite|merge"
+// @Success   200       {object}  response.Response{data=systemRes.RbacImportResult,msg=string}  "差异预览"
+// @Router    /rbac/previewRbacImport [post]
+func (r *RbacApi) PreviewRbacImport(c *gin.Context) {
+	r.importRbac(c, false)
+}
+
+// ImportRbac
+// @Tags      Rbac
+// @Summary   按冲突处理策略导入配置包 在事务中执行
+// @Security  ApiKeyAuth
+// @accept    multipart/form-data
+// @Produce   application/json
+// @Param     file      formData  file    true   "json 或 yaml 配置包"
+// @Param     strategy  formData  string  false  "冲突处理策略 skip|overwrite|merge"
+// @Success   200       {object}  response.Response{data=systemRes.RbacImportResult,msg=string}  "导入结果"
+// @Router    /rbac/importRbac [post]
+func (r *RbacApi) ImportRbac(c *gin.Context) {
+	r.importRbac(c, true)
+}
+
+func (r *RbacApi) importRbac(c *gin.Context, apply bool) {
+	var req systemReq.RbacImport
+	if err := c.ShouldBind(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		response.FailWithMessage("请上传配置包文件", c)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	bundle, err := rbacService.DecodeRbacBundle(data)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	result, err := rbacService.ImportRbac(bundle, req.Strategy, apply)
+	if errors.Is(err, systemService.ErrCasbinRefresh) {
+		global.GVA_LOG.Error("导入成功，权限刷新失败。", zap.Error(err))
+		response.FailWithDetailed(result, "导入成功，权限刷新失败。"+err.Error(), c)
+		return
+	}
+	if err != nil {
+		global.GVA_LOG.Error("导入失败!", zap.Error(err))
+		response.FailWithMessage("导入失败:"+err.Error(), c)
+		return
+	}
+	if !apply {
+		response.OkWithDetailed(result, "获取成功", c)
+		return
+	}
+	response.OkWithDetailed(result, "导入成功", c)
+}
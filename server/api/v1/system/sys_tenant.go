@@ -0,0 +1,135 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TenantApi struct{}
+
+// CreateTenant
+// @Tags      Tenant
+// @Summary   创建租户
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysTenant                                     true  "租户编码, 租户名称, 备注"
+// @Success   200   {object}  response.Response{data=system.SysTenant,msg=string}  "创建租户"
+// @Router    /tenant/createTenant [post]
+func (t *TenantApi) CreateTenant(c *gin.Context) {
+	var tenant system.SysTenant
+	if err := c.ShouldBindJSON(&tenant); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := tenantService.CreateTenant(&tenant); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(tenant, "创建成功", c)
+}
+
+// UpdateTenant
+// @Tags      Tenant
+// @Summary   更新租户名称、状态和备注
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysTenant               true  "租户ID, 租户名称, 状态, 备注"
+// @Success   200   {object}  response.Response{msg=string}  "更新租户"
+// @Router    /tenant/updateTenant [put]
+func (t *TenantApi) UpdateTenant(c *gin.Context) {
+	var tenant system.SysTenant
+	if err := c.ShouldBindJSON(&tenant); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := tenantService.UpdateTenant(tenant); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteTenant
+// @Tags      Tenant
+// @Summary   删除租户
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "租户ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除租户"
+// @Router    /tenant/deleteTenant [delete]
+func (t *TenantApi) DeleteTenant(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := tenantService.DeleteTenant(req.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// FindTenant
+// @Tags      Tenant
+// @Summary   获取租户
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     request.GetById                                      true  "租户ID"
+// @Success   200   {object}  response.Response{data=system.SysTenant,msg=string}  "获取租户"
+// @Router    /tenant/findTenant [get]
+func (t *TenantApi) FindTenant(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	tenant, err := tenantService.GetTenant(req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(tenant, "获取成功", c)
+}
+
+// GetTenantList
+// @Tags      Tenant
+// @Summary   分页获取租户列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.TenantSearch                                  true  "页码, 每页大小, 租户编码, 租户名称"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取租户列表,返回包括列表,总数,页码,每页数量"
+// @Router    /tenant/getTenantList [get]
+func (t *TenantApi) GetTenantList(c *gin.Context) {
+	var search systemReq.TenantSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := tenantService.GetTenantList(search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
@@ -70,7 +71,12 @@ func (b *BaseApi) Login(c *gin.Context) {
 
 	if !oc || (l.CaptchaId != "" && l.Captcha != "" && store.Verify(l.CaptchaId, l.Captcha, true)) {
 		u := &system.SysUser{Username: l.Username, Password: l.Password}
-		user, err := userService.Login(u)
+		var user *system.SysUser
+		if global.GVA_CONFIG.Ldap.Enable {
+			user, err = ldapService.Login(u) // 目录中不存在的用户会回退到本地账号
+		} else {
+			user, err = userService.Login(u)
+		}
 		if err != nil {
 			global.GVA_LOG.Error("登陆失败! 用户名不存在或者密码错误!", zap.Error(err))
 			// 验证码次数+1
@@ -95,6 +101,13 @@ func (b *BaseApi) Login(c *gin.Context) {
 
 // TokenNext 登录以后签发jwt
 func (b *BaseApi) TokenNext(c *gin.Context, user system.SysUser) {
+	if global.GVA_CONFIG.Tenant.Enable {
+		// 通过租户子域名登录时只允许该租户的用户 同时拦截已停用租户的用户
+		if _, err := tenantService.ResolveScope(user.AuthorityId, user.TenantId, c.Request.Host, ""); err != nil {
+			response.FailWithMessage(err.Error(), c)
+			return
+		}
+	}
 	token, claims, err := utils.LoginToken(&user)
 	if err != nil {
 		global.GVA_LOG.Error("获取token失败!", zap.Error(err))
@@ -102,12 +115,7 @@ func (b *BaseApi) TokenNext(c *gin.Context, user system.SysUser) {
 		return
 	}
 	if !global.GVA_CONFIG.System.UseMultipoint {
-		utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
-		response.OkWithDetailed(systemRes.LoginResponse{
-			User:      user,
-			Token:     token,
-			ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
-		}, "登录成功", c)
+		b.loginSucceeded(c, user, token, claims)
 		return
 	}
 
@@ -117,12 +125,7 @@ func (b *BaseApi) TokenNext(c *gin.Context, user system.SysUser) {
 			response.FailWithMessage("设置登录状态失败", c)
 			return
 		}
-		utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
-		response.OkWithDetailed(systemRes.LoginResponse{
-			User:      user,
-			Token:     token,
-			ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
-		}, "登录成功", c)
+		b.loginSucceeded(c, user, token, claims)
 	} else if err != nil {
 		global.GVA_LOG.Error("设置登录状态失败!", zap.Error(err))
 		response.FailWithMessage("设置登录状态失败", c)
@@ -137,15 +140,21 @@ func (b *BaseApi) TokenNext(c *gin.Context, user system.SysUser) {
 			response.FailWithMessage("设置登录状态失败", c)
 			return
 		}
-		utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
-		response.OkWithDetailed(systemRes.LoginResponse{
-			User:      user,
-			Token:     token,
-			ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
-		}, "登录成功", c)
+		b.loginSucceeded(c, user, token, claims)
 	}
 }
 
+// loginSucceeded 返回token并发布登录事件
+func (b *BaseApi) loginSucceeded(c *gin.Context, user system.SysUser, token string, claims systemReq.CustomClaims) {
+	utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
+	response.OkWithDetailed(systemRes.LoginResponse{
+		User:      user,
+		Token:     token,
+		ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+	}, "登录成功", c)
+	userService.LoginSucceeded(c.Request.Context(), user, c.ClientIP(), c.Request.UserAgent())
+}
+
 // Register
 // @Tags     SysUser
 // @Summary  用户注册账号
@@ -172,10 +181,11 @@ func (b *BaseApi) Register(c *gin.Context) {
 		})
 	}
 	user := &system.SysUser{Username: r.Username, NickName: r.NickName, Password: r.Password, HeaderImg: r.HeaderImg, AuthorityId: r.AuthorityId, Authorities: authorities, Enable: r.Enable, Phone: r.Phone, Email: r.Email}
-	userReturn, err := userService.Register(*user)
+	user.TenantId = r.TenantId
+	userReturn, err := userService.Register(c.Request.Context(), *user)
 	if err != nil {
 		global.GVA_LOG.Error("注册失败!", zap.Error(err))
-		response.FailWithDetailed(systemRes.SysUserResponse{User: userReturn}, "注册失败", c)
+		response.FailWithDetailed(systemRes.SysUserResponse{User: userReturn}, "注册失败:"+err.Error(), c)
 		return
 	}
 	response.OkWithDetailed(systemRes.SysUserResponse{User: userReturn}, "注册成功", c)
@@ -203,12 +213,29 @@ func (b *BaseApi) ChangePassword(c *gin.Context) {
 	}
 	uid := utils.GetUserID(c)
 	u := &system.SysUser{GVA_MODEL: global.GVA_MODEL{ID: uid}, Password: req.Password}
-	_, err = userService.ChangePassword(u, req.NewPassword)
+	user, err := userService.ChangePassword(u, req.NewPassword)
 	if err != nil {
 		global.GVA_LOG.Error("修改失败!", zap.Error(err))
-		response.FailWithMessage("修改失败，原密码与当前账户不符", c)
+		response.FailWithMessage("修改失败:"+err.Error(), c)
 		return
 	}
+	// 密码过期状态记录在token中 修改成功后换发新token解除限制
+	if claims := utils.GetUserInfo(c); claims != nil && claims.PasswordExpired {
+		user.AuthorityId = claims.AuthorityId
+		token, newClaims, err := utils.LoginToken(user)
+		if err != nil {
+			global.GVA_LOG.Error("获取token失败!", zap.Error(err))
+			response.FailWithMessage("修改成功,请重新登录", c)
+			return
+		}
+		maxAge := int(newClaims.RegisteredClaims.ExpiresAt.Unix() - time.Now().Unix())
+		if global.GVA_CONFIG.System.UseMultipoint {
+			_ = jwtService.SetRedisJWT(token, user.Username)
+		}
+		utils.SetToken(c, token, maxAge)
+		c.Set("new-token", token)
+		c.Set("new-expires-at", strconv.FormatInt(newClaims.RegisteredClaims.ExpiresAt.Unix(), 10))
+	}
 	response.OkWithMessage("修改成功", c)
 }
 
@@ -233,7 +260,7 @@ func (b *BaseApi) GetUserList(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	list, total, err := userService.GetUserInfoList(pageInfo)
+	list, total, err := userService.GetUserInfoList(c.Request.Context(), pageInfo)
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
@@ -304,8 +331,11 @@ func (b *BaseApi) SetUserAuthorities(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
+	if submitApproval(c, system.ApprovalActionSetUserAuthorities, sua, fmt.Sprintf("设置用户 ID:%d 的角色为 %v", sua.ID, sua.AuthorityIds)) {
+		return
+	}
 	authorityID := utils.GetUserAuthorityId(c)
-	err = userService.SetUserAuthorities(authorityID, sua.ID, sua.AuthorityIds)
+	err = userService.SetUserAuthorities(c.Request.Context(), authorityID, sua.ID, sua.AuthorityIds)
 	if err != nil {
 		global.GVA_LOG.Error("修改失败!", zap.Error(err))
 		response.FailWithMessage("修改失败", c)
@@ -340,7 +370,10 @@ func (b *BaseApi) DeleteUser(c *gin.Context) {
 		response.FailWithMessage("删除失败, 无法删除自己。", c)
 		return
 	}
-	err = userService.DeleteUser(reqId.ID)
+	if submitApproval(c, system.ApprovalActionDeleteUser, reqId, fmt.Sprintf("删除用户 ID:%d", reqId.ID)) {
+		return
+	}
+	err = userService.DeleteUser(c.Request.Context(), reqId.ID)
 	if err != nil {
 		global.GVA_LOG.Error("删除失败!", zap.Error(err))
 		response.FailWithMessage("删除失败", c)
@@ -372,14 +405,14 @@ func (b *BaseApi) SetUserInfo(c *gin.Context) {
 	}
 	if len(user.AuthorityIds) != 0 {
 		authorityID := utils.GetUserAuthorityId(c)
-		err = userService.SetUserAuthorities(authorityID, user.ID, user.AuthorityIds)
+		err = userService.SetUserAuthorities(c.Request.Context(), authorityID, user.ID, user.AuthorityIds)
 		if err != nil {
 			global.GVA_LOG.Error("设置失败!", zap.Error(err))
 			response.FailWithMessage("设置失败", c)
 			return
 		}
 	}
-	err = userService.SetUserInfo(system.SysUser{
+	err = userService.SetUserInfo(c.Request.Context(), system.SysUser{
 		GVA_MODEL: global.GVA_MODEL{
 			ID: user.ID,
 		},
@@ -492,10 +525,10 @@ func (b *BaseApi) ResetPassword(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = userService.ResetPassword(rps.ID, rps.Password)
+	err = userService.ResetPassword(c.Request.Context(), rps.ID, rps.Password)
 	if err != nil {
 		global.GVA_LOG.Error("重置失败!", zap.Error(err))
-		response.FailWithMessage("重置失败"+err.Error(), c)
+		response.FailWithMessage("重置失败:"+err.Error(), c)
 		return
 	}
 	response.OkWithMessage("重置成功", c)
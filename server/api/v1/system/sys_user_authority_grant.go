@@ -0,0 +1,150 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AuthorityGrantApi struct{}
+
+// CreateAuthorityGrant
+// @Tags      AuthorityGrant
+// @Summary   管理员为用户授予限时角色
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.CreateAuthorityGrant                                    true  "用户ID, 角色ID, 生效时间, 失效时间, 原因"
+// @Success   200   {object}  response.Response{data=system.SysUserAuthorityGrant,msg=string}  "授予限时角色"
+// @Router    /authorityGrant/createAuthorityGrant [post]
+func (a *AuthorityGrantApi) CreateAuthorityGrant(c *gin.Context) {
+	var req systemReq.CreateAuthorityGrant
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	grant, err := authorityGrantService.CreateGrant(c.Request.Context(), utils.GetUserAuthorityId(c), utils.GetUserID(c), req)
+	if err != nil {
+		global.GVA_LOG.Error("授权失败!", zap.Error(err))
+		response.FailWithMessage("授权失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(grant, "授权成功", c)
+}
+
+// DelegateAuthority
+// @Tags      AuthorityGrant
+// @Summary   将自己的角色在一段时间内委托给其他用户
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.CreateAuthorityGrant                                    true  "被委托用户ID, 角色ID, 生效时间, 失效时间, 原因"
+// @Success   200   {object}  response.Response{data=system.SysUserAuthorityGrant,msg=string}  "委托角色"
+// @Router    /authorityGrant/delegateAuthority [post]
+func (a *AuthorityGrantApi) DelegateAuthority(c *gin.Context) {
+	var req systemReq.CreateAuthorityGrant
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	grant, err := authorityGrantService.Delegate(c.Request.Context(), utils.GetUserID(c), req)
+	if err != nil {
+		global.GVA_LOG.Error("委托失败!", zap.Error(err))
+		response.FailWithMessage("委托失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(grant, "委托成功", c)
+}
+
+// RevokeAuthorityGrant
+// @Tags      AuthorityGrant
+// @Summary   管理员撤销授权或委托
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.RevokeAuthorityGrant  true  "授权ID, 撤销原因"
+// @Success   200   {object}  response.Response{msg=string}   "撤销授权"
+// @Router    /authorityGrant/revokeAuthorityGrant [post]
+func (a *AuthorityGrantApi) RevokeAuthorityGrant(c *gin.Context) {
+	var req systemReq.RevokeAuthorityGrant
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	a.revokeAuthorityGrant(c, req, 0)
+}
+
+// RevokeDelegation
+// @Tags      AuthorityGrant
+// @Summary   撤销自己发起的委托
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.RevokeAuthorityGrant  true  "授权ID, 撤销原因"
+// @Success   200   {object}  response.Response{msg=string}   "撤销委托"
+// @Router    /authorityGrant/revokeDelegation [post]
+func (a *AuthorityGrantApi) RevokeDelegation(c *gin.Context) {
+	var req systemReq.RevokeAuthorityGrant
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	a.revokeAuthorityGrant(c, req, utils.GetUserID(c))
+}
+
+func (a *AuthorityGrantApi) revokeAuthorityGrant(c *gin.Context, req systemReq.RevokeAuthorityGrant, delegatorId uint) {
+	if err := authorityGrantService.RevokeGrant(c.Request.Context(), req.ID, delegatorId, req.Reason); err != nil {
+		global.GVA_LOG.Error("撤销失败!", zap.Error(err))
+		response.FailWithMessage("撤销失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("撤销成功", c)
+}
+
+// GetAuthorityGrantList
+// @Tags      AuthorityGrant
+// @Summary   分页获取限时授权列表
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  query     systemReq.AuthorityGrantSearch                          true  "页码, 每页大小, 用户ID, 委托人ID, 角色ID, 状态"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取限时授权列表"
+// @Router    /authorityGrant/getAuthorityGrantList [get]
+func (a *AuthorityGrantApi) GetAuthorityGrantList(c *gin.Context) {
+	var search systemReq.AuthorityGrantSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := authorityGrantService.GetGrantList(c.Request.Context(), search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// GetMyAuthorityGrants
+// @Tags      AuthorityGrant
+// @Summary   获取当前用户收到的和委托出去的授权
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=map[string][]system.SysUserAuthorityGrant,msg=string}  "received 收到的授权, delegated 委托出去的授权"
+// @Router    /authorityGrant/getMyAuthorityGrants [get]
+func (a *AuthorityGrantApi) GetMyAuthorityGrants(c *gin.Context) {
+	received, delegated, err := authorityGrantService.GetUserGrants(utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(gin.H{"received": received, "delegated": delegated}, "获取成功", c)
+}
@@ -0,0 +1,199 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WebhookApi struct{}
+
+// CreateWebhook
+// @Tags      Webhook
+// @Summary   创建webhook 未填写密钥时自动生成
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysWebhook                                     true  "名称, 回调地址, 签名密钥, 订阅的事件, 是否启用, 备注"
+// @Success   200   {object}  response.Response{data=system.SysWebhook,msg=string}  "创建webhook"
+// @Router    /webhook/createWebhook [post]
+func (w *WebhookApi) CreateWebhook(c *gin.Context) {
+	var hook system.SysWebhook
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := webhookService.CreateWebhook(&hook); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(hook, "创建成功", c)
+}
+
+// UpdateWebhook
+// @Tags      Webhook
+// @Summary   更新webhook
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      system.SysWebhook              true  "ID, 名称, 回调地址, 签名密钥, 订阅的事件, 是否启用, 备注"
+// @Success   200   {object}  response.Response{msg=string}  "更新webhook"
+// @Router    /webhook/updateWebhook [put]
+func (w *WebhookApi) UpdateWebhook(c *gin.Context) {
+	var hook system.SysWebhook
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := webhookService.UpdateWebhook(hook); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteWebhook
+// @Tags      Webhook
+// @Summary   删除webhook
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "webhook ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除webhook"
+// @Router    /webhook/deleteWebhook [delete]
+func (w *WebhookApi) DeleteWebhook(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err := webhookService.DeleteWebhook(req.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// FindWebhook
+// @Tags      Webhook
+// @Summary   获取webhook
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     request.GetById                                       true  "webhook ID"
+// @Success   200   {object}  response.Response{data=system.SysWebhook,msg=string}  "获取webhook"
+// @Router    /webhook/findWebhook [get]
+func (w *WebhookApi) FindWebhook(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	hook, err := webhookService.GetWebhook(req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(hook, "获取成功", c)
+}
+
+// GetWebhookList
+// @Tags      Webhook
+// @Summary   分页获取webhook列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.WebhookSearch                                 true  "页码, 每页大小, 名称, 是否启用"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取webhook列表,返回包括列表,总数,页码,每页数量"
+// @Router    /webhook/getWebhookList [get]
+func (w *WebhookApi) GetWebhookList(c *gin.Context) {
+	var search systemReq.WebhookSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := webhookService.GetWebhookList(search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// GetWebhookEvents
+// @Tags      Webhook
+// @Summary   获取可订阅的事件
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]string,msg=string}  "获取可订阅的事件, *表示全部事件"
+// @Router    /webhook/getWebhookEvents [get]
+func (w *WebhookApi) GetWebhookEvents(c *gin.Context) {
+	response.OkWithDetailed(webhookService.GetEvents(), "获取成功", c)
+}
+
+// GetDeliveryList
+// @Tags      Webhook
+// @Summary   分页获取webhook投递记录
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.WebhookDeliverySearch                         true  "页码, 每页大小, webhook ID, 事件, 状态"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取webhook投递记录,返回包括列表,总数,页码,每页数量"
+// @Router    /webhook/getDeliveryList [get]
+func (w *WebhookApi) GetDeliveryList(c *gin.Context) {
+	var search systemReq.WebhookDeliverySearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := webhookService.GetDeliveryList(search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// ReplayDelivery
+// @Tags      Webhook
+// @Summary   重放webhook投递 使用原请求内容和投递ID重新发送
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                                               true  "投递记录ID"
+// @Success   200   {object}  response.Response{data=system.SysWebhookDelivery,msg=string}  "重放webhook投递,返回新的投递记录"
+// @Router    /webhook/replayDelivery [post]
+func (w *WebhookApi) ReplayDelivery(c *gin.Context) {
+	var req request.GetById
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	delivery, err := webhookService.Replay(c.Request.Context(), req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("重放失败!", zap.Error(err))
+		response.FailWithMessage("重放失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(delivery, "已重放", c)
+}
@@ -9,6 +9,10 @@ type Server struct {
 	Email     Email   `mapstructure:"email" json:"email" yaml:"email"`
 	System    System  `mapstructure:"system" json:"system" yaml:"system"`
 	Captcha   Captcha `mapstructure:"captcha" json:"captcha" yaml:"captcha"`
+	// 密码策略
+	PasswordPolicy PasswordPolicy `mapstructure:"password-policy" json:"password-policy" yaml:"password-policy"`
+	// 邮件找回密码
+	PasswordReset PasswordReset `mapstructure:"password-reset" json:"password-reset" yaml:"password-reset"`
 	// auto
 	AutoCode Autocode `mapstructure:"autocode" json:"autocode" yaml:"autocode"`
 	// gorm
@@ -34,4 +38,17 @@ type Server struct {
 
 	// 跨域配置
 	Cors CORS `mapstructure:"cors" json:"cors" yaml:"cors"`
+
+	// 单点登录
+	Oidc Oidc `mapstructure:"oidc" json:"oidc" yaml:"oidc"`
+	Ldap Ldap `mapstructure:"ldap" json:"ldap" yaml:"ldap"`
+
+	// 多租户
+	Tenant Tenant `mapstructure:"tenant" json:"tenant" yaml:"tenant"`
+
+	// 数据库迁移
+	Migration Migration `mapstructure:"migration" json:"migration" yaml:"migration"`
+
+	// webhook 投递
+	Webhook Webhook `mapstructure:"webhook" json:"webhook" yaml:"webhook"`
 }
@@ -0,0 +1,30 @@
+package config
+
+type Ldap struct {
+	Enable             bool                    `mapstructure:"enable" json:"enable" yaml:"enable"`                                           // 是否开启LDAP认证
+	URL                string                  `mapstructure:"url" json:"url" yaml:"url"`                                                    // 服务地址 如 ldap://127.0.0.1:389 或 ldaps://ad.example.com:636
+	StartTLS           bool                    `mapstructure:"start-tls" json:"start-tls" yaml:"start-tls"`                                  // ldap:// 连接后是否升级为TLS
+	InsecureSkipVerify bool                    `mapstructure:"insecure-skip-verify" json:"insecure-skip-verify" yaml:"insecure-skip-verify"` // 跳过证书校验 仅用于测试环境
+	BindDN             string                  `mapstructure:"bind-dn" json:"bind-dn" yaml:"bind-dn"`                                        // 用于查询目录的服务账号DN
+	BindPassword       string                  `mapstructure:"bind-password" json:"bind-password" yaml:"bind-password"`                      // 服务账号密码
+	BaseDN             string                  `mapstructure:"base-dn" json:"base-dn" yaml:"base-dn"`                                        // 用户搜索根节点
+	UserFilter         string                  `mapstructure:"user-filter" json:"user-filter" yaml:"user-filter"`                            // 用户过滤条件 %s 会被替换为用户名 如 (&(objectClass=person)(uid=%s))
+	UsernameAttr       string                  `mapstructure:"username-attr" json:"username-attr" yaml:"username-attr"`                      // 用户名属性 OpenLDAP 通常为 uid AD 为 sAMAccountName
+	NickNameAttr       string                  `mapstructure:"nick-name-attr" json:"nick-name-attr" yaml:"nick-name-attr"`                   // 昵称属性
+	EmailAttr          string                  `mapstructure:"email-attr" json:"email-attr" yaml:"email-attr"`                               // 邮箱属性
+	PhoneAttr          string                  `mapstructure:"phone-attr" json:"phone-attr" yaml:"phone-attr"`                               // 手机号属性
+	GroupBaseDN        string                  `mapstructure:"group-base-dn" json:"group-base-dn" yaml:"group-base-dn"`                      // 用户组搜索根节点 为空时使用 base-dn
+	GroupFilter        string                  `mapstructure:"group-filter" json:"group-filter" yaml:"group-filter"`                         // 用户组过滤条件 %s 会被替换为用户DN 如 (&(objectClass=groupOfNames)(member=%s))
+	GroupNameAttr      string                  `mapstructure:"group-name-attr" json:"group-name-attr" yaml:"group-name-attr"`                // 用户组名称属性
+	AutoProvision      bool                    `mapstructure:"auto-provision" json:"auto-provision" yaml:"auto-provision"`                   // 首次登录时自动创建本地用户
+	DefaultAuthorityId uint                    `mapstructure:"default-authority-id" json:"default-authority-id" yaml:"default-authority-id"` // 自动创建用户的默认角色ID
+	GroupMapping       []GroupAuthorityMapping `mapstructure:"group-mapping" json:"group-mapping" yaml:"group-mapping"`                      // 用户组到角色的映射
+	Sync               LdapSync                `mapstructure:"sync" json:"sync" yaml:"sync"`                                                 // 定时同步
+}
+
+type LdapSync struct {
+	Enable       bool   `mapstructure:"enable" json:"enable" yaml:"enable"`                      // 是否开启定时同步
+	Spec         string `mapstructure:"spec" json:"spec" yaml:"spec"`                            // cron表达式 如 @every 1h
+	DisableStale bool   `mapstructure:"disable-stale" json:"disable-stale" yaml:"disable-stale"` // 目录中已不存在的用户是否冻结
+	MinUsers     int    `mapstructure:"min-users" json:"min-users" yaml:"min-users"`             // 目录返回的用户数不超过该值时不冻结 防止搜索配置错误冻结全部用户 目录返回空结果时始终不冻结
+}
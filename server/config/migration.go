@@ -0,0 +1,6 @@
+package config
+
+type Migration struct {
+	Dir    string `mapstructure:"dir" json:"dir" yaml:"dir"`             // 迁移文件目录 相对路径基于server目录, 插件的迁移文件位于 plugin/{插件名}/migrations
+	AutoUp bool   `mapstructure:"auto-up" json:"auto-up" yaml:"auto-up"` // 启动时自动执行待执行的迁移
+}
@@ -0,0 +1,21 @@
+package config
+
+type Oidc struct {
+	Enable             bool                    `mapstructure:"enable" json:"enable" yaml:"enable"`                                           // 是否开启OIDC单点登录
+	Issuer             string                  `mapstructure:"issuer" json:"issuer" yaml:"issuer"`                                           // 身份提供方地址 会通过 /.well-known/openid-configuration 自动发现端点
+	ClientID           string                  `mapstructure:"client-id" json:"client-id" yaml:"client-id"`                                  // 客户端ID
+	ClientSecret       string                  `mapstructure:"client-secret" json:"client-secret" yaml:"client-secret"`                      // 客户端密钥 公共客户端可留空 仅使用PKCE
+	RedirectURL        string                  `mapstructure:"redirect-url" json:"redirect-url" yaml:"redirect-url"`                         // 回调地址 需与身份提供方中登记的一致
+	Scopes             []string                `mapstructure:"scopes" json:"scopes" yaml:"scopes"`                                           // 申请的scope 默认 openid profile email
+	UsernameClaim      string                  `mapstructure:"username-claim" json:"username-claim" yaml:"username-claim"`                   // 作为本地用户名的claim 默认 preferred_username
+	GroupsClaim        string                  `mapstructure:"groups-claim" json:"groups-claim" yaml:"groups-claim"`                         // 用户组claim 默认 groups
+	AutoProvision      bool                    `mapstructure:"auto-provision" json:"auto-provision" yaml:"auto-provision"`                   // 首次登录时自动创建本地用户
+	DefaultAuthorityId uint                    `mapstructure:"default-authority-id" json:"default-authority-id" yaml:"default-authority-id"` // 自动创建用户的默认角色ID
+	GroupMapping       []GroupAuthorityMapping `mapstructure:"group-mapping" json:"group-mapping" yaml:"group-mapping"`                      // 用户组到角色的映射
+}
+
+// GroupAuthorityMapping 外部用户组到本地角色的映射 OIDC与LDAP共用
+type GroupAuthorityMapping struct {
+	Group       string `mapstructure:"group" json:"group" yaml:"group"`                      // 身份提供方中的组名
+	AuthorityId uint   `mapstructure:"authority-id" json:"authority-id" yaml:"authority-id"` // 对应的角色ID
+}
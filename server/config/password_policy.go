@@ -0,0 +1,14 @@
+package config
+
+type PasswordPolicy struct {
+	MinLength             int    `mapstructure:"min-length" json:"min-length" yaml:"min-length"`                                           // 最小长度 0为不限制
+	RequireUpper          bool   `mapstructure:"require-upper" json:"require-upper" yaml:"require-upper"`                                  // 必须包含大写字母
+	RequireLower          bool   `mapstructure:"require-lower" json:"require-lower" yaml:"require-lower"`                                  // 必须包含小写字母
+	RequireDigit          bool   `mapstructure:"require-digit" json:"require-digit" yaml:"require-digit"`                                  // 必须包含数字
+	RequireSpecial        bool   `mapstructure:"require-special" json:"require-special" yaml:"require-special"`                            // 必须包含特殊字符
+	DisallowUsername      bool   `mapstructure:"disallow-username" json:"disallow-username" yaml:"disallow-username"`                      // 不允许包含用户名
+	DictionaryFile        string `mapstructure:"dictionary-file" json:"dictionary-file" yaml:"dictionary-file"`                            // 弱口令/泄露密码字典 每行一个 为空不校验
+	HistoryCount          int    `mapstructure:"history-count" json:"history-count" yaml:"history-count"`                                  // 不允许与最近N次密码相同 0为不限制
+	ExpireDays            int    `mapstructure:"expire-days" json:"expire-days" yaml:"expire-days"`                                        // 密码有效天数 过期后需修改密码 0为永不过期
+	ForceChangeAfterReset bool   `mapstructure:"force-change-after-reset" json:"force-change-after-reset" yaml:"force-change-after-reset"` // 管理员重置后用户需修改密码
+}
@@ -0,0 +1,9 @@
+package config
+
+type PasswordReset struct {
+	Enable        bool   `mapstructure:"enable" json:"enable" yaml:"enable"`                         // 是否开启邮件找回密码
+	LinkURL       string `mapstructure:"link-url" json:"link-url" yaml:"link-url"`                   // 前端重置密码页面地址 令牌以 token 参数附加
+	ExpireMinutes int    `mapstructure:"expire-minutes" json:"expire-minutes" yaml:"expire-minutes"` // 重置链接有效分钟数
+	LimitCount    int    `mapstructure:"limit-count" json:"limit-count" yaml:"limit-count"`          // 限流周期内同一IP或账号最多申请次数
+	LimitTime     int    `mapstructure:"limit-time" json:"limit-time" yaml:"limit-time"`             // 限流周期 单位秒
+}
@@ -11,4 +11,8 @@ type System struct {
 	UseRedis      bool   `mapstructure:"use-redis" json:"use-redis" yaml:"use-redis"`                   // 使用redis
 	UseMongo      bool   `mapstructure:"use-mongo" json:"use-mongo" yaml:"use-mongo"`                   // 使用mongo
 	UseStrictAuth bool   `mapstructure:"use-strict-auth" json:"use-strict-auth" yaml:"use-strict-auth"` // 使用树形角色分配模式
+	// 子角色通过 casbin g 规则继承父角色的api权限
+	UseRoleInheritance bool `mapstructure:"use-role-inheritance" json:"use-role-inheritance" yaml:"use-role-inheritance"`
+	// 多实例部署时通过该 redis stream 转发事件, 需开启 use-redis, 为空时事件只在本实例内分发
+	EventStream string `mapstructure:"event-stream" json:"event-stream" yaml:"event-stream"`
 }
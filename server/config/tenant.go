@@ -0,0 +1,18 @@
+package config
+
+type Tenant struct {
+	Enable            bool   `mapstructure:"enable" json:"enable" yaml:"enable"`                                        // 是否开启多租户
+	Domain            string `mapstructure:"domain" json:"domain" yaml:"domain"`                                        // 主域名 配置后可通过子域名 {租户编码}.{主域名} 识别租户
+	Header            string `mapstructure:"header" json:"header" yaml:"header"`                                        // 超级管理员切换租户的请求头 值为租户ID
+	SuperAuthorityIds []uint `mapstructure:"super-authority-ids" json:"super-authority-ids" yaml:"super-authority-ids"` // 可跨租户访问的角色
+}
+
+// IsSuperAuthority 是否为可跨租户访问的角色
+func (t Tenant) IsSuperAuthority(authorityId uint) bool {
+	for _, id := range t.SuperAuthorityIds {
+		if id == authorityId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,5 @@
+package config
+
+type Webhook struct {
+	AllowPrivateNetwork bool `mapstructure:"allow-private-network" json:"allow-private-network" yaml:"allow-private-network"` // 允许回调地址指向回环、内网等地址 默认只允许公网地址 防止 SSRF
+}
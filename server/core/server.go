@@ -29,6 +29,7 @@ func RunWindowsServer() {
 	if global.GVA_DB != nil {
 		system.LoadAll()
 	}
+	initialize.Event()
 
 	Router := initialize.Routers()
 
@@ -39,7 +40,7 @@ func RunWindowsServer() {
 
 	fmt.Printf(`
 	欢迎使用 gin-vue-admin
-	当前版本:v2.8.1
+	当前版本:%s
 	加群方式:微信号：shouzi_1994 QQ群：470239250
 	项目地址：https://github.com/flipped-aurora/gin-vue-admin
 	插件市场:https://plugin.gin-vue-admin.com
@@ -50,6 +51,8 @@ func RunWindowsServer() {
 	** 版权所有方：flipped-aurora开源团队 **
 	** 版权持有公司：北京翻转极光科技有限责任公司 **
 	** 剔除授权标识需购买商用授权：https://gin-vue-admin.com/empower/index.html **
-`, address)
+`, global.GVA_VERSION, address)
 	global.GVA_LOG.Error(s.ListenAndServe().Error())
+	initialize.StopPlugin()
+	initialize.StopEvent()
 }
@@ -0,0 +1,33 @@
+package dto
+
+import "github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+
+// ストアフロントのイベント (global.GVA_EVENT で発行)
+var (
+	// EventCartChanged カートへの追加・数量変更・削除後に発行
+	EventCartChanged = event.Topic[CartEvent]("cart.changed")
+	// EventFavoriteChanged お気に入りの追加・削除後に発行
+	EventFavoriteChanged = event.Topic[FavoriteEvent]("favorite.changed")
+)
+
+// イベントの操作種別
+const (
+	EventActionAdd    = "add"
+	EventActionChange = "change"
+	EventActionDelete = "delete"
+)
+
+// CartEvent カート変更イベント
+type CartEvent struct {
+	UserID   uint   `json:"user_id"`  // ユーザーID
+	SkuID    string `json:"sku_id"`   // SKU ID
+	Quantity int    `json:"quantity"` // 変更後の数量 (削除時は0)
+	Action   string `json:"action"`   // add | change | delete
+}
+
+// FavoriteEvent お気に入り変更イベント
+type FavoriteEvent struct {
+	UserID uint   `json:"user_id"` // ユーザーID
+	SkuID  string `json:"sku_id"`  // SKU ID
+	Action string `json:"action"`  // add | delete
+}
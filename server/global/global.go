@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/qiniu/qmgo"
 
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+	pluginV2 "github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/v2"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/timer"
 	"github.com/songzhibin97/gkit/cache/local_cache"
 
@@ -32,6 +34,8 @@ var (
 	// GVA_LOG    *oplogging.Logger
 	GVA_LOG                 *zap.Logger
 	GVA_Timer               timer.Timer = timer.NewTimerTask()
+	GVA_PLUGIN                          = pluginV2.NewManager()
+	GVA_EVENT                           = event.NewBus()
 	GVA_Concurrency_Control             = &singleflight.Group{}
 	GVA_ROUTERS             gin.RoutesInfo
 	GVA_ACTIVE_DBNAME       *string
@@ -12,3 +12,11 @@ type GVA_MODEL struct {
 	UpdatedAt time.Time      // 更新时间
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // 删除时间
 }
+
+// GVA_TENANT 租户字段 嵌入后开启多租户时按 tenant_id 自动隔离数据
+type GVA_TENANT struct {
+	TenantId uint `json:"tenantId" form:"tenantId" gorm:"index;default:0;comment:租户ID"` // 租户ID 0为平台
+}
+
+// TenantScoped 标记模型需要按租户隔离
+func (GVA_TENANT) TenantScoped() {}
@@ -0,0 +1,4 @@
+package global
+
+// GVA_VERSION 当前gin-vue-admin版本 安装插件时与插件清单中的coreVersion比较
+const GVA_VERSION = "v2.8.1"
@@ -53,6 +53,26 @@ func (e *ensureTables) MigrateTable(ctx context.Context) (context.Context, error
 		sysModel.Condition{},
 		sysModel.JoinTemplate{},
 		sysModel.SysParams{},
+		sysModel.SysUserIdentity{},
+		sysModel.SysUserPasswordHistory{},
+		sysModel.SysApiToken{},
+		sysModel.SysPasswordReset{},
+		sysModel.SysDataScope{},
+		sysModel.SysAuthorityField{},
+		sysModel.SysDepartment{},
+		sysModel.SysUserDepartment{},
+		sysModel.SysUserAuthorityGrant{},
+		sysModel.SysTenant{},
+		sysModel.SysApprovalFlow{},
+		sysModel.SysApprovalStep{},
+		sysModel.SysApprovalRequest{},
+		sysModel.SysApprovalTask{},
+		sysModel.SysNotificationTemplate{},
+		sysModel.SysNotification{},
+		sysModel.SysWebhook{},
+		sysModel.SysWebhookDelivery{},
+		sysModel.SysSchemaMigration{},
+		sysModel.SysPlugin{},
 
 		adapter.CasbinRule{},
 
@@ -0,0 +1,56 @@
+package initialize
+
+import (
+	"context"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/dto"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/service"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+	"go.uber.org/zap"
+)
+
+// Event 注册核心事件订阅, 配置了 event-stream 时通过 redis stream 在多实例间转发事件
+func Event() {
+	global.GVA_EVENT.OnError(func(name string, err error) {
+		global.GVA_LOG.Error("事件处理失败!", zap.String("event", name), zap.Error(err))
+	})
+	operationRecordService := service.ServiceGroupApp.SystemServiceGroup.OperationRecordService
+	// 操作记录包含完整的请求和响应内容 只由发布的实例入库, 不转发到 event-stream
+	event.Local(global.GVA_EVENT, system.EventOperationRecorded)
+	event.Subscribe(global.GVA_EVENT, system.EventOperationRecorded, func(ctx context.Context, record system.SysOperationRecord) error {
+		return operationRecordService.CreateSysOperationRecord(record)
+	})
+
+	// 可通过 webhook 订阅的事件
+	systemService.WebhookEvent(global.GVA_EVENT, system.EventUserCreated)
+	systemService.WebhookEvent(global.GVA_EVENT, system.EventUserDeleted)
+	systemService.WebhookEvent(global.GVA_EVENT, system.EventUserLogin)
+	systemService.WebhookEvent(global.GVA_EVENT, system.EventAuthorityChanged)
+	systemService.WebhookEvent(global.GVA_EVENT, system.EventDictionaryChanged)
+	systemService.WebhookEvent(global.GVA_EVENT, system.EventFileUploaded)
+	systemService.WebhookEvent(global.GVA_EVENT, dto.EventCartChanged)
+	systemService.WebhookEvent(global.GVA_EVENT, dto.EventFavoriteChanged)
+
+	stream := global.GVA_CONFIG.System.EventStream
+	if stream == "" {
+		return
+	}
+	if global.GVA_REDIS == nil {
+		global.GVA_LOG.Warn("未开启redis, 事件只在本实例内分发", zap.String("event-stream", stream))
+		return
+	}
+	global.GVA_EVENT.Use(event.NewRedisStream(global.GVA_REDIS, stream, 10000))
+}
+
+// StopEvent 服务退出时停止接收远程事件并等待异步订阅处理完成
+func StopEvent() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := global.GVA_EVENT.Close(ctx); err != nil {
+		global.GVA_LOG.Error("等待事件处理超时!", zap.Error(err))
+	}
+}
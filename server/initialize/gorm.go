@@ -6,38 +6,44 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 func Gorm() *gorm.DB {
+	var db *gorm.DB
 	switch global.GVA_CONFIG.System.DbType {
 	case "mysql":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Mysql.Dbname
-		return GormMysql()
+		db = GormMysql()
 	case "pgsql":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Pgsql.Dbname
-		return GormPgSql()
+		db = GormPgSql()
 	case "oracle":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Oracle.Dbname
-		return GormOracle()
+		db = GormOracle()
 	case "mssql":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Mssql.Dbname
-		return GormMssql()
+		db = GormMssql()
 	case "sqlite":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Sqlite.Dbname
-		return GormSqlite()
+		db = GormSqlite()
 	default:
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Mysql.Dbname
-		return GormMysql()
+		db = GormMysql()
 	}
+	// 多租户模式下按请求上下文中的租户隔离数据
+	if err := systemService.RegisterTenantCallbacks(db); err != nil {
+		global.GVA_LOG.Error("register tenant callbacks failed", zap.Error(err))
+	}
+	return db
 }
 
-func RegisterTables() {
-	db := global.GVA_DB
-	err := db.AutoMigrate(
-
+// systemTables system 模块的表 启动时由 RegisterTables 建表, migrate 子命令由基线迁移建表
+func systemTables() []interface{} {
+	return []interface{}{
 		system.SysApi{},
 		system.SysIgnoreApi{},
 		system.SysUser{},
@@ -57,13 +63,43 @@ func RegisterTables() {
 		system.JoinTemplate{},
 		system.SysParams{},
 		system.UserLoginHistory{},
+		system.SysUserIdentity{},
+		system.SysUserPasswordHistory{},
+		system.SysApiToken{},
+		system.SysPasswordReset{},
+		system.SysDataScope{},
+		system.SysAuthorityField{},
+		system.SysDepartment{},
+		system.SysUserDepartment{},
+		system.SysUserAuthorityGrant{},
+		system.SysTenant{},
+		system.SysApprovalFlow{},
+		system.SysApprovalStep{},
+		system.SysApprovalRequest{},
+		system.SysApprovalTask{},
+		system.SysNotificationTemplate{},
+		system.SysNotification{},
+		system.SysWebhook{},
+		system.SysWebhookDelivery{},
+		system.SysSchemaMigration{},
+		system.SysPlugin{},
+	}
+}
 
+// exampleTables example 模块的表
+func exampleTables() []interface{} {
+	return []interface{}{
 		example.ExaFile{},
 		example.ExaCustomer{},
 		example.ExaFileChunk{},
 		example.ExaFileUploadAndDownload{},
 		example.ExaAttachmentCategory{},
-	)
+	}
+}
+
+func RegisterTables() {
+	db := global.GVA_DB
+	err := db.AutoMigrate(append(systemTables(), exampleTables()...)...)
 	if err != nil {
 		global.GVA_LOG.Error("register table failed", zap.Error(err))
 		os.Exit(0)
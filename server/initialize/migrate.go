@@ -0,0 +1,108 @@
+package initialize
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "用法: server [-c config.yaml] migrate up|down|status [-module 模块名] [-steps 回滚数量]"
+
+func init() {
+	// 核心模块的基线迁移 建立与 RegisterTables 相同的表结构, 已有的数据库上执行只补齐缺少的表、字段和索引
+	// 之后的表结构变更使用新的版本号提交迁移, 基线没有down脚本 不可回滚
+	system.RegisterMigration(system.Migration{Module: "system", Version: "0001", Name: "baseline", Up: autoMigrate(systemTables)})
+	system.RegisterMigration(system.Migration{Module: "example", Version: "0001", Name: "baseline", Up: autoMigrate(exampleTables)})
+}
+
+func autoMigrate(tables func() []interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.AutoMigrate(tables()...)
+	}
+}
+
+// Migrate 执行 server migrate up|down|status 子命令 失败时以非0状态码退出
+func Migrate(args []string) {
+	if err := migrate(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	module := fs.String("module", "", "只处理指定模块, 插件为 plugin/{插件名}")
+	steps := fs.Int("steps", 1, "down 回滚的迁移数量")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if global.GVA_DB == nil {
+		return errors.New("数据库未初始化, 请先完成初始化或检查配置")
+	}
+	service := system.MigrationServiceApp
+	switch args[0] {
+	case "up":
+		done, err := service.Up(*module)
+		for _, name := range done {
+			fmt.Println("已执行:", name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("没有待执行的迁移")
+		}
+		return err
+	case "down":
+		done, err := service.Down(*module, *steps)
+		for _, name := range done {
+			fmt.Println("已回滚:", name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+		return err
+	case "status":
+		list, err := service.Status(*module)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "MODULE\tVERSION\tNAME\tDB\tSTATUS\tAPPLIED AT")
+		for _, s := range list {
+			status, appliedAt := "pending", ""
+			if s.Applied {
+				status, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Missing {
+				status = "missing"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Module, s.Version, s.Name, s.DB, status, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// MigrateUp 配置了 migration.auto-up 时在启动时执行待执行的迁移 失败时以非0状态码退出
+func MigrateUp() {
+	if global.GVA_DB == nil || !global.GVA_CONFIG.Migration.AutoUp {
+		return
+	}
+	done, err := system.MigrationServiceApp.Up("")
+	for _, name := range done {
+		global.GVA_LOG.Info("migration applied", zap.String("migration", name))
+	}
+	if err != nil {
+		global.GVA_LOG.Error("migration failed", zap.Error(err))
+		os.Exit(1)
+	}
+}
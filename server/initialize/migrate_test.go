@@ -0,0 +1,49 @@
+package initialize
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestBaselineMigrations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	require.Nil(t, err)
+	oldDB, oldAutoCode, oldMigration := global.GVA_DB, global.GVA_CONFIG.AutoCode, global.GVA_CONFIG.Migration
+	global.GVA_DB = db
+	global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server = t.TempDir(), "server"
+	global.GVA_CONFIG.Migration.Dir = ""
+	t.Cleanup(func() {
+		global.GVA_DB, global.GVA_CONFIG.AutoCode, global.GVA_CONFIG.Migration = oldDB, oldAutoCode, oldMigration
+	})
+
+	// 空库只执行迁移即可建立全部核心表
+	done, err := systemService.MigrationServiceApp.Up("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example/0001_baseline", "system/0001_baseline"}, done)
+	for _, table := range append(systemTables(), exampleTables()...) {
+		assert.True(t, db.Migrator().HasTable(table), "%T", table)
+	}
+	assert.True(t, db.Migrator().HasColumn(&system.SysUser{}, "tenant_id"))
+	assert.True(t, db.Migrator().HasTable(&example.ExaCustomer{}))
+
+	done, err = systemService.MigrationServiceApp.Up("")
+	assert.Nil(t, err)
+	assert.Empty(t, done)
+
+	// 基线不可回滚
+	_, err = systemService.MigrationServiceApp.Down("system", 1)
+	assert.EqualError(t, err, "迁移 system/0001_baseline 没有down脚本, 无法回滚")
+
+	assert.EqualError(t, migrate(nil), migrateUsage)
+	assert.EqualError(t, migrate([]string{"redo"}), migrateUsage)
+}
@@ -1,8 +1,12 @@
 package initialize
 
 import (
+	"context"
+	"time"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 func InstallPlugin(PrivateGroup *gin.RouterGroup, PublicRouter *gin.RouterGroup, engine *gin.Engine) {
@@ -11,5 +15,18 @@ func InstallPlugin(PrivateGroup *gin.RouterGroup, PublicRouter *gin.RouterGroup,
 		return
 	}
 	bizPluginV1(PrivateGroup, PublicRouter)
+	// PluginInitV2 只添加插件, 全部添加后按依赖顺序统一初始化、迁移、注册路由并启动
 	bizPluginV2(engine)
+	if err := global.GVA_PLUGIN.Setup(context.Background(), engine); err != nil {
+		global.GVA_LOG.Error("v2插件启动失败!", zap.Error(err))
+	}
+}
+
+// StopPlugin 服务退出时按依赖的逆序停止v2插件
+func StopPlugin() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := global.GVA_PLUGIN.Stop(ctx); err != nil {
+		global.GVA_LOG.Error("v2插件停止失败!", zap.Error(err))
+	}
 }
@@ -1,15 +1,14 @@
 package initialize
 
 import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/v2"
 	"github.com/gin-gonic/gin"
 )
 
 func PluginInitV2(group *gin.Engine, plugins ...plugin.Plugin) {
-	for i := 0; i < len(plugins); i++ {
-		plugins[i].Register(group)
-	}
+	global.GVA_PLUGIN.Add(plugins...)
 }
 func bizPluginV2(engine *gin.Engine) {
 	PluginInitV2(engine, announcement.Plugin)
@@ -66,7 +66,7 @@ func Routers() *gin.Engine {
 	PublicGroup := Router.Group(global.GVA_CONFIG.System.RouterPrefix)
 	PrivateGroup := Router.Group(global.GVA_CONFIG.System.RouterPrefix)
 
-	PrivateGroup.Use(middleware.JWTAuth()).Use(middleware.CasbinHandler())
+	PrivateGroup.Use(middleware.ApiTokenAuth()).Use(middleware.JWTAuth()).Use(middleware.PasswordExpired()).Use(middleware.TenantResolver()).Use(middleware.CasbinHandler()).Use(middleware.FieldPermission())
 
 	{
 		// 健康监测
@@ -93,8 +93,19 @@ func Routers() *gin.Engine {
 		systemRouter.InitSysOperationRecordRouter(PrivateGroup)             // 操作记录
 		systemRouter.InitSysDictionaryDetailRouter(PrivateGroup)            // 字典详情管理
 		systemRouter.InitAuthorityBtnRouterRouter(PrivateGroup)             // 按钮权限管理
+		systemRouter.InitAuthorityFieldRouter(PrivateGroup)                 // 字段权限管理
 		systemRouter.InitSysExportTemplateRouter(PrivateGroup, PublicGroup) // 导出模板
 		systemRouter.InitSysParamsRouter(PrivateGroup, PublicGroup)         // 参数管理
+		systemRouter.InitLdapRouter(PrivateGroup)                           // LDAP同步
+		systemRouter.InitApiTokenRouter(PrivateGroup)                       // 个人访问令牌
+		systemRouter.InitDataScopeRouter(PrivateGroup)                      // 行级数据权限
+		systemRouter.InitDepartmentRouter(PrivateGroup)                     // 部门管理
+		systemRouter.InitAuthorityGrantRouter(PrivateGroup)                 // 限时授权与委托
+		systemRouter.InitRbacRouter(PrivateGroup)                           // 权限配置导入导出
+		systemRouter.InitTenantRouter(PrivateGroup)                         // 租户管理
+		systemRouter.InitApprovalRouter(PrivateGroup)                       // 审批流程
+		systemRouter.InitNotificationRouter(PrivateGroup)                   // 站内信
+		systemRouter.InitWebhookRouter(PrivateGroup)                        // webhook
 		exampleRouter.InitCustomerRouter(PrivateGroup)                      // 客户路由
 		exampleRouter.InitFileUploadAndDownloadRouter(PrivateGroup)         // 文件上传下载功能路由
 		exampleRouter.InitAttachmentCategoryRouterRouter(PrivateGroup)      // 文件上传下载分类
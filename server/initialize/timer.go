@@ -2,6 +2,7 @@ package initialize
 
 import (
 	"fmt"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/task"
 
 	"github.com/robfig/cron/v3"
@@ -24,6 +25,48 @@ func Timer() {
 			fmt.Println("add timer error:", err)
 		}
 
+		// LDAP 用户与用户组同步
+		if global.GVA_CONFIG.Ldap.Enable && global.GVA_CONFIG.Ldap.Sync.Enable {
+			_, err = global.GVA_Timer.AddTaskByFunc("LdapSync", global.GVA_CONFIG.Ldap.Sync.Spec, func() {
+				if _, err := system.LdapServiceApp.Sync(false); err != nil {
+					fmt.Println("ldap sync error:", err)
+				}
+			}, "定时同步LDAP用户与用户组", option...)
+			if err != nil {
+				fmt.Println("add timer error:", err)
+			}
+		}
+
+		// 限时授权与委托 启用、到期收回
+		_, err = global.GVA_Timer.AddTaskByFunc("AuthorityGrantExpire", "@every 1m", func() {
+			if err := system.AuthorityGrantServiceApp.ExpireGrants(); err != nil {
+				fmt.Println("authority grant expire error:", err)
+			}
+		}, "定时处理限时角色授权的生效与到期", option...)
+		if err != nil {
+			fmt.Println("add timer error:", err)
+		}
+
+		// webhook 失败重试
+		_, err = global.GVA_Timer.AddTaskByFunc("WebhookRetry", "@every 10s", func() {
+			if err := system.WebhookServiceApp.RetryDeliveries(); err != nil {
+				fmt.Println("webhook retry error:", err)
+			}
+		}, "定时重试投递失败的webhook", option...)
+		if err != nil {
+			fmt.Println("add timer error:", err)
+		}
+
+		// 执行已通过但尚未执行的审批单
+		_, err = global.GVA_Timer.AddTaskByFunc("ApprovalResume", "@every 1m", func() {
+			if err := system.ApprovalServiceApp.ResumeApproved(); err != nil {
+				fmt.Println("approval resume error:", err)
+			}
+		}, "定时执行已通过但未执行的审批单", option...)
+		if err != nil {
+			fmt.Println("add timer error:", err)
+		}
+
 		// 其他定时任务定在这里 参考上方使用方法
 
 		//_, err := global.GVA_Timer.AddTaskByFunc("定时任务标识", "corn表达式", func() {
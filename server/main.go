@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/core"
 	_ "github.com/flipped-aurora/gin-vue-admin/server/docs"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
@@ -34,13 +36,21 @@ func main() {
 	global.GVA_LOG = core.Zap() // 初始化zap日志库
 	zap.ReplaceGlobals(global.GVA_LOG)
 	global.GVA_DB = initialize.Gorm() // gorm连接数据库
-	initialize.Timer()
 	initialize.DBList()
 	if global.GVA_DB != nil {
-		initialize.RegisterTables() // 初始化表
 		// 程序结束前关闭数据库链接
 		db, _ := global.GVA_DB.DB()
 		defer db.Close()
 	}
+	// server migrate up|down|status 执行数据库迁移后退出 表结构完全由迁移管理 不自动建表也不启动定时任务
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		initialize.Migrate(args[1:])
+		return
+	}
+	initialize.Timer()
+	if global.GVA_DB != nil {
+		initialize.RegisterTables() // 初始化表
+	}
+	initialize.MigrateUp()
 	core.RunWindowsServer()
 }
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/service"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+)
+
+var apiTokenService = service.ServiceGroupApp.SystemServiceGroup.ApiTokenService
+
+// getApiToken 从 Authorization: Bearer 或 x-api-token 头中读取个人访问令牌
+func getApiToken(c *gin.Context) string {
+	if token := c.Request.Header.Get("x-api-token"); token != "" {
+		return token
+	}
+	if auth := c.Request.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer "+systemService.ApiTokenPrefix) {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ""
+}
+
+// ApiTokenAuth 个人访问令牌鉴权 需放在 JWTAuth 之前
+// 请求未携带令牌时交由 JWTAuth 处理 携带时以令牌所属用户的当前角色继续走 Casbin 鉴权
+func ApiTokenAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := getApiToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+		apiToken, user, err := apiTokenService.Authenticate(token, c.ClientIP())
+		if err != nil {
+			response.NoAuth(err.Error(), c)
+			c.Abort()
+			return
+		}
+		path := strings.TrimPrefix(c.Request.URL.Path, global.GVA_CONFIG.System.RouterPrefix)
+		if !apiToken.Allow(c.Request.Method, path) {
+			response.FailWithDetailed(gin.H{}, "访问令牌的权限范围不包含该接口", c)
+			c.Abort()
+			return
+		}
+		utils.SetClaims(c, &systemReq.CustomClaims{
+			BaseClaims: systemReq.BaseClaims{
+				UUID:        user.UUID,
+				ID:          user.ID,
+				Username:    user.Username,
+				NickName:    user.NickName,
+				AuthorityId: user.AuthorityId,
+				TenantId:    user.TenantId,
+			},
+		})
+		c.Set("apiTokenId", apiToken.ID)
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newApiTokenRouter 返回 ApiTokenAuth+JWTAuth 保护的路由 以及一个只能访问 GET /report/* 的令牌
+func newApiTokenRouter(t *testing.T) (*gin.Engine, string) {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, db.AutoMigrate(&system.SysUser{}, &system.SysApiToken{}))
+	oldDB, oldLog, oldSystem := global.GVA_DB, global.GVA_LOG, global.GVA_CONFIG.System
+	global.GVA_DB, global.GVA_LOG = db, zap.NewNop()
+	global.GVA_CONFIG.System.RouterPrefix = "/api"
+	t.Cleanup(func() {
+		global.GVA_DB, global.GVA_LOG, global.GVA_CONFIG.System = oldDB, oldLog, oldSystem
+	})
+	user := system.SysUser{Username: "robot", AuthorityId: 888, Enable: 1}
+	assert.Nil(t, db.Create(&user).Error)
+	token, _, err := systemService.ApiTokenServiceApp.CreateApiToken(context.Background(), user.ID, systemReq.CreateApiToken{Name: "ci", Scopes: []string{"GET /report/*"}})
+	assert.Nil(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ApiTokenAuth(), JWTAuth())
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, "%s %d", utils.GetUserName(c), utils.GetUserAuthorityId(c))
+	}
+	r.GET("/api/report/list", handler)
+	r.POST("/api/report/delete", handler)
+	return r, token
+}
+
+func TestApiTokenAuth(t *testing.T) {
+	r, token := newApiTokenRouter(t)
+	serve := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 令牌可通过 Authorization 或 x-api-token 头携带 以所属用户身份跳过 JWTAuth
+	for _, header := range []http.Header{{"Authorization": {"Bearer " + token}}, {"X-Api-Token": {token}}} {
+		w := serve(http.MethodGet, "/api/report/list", header)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "robot 888", w.Body.String())
+	}
+
+	// 超出令牌范围
+	w := serve(http.MethodPost, "/api/report/delete", http.Header{"X-Api-Token": {token}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "访问令牌的权限范围不包含该接口")
+
+	// 无效令牌直接拒绝 不再尝试 JWT
+	w = serve(http.MethodGet, "/api/report/list", http.Header{"X-Api-Token": {token + "x"}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), systemService.ErrApiTokenInvalid.Error())
+
+	// 未携带令牌时交由 JWTAuth 处理 Bearer 后不是个人令牌时同样如此
+	for _, header := range []http.Header{nil, {"Authorization": {"Bearer eyJhbGciOi"}}} {
+		w = serve(http.MethodGet, "/api/report/list", header)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "未登录或非法访问")
+	}
+}
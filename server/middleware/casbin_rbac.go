@@ -16,7 +16,8 @@ var casbinService = service.ServiceGroupApp.SystemServiceGroup.CasbinService
 // CasbinHandler 拦截器
 func CasbinHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		waitUse, _ := utils.GetClaims(c)
+		// 优先使用 JWTAuth/ApiTokenAuth 写入上下文的 claims
+		authorityId := utils.GetUserAuthorityId(c)
 
 		// 获取请求的 PATH
 		path := c.Request.URL.Path
@@ -24,7 +25,7 @@ func CasbinHandler() gin.HandlerFunc {
 		// 获取请求方法
 		act := c.Request.Method
 		// 获取用户的角色
-		sub := strconv.Itoa(int(waitUse.AuthorityId))
+		sub := strconv.Itoa(int(authorityId))
 
 		// 判断 Casbin 是否正确加载
 		e := casbinService.Casbin()
@@ -36,7 +37,7 @@ func CasbinHandler() gin.HandlerFunc {
 		}
 
 		// 判断策略中是否存在权限
-		success, _ := e.Enforce(sub, obj, act)
+		success, _ := e.Enforce(casbinService.EnforceArgs(sub, utils.GetTenantId(c), obj, act)...)
 		if !success {
 			// 如果权限验证失败，返回权限不足
 			response.FailWithDetailed(gin.H{}, "权限不足", c)
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FieldPermission 字段权限 按角色对接口返回的JSON隐藏或脱敏字段 需放在 CasbinHandler 之后
+func FieldPermission() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := strings.TrimPrefix(c.Request.URL.Path, global.GVA_CONFIG.System.RouterPrefix)
+		rules := systemService.AuthorityFieldRules(utils.GetUserAuthorityId(c), path)
+		if len(rules) == 0 {
+			c.Next()
+			return
+		}
+		writer := &fieldMaskWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		if writer.passthrough {
+			return
+		}
+
+		body := writer.body.Bytes()
+		if isJSONResponse(c.Writer.Header()) {
+			body = utils.MaskJSON(body, rules)
+			c.Writer.Header().Del("Content-Length")
+		}
+		_, _ = c.Writer.Write(body)
+	}
+}
+
+func isJSONResponse(header http.Header) bool {
+	return strings.Contains(header.Get("Content-Type"), "application/json")
+}
+
+// fieldMaskWriter 缓存JSON响应 处理完字段权限后再写出
+// 首次写入时按 Content-Type 决定是否缓存, 其余响应(如 text/event-stream)直接写出并支持 Flush
+type fieldMaskWriter struct {
+	gin.ResponseWriter
+	body        *bytes.Buffer
+	decided     bool
+	passthrough bool
+}
+
+func (w *fieldMaskWriter) buffering() bool {
+	if !w.decided {
+		w.decided = true
+		w.passthrough = !isJSONResponse(w.Header())
+	}
+	return !w.passthrough
+}
+
+func (w *fieldMaskWriter) Write(b []byte) (int, error) {
+	if !w.buffering() {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *fieldMaskWriter) WriteString(s string) (int, error) {
+	if !w.buffering() {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *fieldMaskWriter) Flush() {
+	if !w.buffering() {
+		w.ResponseWriter.Flush()
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newFieldPermissionRouter 为角色 888 在全部接口上隐藏 phone 字段
+func newFieldPermissionRouter(t *testing.T) *gin.Engine {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, db.AutoMigrate(&system.SysAuthorityField{}))
+	oldDB, oldLog, oldSystem := global.GVA_DB, global.GVA_LOG, global.GVA_CONFIG.System
+	global.GVA_DB, global.GVA_LOG = db, zap.NewNop()
+	global.GVA_CONFIG.System.UseStrictAuth = false
+	t.Cleanup(func() {
+		// 清空规则缓存 避免影响其他测试
+		_ = systemService.AuthorityFieldServiceApp.SetAuthorityField(888, systemReq.SysAuthorityFieldReq{AuthorityId: 888, Resource: "*"})
+		global.GVA_DB, global.GVA_LOG, global.GVA_CONFIG.System = oldDB, oldLog, oldSystem
+	})
+	assert.Nil(t, systemService.AuthorityFieldServiceApp.SetAuthorityField(888, systemReq.SysAuthorityFieldReq{
+		AuthorityId: 888,
+		Resource:    "*",
+		Fields:      []system.SysAuthorityField{{Field: "phone", Action: "hide"}},
+	}))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("claims", &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{AuthorityId: 888}})
+	}, FieldPermission())
+	return r
+}
+
+func TestFieldPermissionMasksJSON(t *testing.T) {
+	r := newFieldPermissionRouter(t)
+	r.GET("/user", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"name": "a", "phone": "13800000000"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"a"}`, w.Body.String())
+}
+
+func TestFieldPermissionStreamsSSE(t *testing.T) {
+	r := newFieldPermissionRouter(t)
+	received := make(chan struct{})
+	r.GET("/stream", func(c *gin.Context) {
+		c.SSEvent("message", "one")
+		c.Writer.Flush()
+		// 客户端收到第一条事件后才发送第二条 响应被缓存时会等到超时
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			c.SSEvent("message", "timeout")
+			return
+		}
+		c.SSEvent("message", "two")
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/stream")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.Nil(t, err) {
+			return
+		}
+		if strings.HasPrefix(line, "data:") {
+			assert.Equal(t, "data:one\n", line)
+			break
+		}
+	}
+	close(received)
+	rest, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Contains(t, string(rest), "data:two")
+}
@@ -12,16 +12,15 @@ import (
 	"time"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
-	"github.com/flipped-aurora/gin-vue-admin/server/service"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-var operationRecordService = service.ServiceGroupApp.SystemServiceGroup.OperationRecordService
-
 var respPool sync.Pool
 var bufferSize = 1024
 
@@ -56,9 +55,8 @@ func OperationRecord() gin.HandlerFunc {
 			}
 			body, _ = json.Marshal(&m)
 		}
-		claims, _ := utils.GetClaims(c)
-		if claims != nil && claims.BaseClaims.ID != 0 {
-			userId = int(claims.BaseClaims.ID)
+		if uid := utils.GetUserID(c); uid != 0 {
+			userId = int(uid)
 		} else {
 			id, err := strconv.Atoi(c.Request.Header.Get("x-user-id"))
 			if err != nil {
@@ -101,6 +99,15 @@ func OperationRecord() gin.HandlerFunc {
 		record.Latency = latency
 		record.Resp = writer.body.String()
 
+		// 操作记录与用户实际看到的内容保持一致 按字段权限处理请求和响应
+		if _, ok := c.Get("claims"); ok {
+			path := strings.TrimPrefix(record.Path, global.GVA_CONFIG.System.RouterPrefix)
+			if rules := systemService.AuthorityFieldRules(utils.GetUserAuthorityId(c), path); len(rules) > 0 {
+				record.Body = string(utils.MaskJSON([]byte(record.Body), rules))
+				record.Resp = string(utils.MaskJSON([]byte(record.Resp), rules))
+			}
+		}
+
 		if strings.Contains(c.Writer.Header().Get("Pragma"), "public") ||
 			strings.Contains(c.Writer.Header().Get("Expires"), "0") ||
 			strings.Contains(c.Writer.Header().Get("Cache-Control"), "must-revalidate, post-check=0, pre-check=0") ||
@@ -116,7 +123,8 @@ func OperationRecord() gin.HandlerFunc {
 			}
 		}
 
-		if err := operationRecordService.CreateSysOperationRecord(record); err != nil {
+		// 由操作记录服务订阅入库
+		if err := event.Publish(c.Request.Context(), global.GVA_EVENT, system.EventOperationRecorded, record); err != nil {
 			global.GVA_LOG.Error("create operation record error:", zap.Error(err))
 		}
 	}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// passwordExpiredAllowed 密码过期后仍可访问的接口 用于修改密码和正常退出
+var passwordExpiredAllowed = map[string]struct{}{
+	"/user/changePassword": {},
+	"/user/getUserInfo":    {},
+	"/menu/getMenu":        {},
+	"/jwt/jsonInBlacklist": {},
+}
+
+// PasswordExpired 密码过期或被管理员重置后 拦截除修改密码外的请求
+func PasswordExpired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := utils.GetUserInfo(c)
+		if claims == nil || !claims.PasswordExpired {
+			c.Next()
+			return
+		}
+		path := strings.TrimPrefix(c.Request.URL.Path, global.GVA_CONFIG.System.RouterPrefix)
+		if _, ok := passwordExpiredAllowed[path]; ok {
+			c.Next()
+			return
+		}
+		response.FailWithDetailed(gin.H{"passwordExpired": true}, "密码已过期,请先修改密码", c)
+		c.Abort()
+	}
+}
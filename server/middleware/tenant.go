@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/service"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+)
+
+var tenantService = service.ServiceGroupApp.SystemServiceGroup.TenantService
+
+// TenantResolver 多租户识别 需放在 JWTAuth 之后 CasbinHandler 之前
+// 普通用户固定为jwt中的租户 子域名与之不符时拒绝访问 超级管理员可通过请求头或子域名切换租户
+func TenantResolver() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := global.GVA_CONFIG.Tenant
+		if !cfg.Enable {
+			c.Next()
+			return
+		}
+		claims := utils.GetUserInfo(c)
+		if claims == nil {
+			response.NoAuth("未登录或非法访问", c)
+			c.Abort()
+			return
+		}
+		header := cfg.Header
+		if header == "" {
+			header = "x-tenant-id"
+		}
+		scope, err := tenantService.ResolveScope(claims.AuthorityId, claims.TenantId, c.Request.Host, c.GetHeader(header))
+		if err != nil {
+			response.FailWithDetailed(gin.H{}, err.Error(), c)
+			c.Abort()
+			return
+		}
+		utils.SetTenant(c, scope)
+		c.Next()
+	}
+}
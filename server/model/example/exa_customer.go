@@ -7,6 +7,7 @@ import (
 
 type ExaCustomer struct {
 	global.GVA_MODEL
+	global.GVA_TENANT
 	CustomerName       string         `json:"customerName" form:"customerName" gorm:"comment:客户名"`                // 客户名
 	CustomerPhoneData  string         `json:"customerPhoneData" form:"customerPhoneData" gorm:"comment:客户手机号"`    // 客户手机号
 	SysUserID          uint           `json:"sysUserId" form:"sysUserId" gorm:"comment:管理ID"`                     // 管理ID
@@ -18,4 +18,8 @@ type BaseClaims struct {
 	Username    string
 	NickName    string
 	AuthorityId uint
+	// 密码已过期 仅允许修改密码等少量接口
+	PasswordExpired bool `json:",omitempty"`
+	// 所属租户 未开启多租户时为0
+	TenantId uint `json:",omitempty"`
 }
@@ -0,0 +1,22 @@
+package request
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+// CreateApiToken 创建个人访问令牌
+type CreateApiToken struct {
+	UserId    uint       `json:"userId"`    // 所属用户 仅管理员接口使用
+	Name      string     `json:"name"`      // 令牌名称
+	Scopes    []string   `json:"scopes"`    // 允许访问的接口 如 "GET /sysParams/*" 为空表示继承用户全部权限
+	ExpiresAt *time.Time `json:"expiresAt"` // 过期时间 为空表示永不过期
+}
+
+// ApiTokenSearch 令牌列表查询
+type ApiTokenSearch struct {
+	UserId uint   `json:"userId" form:"userId"`
+	Name   string `json:"name" form:"name"`
+	request.PageInfo
+}
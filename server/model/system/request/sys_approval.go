@@ -0,0 +1,31 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+
+// ApprovalFlowSearch 审批流程查询
+type ApprovalFlowSearch struct {
+	Action string `json:"action" form:"action"`
+	Name   string `json:"name" form:"name"`
+	request.PageInfo
+}
+
+// ApprovalRequestSearch 审批单查询
+type ApprovalRequestSearch struct {
+	Action      string `json:"action" form:"action"`
+	Status      string `json:"status" form:"status"`
+	RequesterId uint   `json:"requesterId" form:"requesterId"`
+	request.PageInfo
+}
+
+// ApprovalAct 审批 同意或驳回
+type ApprovalAct struct {
+	ID      uint   `json:"ID"`      // 审批单ID
+	Comment string `json:"comment"` // 审批意见 驳回时必填
+}
+
+// ApprovalImportExcel 待审批的表格导入 文件内容随审批单保存
+type ApprovalImportExcel struct {
+	TemplateID string `json:"templateID"`
+	FileName   string `json:"fileName"`
+	File       []byte `json:"file"`
+}
@@ -0,0 +1,9 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+
+type SysAuthorityFieldReq struct {
+	AuthorityId uint                       `json:"authorityId"`
+	Resource    string                     `json:"resource"` // 为空时获取该角色全部规则
+	Fields      []system.SysAuthorityField `json:"fields"`   // 设置时覆盖该角色在此资源上的全部规则
+}
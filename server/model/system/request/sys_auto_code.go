@@ -7,6 +7,7 @@ import (
 	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/pkg/errors"
 	"go/token"
+	"gorm.io/gorm/schema"
 	"strings"
 )
 
@@ -22,6 +23,7 @@ type AutoCode struct {
 	HumpPackageName     string                 `json:"humpPackageName" example:"go文件名称"`    // go文件名称
 	GvaModel            bool                   `json:"gvaModel" example:"false"`            // 是否使用gva默认Model
 	AutoMigrate         bool                   `json:"autoMigrate" example:"false"`         // 是否自动迁移表结构
+	Migration           bool                   `json:"migration" example:"false"`           // 是否生成迁移文件代替自动迁移表结构
 	AutoCreateResource  bool                   `json:"autoCreateResource" example:"false"`  // 是否自动创建资源标识
 	AutoCreateApiToSql  bool                   `json:"autoCreateApiToSql" example:"false"`  // 是否自动创建api
 	AutoCreateMenuToSql bool                   `json:"autoCreateMenuToSql" example:"false"` // 是否自动创建menu
@@ -31,8 +33,10 @@ type AutoCode struct {
 	TreeJson            string                 `json:"treeJson" example:"展示的树json字段"`       // 展示的树json字段
 	IsAdd               bool                   `json:"isAdd" example:"false"`               // 是否新增
 	Fields              []*AutoCodeField       `json:"fields"`
+	Children            []*AutoCodeChild       `json:"children"`                      // 子表 一对多或多对多关联
 	GenerateWeb         bool                   `json:"generateWeb" example:"true"`    // 是否生成web
 	GenerateServer      bool                   `json:"generateServer" example:"true"` // 是否生成server
+	GenerateTest        bool                   `json:"generateTest" example:"false"`  // 是否生成单元测试和接口测试
 	Module              string                 `json:"-"`
 	DictTypes           []string               `json:"-"`
 	PrimaryField        *AutoCodeField         `json:"primaryField"`
@@ -47,6 +51,7 @@ type AutoCode struct {
 	HasSearchTimer      bool                   `json:"-"`
 	HasArray            bool                   `json:"-"`
 	HasExcel            bool                   `json:"-"`
+	HasChildren         bool                   `json:"-"`
 }
 
 type DataSource struct {
@@ -58,6 +63,21 @@ type DataSource struct {
 	HasDeletedAt bool   `json:"hasDeletedAt"`
 }
 
+// AutoCodeUpdate 修改已生成结构的字段
+type AutoCodeUpdate struct {
+	AutoCode
+	DryRun bool `json:"dryRun" example:"true"` // 只预览变更 不写入文件
+}
+
+// AutoCodeOpenAPI 从OpenAPI文档生成代码
+type AutoCodeOpenAPI struct {
+	Path       string `json:"path" form:"path" example:"docs/openapi.yaml"` // 文档路径 相对于AutoCode.Root
+	Schema     string `json:"schema" example:"Book"`                        // components.schemas 中的名称
+	Package    string `json:"package" example:"example"`                    // 生成到的包
+	BusinessDB string `json:"businessDB" example:"业务数据库"`                   // 业务数据库
+	Mode       string `json:"mode" example:"preview"`                       // 为空只转换配置 preview 预览代码 create 创建字典并生成代码
+}
+
 func (r *AutoCode) Apis() []model.SysApi {
 	return []model.SysApi{
 		{
@@ -196,6 +216,9 @@ func (r *AutoCode) Pretreatment() error {
 			r.PrimaryField = new(AutoCodeField)
 		}
 	} // 新增字段模式下不关注主键
+	if err := r.pretreatmentChildren(); err != nil {
+		return err
+	} // 子表
 	if r.Package == "" {
 		return errors.New("Package为空!")
 	} // 增加判断：Package不为空
@@ -248,6 +271,112 @@ type AutoCodeField struct {
 	FieldIndexType  string      `json:"fieldIndexType"`  // 索引类型
 }
 
+// 子表关联关系
+const (
+	ChildHasMany   = "hasMany"   // 一对多 子表中保存主表ID
+	ChildMany2Many = "many2many" // 多对多 中间表中保存主表和子表ID
+)
+
+// AutoCodeChild 子表 随主表一起创建、更新和删除
+type AutoCodeChild struct {
+	StructName      string           `json:"structName" example:"Struct名称"`    // Struct名称
+	TableName       string           `json:"tableName" example:"表名"`           // 表名
+	Description     string           `json:"description" example:"Struct中文名称"` // Struct中文名称
+	FieldName       string           `json:"fieldName" example:"主表中的字段名"`      // 主表中的字段名
+	FieldJson       string           `json:"fieldJson" example:"主表中的字段json"`   // 主表中的字段json 为空时为字段名首字母小写
+	Association     string           `json:"association" example:"hasMany"`    // 关联关系 hasMany 一对多 many2many 多对多
+	JoinTable       string           `json:"joinTable" example:"中间表"`          // 多对多中间表 为空时为 主表_子表
+	Fields          []*AutoCodeField `json:"fields"`
+	ForeignKey      string           `json:"-"` // 子表或中间表中指向主表的字段
+	ForeignJson     string           `json:"-"`
+	ForeignColumn   string           `json:"-"`
+	ReferenceKey    string           `json:"-"` // 中间表中指向子表的字段
+	ReferenceColumn string           `json:"-"`
+}
+
+// ChildStructNames 子表结构体名称 需要随主表一起迁移
+func (r *AutoCode) ChildStructNames() []string {
+	names := make([]string, 0, len(r.Children))
+	for _, child := range r.Children {
+		names = append(names, child.StructName)
+	}
+	return names
+}
+
+// SearchFields 子表的搜索字段 字段名和json加上主表中的字段名前缀 避免与主表搜索条件重名
+func (c *AutoCodeChild) SearchFields() []*AutoCodeField {
+	fields := make([]*AutoCodeField, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		if f.FieldSearchType == "" || f.FieldJson == "" {
+			continue
+		}
+		field := *f
+		field.FieldName = c.FieldName + f.FieldName
+		field.FieldJson = c.FieldJson + strings.ToUpper(f.FieldJson[:1]) + f.FieldJson[1:]
+		fields = append(fields, &field)
+	}
+	return fields
+}
+
+// pretreatmentChildren 校验子表并补全外键
+func (r *AutoCode) pretreatmentChildren() error {
+	if len(r.Children) == 0 || r.OnlyTemplate || r.IsAdd {
+		return nil
+	}
+	if !r.GvaModel {
+		return errors.New("子表需要主表使用GVA默认Model!")
+	}
+	naming := schema.NamingStrategy{}
+	names := map[string]bool{r.StructName: true}
+	fields := map[string]bool{"Children": r.IsTree, "ParentID": r.IsTree}
+	for _, f := range r.Fields {
+		fields[f.FieldName] = true
+	}
+	for _, child := range r.Children {
+		if child.StructName == "" || child.TableName == "" || child.FieldName == "" {
+			return errors.New("子表的Struct名称、表名和主表中的字段名不能为空!")
+		}
+		if names[child.StructName] {
+			return errors.Errorf("子表Struct名称[%s]重复!", child.StructName)
+		}
+		names[child.StructName] = true
+		if fields[child.FieldName] {
+			return errors.Errorf("子表字段名[%s]与主表字段重复!", child.FieldName)
+		}
+		fields[child.FieldName] = true
+		if child.FieldJson == "" {
+			child.FieldJson = strings.ToLower(child.FieldName[:1]) + child.FieldName[1:]
+		}
+		child.ForeignKey = r.StructName + "ID"
+		child.ForeignJson = strings.ToLower(r.StructName[:1]) + r.StructName[1:] + "ID"
+		child.ForeignColumn = naming.ColumnName("", child.ForeignKey)
+		switch child.Association {
+		case ChildHasMany:
+		case ChildMany2Many:
+			if child.JoinTable == "" {
+				child.JoinTable = r.TableName + "_" + child.TableName
+			}
+			child.ReferenceKey = child.StructName + "ID"
+			child.ReferenceColumn = naming.ColumnName("", child.ReferenceKey)
+		default:
+			return errors.Errorf("子表[%s]的关联关系只能为%s或%s!", child.StructName, ChildHasMany, ChildMany2Many)
+		}
+		for _, f := range child.Fields {
+			switch f.FieldType {
+			case "file", "json", "array", "pictures":
+				r.NeedJSON = true
+			case "time.Time":
+				r.HasTimer = true
+				if f.FieldSearchType != "" {
+					r.HasSearchTimer = true
+				}
+			}
+		}
+	}
+	r.HasChildren = true
+	return nil
+}
+
 type AutoFunc struct {
 	Package         string `json:"package"`
 	FuncName        string `json:"funcName"`        // 方法名称
@@ -280,6 +409,12 @@ type InitApi struct {
 	APIs     []uint `json:"apis"`
 }
 
+// PluginUninstall 卸载通过插件包安装的插件 DryRun为true时只列出将要执行的操作
+type PluginUninstall struct {
+	Name   string `json:"name" form:"name"`
+	DryRun bool   `json:"dryRun" form:"dryRun"`
+}
+
 type LLMAutoCode struct {
 	Prompt string `json:"prompt" form:"prompt" gorm:"column:prompt;comment:提示语;type:text;"` //提示语
 	Mode   string `json:"mode" form:"mode" gorm:"column:mode;comment:模式;type:text;"`        //模式
@@ -29,3 +29,10 @@ func (r *SysAutoCodePackageCreate) Create() model.SysAutoCodePackage {
 		Module:      global.GVA_CONFIG.AutoCode.Module,
 	}
 }
+
+// TemplatePackPreview 预览并校验自定义模板包
+type TemplatePackPreview struct {
+	Template string    `json:"template" example:"模板包名称"`
+	Package  string    `json:"package" example:"预览使用的包名"` // 为空时使用example
+	AutoCode *AutoCode `json:"autoCode"`                  // 预览使用的结构, 为空时使用示例结构
+}
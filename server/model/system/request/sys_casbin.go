@@ -2,8 +2,10 @@ package request
 
 // CasbinInfo Casbin info structure
 type CasbinInfo struct {
-	Path   string `json:"path"`   // 路径
-	Method string `json:"method"` // 方法
+	Path        string `json:"path"`                  // 路径
+	Method      string `json:"method"`                // 方法
+	Inherited   bool   `json:"inherited,omitempty"`   // 是否继承自上级角色
+	InheritFrom uint   `json:"inheritFrom,omitempty"` // 继承来源角色id
 }
 
 // CasbinInReceive Casbin structure for input parameters
@@ -25,3 +27,11 @@ func DefaultCasbin() []CasbinInfo {
 		{Path: "/sysDictionary/findSysDictionary", Method: "GET"},
 	}
 }
+
+// CasbinSimulate 权限模拟 UserId 与 AuthorityId 二选一, 传 UserId 时会逐个评估该用户拥有的角色
+type CasbinSimulate struct {
+	UserId      uint   `json:"userId" form:"userId"`           // 用户id
+	AuthorityId uint   `json:"authorityId" form:"authorityId"` // 角色id
+	Path        string `json:"path" form:"path"`               // 请求路径 不含路由前缀
+	Method      string `json:"method" form:"method"`           // 请求方法
+}
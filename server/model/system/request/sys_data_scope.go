@@ -0,0 +1,6 @@
+package request
+
+// GetDataScopes 获取角色数据范围
+type GetDataScopes struct {
+	AuthorityId uint `json:"authorityId" form:"authorityId"` // 角色ID
+}
@@ -0,0 +1,34 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+
+// MoveDepartment 移动部门
+type MoveDepartment struct {
+	ID       uint `json:"ID"`       // 部门ID
+	ParentId uint `json:"parentId"` // 新的父部门ID 0为顶级
+}
+
+// MergeDepartment 合并部门 源部门的子部门和成员并入目标部门后删除源部门
+type MergeDepartment struct {
+	SourceId uint `json:"sourceId"` // 源部门ID
+	TargetId uint `json:"targetId"` // 目标部门ID
+}
+
+// SetUserDepartments 设置用户所属部门
+type SetUserDepartments struct {
+	UserId        uint   `json:"userId"`        // 用户ID
+	PrimaryId     uint   `json:"primaryId"`     // 主部门ID 0为无
+	DepartmentIds []uint `json:"departmentIds"` // 兼职部门ID
+}
+
+// SetDepartmentLeaders 设置部门负责人
+type SetDepartmentLeaders struct {
+	DepartmentId uint   `json:"departmentId"` // 部门ID
+	UserIds      []uint `json:"userIds"`      // 负责人用户ID 必须是部门成员
+}
+
+// DepartmentUserSearch 部门成员查询
+type DepartmentUserSearch struct {
+	DepartmentId uint `json:"departmentId" form:"departmentId"`
+	request.PageInfo
+}
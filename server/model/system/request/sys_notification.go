@@ -0,0 +1,36 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+
+// NotificationTemplateSearch 站内信模板查询
+type NotificationTemplateSearch struct {
+	Code     string `json:"code" form:"code"`
+	Name     string `json:"name" form:"name"`
+	Category string `json:"category" form:"category"`
+	request.PageInfo
+}
+
+// NotificationSearch 我的站内信查询
+type NotificationSearch struct {
+	Category string `json:"category" form:"category"`
+	Unread   bool   `json:"unread" form:"unread"` // 只看未读
+	request.PageInfo
+}
+
+// NotificationSend 手动发送站内信 指定模板时按模板渲染 否则直接使用标题和内容
+type NotificationSend struct {
+	UserIds      []uint                 `json:"userIds"`
+	TemplateCode string                 `json:"templateCode"`
+	Data         map[string]interface{} `json:"data"`
+	Category     string                 `json:"category"`
+	Title        string                 `json:"title"`
+	Content      string                 `json:"content"`
+	Link         string                 `json:"link"`
+}
+
+// NotificationRead 标记已读或删除 Ids 为空且 All 为true时作用于全部(可按分类)
+type NotificationRead struct {
+	Ids      []uint `json:"ids"`
+	All      bool   `json:"all"`
+	Category string `json:"category"`
+}
@@ -0,0 +1,9 @@
+package request
+
+// OidcCallback 身份提供方回调参数
+type OidcCallback struct {
+	Code             string `json:"code" form:"code"`
+	State            string `json:"state" form:"state"`
+	Error            string `json:"error" form:"error"`
+	ErrorDescription string `json:"error_description" form:"error_description"`
+}
@@ -0,0 +1,12 @@
+package request
+
+// ForgotPassword 申请找回密码
+type ForgotPassword struct {
+	Account string `json:"account"` // 用户名或邮箱
+}
+
+// ResetPasswordByToken 通过邮件中的令牌重置密码
+type ResetPasswordByToken struct {
+	Token       string `json:"token"`       // 邮件链接中的令牌
+	NewPassword string `json:"newPassword"` // 新密码
+}
@@ -0,0 +1,12 @@
+package request
+
+const (
+	RbacImportSkip      = "skip"      // 已存在的配置保持不变 只新增
+	RbacImportOverwrite = "overwrite" // 已存在的配置以导入包为准 角色的菜单、按钮、策略整体替换
+	RbacImportMerge     = "merge"     // 已存在的配置更新基础字段 角色的菜单、按钮、策略取并集
+)
+
+// RbacImport 导入权限配置包 文件通过 multipart 的 file 字段上传
+type RbacImport struct {
+	Strategy string `json:"strategy" form:"strategy"` // 冲突处理策略 skip|overwrite|merge
+}
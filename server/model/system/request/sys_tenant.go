@@ -0,0 +1,10 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+
+// TenantSearch 租户查询
+type TenantSearch struct {
+	Code string `json:"code" form:"code"`
+	Name string `json:"name" form:"name"`
+	request.PageInfo
+}
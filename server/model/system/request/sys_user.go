@@ -16,6 +16,7 @@ type Register struct {
 	AuthorityIds []uint `json:"authorityIds" swaggertype:"string" example:"[]uint 角色id"`
 	Phone        string `json:"phone" example:"电话号码"`
 	Email        string `json:"email" example:"电子邮箱"`
+	TenantId     uint   `json:"tenantId" swaggertype:"string" example:"int 租户id 多租户模式下仅超级管理员可指定"`
 }
 
 // Login User login structure
@@ -0,0 +1,31 @@
+package request
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+// CreateAuthorityGrant 限时授权或委托角色
+type CreateAuthorityGrant struct {
+	UserId      uint       `json:"userId"`      // 被授权用户ID
+	AuthorityId uint       `json:"authorityId"` // 角色ID
+	ValidFrom   *time.Time `json:"validFrom"`   // 生效时间 为空表示立即生效
+	ValidUntil  time.Time  `json:"validUntil"`  // 失效时间
+	Reason      string     `json:"reason"`      // 授权原因 委托时必填
+}
+
+// RevokeAuthorityGrant 撤销授权
+type RevokeAuthorityGrant struct {
+	ID     uint   `json:"id"`
+	Reason string `json:"reason"` // 撤销原因
+}
+
+// AuthorityGrantSearch 授权列表查询
+type AuthorityGrantSearch struct {
+	UserId      uint   `json:"userId" form:"userId"`
+	DelegatorId uint   `json:"delegatorId" form:"delegatorId"`
+	AuthorityId uint   `json:"authorityId" form:"authorityId"`
+	Status      string `json:"status" form:"status"`
+	request.PageInfo
+}
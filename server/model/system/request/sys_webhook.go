@@ -0,0 +1,18 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+
+// WebhookSearch webhook查询
+type WebhookSearch struct {
+	Name   string `json:"name" form:"name"`
+	Active *bool  `json:"active" form:"active"`
+	request.PageInfo
+}
+
+// WebhookDeliverySearch webhook投递记录查询
+type WebhookDeliverySearch struct {
+	WebhookId uint   `json:"webhookId" form:"webhookId"`
+	Event     string `json:"event" form:"event"`
+	Status    string `json:"status" form:"status"`
+	request.PageInfo
+}
@@ -0,0 +1,9 @@
+package response
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+
+// ApiTokenResponse 创建令牌的返回 明文令牌只在此时返回一次
+type ApiTokenResponse struct {
+	Token    string             `json:"token"`
+	ApiToken system.SysApiToken `json:"apiToken"`
+}
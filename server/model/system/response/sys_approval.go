@@ -0,0 +1,7 @@
+package response
+
+// ApprovalAction 可配置审批流程的操作
+type ApprovalAction struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
@@ -1,5 +1,10 @@
 package response
 
+import (
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
 type Db struct {
 	Database string `json:"database" gorm:"column:database"`
 }
@@ -15,3 +20,37 @@ type Column struct {
 	ColumnComment string `json:"columnComment" gorm:"column:column_comment"`
 	PrimaryKey    bool   `json:"primaryKey" gorm:"column:primary_key"`
 }
+
+// AutoCodeUpdate 修改已生成结构的结果 文件路径均相对于AutoCode.Root
+type AutoCodeUpdate struct {
+	Added          []string `json:"added"`          // 新增字段
+	Removed        []string `json:"removed"`        // 删除字段
+	Changed        []string `json:"changed"`        // 修改字段
+	Files          []string `json:"files"`          // 已合并的文件
+	Conflicts      []string `json:"conflicts"`      // 手动修改过而未合并的字段
+	Manual         []string `json:"manual"`         // 需要参照新模板手动调整的文件
+	MigrationUp    string   `json:"migrationUp"`    // 字段变更SQL
+	MigrationDown  string   `json:"migrationDown"`  // 回退SQL
+	MigrationFiles []string `json:"migrationFiles"` // 迁移文件
+}
+
+// OpenAPISchema OpenAPI文档中可生成代码的Schema
+type OpenAPISchema struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// OpenAPIDictionary 枚举对应的字典 Exists 为已存在同类型字典
+type OpenAPIDictionary struct {
+	model.SysDictionary
+	Exists bool `json:"exists"`
+}
+
+// AutoCodeOpenAPI OpenAPI文档转换结果
+type AutoCodeOpenAPI struct {
+	AutoCode     request.AutoCode    `json:"autoCode"`          // 转换后的代码生成配置
+	Dictionaries []OpenAPIDictionary `json:"dictionaries"`      // 枚举对应的字典
+	Warnings     []string            `json:"warnings"`          // 无法准确转换的字段
+	Preview      map[string]string   `json:"preview,omitempty"` // 预览代码
+}
@@ -0,0 +1,12 @@
+package response
+
+// TemplatePackPreview 模板包预览结果 文件路径均相对于AutoCode.Root
+type TemplatePackPreview struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Extends     string            `json:"extends"`    // 继承的内置模板
+	Files       map[string]string `json:"files"`      // 生成路径与代码
+	Injections  []string          `json:"injections"` // 清单声明的注入点
+	Problems    []string          `json:"problems"`   // 清单错误、渲染失败和违反约束的文件
+	Valid       bool              `json:"valid"`
+}
@@ -7,3 +7,31 @@ import (
 type PolicyPathResponse struct {
 	Paths []request.CasbinInfo `json:"paths"`
 }
+
+// CasbinSimulateResult 单个角色的权限判定结果
+type CasbinSimulateResult struct {
+	AuthorityId   uint       `json:"authorityId"`
+	AuthorityName string     `json:"authorityName"`
+	Current       bool       `json:"current"`    // 是否为用户当前使用的角色, CasbinHandler 只按当前角色判定
+	Allowed       bool       `json:"allowed"`    // 是否放行
+	Matched       []string   `json:"matched"`    // 命中的策略
+	Candidates    [][]string `json:"candidates"` // 路径匹配但方法不同的策略
+	Reason        string     `json:"reason"`     // 判定说明
+}
+
+// CasbinSimulateResponse 权限模拟结果
+type CasbinSimulateResponse struct {
+	Path          string                 `json:"path"`
+	Method        string                 `json:"method"`
+	ApiRegistered bool                   `json:"apiRegistered"` // 接口是否存在于 api 列表
+	Allowed       bool                   `json:"allowed"`       // 实际请求时的判定结果
+	Results       []CasbinSimulateResult `json:"results"`
+	GrantedBy     []uint                 `json:"grantedBy"` // 拥有该接口权限的全部角色
+}
+
+// CasbinDiffResponse 更新角色api权限前的差异预览
+type CasbinDiffResponse struct {
+	Added     []request.CasbinInfo `json:"added"`     // 将新增的权限
+	Removed   []request.CasbinInfo `json:"removed"`   // 将移除的权限
+	Unchanged int                  `json:"unchanged"` // 保持不变的权限数量
+}
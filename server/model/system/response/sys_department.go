@@ -0,0 +1,10 @@
+package response
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+
+// DepartmentUser 部门成员
+type DepartmentUser struct {
+	system.SysUser
+	IsPrimary bool `json:"isPrimary"`
+	IsLeader  bool `json:"isLeader"`
+}
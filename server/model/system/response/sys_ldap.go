@@ -0,0 +1,24 @@
+package response
+
+// LdapSyncUser 同步中单个用户的变更
+type LdapSyncUser struct {
+	UserId       uint     `json:"userId,omitempty"` // 本地用户ID 新建时为空
+	Username     string   `json:"username"`
+	NickName     string   `json:"nickName"`
+	Email        string   `json:"email"`
+	Phone        string   `json:"phone"`
+	AuthorityIds []uint   `json:"authorityIds"` // 同步后的角色
+	Changes      []string `json:"changes"`      // 变更的字段
+	Reason       string   `json:"reason,omitempty"`
+}
+
+// LdapSyncDiff 目录与本地用户的差异 dryRun 时仅预览不落库
+type LdapSyncDiff struct {
+	DryRun    bool           `json:"dryRun"`
+	Create    []LdapSyncUser `json:"create"`    // 新建的用户
+	Update    []LdapSyncUser `json:"update"`    // 更新资料或角色的用户
+	Disable   []LdapSyncUser `json:"disable"`   // 目录中已不存在而被冻结的用户
+	Conflicts []LdapSyncUser `json:"conflicts"` // 用户名被未绑定的本地账号占用 需人工处理
+
+	DisableSkipped string `json:"disableSkipped,omitempty"` // 目录返回的用户过少而未执行冻结的原因
+}
@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Module    string     `json:"module"`
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	DB        string     `json:"db"`        // 业务库 空为主库
+	Applied   bool       `json:"applied"`   // 是否已执行
+	AppliedAt *time.Time `json:"appliedAt"` // 执行时间
+	Missing   bool       `json:"missing"`   // 已执行但迁移文件不存在
+}
@@ -0,0 +1,7 @@
+package response
+
+// NotificationUnread 未读站内信数量
+type NotificationUnread struct {
+	Total      int64            `json:"total"`
+	Categories map[string]int64 `json:"categories"` // 按分类统计
+}
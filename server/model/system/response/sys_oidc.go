@@ -0,0 +1,5 @@
+package response
+
+type OidcAuthorizeResponse struct {
+	AuthURL string `json:"authUrl"` // 身份提供方授权地址
+}
@@ -0,0 +1,10 @@
+package response
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+
+// PluginSteps 插件安装或卸载依次执行的操作 预演时为将要执行的操作
+type PluginSteps struct {
+	Manifest system.PluginManifest `json:"manifest"`
+	DryRun   bool                  `json:"dryRun"`
+	Steps    []string              `json:"steps"`
+}
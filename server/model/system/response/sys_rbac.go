@@ -0,0 +1,18 @@
+package response
+
+// RbacDiffSection 单类配置的差异 与 SyncApi 一致 仅在当前环境存在的配置只做提示 导入时不会删除
+type RbacDiffSection struct {
+	Added     []string `json:"added"`     // 将新增
+	Changed   []string `json:"changed"`   // 已存在且内容不同
+	Missing   []string `json:"missing"`   // 当前环境存在但导入包中没有
+	Unchanged int      `json:"unchanged"` // 完全一致
+}
+
+// RbacImportResult 导入预览或导入结果
+type RbacImportResult struct {
+	Strategy    string          `json:"strategy"`
+	Applied     bool            `json:"applied"` // 为 false 时仅为预览
+	Apis        RbacDiffSection `json:"apis"`
+	Menus       RbacDiffSection `json:"menus"`
+	Authorities RbacDiffSection `json:"authorities"`
+}
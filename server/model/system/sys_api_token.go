@@ -0,0 +1,63 @@
+package system
+
+import (
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"gorm.io/datatypes"
+)
+
+// SysApiToken 个人访问令牌 用于脚本等机器间调用 只保存哈希
+type SysApiToken struct {
+	global.GVA_MODEL
+	UserId     uint                        `json:"userId" gorm:"index;comment:所属用户ID"`                                    // 所属用户ID
+	Name       string                      `json:"name" gorm:"comment:令牌名称"`                                              // 令牌名称
+	Prefix     string                      `json:"prefix" gorm:"comment:令牌前缀 用于识别"`                                       // 令牌前缀 用于识别
+	TokenHash  string                      `json:"-" gorm:"uniqueIndex;size:64;comment:令牌哈希"`                             // 令牌哈希
+	Scopes     datatypes.JSONSlice[string] `json:"scopes" gorm:"comment:允许访问的接口 为空表示继承用户全部权限" swaggertype:"array,string"` // 允许访问的接口
+	ExpiresAt  *time.Time                  `json:"expiresAt" gorm:"comment:过期时间 为空表示永不过期"`                                // 过期时间
+	RevokedAt  *time.Time                  `json:"revokedAt" gorm:"comment:吊销时间"`                                         // 吊销时间
+	LastUsedAt *time.Time                  `json:"lastUsedAt" gorm:"comment:最后使用时间"`                                      // 最后使用时间
+	LastUsedIp string                      `json:"lastUsedIp" gorm:"comment:最后使用IP"`                                      // 最后使用IP
+}
+
+func (SysApiToken) TableName() string {
+	return "sys_api_tokens"
+}
+
+// Active 未吊销且未过期
+func (t *SysApiToken) Active() bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || t.ExpiresAt.After(time.Now())
+}
+
+// Allow 判断令牌的范围是否包含该请求
+// 范围格式为 "/path" 或 "METHOD /path" 路径以 * 结尾时按前缀匹配
+func (t *SysApiToken) Allow(method, path string) bool {
+	if len(t.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range t.Scopes {
+		m, p, ok := strings.Cut(strings.TrimSpace(scope), " ")
+		if !ok {
+			m, p = "", m
+		}
+		if m != "" && m != "*" && !strings.EqualFold(m, method) {
+			continue
+		}
+		p = strings.TrimSpace(p)
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+			continue
+		}
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
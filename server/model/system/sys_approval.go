@@ -0,0 +1,121 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"gorm.io/datatypes"
+)
+
+// 需要审批的敏感操作
+const (
+	ApprovalActionDeleteUser         = "user.delete"
+	ApprovalActionSetUserAuthorities = "user.setAuthorities"
+	ApprovalActionUpdateCasbin       = "casbin.update"
+	ApprovalActionRollBackAutoCode   = "autoCode.rollback"
+	ApprovalActionImportExcel        = "exportTemplate.importExcel"
+)
+
+// 审批人类型
+const (
+	ApproverUser      = "user"      // 指定用户
+	ApproverAuthority = "authority" // 指定角色下的全部用户
+	ApproverLeader    = "leader"    // 申请人所在部门负责人
+)
+
+// 同一步骤多个审批人时的通过方式
+const (
+	ApprovalModeAny = "any" // 或签 任一审批人通过即可
+	ApprovalModeAll = "all" // 会签 全部审批人通过
+)
+
+// 审批单状态
+const (
+	ApprovalPending   = "pending"   // 审批中
+	ApprovalApproved  = "approved"  // 已通过 等待执行
+	ApprovalExecuting = "executing" // 执行中
+	ApprovalExecuted  = "executed"  // 已执行
+	ApprovalFailed    = "failed"    // 执行失败
+	ApprovalRejected  = "rejected"  // 已驳回
+	ApprovalCanceled  = "canceled"  // 已撤回
+)
+
+// 审批任务状态
+const (
+	ApprovalTaskWaiting  = "waiting"  // 尚未轮到该步骤
+	ApprovalTaskPending  = "pending"  // 待处理
+	ApprovalTaskApproved = "approved" // 已同意
+	ApprovalTaskRejected = "rejected" // 已驳回
+	ApprovalTaskSkipped  = "skipped"  // 无需处理 或签已通过或审批单已结束
+)
+
+// SysApprovalFlow 审批流程定义 每个操作最多一个流程
+type SysApprovalFlow struct {
+	global.GVA_MODEL
+	Name   string            `json:"name" gorm:"comment:流程名称"`                 // 流程名称
+	Action string            `json:"action" gorm:"index;size:64;comment:受控操作"` // 受控操作
+	Enable bool              `json:"enable" gorm:"comment:是否启用"`               // 是否启用
+	Remark string            `json:"remark" gorm:"comment:备注"`                 // 备注
+	Steps  []SysApprovalStep `json:"steps" gorm:"foreignKey:FlowId"`           // 审批步骤 按顺序依次审批
+}
+
+func (SysApprovalFlow) TableName() string {
+	return "sys_approval_flows"
+}
+
+// SysApprovalStep 审批步骤 步骤之间顺序执行 步骤内多个审批人并行处理
+type SysApprovalStep struct {
+	ID           uint                      `json:"ID" gorm:"primarykey"`
+	FlowId       uint                      `json:"flowId" gorm:"index;comment:流程ID"`                                 // 流程ID
+	Sort         int                       `json:"sort" gorm:"comment:顺序"`                                           // 顺序
+	Name         string                    `json:"name" gorm:"comment:步骤名称"`                                         // 步骤名称
+	ApproverType string                    `json:"approverType" gorm:"size:16;comment:审批人类型 user/authority/leader"`  // 审批人类型
+	ApproverIds  datatypes.JSONSlice[uint] `json:"approverIds" gorm:"comment:用户ID或角色ID" swaggertype:"array,integer"` // 用户ID或角色ID 部门负责人时为空
+	Mode         string                    `json:"mode" gorm:"size:16;default:any;comment:多人审批方式 any或签 all会签"`       // 多人审批方式
+}
+
+func (SysApprovalStep) TableName() string {
+	return "sys_approval_steps"
+}
+
+// SysApprovalRequest 审批单 记录被拦截操作的完整参数 审批通过后按申请人身份执行
+type SysApprovalRequest struct {
+	global.GVA_MODEL
+	global.GVA_TENANT
+	FlowId               uint                                 `json:"flowId" gorm:"index;comment:流程ID"`                           // 流程ID
+	FlowName             string                               `json:"flowName" gorm:"comment:流程名称"`                               // 流程名称
+	Action               string                               `json:"action" gorm:"index;size:64;comment:受控操作"`                   // 受控操作
+	Summary              string                               `json:"summary" gorm:"comment:操作摘要"`                                // 操作摘要
+	Payload              datatypes.JSON                       `json:"payload,omitempty" gorm:"comment:操作参数" swaggertype:"object"` // 操作参数
+	Steps                datatypes.JSONSlice[SysApprovalStep] `json:"steps" gorm:"comment:提交时的流程步骤" swaggertype:"array,object"`   // 提交时的流程步骤
+	CurrentStep          int                                  `json:"currentStep" gorm:"comment:当前步骤"`                            // 当前步骤 从0开始
+	Status               string                               `json:"status" gorm:"index;size:16;comment:状态"`                     // 状态
+	RequesterId          uint                                 `json:"requesterId" gorm:"index;comment:申请人ID"`                     // 申请人ID
+	RequesterName        string                               `json:"requesterName" gorm:"comment:申请人用户名"`                        // 申请人用户名
+	RequesterAuthorityId uint                                 `json:"requesterAuthorityId" gorm:"comment:申请时的角色ID"`               // 申请时的角色ID 执行时以该角色校验权限
+	TenantAll            bool                                 `json:"tenantAll" gorm:"comment:申请时可访问全部租户"`                        // 申请时可访问全部租户
+	Result               string                               `json:"result" gorm:"type:text;comment:处理结果"`                       // 驳回原因、执行结果或失败原因
+	FinishedAt           *time.Time                           `json:"finishedAt" gorm:"comment:结束时间"`                             // 结束时间
+	Tasks                []SysApprovalTask                    `json:"tasks,omitempty" gorm:"foreignKey:RequestId"`                // 审批记录
+}
+
+func (SysApprovalRequest) TableName() string {
+	return "sys_approval_requests"
+}
+
+// SysApprovalTask 审批任务 每个步骤的每个审批人一条 同时作为审批记录
+type SysApprovalTask struct {
+	ID         uint       `json:"ID" gorm:"primarykey"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RequestId  uint       `json:"requestId" gorm:"index;comment:审批单ID"`   // 审批单ID
+	Step       int        `json:"step" gorm:"comment:步骤"`                 // 步骤 从0开始
+	StepName   string     `json:"stepName" gorm:"comment:步骤名称"`           // 步骤名称
+	ApproverId uint       `json:"approverId" gorm:"index;comment:审批人ID"`  // 审批人ID
+	Status     string     `json:"status" gorm:"index;size:16;comment:状态"` // 状态
+	Comment    string     `json:"comment" gorm:"comment:审批意见"`            // 审批意见
+	ActedAt    *time.Time `json:"actedAt" gorm:"comment:处理时间"`            // 处理时间
+}
+
+func (SysApprovalTask) TableName() string {
+	return "sys_approval_tasks"
+}
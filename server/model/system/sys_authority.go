@@ -16,6 +16,7 @@ type SysAuthority struct {
 	SysBaseMenus    []SysBaseMenu   `json:"menus" gorm:"many2many:sys_authority_menus;"`
 	Users           []SysUser       `json:"-" gorm:"many2many:sys_user_authority;"`
 	DefaultRouter   string          `json:"defaultRouter" gorm:"comment:默认菜单;default:dashboard"` // 默认菜单(默认dashboard)
+	TenantId        uint            `json:"tenantId" gorm:"default:0;comment:所属租户 0为全部租户共用"`     // 所属租户 用于casbin域隔离
 }
 
 func (SysAuthority) TableName() string {
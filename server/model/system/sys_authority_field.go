@@ -0,0 +1,14 @@
+package system
+
+// SysAuthorityField 角色字段权限 控制接口返回、导出和操作记录中字段的可见性
+type SysAuthorityField struct {
+	ID          uint   `gorm:"primarykey" json:"ID"`
+	AuthorityId uint   `json:"authorityId" gorm:"index;comment:角色ID"`            // 角色ID
+	Resource    string `json:"resource" gorm:"size:191;comment:接口路径或导出表名 *为全部"`  // 接口路径(如 /user/getUserList)或导出表名 *为全部
+	Field       string `json:"field" gorm:"size:64;comment:字段名 接口为json字段 导出为列名"` // 字段名
+	Action      string `json:"action" gorm:"size:16;comment:hide隐藏 mask脱敏"`      // hide隐藏 mask脱敏
+}
+
+func (SysAuthorityField) TableName() string {
+	return "sys_authority_fields"
+}
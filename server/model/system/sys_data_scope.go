@@ -0,0 +1,28 @@
+package system
+
+import "github.com/flipped-aurora/gin-vue-admin/server/global"
+
+// 数据范围
+const (
+	DataScopeAll               = "all"               // 全部数据
+	DataScopeSelf              = "self"              // 仅本人创建的数据
+	DataScopeDept              = "dept"              // 本部门成员创建的数据
+	DataScopeAuthorityChildren = "authorityChildren" // 本角色及子角色成员创建的数据
+	DataScopeCustom            = "custom"            // 角色资源权限(DataAuthorityId)中的角色成员创建的数据
+)
+
+// DataScopeAllTables 对该角色未单独配置且存在创建者字段的表生效
+const DataScopeAllTables = "*"
+
+// SysDataScope 角色的行级数据权限 按表配置
+type SysDataScope struct {
+	global.GVA_MODEL
+	AuthorityId uint   `json:"authorityId" gorm:"uniqueIndex:idx_authority_table;comment:角色ID"`                          // 角色ID
+	Table       string `json:"table" gorm:"column:table_name;size:128;uniqueIndex:idx_authority_table;comment:表名 *为全部表"` // 表名 *为全部表
+	Scope       string `json:"scope" gorm:"size:32;comment:数据范围"`                                                        // 数据范围
+	Column      string `json:"column" gorm:"size:64;comment:记录创建者字段 默认created_by"`                                       // 记录创建者字段
+}
+
+func (SysDataScope) TableName() string {
+	return "sys_data_scopes"
+}
@@ -0,0 +1,30 @@
+package system
+
+import "github.com/flipped-aurora/gin-vue-admin/server/global"
+
+// SysDepartment 部门
+type SysDepartment struct {
+	global.GVA_MODEL
+	ParentId uint            `json:"parentId" gorm:"index;default:0;comment:父部门ID"` // 父部门ID 0为顶级
+	Name     string          `json:"name" gorm:"comment:部门名称"`                      // 部门名称
+	Sort     int             `json:"sort" gorm:"default:0;comment:排序"`              // 排序
+	Children []SysDepartment `json:"children" gorm:"-"`
+	Leaders  []SysUser       `json:"leaders" gorm:"-"`
+}
+
+func (SysDepartment) TableName() string {
+	return "sys_departments"
+}
+
+// SysUserDepartment 用户所属部门 每个用户最多一个主部门 可有多个兼职部门
+type SysUserDepartment struct {
+	ID           uint `gorm:"primarykey" json:"ID"`
+	UserId       uint `json:"userId" gorm:"uniqueIndex:idx_user_department;comment:用户ID"`       // 用户ID
+	DepartmentId uint `json:"departmentId" gorm:"uniqueIndex:idx_user_department;comment:部门ID"` // 部门ID
+	IsPrimary    bool `json:"isPrimary" gorm:"comment:是否主部门"`                                   // 是否主部门
+	IsLeader     bool `json:"isLeader" gorm:"comment:是否部门负责人"`                                  // 是否部门负责人
+}
+
+func (SysUserDepartment) TableName() string {
+	return "sys_user_departments"
+}
@@ -0,0 +1,75 @@
+package system
+
+import "github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+
+// 核心事件 插件可通过 event.Subscribe(global.GVA_EVENT, system.EventUserCreated, handler) 订阅
+// 开启 system.event-stream 后, 订阅时使用 event.Remote() 可同时收到其他实例发布的事件
+var (
+	// EventUserCreated 用户创建后发布
+	EventUserCreated = event.Topic[UserEvent]("user.created")
+	// EventUserDeleted 用户删除后发布
+	EventUserDeleted = event.Topic[UserEvent]("user.deleted")
+	// EventUserLogin 用户登录成功并签发token后发布
+	EventUserLogin = event.Topic[UserLoginEvent]("user.login")
+	// EventAuthorityChanged 角色创建、复制、更新、删除、导入及菜单、api、数据范围、字段权限变更后发布
+	EventAuthorityChanged = event.Topic[AuthorityEvent]("authority.changed")
+	// EventDictionaryChanged 字典及字典详情创建、更新、删除后发布
+	EventDictionaryChanged = event.Topic[DictionaryEvent]("dictionary.changed")
+	// EventFileUploaded 文件上传并保存记录后发布
+	EventFileUploaded = event.Topic[FileUploadedEvent]("file.uploaded")
+	// EventOperationRecorded 操作记录中间件在请求结束后同步发布, 由操作记录服务订阅入库, 只在本实例内分发
+	EventOperationRecorded = event.Topic[SysOperationRecord]("operation.recorded")
+)
+
+// 字典变更类型
+const (
+	DictionaryCreated = "create"
+	DictionaryUpdated = "update"
+	DictionaryDeleted = "delete"
+)
+
+// 角色变更类型
+const (
+	AuthorityCreated   = "create"
+	AuthorityCopied    = "copy"
+	AuthorityUpdated   = "update"
+	AuthorityDeleted   = "delete"
+	AuthorityMenus     = "menus"
+	AuthorityApis      = "apis"
+	AuthorityImported  = "import"
+	AuthorityDataScope = "dataScope"
+	AuthorityFields    = "fields"
+)
+
+type UserEvent struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	AuthorityId uint   `json:"authorityId"`
+}
+
+type UserLoginEvent struct {
+	UserEvent
+	Ip    string `json:"ip"`
+	Agent string `json:"agent"`
+}
+
+type AuthorityEvent struct {
+	AuthorityId uint   `json:"authorityId"`
+	Action      string `json:"action"`
+}
+
+// DictionaryEvent 字典详情变更时 DetailId 不为0
+type DictionaryEvent struct {
+	DictionaryId uint   `json:"dictionaryId"`
+	Type         string `json:"type"`
+	DetailId     uint   `json:"detailId"`
+	Action       string `json:"action"`
+}
+
+type FileUploadedEvent struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Url  string `json:"url"`
+	Key  string `json:"key"`
+	Tag  string `json:"tag"`
+}
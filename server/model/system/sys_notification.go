@@ -0,0 +1,47 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// 站内信分类 模板和发送方可自定义其他分类
+const (
+	NotificationCategorySystem   = "system"   // 系统通知
+	NotificationCategoryApproval = "approval" // 审批通知
+	NotificationCategorySecurity = "security" // 安全提醒
+)
+
+// SysNotificationTemplate 站内信模板 标题和内容使用 text/template 语法 如 {{.Name}}
+type SysNotificationTemplate struct {
+	global.GVA_MODEL
+	Code     string `json:"code" gorm:"uniqueIndex;size:64;comment:模板编码"`      // 模板编码 发送时使用
+	Name     string `json:"name" gorm:"comment:模板名称"`                          // 模板名称
+	Category string `json:"category" gorm:"size:32;default:system;comment:分类"` // 分类
+	Title    string `json:"title" gorm:"comment:标题模板"`                         // 标题模板
+	Content  string `json:"content" gorm:"type:text;comment:内容模板"`             // 内容模板
+	Link     string `json:"link" gorm:"comment:跳转地址模板"`                        // 跳转地址模板 可为空
+	Remark   string `json:"remark" gorm:"comment:备注"`                          // 备注
+}
+
+func (SysNotificationTemplate) TableName() string {
+	return "sys_notification_templates"
+}
+
+// SysNotification 用户站内信
+type SysNotification struct {
+	global.GVA_MODEL
+	UserId       uint       `json:"userId" gorm:"index:idx_notification_user_read;comment:接收人ID"` // 接收人ID
+	Category     string     `json:"category" gorm:"index;size:32;comment:分类"`                     // 分类
+	Title        string     `json:"title" gorm:"comment:标题"`                                      // 标题
+	Content      string     `json:"content" gorm:"type:text;comment:内容"`                          // 内容
+	Link         string     `json:"link" gorm:"comment:跳转地址"`                                     // 跳转地址
+	TemplateCode string     `json:"templateCode" gorm:"size:64;comment:模板编码"`                     // 模板编码 直接发送时为空
+	IsRead       bool       `json:"isRead" gorm:"index:idx_notification_user_read;comment:是否已读"`  // 是否已读
+	ReadAt       *time.Time `json:"readAt" gorm:"comment:阅读时间"`                                   // 阅读时间
+}
+
+func (SysNotification) TableName() string {
+	return "sys_notifications"
+}
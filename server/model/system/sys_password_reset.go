@@ -0,0 +1,21 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysPasswordReset 找回密码令牌 只保存哈希 使用后即失效
+type SysPasswordReset struct {
+	global.GVA_MODEL
+	UserId    uint       `json:"userId" gorm:"index;comment:用户ID"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;comment:令牌哈希"`
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"comment:过期时间"`
+	UsedAt    *time.Time `json:"usedAt" gorm:"index;comment:使用时间"`
+	RequestIp string     `json:"requestIp" gorm:"comment:申请IP"`
+}
+
+func (SysPasswordReset) TableName() string {
+	return "sys_password_resets"
+}
@@ -0,0 +1,34 @@
+package system
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// PluginManifest 插件包中的 plugin.json 描述安装插件需要的信息
+// 菜单与 api 使用与权限配置包相同的结构 菜单以路由 name、api 以 method+path 作为唯一标识
+type PluginManifest struct {
+	Name        string     `json:"name"`        // 插件名 与 server/plugin、web/plugin 下的目录名及包名一致
+	Version     string     `json:"version"`     // 插件版本 如 v1.0.0
+	CoreVersion string     `json:"coreVersion"` // 需要的最低gin-vue-admin版本 如 v2.8.0
+	Description string     `json:"description"`
+	Tables      []string   `json:"tables,omitempty"` // 插件使用的表 卸载时删除
+	Menus       []RbacMenu `json:"menus,omitempty"`  // 安装时创建的菜单 父菜单总在子菜单之前
+	Apis        []RbacApi  `json:"apis,omitempty"`   // 安装时创建的api
+}
+
+// SysPlugin 通过插件包安装的插件 卸载时按安装时的清单撤销
+type SysPlugin struct {
+	ID          uint           `json:"ID" gorm:"primarykey"`
+	Name        string         `json:"name" gorm:"size:64;uniqueIndex;comment:插件名"`
+	Version     string         `json:"version" gorm:"size:32;comment:插件版本"`
+	Description string         `json:"description" gorm:"comment:插件描述"`
+	Manifest    datatypes.JSON `json:"manifest" gorm:"comment:安装时的插件清单"`
+	Web         bool           `json:"web" gorm:"comment:是否安装了前端代码"`
+	InstalledAt time.Time      `json:"installedAt" gorm:"comment:安装时间"`
+}
+
+func (SysPlugin) TableName() string {
+	return "sys_plugins"
+}
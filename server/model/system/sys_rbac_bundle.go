@@ -0,0 +1,70 @@
+package system
+
+import (
+	"time"
+)
+
+// RbacBundleVersion 权限配置包格式版本 结构不兼容变更时递增
+const RbacBundleVersion = 1
+
+// RbacBundle 角色、菜单、按钮、api 与 casbin 策略的完整配置 用于在不同环境之间迁移
+// 菜单以路由 name、api 以 method+path、角色以角色ID 作为跨环境的唯一标识
+// 导出为 yaml 时同样使用 json 字段名
+type RbacBundle struct {
+	Version     int             `json:"version"`
+	ExportedAt  time.Time       `json:"exportedAt"`
+	Apis        []RbacApi       `json:"apis"`
+	Menus       []RbacMenu      `json:"menus"` // 父菜单总在子菜单之前
+	Authorities []RbacAuthority `json:"authorities"`
+}
+
+type RbacApi struct {
+	Path        string `json:"path"`
+	Method      string `json:"method"`
+	ApiGroup    string `json:"apiGroup"`
+	Description string `json:"description"`
+}
+
+type RbacMenu struct {
+	Name       string              `json:"name"`
+	ParentName string              `json:"parentName,omitempty"` // 父菜单路由name 顶级菜单为空
+	Path       string              `json:"path"`
+	Hidden     bool                `json:"hidden"`
+	Component  string              `json:"component"`
+	Sort       int                 `json:"sort"`
+	Meta       Meta                `json:"meta"`
+	Parameters []RbacMenuParameter `json:"parameters,omitempty"`
+	Buttons    []RbacMenuButton    `json:"buttons,omitempty"`
+}
+
+type RbacMenuParameter struct {
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type RbacMenuButton struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+type RbacAuthority struct {
+	AuthorityId      uint               `json:"authorityId"`
+	AuthorityName    string             `json:"authorityName"`
+	ParentId         uint               `json:"parentId"`
+	DefaultRouter    string             `json:"defaultRouter"`
+	DataAuthorityIds []uint             `json:"dataAuthorityIds,omitempty"`
+	Menus            []string           `json:"menus,omitempty"`   // 菜单路由name
+	Buttons          []RbacAuthorityBtn `json:"buttons,omitempty"` // 按钮权限
+	Policies         []RbacPolicy       `json:"policies,omitempty"`
+}
+
+type RbacAuthorityBtn struct {
+	Menu   string `json:"menu"`   // 菜单路由name
+	Button string `json:"button"` // 按钮关键key
+}
+
+type RbacPolicy struct {
+	Path   string `json:"path"`
+	Method string `json:"method"`
+}
@@ -0,0 +1,16 @@
+package system
+
+import "time"
+
+// SysSchemaMigration 已执行的数据库迁移 按模块记录版本
+type SysSchemaMigration struct {
+	ID        uint      `json:"ID" gorm:"primarykey"`
+	Module    string    `json:"module" gorm:"size:128;uniqueIndex:idx_schema_migration;comment:模块/插件"`
+	Version   string    `json:"version" gorm:"size:32;uniqueIndex:idx_schema_migration;comment:版本号"`
+	Name      string    `json:"name" gorm:"comment:迁移名称"`
+	AppliedAt time.Time `json:"appliedAt" gorm:"comment:执行时间"`
+}
+
+func (SysSchemaMigration) TableName() string {
+	return "schema_migrations"
+}
@@ -0,0 +1,16 @@
+package system
+
+import "github.com/flipped-aurora/gin-vue-admin/server/global"
+
+// SysTenant 租户
+type SysTenant struct {
+	global.GVA_MODEL
+	Code   string `json:"code" form:"code" gorm:"uniqueIndex;size:64;comment:租户编码 用于子域名识别"` // 租户编码
+	Name   string `json:"name" form:"name" gorm:"comment:租户名称"`                             // 租户名称
+	Enable int    `json:"enable" form:"enable" gorm:"default:1;comment:租户状态 1启用 2停用"`       // 租户状态 1启用 2停用
+	Remark string `json:"remark" form:"remark" gorm:"comment:备注"`                           // 备注
+}
+
+func (SysTenant) TableName() string {
+	return "sys_tenants"
+}
@@ -1,6 +1,8 @@
 package system
 
 import (
+	"time"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common"
 	"github.com/google/uuid"
@@ -13,12 +15,15 @@ type Login interface {
 	GetUserId() uint
 	GetAuthorityId() uint
 	GetUserInfo() any
+	GetPasswordExpired() bool
+	GetTenantId() uint
 }
 
 var _ Login = new(SysUser)
 
 type SysUser struct {
 	global.GVA_MODEL
+	global.GVA_TENANT
 	UUID          uuid.UUID      `json:"uuid" gorm:"index;comment:用户UUID"`                                                                   // 用户UUID
 	Username      string         `json:"userName" gorm:"index;comment:用户登录名"`                                                                // 用户登录名
 	Password      string         `json:"-"  gorm:"comment:用户登录密码"`                                                                           // 用户登录密码
@@ -33,6 +38,10 @@ type SysUser struct {
 	OriginSetting common.JSONMap `json:"originSetting" form:"originSetting" gorm:"type:text;default:null;column:origin_setting;comment:配置;"` //配置
 	Gender        string         `json:"gender"  gorm:"comment:用户性别"`                                                                        // 用户邮箱
 	Nationality   string         `json:"nationality"  gorm:"comment:用户国籍"`
+	// 密码策略
+	PasswordUpdatedAt   *time.Time `json:"passwordUpdatedAt" gorm:"comment:密码修改时间"`                    // 密码修改时间
+	ForceChangePassword bool       `json:"forceChangePassword" gorm:"default:false;comment:下次登录需修改密码"` // 下次登录需修改密码
+	PasswordExpired     bool       `json:"passwordExpired" gorm:"-"`                                   // 本次登录时密码是否已过期 仅本地账号登录时计算
 }
 
 func (SysUser) TableName() string {
@@ -62,3 +71,23 @@ func (s *SysUser) GetAuthorityId() uint {
 func (s *SysUser) GetUserInfo() any {
 	return *s
 }
+
+func (s *SysUser) GetPasswordExpired() bool {
+	return s.PasswordExpired
+}
+
+func (s *SysUser) GetTenantId() uint {
+	return s.TenantId
+}
+
+// CheckPasswordExpired 是否需要修改密码 管理员重置后或超过密码有效期
+// 没有密码修改时间的账号(开启有效期前创建)视为未过期 登录时从当前时间开始计算
+func (s *SysUser) CheckPasswordExpired(expireDays int) bool {
+	if s.ForceChangePassword {
+		return true
+	}
+	if expireDays <= 0 || s.PasswordUpdatedAt == nil {
+		return false
+	}
+	return time.Since(*s.PasswordUpdatedAt) > time.Duration(expireDays)*24*time.Hour
+}
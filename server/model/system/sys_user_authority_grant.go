@@ -0,0 +1,34 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+const (
+	AuthorityGrantPending = "pending" // 未到生效时间
+	AuthorityGrantActive  = "active"  // 生效中
+	AuthorityGrantExpired = "expired" // 已到期
+	AuthorityGrantRevoked = "revoked" // 已撤销
+)
+
+// SysUserAuthorityGrant 限时角色授权 由管理员授予或由用户委托 生效期间写入 sys_user_authority
+type SysUserAuthorityGrant struct {
+	global.GVA_MODEL
+	UserId      uint       `json:"userId" gorm:"index;comment:被授权用户ID"`            // 被授权用户ID
+	AuthorityId uint       `json:"authorityId" gorm:"comment:角色ID"`                // 角色ID
+	DelegatorId uint       `json:"delegatorId" gorm:"index;comment:委托人ID 0为管理员授权"` // 委托人ID 0为管理员授权
+	OperatorId  uint       `json:"operatorId" gorm:"comment:操作人ID"`                // 操作人ID
+	ValidFrom   time.Time  `json:"validFrom" gorm:"comment:生效时间"`                  // 生效时间
+	ValidUntil  time.Time  `json:"validUntil" gorm:"index;comment:失效时间"`           // 失效时间
+	Reason      string     `json:"reason" gorm:"comment:授权原因"`                     // 授权原因
+	Status      string     `json:"status" gorm:"index;size:16;comment:状态"`         // 状态
+	Applied     bool       `json:"applied" gorm:"comment:用户角色是否由本授权写入"`            // 用户角色是否由本授权写入 结束时需要移除
+	EndedAt     *time.Time `json:"endedAt" gorm:"comment:结束时间"`                    // 结束时间
+	EndReason   string     `json:"endReason" gorm:"comment:结束原因"`                  // 结束原因
+}
+
+func (SysUserAuthorityGrant) TableName() string {
+	return "sys_user_authority_grants"
+}
@@ -0,0 +1,18 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysUserIdentity 外部身份(OIDC/LDAP等)与本地用户的绑定关系
+type SysUserIdentity struct {
+	global.GVA_MODEL
+	UserId   uint   `json:"userId" gorm:"index;comment:本地用户ID"`                                        // 本地用户ID
+	Provider string `json:"provider" gorm:"uniqueIndex:idx_provider_subject;size:191;comment:身份提供方"`   // 身份提供方
+	Subject  string `json:"subject" gorm:"uniqueIndex:idx_provider_subject;size:191;comment:外部用户唯一标识"` // 外部用户唯一标识
+	Email    string `json:"email" gorm:"comment:外部邮箱"`                                                 // 外部邮箱
+}
+
+func (SysUserIdentity) TableName() string {
+	return "sys_user_identities"
+}
@@ -0,0 +1,15 @@
+package system
+
+import "time"
+
+// SysUserPasswordHistory 用户历史密码 用于防止重复使用最近的密码
+type SysUserPasswordHistory struct {
+	ID        uint      `gorm:"primarykey" json:"ID"`
+	UserId    uint      `json:"userId" gorm:"index;comment:用户ID"`
+	Password  string    `json:"-" gorm:"comment:密码哈希"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (SysUserPasswordHistory) TableName() string {
+	return "sys_user_password_histories"
+}
@@ -0,0 +1,67 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"gorm.io/datatypes"
+)
+
+// webhook 投递状态
+const (
+	WebhookDeliveryPending  = "pending"  // 等待首次投递
+	WebhookDeliverySending  = "sending"  // 投递中
+	WebhookDeliveryRetrying = "retrying" // 失败 等待重试
+	WebhookDeliverySuccess  = "success"  // 投递成功
+	WebhookDeliveryFailed   = "failed"   // 超过最大重试次数或webhook已停用
+)
+
+// WebhookAllEvents 订阅全部事件
+const WebhookAllEvents = "*"
+
+// SysWebhook webhook订阅 事件发生时向 Url 发送带 HMAC 签名的 POST 请求
+type SysWebhook struct {
+	global.GVA_MODEL
+	Name   string                      `json:"name" gorm:"comment:名称"`                                          // 名称
+	Url    string                      `json:"url" gorm:"size:1024;comment:回调地址"`                               // 回调地址
+	Secret string                      `json:"secret" gorm:"comment:签名密钥"`                                      // 签名密钥 为空时自动生成
+	Events datatypes.JSONSlice[string] `json:"events" gorm:"comment:订阅的事件 包含*时订阅全部" swaggertype:"array,string"` // 订阅的事件
+	Active bool                        `json:"active" gorm:"index;comment:是否启用"`                                // 是否启用
+	Remark string                      `json:"remark" gorm:"comment:备注"`                                        // 备注
+}
+
+func (SysWebhook) TableName() string {
+	return "sys_webhooks"
+}
+
+// Subscribed 是否订阅了该事件
+func (w *SysWebhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event || e == WebhookAllEvents {
+			return true
+		}
+	}
+	return false
+}
+
+// SysWebhookDelivery webhook投递记录 重试时更新同一条记录 重放时新建记录
+type SysWebhookDelivery struct {
+	global.GVA_MODEL
+	WebhookId    uint       `json:"webhookId" gorm:"index;comment:webhook ID"`                        // webhook ID
+	DeliveryId   string     `json:"deliveryId" gorm:"index;size:36;comment:投递ID"`                     // 投递ID 重放时不变
+	Event        string     `json:"event" gorm:"index;size:64;comment:事件"`                            // 事件
+	Payload      string     `json:"payload" gorm:"type:text;comment:请求内容"`                            // 请求内容
+	Status       string     `json:"status" gorm:"index:idx_webhook_delivery_due;size:16;comment:状态"`  // 状态
+	NextRetryAt  *time.Time `json:"nextRetryAt" gorm:"index:idx_webhook_delivery_due;comment:下次投递时间"` // 下次投递时间
+	Attempts     int        `json:"attempts" gorm:"comment:已投递次数"`                                    // 已投递次数
+	ResponseCode int        `json:"responseCode" gorm:"comment:最后一次响应状态码"`                            // 最后一次响应状态码
+	ResponseBody string     `json:"responseBody" gorm:"type:text;comment:最后一次响应内容"`                   // 最后一次响应内容
+	Error        string     `json:"error" gorm:"type:text;comment:最后一次错误"`                            // 最后一次错误
+	Duration     int64      `json:"duration" gorm:"comment:最后一次耗时(毫秒)"`                               // 最后一次耗时(毫秒)
+	DeliveredAt  *time.Time `json:"deliveredAt" gorm:"comment:投递成功时间"`                                // 投递成功时间
+	ReplayOf     uint       `json:"replayOf" gorm:"comment:重放的原投递记录ID"`                               // 重放的原投递记录ID
+}
+
+func (SysWebhookDelivery) TableName() string {
+	return "sys_webhook_deliveries"
+}
@@ -10,6 +10,6 @@ import (
 func Router(engine *gin.Engine) {
 	public := engine.Group(global.GVA_CONFIG.System.RouterPrefix).Group("")
 	private := engine.Group(global.GVA_CONFIG.System.RouterPrefix).Group("")
-	private.Use(middleware.JWTAuth()).Use(middleware.CasbinHandler())
+	private.Use(middleware.JWTAuth()).Use(middleware.TenantResolver()).Use(middleware.CasbinHandler())
 	router.Router.Info.Init(public, private)
 }
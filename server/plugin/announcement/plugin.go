@@ -7,20 +7,35 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-var _ interfaces.Plugin = (*plugin)(nil)
+var _ interfaces.Lifecycle = (*plugin)(nil)
 
 var Plugin = new(plugin)
 
 type plugin struct{}
 
-func (p *plugin) Register(group *gin.Engine) {
-	ctx := context.Background()
-	// 如果需要配置文件，请到config.Config中填充配置结构，且到下方发放中填入其在config.yaml中的key
+// Init 如果需要配置文件，请到config.Config中填充配置结构，且到下方发放中填入其在config.yaml中的key
+func (p *plugin) Init(ctx context.Context) error {
 	// initialize.Viper()
 	// 安装插件时候自动注册的api数据请到下方法.Api方法中实现
 	initialize.Api(ctx)
 	// 安装插件时候自动注册的api数据请到下方法.Menu方法中实现
 	initialize.Menu(ctx)
+	return nil
+}
+
+func (p *plugin) Migrate(ctx context.Context) error {
 	initialize.Gorm(ctx)
+	return nil
+}
+
+func (p *plugin) Register(group *gin.Engine) {
 	initialize.Router(group)
 }
+
+func (p *plugin) Start(ctx context.Context) error {
+	return nil
+}
+
+func (p *plugin) Stop(ctx context.Context) error {
+	return nil
+}
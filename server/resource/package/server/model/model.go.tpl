@@ -27,9 +27,17 @@ type {{.StructName}} struct {
 {{- if not .OnlyTemplate}}
 {{- if .GvaModel }}
     global.GVA_MODEL
+    global.GVA_TENANT
 {{- end }}
 {{- range .Fields}}
   {{ GenerateField . }}
+{{- end }}
+{{- range .Children }}
+    {{- if eq .Association "hasMany" }}
+    {{.FieldName}}  []{{.StructName}} `json:"{{.FieldJson}}" gorm:"foreignKey:{{.ForeignKey}}"`  //{{.Description}}
+    {{- else }}
+    {{.FieldName}}  []{{.StructName}} `json:"{{.FieldJson}}" gorm:"many2many:{{.JoinTable}};joinForeignKey:{{.ForeignKey}};joinReferences:{{.ReferenceKey}}"`  //{{.Description}}
+    {{- end }}
 {{- end }}
     {{- if .AutoCreateResource }}
     CreatedBy  uint   `gorm:"column:created_by;comment:创建者"`
@@ -50,6 +58,28 @@ func ({{.StructName}}) TableName() string {
 }
 {{ end }}
 
+{{- if not .OnlyTemplate }}
+{{- range .Children }}
+
+// {{.Description}} 结构体  {{.StructName}}
+type {{.StructName}} struct {
+    global.GVA_MODEL
+    global.GVA_TENANT
+{{- range .Fields}}
+  {{ GenerateField . }}
+{{- end }}
+    {{- if eq .Association "hasMany" }}
+    {{.ForeignKey}}  uint `json:"{{.ForeignJson}}" gorm:"column:{{.ForeignColumn}};index;comment:所属主表ID"`
+    {{- end }}
+}
+
+// TableName {{.Description}} {{.StructName}}自定义表名 {{.TableName}}
+func ({{.StructName}}) TableName() string {
+    return "{{.TableName}}"
+}
+{{- end }}
+{{ end }}
+
 {{if .IsTree }}
 // GetChildren 实现TreeNode接口
 func (s *{{.StructName}}) GetChildren() []*{{.StructName}} {
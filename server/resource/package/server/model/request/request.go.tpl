@@ -47,6 +47,18 @@ type {{.StructName}}Search struct{
             {{- end }}
         {{- end }}
     {{- end}}
+{{- end }}
+{{- range .Children }}
+{{- range .SearchFields }}
+        {{- if eq .FieldSearchType "BETWEEN" "NOT BETWEEN"}}
+    Start{{.FieldName}}  *{{.FieldType}}  `json:"start{{.FieldName}}" form:"start{{.FieldName}}"`
+    End{{.FieldName}}  *{{.FieldType}}  `json:"end{{.FieldName}}" form:"end{{.FieldName}}"`
+        {{- else if or (eq .FieldType "enum") (eq .FieldType "picture") (eq .FieldType "pictures") (eq .FieldType "video") (eq .FieldType "json") }}
+    {{.FieldName}}  string `json:"{{.FieldJson}}" form:"{{.FieldJson}}" `
+        {{- else }}
+    {{.FieldName}}  *{{.FieldType}} `json:"{{.FieldJson}}" form:"{{.FieldJson}}" `
+        {{- end }}
+{{- end }}
 {{- end }}
     request.PageInfo
     {{- if .NeedSort}}
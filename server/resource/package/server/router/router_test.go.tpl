@@ -0,0 +1,280 @@
+{{- $enum := false }}
+{{- $check := "" }}
+{{- range .Fields }}
+    {{- if eq .FieldType "enum" }}
+        {{- $enum = true }}
+    {{- end }}
+    {{- if and (eq .FieldType "string") (not .PrimaryKey) (eq $check "") }}
+        {{- $check = .FieldName }}
+    {{- end }}
+{{- end }}
+{{- range .Children }}
+    {{- range .Fields }}
+        {{- if eq .FieldType "enum" }}
+            {{- $enum = true }}
+        {{- end }}
+    {{- end }}
+{{- end -}}
+package {{.Package}}
+
+import (
+	"bytes"
+	"encoding/json"
+	{{- if not .GvaModel }}
+	"fmt"
+	{{- end }}
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	{{- if .GvaModel }}
+	"strconv"
+	{{- end }}
+	{{- if $enum }}
+	"strings"
+	{{- end }}
+	"testing"
+	{{- if .HasTimer }}
+	"time"
+	{{- end }}
+
+	"{{.Module}}/global"
+	"{{.Module}}/model/common/response"
+	"{{.Module}}/model/{{.Package}}"
+	"{{.Module}}/model/system"
+	systemReq "{{.Module}}/model/system/request"
+	"{{.Module}}/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// {{.Abbreviation}}TestApis 生成的需要鉴权的接口, 桩Casbin只放行这些接口
+var {{.Abbreviation}}TestApis = map[string]bool{
+	"POST /{{.Abbreviation}}/create{{.StructName}}":       true,
+	"DELETE /{{.Abbreviation}}/delete{{.StructName}}":      true,
+	"DELETE /{{.Abbreviation}}/delete{{.StructName}}ByIds": true,
+	"PUT /{{.Abbreviation}}/update{{.StructName}}":         true,
+	"GET /{{.Abbreviation}}/find{{.StructName}}":           true,
+	"GET /{{.Abbreviation}}/get{{.StructName}}List":        true,
+}
+
+// setup{{.StructName}}Router 使用内存SQLite代替数据库, 注册生成的路由
+// 桩JWT写入测试用户, 桩Casbin只放行生成的接口
+func setup{{.StructName}}Router(t *testing.T) *gin.Engine {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	{{- if $enum }}
+	// SQLite不支持enum类型 迁移前改为text
+	for _, value := range []any{&{{.Package}}.{{.StructName}}{}{{range .Children}}, &{{$.Package}}.{{.StructName}}{}{{end}}} {
+		stmt := &gorm.Statement{DB: db}
+		if err = stmt.Parse(value); err != nil {
+			t.Fatalf("解析{{.Description}}结构失败: %v", err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if strings.HasPrefix(string(field.DataType), "enum") {
+				field.DataType = "text"
+			}
+		}
+	}
+	{{- end }}
+	if err = db.AutoMigrate(&{{.Package}}.{{.StructName}}{}{{range .Children}}, &{{$.Package}}.{{.StructName}}{}{{end}}, &system.SysOperationRecord{}); err != nil {
+		t.Fatalf("迁移{{.Description}}表失败: %v", err)
+	}
+	origin, originList, originLog := global.GVA_DB, global.GVA_DBList, global.GVA_LOG
+	global.GVA_DB = db
+	{{- if ne .BusinessDB "" }}
+	global.GVA_DBList = map[string]*gorm.DB{"{{.BusinessDB}}": db}
+	{{- end }}
+	global.GVA_LOG = zap.NewNop()
+	t.Cleanup(func() {
+		global.GVA_DB, global.GVA_DBList, global.GVA_LOG = origin, originList, originLog
+		if sqlDB, e := db.DB(); e == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	private := engine.Group("")
+	private.Use(func(c *gin.Context) {
+		utils.SetClaims(c, &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{ID: 1, Username: "test", AuthorityId: 888}})
+		c.Next()
+	}, func(c *gin.Context) {
+		if !{{.Abbreviation}}TestApis[c.Request.Method+" "+c.FullPath()] {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	})
+	public := engine.Group("")
+	new({{.StructName}}Router).Init{{.StructName}}Router(private, public)
+	return engine
+}
+
+// {{.Abbreviation}}TestRequest 发送请求并把响应的data解析到out, 返回响应的code和msg
+func {{.Abbreviation}}TestRequest(t *testing.T, engine *gin.Engine, method, path string, body, out any) (int, string) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("序列化请求失败: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s %s 返回状态码%d", method, path, w.Code)
+	}
+	var resp struct {
+		Code int             `json:"code"`
+		Data json.RawMessage `json:"data"`
+		Msg  string          `json:"msg"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s 解析响应失败: %v", method, path, err)
+	}
+	if out != nil && resp.Code == response.SUCCESS {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			t.Fatalf("%s %s 解析响应数据失败: %v", method, path, err)
+		}
+	}
+	return resp.Code, resp.Msg
+}
+
+// {{.Abbreviation}}TestKey 接口使用的主键参数
+func {{.Abbreviation}}TestKey({{.Abbreviation}} {{.Package}}.{{.StructName}}) string {
+	{{- if .GvaModel }}
+	return strconv.FormatUint(uint64({{.Abbreviation}}.ID), 10)
+	{{- else }}
+	return {{ GenerateTestKey .PrimaryField .Abbreviation }}
+	{{- end }}
+}
+
+func Test{{.StructName}}Router(t *testing.T) {
+	engine := setup{{.StructName}}Router(t)
+	mustOk := func(method, path string, body, out any) {
+		t.Helper()
+		if code, msg := {{.Abbreviation}}TestRequest(t, engine, method, path, body, out); code != response.SUCCESS {
+			t.Fatalf("%s %s 失败: %s", method, path, msg)
+		}
+	}
+
+	for _, {{.Abbreviation}} := range []{{.Package}}.{{.StructName}}{
+		{
+		{{- range .Fields }}
+			{{.FieldName}}: {{ GenerateTestValue . 1 }},
+		{{- end }}
+		{{- range .Children }}
+			{{.FieldName}}: []{{$.Package}}.{{.StructName}}{
+				{
+				{{- range .Fields }}
+					{{.FieldName}}: {{ GenerateTestValue . 1 }},
+				{{- end }}
+				},
+			},
+		{{- end }}
+		},
+		{
+		{{- range .Fields }}
+			{{.FieldName}}: {{ GenerateTestValue . 2 }},
+		{{- end }}
+		{{- range .Children }}
+			{{.FieldName}}: []{{$.Package}}.{{.StructName}}{
+				{
+				{{- range .Fields }}
+					{{.FieldName}}: {{ GenerateTestValue . 2 }},
+				{{- end }}
+				},
+			},
+		{{- end }}
+		},
+	} {
+		mustOk(http.MethodPost, "/{{.Abbreviation}}/create{{.StructName}}", {{.Abbreviation}}, nil)
+	}
+	{{- if .IsTree }}
+	var list []{{.Package}}.{{.StructName}}
+	mustOk(http.MethodGet, "/{{.Abbreviation}}/get{{.StructName}}List", nil, &list)
+	if len(list) != 2 {
+		t.Fatalf("{{.Description}}列表应有2条记录, 实际为%d", len(list))
+	}
+	{{- else }}
+	var page struct {
+		List  []{{.Package}}.{{.StructName}} `json:"list"`
+		Total int64                         `json:"total"`
+	}
+	mustOk(http.MethodGet, "/{{.Abbreviation}}/get{{.StructName}}List?page=1&pageSize=10", nil, &page)
+	if page.Total != 2 || len(page.List) != 2 {
+		t.Fatalf("{{.Description}}列表应有2条记录, 实际为total=%d len=%d", page.Total, len(page.List))
+	}
+	list := page.List
+	{{- end }}
+
+	key, other := {{.Abbreviation}}TestKey(list[0]), {{.Abbreviation}}TestKey(list[1])
+	var got {{.Package}}.{{.StructName}}
+	mustOk(http.MethodGet, "/{{.Abbreviation}}/find{{.StructName}}?{{.PrimaryField.FieldJson}}="+url.QueryEscape(key), nil, &got)
+	if {{.Abbreviation}}TestKey(got) != key {
+		t.Fatalf("获取的{{.Description}}主键应为%s, 实际为%s", key, {{.Abbreviation}}TestKey(got))
+	}
+	{{- range .Children }}
+	if len(got.{{.FieldName}}) != 1 {
+		t.Fatalf("{{$.Description}}应预加载1条{{.Description}}, 实际为%d", len(got.{{.FieldName}}))
+	}
+	{{- end }}
+
+	{{- range .Fields }}
+	{{- if not .PrimaryKey }}
+	got.{{.FieldName}} = {{ GenerateTestValue . 3 }}
+	{{- end }}
+	{{- end }}
+	{{- range .Children }}
+	got.{{.FieldName}} = []{{$.Package}}.{{.StructName}}{
+		{
+		{{- range .Fields }}
+			{{.FieldName}}: {{ GenerateTestValue . 3 }},
+		{{- end }}
+		},
+	}
+	{{- end }}
+	mustOk(http.MethodPut, "/{{.Abbreviation}}/update{{.StructName}}", got, nil)
+	{{- if or (ne $check "") .HasChildren }}
+	var updated {{.Package}}.{{.StructName}}
+	mustOk(http.MethodGet, "/{{.Abbreviation}}/find{{.StructName}}?{{.PrimaryField.FieldJson}}="+url.QueryEscape(key), nil, &updated)
+	{{- if ne $check "" }}
+	if updated.{{$check}} == nil || *updated.{{$check}} != *got.{{$check}} {
+		t.Fatalf("{{.Description}}的{{$check}}没有更新")
+	}
+	{{- end }}
+	{{- range .Children }}
+	if len(updated.{{.FieldName}}) != 1 {
+		t.Fatalf("更新后{{$.Description}}应有1条{{.Description}}, 实际为%d", len(updated.{{.FieldName}}))
+	}
+	{{- end }}
+	{{- end }}
+
+	mustOk(http.MethodDelete, "/{{.Abbreviation}}/delete{{.StructName}}?{{.PrimaryField.FieldJson}}="+url.QueryEscape(key), nil, nil)
+	if code, _ := {{.Abbreviation}}TestRequest(t, engine, http.MethodGet, "/{{.Abbreviation}}/find{{.StructName}}?{{.PrimaryField.FieldJson}}="+url.QueryEscape(key), nil, nil); code == response.SUCCESS {
+		t.Fatalf("删除后仍能获取到{{.Description}}")
+	}
+	mustOk(http.MethodDelete, "/{{.Abbreviation}}/delete{{.StructName}}ByIds?"+url.Values{"{{.PrimaryField.FieldJson}}s[]": {other}}.Encode(), nil, nil)
+	{{- if .IsTree }}
+	mustOk(http.MethodGet, "/{{.Abbreviation}}/get{{.StructName}}List", nil, &list)
+	if len(list) != 0 {
+		t.Fatalf("删除后{{.Description}}列表应为空, 实际为%d条", len(list))
+	}
+	{{- else }}
+	mustOk(http.MethodGet, "/{{.Abbreviation}}/get{{.StructName}}List?page=1&pageSize=10", nil, &page)
+	if page.Total != 0 {
+		t.Fatalf("删除后{{.Description}}列表应为空, 实际为%d条", page.Total)
+	}
+	{{- end }}
+}
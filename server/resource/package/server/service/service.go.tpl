@@ -39,15 +39,19 @@ import (
 	"context"
 	"{{.Module}}/global"
 	"{{.Module}}/model/{{.Package}}"
+	systemService "{{.Module}}/service/system"
 	{{- if not .IsTree}}
     {{.Package}}Req "{{.Module}}/model/{{.Package}}/request"
     {{- else }}
     "{{.Module}}/utils"
     "errors"
     {{- end }}
-    {{- if .AutoCreateResource }}
+    {{- if or .AutoCreateResource .HasChildren }}
     "gorm.io/gorm"
     {{- end}}
+    {{- if .HasChildren }}
+    "gorm.io/gorm/clause"
+    {{- end}}
 {{- end }}
 )
 
@@ -57,7 +61,7 @@ type {{.StructName}}Service struct {}
 // Create{{.StructName}} 创建{{.Description}}记录
 // Author [yourname](https://github.com/yourname)
 func ({{.Abbreviation}}Service *{{.StructName}}Service) Create{{.StructName}}(ctx context.Context, {{.Abbreviation}} *{{.Package}}.{{.StructName}}) (err error) {
-	err = {{$db}}.Create({{.Abbreviation}}).Error
+	err = {{$db}}.WithContext(ctx).Create({{.Abbreviation}}).Error
 	return err
 }
 
@@ -77,16 +81,30 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Delete{{.StructName}}(ctx
 
 	{{- if .AutoCreateResource }}
 	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
-	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).Update("deleted_by", userID).Error; err != nil {
+	    {{- if .HasChildren }}
+	    if err := {{.Abbreviation}}Service.delete{{.StructName}}Children(ctx, tx, []string{ {{- .PrimaryField.FieldJson}}}); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).Update("deleted_by", userID).Error; err != nil {
               return err
         }
-        if err = tx.Delete(&{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error; err != nil {
+        if err = tx.Scopes(systemService.DataScope(ctx)).Delete(&{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error; err != nil {
               return err
         }
         return nil
 	})
+    {{- else if .HasChildren }}
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    {{- if .HasChildren }}
+	    if err := {{.Abbreviation}}Service.delete{{.StructName}}Children(ctx, tx, []string{ {{- .PrimaryField.FieldJson}}}); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    return tx.Scopes(systemService.DataScope(ctx)).Delete(&{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error
+	})
     {{- else }}
-	err = {{$db}}.Delete(&{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error
+	err = {{$db}}.Scopes(systemService.DataScope(ctx)).Delete(&{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error
 	{{- end }}
 	return err
 }
@@ -96,31 +114,97 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Delete{{.StructName}}(ctx
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Delete{{.StructName}}ByIds(ctx context.Context, {{.PrimaryField.FieldJson}}s []string {{- if .AutoCreateResource }},deleted_by uint{{- end}}) (err error) {
 	{{- if .AutoCreateResource }}
 	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
-	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Update("deleted_by", deleted_by).Error; err != nil {
+	    {{- if .HasChildren }}
+	    if err := {{.Abbreviation}}Service.delete{{.StructName}}Children(ctx, tx, {{.PrimaryField.FieldJson}}s); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Update("deleted_by", deleted_by).Error; err != nil {
             return err
         }
-        if err := tx.Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Delete(&{{.Package}}.{{.StructName}}{}).Error; err != nil {
+        if err := tx.Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Delete(&{{.Package}}.{{.StructName}}{}).Error; err != nil {
             return err
         }
         return nil
     })
+    {{- else if .HasChildren }}
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    {{- if .HasChildren }}
+	    if err := {{.Abbreviation}}Service.delete{{.StructName}}Children(ctx, tx, {{.PrimaryField.FieldJson}}s); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    return tx.Scopes(systemService.DataScope(ctx)).Delete(&[]{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} in ?",{{.PrimaryField.FieldJson}}s).Error
+	})
     {{- else}}
-	err = {{$db}}.Delete(&[]{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} in ?",{{.PrimaryField.FieldJson}}s).Error
+	err = {{$db}}.Scopes(systemService.DataScope(ctx)).Delete(&[]{{.Package}}.{{.StructName}}{},"{{.PrimaryField.ColumnName}} in ?",{{.PrimaryField.FieldJson}}s).Error
     {{- end}}
 	return err
 }
 
+{{- if .HasChildren }}
+
+// delete{{.StructName}}Children 删除{{.Description}}的子表记录和多对多关联, 只处理有数据权限的记录
+func ({{.Abbreviation}}Service *{{.StructName}}Service) delete{{.StructName}}Children(ctx context.Context, tx *gorm.DB, {{.PrimaryField.FieldJson}}s []string) error {
+	var visible []uint
+	if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Pluck("{{.PrimaryField.ColumnName}}", &visible).Error; err != nil {
+		return err
+	}
+	if len(visible) == 0 {
+		return nil
+	}
+	{{- range .Children }}
+	{{- if eq .Association "hasMany" }}
+	if err := tx.Where("{{.ForeignColumn}} in ?", visible).Delete(&{{$.Package}}.{{.StructName}}{}).Error; err != nil {
+		return err
+	}
+	{{- else }}
+	if err := tx.Exec("DELETE FROM ? WHERE ? IN ?", clause.Table{Name: "{{.JoinTable}}"}, clause.Column{Name: "{{.ForeignColumn}}"}, visible).Error; err != nil {
+		return err
+	}
+	{{- end }}
+	{{- end }}
+	return nil
+}
+{{- end }}
+
 // Update{{.StructName}} 更新{{.Description}}记录
 // Author [yourname](https://github.com/yourname)
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Update{{.StructName}}(ctx context.Context, {{.Abbreviation}} {{.Package}}.{{.StructName}}) (err error) {
-	err = {{$db}}.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Updates(&{{.Abbreviation}}).Error
+	{{- if .HasChildren }}
+	// 主表和子表在同一事务中更新 提交的子表记录以外的记录会被删除
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    db := tx.Model(&{{.Package}}.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Omit(clause.Associations).Updates(&{{.Abbreviation}})
+	    if db.Error != nil {
+	        return db.Error
+	    }
+	    if db.RowsAffected == 0 {
+	        return gorm.ErrRecordNotFound
+	    }
+	    tx = tx.Session(&gorm.Session{FullSaveAssociations: true})
+	    {{- range .Children }}
+	    {{- if eq .Association "hasMany" }}
+	    if err := tx.Model(&{{$.Abbreviation}}).Association("{{.FieldName}}").Unscoped().Replace({{$.Abbreviation}}.{{.FieldName}}); err != nil {
+	        return err
+	    }
+	    {{- else }}
+	    if err := tx.Model(&{{$.Abbreviation}}).Association("{{.FieldName}}").Replace({{$.Abbreviation}}.{{.FieldName}}); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    {{- end }}
+	    return nil
+	})
+	{{- else }}
+	err = {{$db}}.Model(&{{.Package}}.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Updates(&{{.Abbreviation}}).Error
+	{{- end }}
 	return err
 }
 
 // Get{{.StructName}} 根据{{.PrimaryField.FieldJson}}获取{{.Description}}记录
 // Author [yourname](https://github.com/yourname)
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}(ctx context.Context, {{.PrimaryField.FieldJson}} string) ({{.Abbreviation}} {{.Package}}.{{.StructName}}, err error) {
-	err = {{$db}}.Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).First(&{{.Abbreviation}}).Error
+	err = {{$db}}.Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}){{range .Children}}.Preload("{{.FieldName}}"){{end}}.First(&{{.Abbreviation}}).Error
 	return
 }
 
@@ -130,10 +214,10 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}(ctx co
 // Author [yourname](https://github.com/yourname)
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}InfoList(ctx context.Context) (list []*{{.Package}}.{{.StructName}},err error) {
     // 创建db
-	db := {{$db}}.Model(&{{.Package}}.{{.StructName}}{})
+	db := {{$db}}.Model(&{{.Package}}.{{.StructName}}{}).Scopes(systemService.DataScope(ctx))
     var {{.Abbreviation}}s []*{{.Package}}.{{.StructName}}
 
-	err = db.Find(&{{.Abbreviation}}s).Error
+	err = db{{range .Children}}.Preload("{{.FieldName}}"){{end}}.Find(&{{.Abbreviation}}s).Error
 
 	return utils.BuildTree({{.Abbreviation}}s), err
 }
@@ -144,7 +228,7 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}InfoLis
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
     // 创建db
-	db := {{$db}}.Model(&{{.Package}}.{{.StructName}}{})
+	db := {{$db}}.Model(&{{.Package}}.{{.StructName}}{}).Scopes(systemService.DataScope(ctx))
     var {{.Abbreviation}}s []{{.Package}}.{{.StructName}}
     // 如果有条件搜索 下方会自动创建搜索语句
 {{- if .GvaModel }}
@@ -153,6 +237,9 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}InfoLis
     }
 {{- end }}
     {{ GenerateSearchConditions .Fields }}
+    {{- range .Children }}
+    {{ GenerateChildSearchConditions . $.Package $db }}
+    {{- end }}
 	err = db.Count(&total).Error
 	if err!=nil {
     	return
@@ -182,7 +269,7 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}InfoLis
        db = db.Limit(limit).Offset(offset)
     }
 
-	err = db.Find(&{{.Abbreviation}}s).Error
+	err = db{{range .Children}}.Preload("{{.FieldName}}"){{end}}.Find(&{{.Abbreviation}}s).Error
 	return  {{.Abbreviation}}s, total, err
 }
 
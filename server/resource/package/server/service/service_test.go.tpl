@@ -0,0 +1,226 @@
+{{- $pointer := false }}
+{{- $enum := false }}
+{{- $check := "" }}
+{{- range .Fields }}
+    {{- if eq .FieldType "string" "richtext" "int" "float64" "bool" "time.Time" }}
+        {{- $pointer = true }}
+    {{- end }}
+    {{- if eq .FieldType "enum" }}
+        {{- $enum = true }}
+    {{- end }}
+    {{- if and (eq .FieldType "string") (not .PrimaryKey) (eq $check "") }}
+        {{- $check = .FieldName }}
+    {{- end }}
+{{- end }}
+{{- range .Children }}
+    {{- range .Fields }}
+        {{- if eq .FieldType "string" "richtext" "int" "float64" "bool" "time.Time" }}
+            {{- $pointer = true }}
+        {{- end }}
+        {{- if eq .FieldType "enum" }}
+            {{- $enum = true }}
+        {{- end }}
+    {{- end }}
+{{- end -}}
+package {{.Package}}
+
+import (
+	"context"
+	{{- if .GvaModel }}
+	"strconv"
+	{{- else }}
+	"fmt"
+	{{- end }}
+	{{- if $enum }}
+	"strings"
+	{{- end }}
+	"testing"
+	{{- if .HasTimer }}
+	"time"
+	{{- end }}
+
+	"{{.Module}}/global"
+	"{{.Module}}/model/{{.Package}}"
+	{{- if not .IsTree }}
+	{{.Package}}Req "{{.Module}}/model/{{.Package}}/request"
+	{{- end }}
+	{{- if $pointer }}
+	"{{.Module}}/utils"
+	{{- end }}
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setup{{.StructName}}DB 使用内存SQLite代替数据库, 测试结束后恢复
+func setup{{.StructName}}DB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	{{- if $enum }}
+	// SQLite不支持enum类型 迁移前改为text
+	for _, value := range []any{&{{.Package}}.{{.StructName}}{}{{range .Children}}, &{{$.Package}}.{{.StructName}}{}{{end}}} {
+		stmt := &gorm.Statement{DB: db}
+		if err = stmt.Parse(value); err != nil {
+			t.Fatalf("解析{{.Description}}结构失败: %v", err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if strings.HasPrefix(string(field.DataType), "enum") {
+				field.DataType = "text"
+			}
+		}
+	}
+	{{- end }}
+	if err = db.AutoMigrate(&{{.Package}}.{{.StructName}}{}{{range .Children}}, &{{$.Package}}.{{.StructName}}{}{{end}}); err != nil {
+		t.Fatalf("迁移{{.Description}}表失败: %v", err)
+	}
+	origin, originList := global.GVA_DB, global.GVA_DBList
+	global.GVA_DB = db
+	{{- if ne .BusinessDB "" }}
+	global.GVA_DBList = map[string]*gorm.DB{"{{.BusinessDB}}": db}
+	{{- end }}
+	t.Cleanup(func() {
+		global.GVA_DB, global.GVA_DBList = origin, originList
+		if sqlDB, e := db.DB(); e == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// {{.Abbreviation}}TestKey 接口使用的主键参数
+func {{.Abbreviation}}TestKey({{.Abbreviation}} {{.Package}}.{{.StructName}}) string {
+	{{- if .GvaModel }}
+	return strconv.FormatUint(uint64({{.Abbreviation}}.ID), 10)
+	{{- else }}
+	return {{ GenerateTestKey .PrimaryField .Abbreviation }}
+	{{- end }}
+}
+
+func Test{{.StructName}}Service(t *testing.T) {
+	setup{{.StructName}}DB(t)
+	ctx := context.Background()
+	service := new({{.StructName}}Service)
+
+	first := {{.Package}}.{{.StructName}}{
+	{{- range .Fields }}
+		{{.FieldName}}: {{ GenerateTestValue . 1 }},
+	{{- end }}
+	{{- range .Children }}
+		{{.FieldName}}: []{{$.Package}}.{{.StructName}}{
+			{
+			{{- range .Fields }}
+				{{.FieldName}}: {{ GenerateTestValue . 1 }},
+			{{- end }}
+			},
+		},
+	{{- end }}
+	}
+	second := {{.Package}}.{{.StructName}}{
+	{{- range .Fields }}
+		{{.FieldName}}: {{ GenerateTestValue . 2 }},
+	{{- end }}
+	{{- range .Children }}
+		{{.FieldName}}: []{{$.Package}}.{{.StructName}}{
+			{
+			{{- range .Fields }}
+				{{.FieldName}}: {{ GenerateTestValue . 2 }},
+			{{- end }}
+			},
+		},
+	{{- end }}
+	}
+	for _, {{.Abbreviation}} := range []*{{.Package}}.{{.StructName}}{&first, &second} {
+		if err := service.Create{{.StructName}}(ctx, {{.Abbreviation}}); err != nil {
+			t.Fatalf("创建{{.Description}}失败: %v", err)
+		}
+	}
+	{{- if .IsTree }}
+	list, err := service.Get{{.StructName}}InfoList(ctx)
+	if err != nil {
+		t.Fatalf("获取{{.Description}}列表失败: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("{{.Description}}列表应有2条记录, 实际为%d", len(list))
+	}
+	{{- else }}
+	search := {{.Package}}Req.{{.StructName}}Search{}
+	search.Page, search.PageSize = 1, 10
+	list, total, err := service.Get{{.StructName}}InfoList(ctx, search)
+	if err != nil {
+		t.Fatalf("获取{{.Description}}列表失败: %v", err)
+	}
+	if total != 2 || len(list) != 2 {
+		t.Fatalf("{{.Description}}列表应有2条记录, 实际为total=%d len=%d", total, len(list))
+	}
+	{{- end }}
+
+	key := {{.Abbreviation}}TestKey(first)
+	got, err := service.Get{{.StructName}}(ctx, key)
+	if err != nil {
+		t.Fatalf("获取{{.Description}}失败: %v", err)
+	}
+	if {{.Abbreviation}}TestKey(got) != key {
+		t.Fatalf("获取的{{.Description}}主键应为%s, 实际为%s", key, {{.Abbreviation}}TestKey(got))
+	}
+	{{- range .Children }}
+	if len(got.{{.FieldName}}) != 1 {
+		t.Fatalf("{{$.Description}}应预加载1条{{.Description}}, 实际为%d", len(got.{{.FieldName}}))
+	}
+	{{- end }}
+
+	{{- range .Fields }}
+	{{- if not .PrimaryKey }}
+	got.{{.FieldName}} = {{ GenerateTestValue . 3 }}
+	{{- end }}
+	{{- end }}
+	{{- range .Children }}
+	got.{{.FieldName}} = []{{$.Package}}.{{.StructName}}{
+		{
+		{{- range .Fields }}
+			{{.FieldName}}: {{ GenerateTestValue . 3 }},
+		{{- end }}
+		},
+	}
+	{{- end }}
+	if err = service.Update{{.StructName}}(ctx, got); err != nil {
+		t.Fatalf("更新{{.Description}}失败: %v", err)
+	}
+	{{- if or (ne $check "") .HasChildren }}
+	updated, err := service.Get{{.StructName}}(ctx, key)
+	if err != nil {
+		t.Fatalf("获取更新后的{{.Description}}失败: %v", err)
+	}
+	{{- if ne $check "" }}
+	if updated.{{$check}} == nil || *updated.{{$check}} != *got.{{$check}} {
+		t.Fatalf("{{.Description}}的{{$check}}没有更新")
+	}
+	{{- end }}
+	{{- range .Children }}
+	if len(updated.{{.FieldName}}) != 1 {
+		t.Fatalf("更新后{{$.Description}}应有1条{{.Description}}, 实际为%d", len(updated.{{.FieldName}}))
+	}
+	{{- end }}
+	{{- end }}
+
+	if err = service.Delete{{.StructName}}(ctx, key{{- if .AutoCreateResource }}, 1{{- end }}); err != nil {
+		t.Fatalf("删除{{.Description}}失败: %v", err)
+	}
+	if _, err = service.Get{{.StructName}}(ctx, key); err == nil {
+		t.Fatalf("删除后仍能获取到{{.Description}}")
+	}
+	if err = service.Delete{{.StructName}}ByIds(ctx, []string{ {{- .Abbreviation}}TestKey(second)}{{- if .AutoCreateResource }}, 1{{- end }}); err != nil {
+		t.Fatalf("批量删除{{.Description}}失败: %v", err)
+	}
+	{{- if .IsTree }}
+	if list, err = service.Get{{.StructName}}InfoList(ctx); err != nil || len(list) != 0 {
+		t.Fatalf("删除后{{.Description}}列表应为空, 实际为%d条 err=%v", len(list), err)
+	}
+	{{- else }}
+	if _, total, err = service.Get{{.StructName}}InfoList(ctx, search); err != nil || total != 0 {
+		t.Fatalf("删除后{{.Description}}列表应为空, 实际为%d条 err=%v", total, err)
+	}
+	{{- end }}
+}
@@ -10,5 +10,5 @@ func Router(engine *gin.Engine) {
 	public := engine.Group(global.GVA_CONFIG.System.RouterPrefix).Group("")
 	public.Use()
 	private := engine.Group(global.GVA_CONFIG.System.RouterPrefix).Group("")
-	private.Use(middleware.JWTAuth()).Use(middleware.CasbinHandler())
+	private.Use(middleware.JWTAuth()).Use(middleware.TenantResolver()).Use(middleware.CasbinHandler())
 }
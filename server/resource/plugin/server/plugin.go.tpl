@@ -7,20 +7,41 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-var _ interfaces.Plugin = (*plugin)(nil)
+var _ interfaces.Lifecycle = (*plugin)(nil)
 
 var Plugin = new(plugin)
 
 type plugin struct{}
 
-// 如果需要配置文件，请到config.Config中填充配置结构，且到下方发放中填入其在config.yaml中的key并添加如下方法
+// Init 如果需要配置文件，请到config.Config中填充配置结构，且到下方发放中填入其在config.yaml中的key并添加如下方法
 // initialize.Viper()
 // 安装插件时候自动注册的api数据请到下方法.Api方法中实现并添加如下方法
 // initialize.Api(ctx)
 // 安装插件时候自动注册的api数据请到下方法.Menu方法中实现并添加如下方法
 // initialize.Menu(ctx)
-func (p *plugin) Register(group *gin.Engine) {
-	ctx := context.Background() 
+func (p *plugin) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *plugin) Migrate(ctx context.Context) error {
 	initialize.Gorm(ctx)
+	return nil
+}
+
+func (p *plugin) Register(group *gin.Engine) {
 	initialize.Router(group)
 }
+
+// Start 路由注册完成后调用, 可在此启动后台任务
+func (p *plugin) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop 服务退出时调用, 可在此优雅停止后台任务
+func (p *plugin) Stop(ctx context.Context) error {
+	return nil
+}
+
+// 依赖其他插件时实现 interfaces.Dependent, 插件将在依赖启动完成后启动
+// func (p *plugin) Name() string { return "{{ .Package }}" }
+// func (p *plugin) Dependencies() []string { return []string{} }
@@ -35,14 +35,18 @@ import (
 	"context"
 	"{{.Module}}/global"
 	"{{.Module}}/plugin/{{.Package}}/model"
+	systemService "{{.Module}}/service/system"
 	{{- if not .IsTree }}
     "{{.Module}}/plugin/{{.Package}}/model/request"
     {{- else }}
     "errors"
     {{- end }}
-    {{- if .AutoCreateResource }}
+    {{- if or .AutoCreateResource .HasChildren }}
     "gorm.io/gorm"
     {{- end}}
+    {{- if .HasChildren }}
+    "gorm.io/gorm/clause"
+    {{- end}}
 {{- if .IsTree }}
     "{{.Module}}/utils"
 {{- end }}
@@ -63,7 +67,7 @@ type {{.Abbreviation}} struct {}
 // Create{{.StructName}} 创建{{.Description}}记录
 // Author [yourname](https://github.com/yourname)
 func (s *{{.Abbreviation}}) Create{{.StructName}}(ctx context.Context, {{.Abbreviation}} *model.{{.StructName}}) (err error) {
-	err = {{$db}}.Create({{.Abbreviation}}).Error
+	err = {{$db}}.WithContext(ctx).Create({{.Abbreviation}}).Error
 	return err
 }
 
@@ -84,16 +88,30 @@ func (s *{{.Abbreviation}}) Delete{{.StructName}}(ctx context.Context, {{.Primar
 
 	{{- if .AutoCreateResource }}
 	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
-	    if err := tx.Model(&model.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).Update("deleted_by", userID).Error; err != nil {
+	    {{- if .HasChildren }}
+	    if err := s.delete{{.StructName}}Children(ctx, tx, []string{ {{- .PrimaryField.FieldJson}}}); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    if err := tx.Model(&model.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).Update("deleted_by", userID).Error; err != nil {
               return err
         }
-        if err = tx.Delete(&model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error; err != nil {
+        if err = tx.Scopes(systemService.DataScope(ctx)).Delete(&model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error; err != nil {
               return err
         }
         return nil
 	})
+    {{- else if .HasChildren }}
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    {{- if .HasChildren }}
+	    if err := s.delete{{.StructName}}Children(ctx, tx, []string{ {{- .PrimaryField.FieldJson}}}); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    return tx.Scopes(systemService.DataScope(ctx)).Delete(&model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error
+	})
     {{- else }}
-	err = {{$db}}.Delete(&model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error
+	err = {{$db}}.Scopes(systemService.DataScope(ctx)).Delete(&model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} = ?",{{.PrimaryField.FieldJson}}).Error
 	{{- end }}
 	return err
 }
@@ -103,31 +121,97 @@ func (s *{{.Abbreviation}}) Delete{{.StructName}}(ctx context.Context, {{.Primar
 func (s *{{.Abbreviation}}) Delete{{.StructName}}ByIds(ctx context.Context, {{.PrimaryField.FieldJson}}s []string {{- if .AutoCreateResource }},deleted_by uint{{- end}}) (err error) {
 	{{- if .AutoCreateResource }}
 	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
-	    if err := tx.Model(&model.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Update("deleted_by", deleted_by).Error; err != nil {
+	    {{- if .HasChildren }}
+	    if err := s.delete{{.StructName}}Children(ctx, tx, {{.PrimaryField.FieldJson}}s); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    if err := tx.Model(&model.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Update("deleted_by", deleted_by).Error; err != nil {
             return err
         }
-        if err := tx.Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Delete(&model.{{.StructName}}{}).Error; err != nil {
+        if err := tx.Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Delete(&model.{{.StructName}}{}).Error; err != nil {
             return err
         }
         return nil
     })
+    {{- else if .HasChildren }}
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    {{- if .HasChildren }}
+	    if err := s.delete{{.StructName}}Children(ctx, tx, {{.PrimaryField.FieldJson}}s); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    return tx.Scopes(systemService.DataScope(ctx)).Delete(&[]model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} in ?",{{.PrimaryField.FieldJson}}s).Error
+	})
     {{- else}}
-	err = {{$db}}.Delete(&[]model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} in ?",{{.PrimaryField.FieldJson}}s).Error
+	err = {{$db}}.Scopes(systemService.DataScope(ctx)).Delete(&[]model.{{.StructName}}{},"{{.PrimaryField.ColumnName}} in ?",{{.PrimaryField.FieldJson}}s).Error
     {{- end}}
 	return err
 }
 
+{{- if .HasChildren }}
+
+// delete{{.StructName}}Children 删除{{.Description}}的子表记录和多对多关联, 只处理有数据权限的记录
+func (s *{{.Abbreviation}}) delete{{.StructName}}Children(ctx context.Context, tx *gorm.DB, {{.PrimaryField.FieldJson}}s []string) error {
+	var visible []uint
+	if err := tx.Model(&model.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Pluck("{{.PrimaryField.ColumnName}}", &visible).Error; err != nil {
+		return err
+	}
+	if len(visible) == 0 {
+		return nil
+	}
+	{{- range .Children }}
+	{{- if eq .Association "hasMany" }}
+	if err := tx.Where("{{.ForeignColumn}} in ?", visible).Delete(&model.{{.StructName}}{}).Error; err != nil {
+		return err
+	}
+	{{- else }}
+	if err := tx.Exec("DELETE FROM ? WHERE ? IN ?", clause.Table{Name: "{{.JoinTable}}"}, clause.Column{Name: "{{.ForeignColumn}}"}, visible).Error; err != nil {
+		return err
+	}
+	{{- end }}
+	{{- end }}
+	return nil
+}
+{{- end }}
+
 // Update{{.StructName}} 更新{{.Description}}记录
 // Author [yourname](https://github.com/yourname)
 func (s *{{.Abbreviation}}) Update{{.StructName}}(ctx context.Context, {{.Abbreviation}} model.{{.StructName}}) (err error) {
-	err = {{$db}}.Model(&model.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Updates(&{{.Abbreviation}}).Error
+	{{- if .HasChildren }}
+	// 主表和子表在同一事务中更新 提交的子表记录以外的记录会被删除
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    db := tx.Model(&model.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Omit(clause.Associations).Updates(&{{.Abbreviation}})
+	    if db.Error != nil {
+	        return db.Error
+	    }
+	    if db.RowsAffected == 0 {
+	        return gorm.ErrRecordNotFound
+	    }
+	    tx = tx.Session(&gorm.Session{FullSaveAssociations: true})
+	    {{- range .Children }}
+	    {{- if eq .Association "hasMany" }}
+	    if err := tx.Model(&{{$.Abbreviation}}).Association("{{.FieldName}}").Unscoped().Replace({{$.Abbreviation}}.{{.FieldName}}); err != nil {
+	        return err
+	    }
+	    {{- else }}
+	    if err := tx.Model(&{{$.Abbreviation}}).Association("{{.FieldName}}").Replace({{$.Abbreviation}}.{{.FieldName}}); err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    {{- end }}
+	    return nil
+	})
+	{{- else }}
+	err = {{$db}}.Model(&model.{{.StructName}}{}).Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Updates(&{{.Abbreviation}}).Error
+	{{- end }}
 	return err
 }
 
 // Get{{.StructName}} 根据{{.PrimaryField.FieldJson}}获取{{.Description}}记录
 // Author [yourname](https://github.com/yourname)
 func (s *{{.Abbreviation}}) Get{{.StructName}}(ctx context.Context, {{.PrimaryField.FieldJson}} string) ({{.Abbreviation}} model.{{.StructName}}, err error) {
-	err = {{$db}}.Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).First(&{{.Abbreviation}}).Error
+	err = {{$db}}.Scopes(systemService.DataScope(ctx)).Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}){{range .Children}}.Preload("{{.FieldName}}"){{end}}.First(&{{.Abbreviation}}).Error
 	return
 }
 
@@ -137,10 +221,10 @@ func (s *{{.Abbreviation}}) Get{{.StructName}}(ctx context.Context, {{.PrimaryFi
 // Author [yourname](https://github.com/yourname)
 func (s *{{.Abbreviation}}) Get{{.StructName}}InfoList(ctx context.Context) (list []*model.{{.StructName}},err error) {
     // 创建db
-	db := {{$db}}.Model(&model.{{.StructName}}{})
+	db := {{$db}}.Model(&model.{{.StructName}}{}).Scopes(systemService.DataScope(ctx))
     var {{.Abbreviation}}s []*model.{{.StructName}}
 
-	err = db.Find(&{{.Abbreviation}}s).Error
+	err = db{{range .Children}}.Preload("{{.FieldName}}"){{end}}.Find(&{{.Abbreviation}}s).Error
 
 	return utils.BuildTree({{.Abbreviation}}s), err
 }
@@ -151,7 +235,7 @@ func (s *{{.Abbreviation}}) Get{{.StructName}}InfoList(ctx context.Context, info
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
     // 创建db
-	db := {{$db}}.Model(&model.{{.StructName}}{})
+	db := {{$db}}.Model(&model.{{.StructName}}{}).Scopes(systemService.DataScope(ctx))
     var {{.Abbreviation}}s []model.{{.StructName}}
     // 如果有条件搜索 下方会自动创建搜索语句
 {{- if .GvaModel }}
@@ -160,6 +244,9 @@ func (s *{{.Abbreviation}}) Get{{.StructName}}InfoList(ctx context.Context, info
     }
 {{- end }}
   {{ GenerateSearchConditions .Fields }}
+    {{- range .Children }}
+    {{ GenerateChildSearchConditions . "model" $db }}
+    {{- end }}
 	err = db.Count(&total).Error
 	if err!=nil {
     	return
@@ -188,7 +275,7 @@ func (s *{{.Abbreviation}}) Get{{.StructName}}InfoList(ctx context.Context, info
 	if limit != 0 {
        db = db.Limit(limit).Offset(offset)
     }
-	err = db.Find(&{{.Abbreviation}}s).Error
+	err = db{{range .Children}}.Preload("{{.FieldName}}"){{end}}.Find(&{{.Abbreviation}}s).Error
 	return  {{.Abbreviation}}s, total, err
 }
 {{- end }}
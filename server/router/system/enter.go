@@ -17,8 +17,19 @@ type RouterGroup struct {
 	OperationRecordRouter
 	DictionaryDetailRouter
 	AuthorityBtnRouter
+	AuthorityFieldRouter
 	SysExportTemplateRouter
 	SysParamsRouter
+	LdapRouter
+	ApiTokenRouter
+	DataScopeRouter
+	DepartmentRouter
+	AuthorityGrantRouter
+	RbacRouter
+	TenantRouter
+	ApprovalRouter
+	NotificationRouter
+	WebhookRouter
 }
 
 var (
@@ -33,6 +44,7 @@ var (
 	apiRouterApi        = api.ApiGroupApp.SystemApiGroup.SystemApiApi
 	dictionaryApi       = api.ApiGroupApp.SystemApiGroup.DictionaryApi
 	authorityBtnApi     = api.ApiGroupApp.SystemApiGroup.AuthorityBtnApi
+	authorityFieldApi   = api.ApiGroupApp.SystemApiGroup.AuthorityFieldApi
 	authorityMenuApi    = api.ApiGroupApp.SystemApiGroup.AuthorityMenuApi
 	autoCodePluginApi   = api.ApiGroupApp.SystemApiGroup.AutoCodePluginApi
 	autocodeHistoryApi  = api.ApiGroupApp.SystemApiGroup.AutoCodeHistoryApi
@@ -41,4 +53,14 @@ var (
 	dictionaryDetailApi = api.ApiGroupApp.SystemApiGroup.DictionaryDetailApi
 	autoCodeTemplateApi = api.ApiGroupApp.SystemApiGroup.AutoCodeTemplateApi
 	exportTemplateApi   = api.ApiGroupApp.SystemApiGroup.SysExportTemplateApi
+	ldapApi             = api.ApiGroupApp.SystemApiGroup.LdapApi
+	apiTokenApi         = api.ApiGroupApp.SystemApiGroup.ApiTokenApi
+	dataScopeApi        = api.ApiGroupApp.SystemApiGroup.DataScopeApi
+	departmentApi       = api.ApiGroupApp.SystemApiGroup.DepartmentApi
+	authorityGrantApi   = api.ApiGroupApp.SystemApiGroup.AuthorityGrantApi
+	rbacApi             = api.ApiGroupApp.SystemApiGroup.RbacApi
+	tenantApi           = api.ApiGroupApp.SystemApiGroup.TenantApi
+	approvalApi         = api.ApiGroupApp.SystemApiGroup.ApprovalApi
+	notificationApi     = api.ApiGroupApp.SystemApiGroup.NotificationApi
+	webhookApi          = api.ApiGroupApp.SystemApiGroup.WebhookApi
 )
@@ -0,0 +1,23 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type ApiTokenRouter struct{}
+
+func (s *ApiTokenRouter) InitApiTokenRouter(Router *gin.RouterGroup) {
+	apiTokenRouter := Router.Group("apiToken").Use(middleware.OperationRecord())
+	apiTokenRouterWithoutRecord := Router.Group("apiToken")
+	{
+		apiTokenRouter.POST("createApiToken", apiTokenApi.CreateApiToken)           // 创建个人访问令牌
+		apiTokenRouter.POST("revokeApiToken", apiTokenApi.RevokeApiToken)           // 吊销个人访问令牌
+		apiTokenRouter.POST("adminCreateApiToken", apiTokenApi.AdminCreateApiToken) // 管理员为用户创建令牌
+		apiTokenRouter.POST("adminRevokeApiToken", apiTokenApi.AdminRevokeApiToken) // 管理员吊销令牌
+	}
+	{
+		apiTokenRouterWithoutRecord.GET("getApiTokenList", apiTokenApi.GetApiTokenList)           // 获取个人访问令牌列表
+		apiTokenRouterWithoutRecord.GET("adminGetApiTokenList", apiTokenApi.AdminGetApiTokenList) // 管理员获取全部令牌
+	}
+}
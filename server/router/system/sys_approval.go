@@ -0,0 +1,30 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type ApprovalRouter struct{}
+
+func (s *ApprovalRouter) InitApprovalRouter(Router *gin.RouterGroup) {
+	approvalRouter := Router.Group("approval").Use(middleware.OperationRecord())
+	approvalRouterWithoutRecord := Router.Group("approval")
+	{
+		approvalRouter.POST("createFlow", approvalApi.CreateApprovalFlow)   // 创建审批流程
+		approvalRouter.PUT("updateFlow", approvalApi.UpdateApprovalFlow)    // 更新审批流程
+		approvalRouter.DELETE("deleteFlow", approvalApi.DeleteApprovalFlow) // 删除审批流程
+		approvalRouter.POST("approve", approvalApi.ApproveRequest)          // 同意审批
+		approvalRouter.POST("reject", approvalApi.RejectRequest)            // 驳回审批
+		approvalRouter.POST("cancel", approvalApi.CancelRequest)            // 撤回审批单
+	}
+	{
+		approvalRouterWithoutRecord.GET("getActions", approvalApi.GetApprovalActions)         // 获取可配置审批流程的操作
+		approvalRouterWithoutRecord.GET("findFlow", approvalApi.FindApprovalFlow)             // 获取审批流程
+		approvalRouterWithoutRecord.GET("getFlowList", approvalApi.GetApprovalFlowList)       // 分页获取审批流程列表
+		approvalRouterWithoutRecord.GET("findRequest", approvalApi.FindApprovalRequest)       // 获取审批单详情
+		approvalRouterWithoutRecord.GET("getRequestList", approvalApi.GetApprovalRequestList) // 分页获取审批单列表
+		approvalRouterWithoutRecord.GET("getMyRequests", approvalApi.GetMyApprovalRequests)   // 我提交的审批单
+		approvalRouterWithoutRecord.GET("getMyTasks", approvalApi.GetMyApprovalTasks)         // 待我审批
+	}
+}
@@ -0,0 +1,19 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthorityFieldRouter struct{}
+
+func (s *AuthorityFieldRouter) InitAuthorityFieldRouter(Router *gin.RouterGroup) {
+	authorityFieldRouter := Router.Group("authorityField").Use(middleware.OperationRecord())
+	authorityFieldRouterWithoutRecord := Router.Group("authorityField")
+	{
+		authorityFieldRouter.POST("setAuthorityField", authorityFieldApi.SetAuthorityField) // 设置字段权限
+	}
+	{
+		authorityFieldRouterWithoutRecord.POST("getAuthorityField", authorityFieldApi.GetAuthorityField) // 获取字段权限
+	}
+}
@@ -17,19 +17,29 @@ func (s *AutoCodeRouter) InitAutoCodeRouter(Router *gin.RouterGroup, RouterPubli
 	{
 		autoCodeRouter.POST("preview", autoCodeTemplateApi.Preview)   // 获取自动创建代码预览
 		autoCodeRouter.POST("createTemp", autoCodeTemplateApi.Create) // 创建自动化代码
+		autoCodeRouter.POST("updateTemp", autoCodeTemplateApi.Update) // 修改已生成结构的字段
 		autoCodeRouter.POST("addFunc", autoCodeTemplateApi.AddFunc)   // 为代码插入方法
 	}
+	{
+		autoCodeRouter.GET("getOpenAPISchemas", autoCodeTemplateApi.GetOpenAPISchemas) // 获取OpenAPI文档中的Schema
+		autoCodeRouter.POST("openAPI", autoCodeTemplateApi.OpenAPI)                    // 从OpenAPI文档生成代码
+	}
 	{
 		autoCodeRouter.POST("getPackage", autoCodePackageApi.All)       // 获取package包
 		autoCodeRouter.POST("delPackage", autoCodePackageApi.Delete)    // 删除package包
 		autoCodeRouter.POST("createPackage", autoCodePackageApi.Create) // 创建package包
 	}
 	{
-		autoCodeRouter.GET("getTemplates", autoCodePackageApi.Templates) // 创建package包
+		autoCodeRouter.GET("getTemplates", autoCodePackageApi.Templates)   // 创建package包
+		autoCodeRouter.POST("previewPack", autoCodePackageApi.PreviewPack) // 预览并校验自定义模板包
 	}
 	{
-		autoCodeRouter.POST("pubPlug", autoCodePluginApi.Packaged)      // 打包插件
-		autoCodeRouter.POST("installPlugin", autoCodePluginApi.Install) // 自动安装插件
+		autoCodeRouter.POST("pubPlug", autoCodePluginApi.Packaged)                       // 打包插件
+		autoCodeRouter.POST("installPlugin", autoCodePluginApi.Install)                  // 自动安装插件
+		autoCodeRouter.POST("installPluginPackage", autoCodePluginApi.InstallPackage)    // 安装插件包
+		autoCodeRouter.GET("getInstalledPlugins", autoCodePluginApi.GetInstalledPlugins) // 获取已安装的插件
+		autoCodeRouter.POST("uninstallPlugin", autoCodePluginApi.Uninstall)              // 卸载插件
+		autoCodeRouter.GET("getPluginStatus", autoCodePluginApi.GetPluginStatus)         // 获取插件运行状态
 
 	}
 	{
@@ -11,6 +11,10 @@ func (s *BaseRouter) InitBaseRouter(Router *gin.RouterGroup) (R gin.IRoutes) {
 	{
 		baseRouter.POST("login", baseApi.Login)
 		baseRouter.POST("captcha", baseApi.Captcha)
+		baseRouter.GET("oidc/authorize", baseApi.OidcAuthorize)        // 获取OIDC授权地址
+		baseRouter.GET("oidc/callback", baseApi.OidcCallback)          // OIDC回调登录
+		baseRouter.POST("forgotPassword", baseApi.ForgotPassword)      // 申请找回密码
+		baseRouter.POST("resetPassword", baseApi.ResetPasswordByToken) // 通过邮件链接重置密码
 	}
 	return baseRouter
 }
@@ -12,8 +12,11 @@ func (s *CasbinRouter) InitCasbinRouter(Router *gin.RouterGroup) {
 	casbinRouterWithoutRecord := Router.Group("casbin")
 	{
 		casbinRouter.POST("updateCasbin", casbinApi.UpdateCasbin)
+		casbinRouter.POST("migrateRoleInheritance", casbinApi.MigrateRoleInheritance) // 角色继承迁移
 	}
 	{
 		casbinRouterWithoutRecord.POST("getPolicyPathByAuthorityId", casbinApi.GetPolicyPathByAuthorityId)
+		casbinRouterWithoutRecord.POST("simulateCasbin", casbinApi.SimulateCasbin) // 权限模拟
+		casbinRouterWithoutRecord.POST("diffCasbin", casbinApi.DiffCasbin)         // 权限差异预览
 	}
 }
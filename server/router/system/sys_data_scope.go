@@ -0,0 +1,20 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type DataScopeRouter struct{}
+
+func (s *DataScopeRouter) InitDataScopeRouter(Router *gin.RouterGroup) {
+	dataScopeRouter := Router.Group("dataScope").Use(middleware.OperationRecord())
+	dataScopeRouterWithoutRecord := Router.Group("dataScope")
+	{
+		dataScopeRouter.POST("setDataScope", dataScopeApi.SetDataScope)       // 设置行级数据范围
+		dataScopeRouter.POST("deleteDataScope", dataScopeApi.DeleteDataScope) // 删除行级数据范围
+	}
+	{
+		dataScopeRouterWithoutRecord.GET("getDataScopes", dataScopeApi.GetDataScopes) // 获取角色的数据范围规则
+	}
+}
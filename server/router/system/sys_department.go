@@ -0,0 +1,27 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type DepartmentRouter struct{}
+
+func (s *DepartmentRouter) InitDepartmentRouter(Router *gin.RouterGroup) {
+	departmentRouter := Router.Group("department").Use(middleware.OperationRecord())
+	departmentRouterWithoutRecord := Router.Group("department")
+	{
+		departmentRouter.POST("createDepartment", departmentApi.CreateDepartment)         // 创建部门
+		departmentRouter.PUT("updateDepartment", departmentApi.UpdateDepartment)          // 更新部门
+		departmentRouter.DELETE("deleteDepartment", departmentApi.DeleteDepartment)       // 删除部门
+		departmentRouter.POST("moveDepartment", departmentApi.MoveDepartment)             // 移动部门
+		departmentRouter.POST("mergeDepartment", departmentApi.MergeDepartment)           // 合并部门
+		departmentRouter.POST("setUserDepartments", departmentApi.SetUserDepartments)     // 设置用户部门
+		departmentRouter.POST("setDepartmentLeaders", departmentApi.SetDepartmentLeaders) // 设置部门负责人
+	}
+	{
+		departmentRouterWithoutRecord.GET("getDepartmentTree", departmentApi.GetDepartmentTree)   // 获取部门树
+		departmentRouterWithoutRecord.GET("getDepartmentUsers", departmentApi.GetDepartmentUsers) // 获取部门成员
+		departmentRouterWithoutRecord.GET("getUserDepartments", departmentApi.GetUserDepartments) // 获取用户所属部门
+	}
+}
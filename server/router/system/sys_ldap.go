@@ -0,0 +1,19 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type LdapRouter struct{}
+
+func (s *LdapRouter) InitLdapRouter(Router *gin.RouterGroup) {
+	ldapRouter := Router.Group("ldap").Use(middleware.OperationRecord())
+	ldapRouterWithoutRecord := Router.Group("ldap")
+	{
+		ldapRouter.POST("sync", ldapApi.Sync) // 执行LDAP同步
+	}
+	{
+		ldapRouterWithoutRecord.GET("syncPreview", ldapApi.SyncPreview) // 预览LDAP同步差异
+	}
+}
@@ -0,0 +1,28 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationRouter struct{}
+
+func (s *NotificationRouter) InitNotificationRouter(Router *gin.RouterGroup) {
+	notificationRouter := Router.Group("notification").Use(middleware.OperationRecord())
+	notificationRouterWithoutRecord := Router.Group("notification")
+	{
+		notificationRouter.POST("send", notificationApi.SendNotification)                       // 发送站内信
+		notificationRouter.POST("createTemplate", notificationApi.CreateNotificationTemplate)   // 创建站内信模板
+		notificationRouter.PUT("updateTemplate", notificationApi.UpdateNotificationTemplate)    // 更新站内信模板
+		notificationRouter.DELETE("deleteTemplate", notificationApi.DeleteNotificationTemplate) // 删除站内信模板
+	}
+	{
+		notificationRouterWithoutRecord.GET("stream", notificationApi.Stream)                                  // 订阅实时站内信
+		notificationRouterWithoutRecord.GET("getMyNotifications", notificationApi.GetMyNotifications)          // 我的站内信
+		notificationRouterWithoutRecord.GET("getUnread", notificationApi.GetUnread)                            // 未读数量
+		notificationRouterWithoutRecord.POST("markRead", notificationApi.MarkRead)                             // 标记已读
+		notificationRouterWithoutRecord.DELETE("deleteMyNotifications", notificationApi.DeleteMyNotifications) // 删除我的站内信
+		notificationRouterWithoutRecord.GET("findTemplate", notificationApi.FindNotificationTemplate)          // 获取站内信模板
+		notificationRouterWithoutRecord.GET("getTemplateList", notificationApi.GetNotificationTemplateList)    // 分页获取站内信模板列表
+	}
+}
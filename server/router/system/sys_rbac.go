@@ -0,0 +1,20 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type RbacRouter struct{}
+
+func (s *RbacRouter) InitRbacRouter(Router *gin.RouterGroup) {
+	rbacRouter := Router.Group("rbac").Use(middleware.OperationRecord())
+	rbacRouterWithoutRecord := Router.Group("rbac")
+	{
+		rbacRouter.POST("importRbac", rbacApi.ImportRbac) // 导入权限配置包
+	}
+	{
+		rbacRouterWithoutRecord.GET("exportRbac", rbacApi.ExportRbac)                // 导出权限配置包
+		rbacRouterWithoutRecord.POST("previewRbacImport", rbacApi.PreviewRbacImport) // 预览导入差异
+	}
+}
@@ -0,0 +1,22 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type TenantRouter struct{}
+
+func (s *TenantRouter) InitTenantRouter(Router *gin.RouterGroup) {
+	tenantRouter := Router.Group("tenant").Use(middleware.OperationRecord())
+	tenantRouterWithoutRecord := Router.Group("tenant")
+	{
+		tenantRouter.POST("createTenant", tenantApi.CreateTenant)   // 创建租户
+		tenantRouter.PUT("updateTenant", tenantApi.UpdateTenant)    // 更新租户
+		tenantRouter.DELETE("deleteTenant", tenantApi.DeleteTenant) // 删除租户
+	}
+	{
+		tenantRouterWithoutRecord.GET("findTenant", tenantApi.FindTenant)       // 获取租户
+		tenantRouterWithoutRecord.GET("getTenantList", tenantApi.GetTenantList) // 分页获取租户列表
+	}
+}
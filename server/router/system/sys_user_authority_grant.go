@@ -0,0 +1,23 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthorityGrantRouter struct{}
+
+func (s *AuthorityGrantRouter) InitAuthorityGrantRouter(Router *gin.RouterGroup) {
+	grantRouter := Router.Group("authorityGrant").Use(middleware.OperationRecord())
+	grantRouterWithoutRecord := Router.Group("authorityGrant")
+	{
+		grantRouter.POST("createAuthorityGrant", authorityGrantApi.CreateAuthorityGrant) // 授予限时角色
+		grantRouter.POST("delegateAuthority", authorityGrantApi.DelegateAuthority)       // 委托角色
+		grantRouter.POST("revokeAuthorityGrant", authorityGrantApi.RevokeAuthorityGrant) // 撤销授权
+		grantRouter.POST("revokeDelegation", authorityGrantApi.RevokeDelegation)         // 撤销自己发起的委托
+	}
+	{
+		grantRouterWithoutRecord.GET("getAuthorityGrantList", authorityGrantApi.GetAuthorityGrantList) // 获取限时授权列表
+		grantRouterWithoutRecord.GET("getMyAuthorityGrants", authorityGrantApi.GetMyAuthorityGrants)   // 获取我的授权与委托
+	}
+}
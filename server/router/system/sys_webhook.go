@@ -0,0 +1,25 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookRouter struct{}
+
+func (s *WebhookRouter) InitWebhookRouter(Router *gin.RouterGroup) {
+	webhookRouter := Router.Group("webhook").Use(middleware.OperationRecord())
+	webhookRouterWithoutRecord := Router.Group("webhook")
+	{
+		webhookRouter.POST("createWebhook", webhookApi.CreateWebhook)   // 创建webhook
+		webhookRouter.PUT("updateWebhook", webhookApi.UpdateWebhook)    // 更新webhook
+		webhookRouter.DELETE("deleteWebhook", webhookApi.DeleteWebhook) // 删除webhook
+		webhookRouter.POST("replayDelivery", webhookApi.ReplayDelivery) // 重放投递
+	}
+	{
+		webhookRouterWithoutRecord.GET("findWebhook", webhookApi.FindWebhook)           // 获取webhook
+		webhookRouterWithoutRecord.GET("getWebhookList", webhookApi.GetWebhookList)     // 分页获取webhook列表
+		webhookRouterWithoutRecord.GET("getWebhookEvents", webhookApi.GetWebhookEvents) // 获取可订阅的事件
+		webhookRouterWithoutRecord.GET("getDeliveryList", webhookApi.GetDeliveryList)   // 分页获取投递记录
+	}
+}
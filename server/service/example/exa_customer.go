@@ -1,6 +1,8 @@
 package example
 
 import (
+	"context"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
@@ -15,57 +17,57 @@ var CustomerServiceApp = new(CustomerService)
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: CreateExaCustomer
 //@description: 创建客户
-//@param: e model.ExaCustomer
+//@param: ctx context.Context, e model.ExaCustomer
 //@return: err error
 
-func (exa *CustomerService) CreateExaCustomer(e example.ExaCustomer) (err error) {
-	err = global.GVA_DB.Create(&e).Error
+func (exa *CustomerService) CreateExaCustomer(ctx context.Context, e example.ExaCustomer) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Create(&e).Error
 	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: DeleteFileChunk
 //@description: 删除客户
-//@param: e model.ExaCustomer
+//@param: ctx context.Context, e model.ExaCustomer
 //@return: err error
 
-func (exa *CustomerService) DeleteExaCustomer(e example.ExaCustomer) (err error) {
-	err = global.GVA_DB.Delete(&e).Error
+func (exa *CustomerService) DeleteExaCustomer(ctx context.Context, e example.ExaCustomer) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Delete(&e).Error
 	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: UpdateExaCustomer
 //@description: 更新客户
-//@param: e *model.ExaCustomer
+//@param: ctx context.Context, e *model.ExaCustomer
 //@return: err error
 
-func (exa *CustomerService) UpdateExaCustomer(e *example.ExaCustomer) (err error) {
-	err = global.GVA_DB.Save(e).Error
+func (exa *CustomerService) UpdateExaCustomer(ctx context.Context, e *example.ExaCustomer) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Save(e).Error
 	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetExaCustomer
 //@description: 获取客户信息
-//@param: id uint
+//@param: ctx context.Context, id uint
 //@return: customer model.ExaCustomer, err error
 
-func (exa *CustomerService) GetExaCustomer(id uint) (customer example.ExaCustomer, err error) {
-	err = global.GVA_DB.Where("id = ?", id).First(&customer).Error
+func (exa *CustomerService) GetExaCustomer(ctx context.Context, id uint) (customer example.ExaCustomer, err error) {
+	err = global.GVA_DB.WithContext(ctx).Where("id = ?", id).First(&customer).Error
 	return
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetCustomerInfoList
 //@description: 分页获取客户列表
-//@param: sysUserAuthorityID string, info request.PageInfo
+//@param: ctx context.Context, sysUserAuthorityID string, info request.PageInfo
 //@return: list interface{}, total int64, err error
 
-func (exa *CustomerService) GetCustomerInfoList(sysUserAuthorityID uint, info request.PageInfo) (list interface{}, total int64, err error) {
+func (exa *CustomerService) GetCustomerInfoList(ctx context.Context, sysUserAuthorityID uint, info request.PageInfo) (list interface{}, total int64, err error) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
-	db := global.GVA_DB.Model(&example.ExaCustomer{})
+	db := global.GVA_DB.WithContext(ctx).Model(&example.ExaCustomer{})
 	var a system.SysAuthority
 	a.AuthorityId = sysUserAuthorityID
 	auth, err := systemService.AuthorityServiceApp.GetAuthorityInfo(a)
@@ -1,6 +1,7 @@
 package example
 
 import (
+	"context"
 	"errors"
 	"mime/multipart"
 	"strings"
@@ -8,7 +9,10 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"go.uber.org/zap"
 )
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -107,7 +111,16 @@ func (e *FileUploadAndDownloadService) UploadFile(header *multipart.FileHeader,
 		Key:     key,
 	}
 	if noSave == "0" {
-		return f, e.Upload(f)
+		if err = global.GVA_DB.Create(&f).Error; err != nil {
+			return f, err
+		}
+	}
+	// 不保存记录时 ID 为 0
+	err = event.Publish(context.Background(), global.GVA_EVENT, system.EventFileUploaded, system.FileUploadedEvent{
+		ID: f.ID, Name: f.Name, Url: f.Url, Key: f.Key, Tag: f.Tag,
+	})
+	if err != nil {
+		global.GVA_LOG.Error("事件处理失败!", zap.String("event", string(system.EventFileUploaded)), zap.Error(err))
 	}
 	return f, nil
 }
@@ -0,0 +1,16 @@
+package product
+
+import (
+	"context"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+	"go.uber.org/zap"
+)
+
+// publishEvent 发布事件, 订阅方的错误只记录日志不影响业务
+func publishEvent[T any](topic event.Topic[T], payload T) {
+	if err := event.Publish(context.Background(), global.GVA_EVENT, topic, payload); err != nil {
+		global.GVA_LOG.Error("事件处理失败!", zap.String("event", string(topic)), zap.Error(err))
+	}
+}
@@ -279,7 +279,9 @@ func (P *ProductSkusService) ChangeItemsInCart(UserId uint, SkuId string, Quanti
 
 	err = db.Table("user_cart_items").Where("user_id = ? AND sku_id = ?", UserId, SkuId).
 		Update("quantity", Quantity).Error
-
+	if err == nil {
+		publishEvent(dto.EventCartChanged, dto.CartEvent{UserID: UserId, SkuID: SkuId, Quantity: Quantity, Action: dto.EventActionChange})
+	}
 	return err
 }
 
@@ -312,6 +314,7 @@ func (P *ProductSkusService) DeleteItemsFromCart(UserId uint, SkuId string) erro
 		Delete(nil).Error; err != nil {
 		return err
 	}
+	publishEvent(dto.EventCartChanged, dto.CartEvent{UserID: UserId, SkuID: SkuId, Action: dto.EventActionDelete})
 	return nil
 }
 func (P *ProductSkusService) AddItemsIntoCart(UserId uint, SkuId string, Quantity int) error {
@@ -362,6 +365,7 @@ func (P *ProductSkusService) AddItemsIntoCart(UserId uint, SkuId string, Quantit
 		} //加入表
 		err = db.Table("user_cart_items").Where("user_id = ? AND sku_id = ?", UserId, SkuId).
 			Update("quantity", new).Error
+		Quantity = new
 	} else if err != nil {
 		if Quantity > available {
 			return fmt.Errorf("超过可购买数量")
@@ -373,7 +377,9 @@ func (P *ProductSkusService) AddItemsIntoCart(UserId uint, SkuId string, Quantit
 		}).Error
 
 	}
-
+	if err == nil {
+		publishEvent(dto.EventCartChanged, dto.CartEvent{UserID: UserId, SkuID: SkuId, Quantity: Quantity, Action: dto.EventActionAdd})
+	}
 	return err
 }
 func (P *ProductSkusService) GetCartItems(UserId uint) (res dto.CartResponse, err error) {
@@ -1806,7 +1812,9 @@ func (P *ProductSkusService) AddFavouriteSku(UserId uint, SkuId string) error {
 		UserId: UserId,
 		SkuId:  SkuId,
 	}).Error
-
+	if err == nil {
+		publishEvent(dto.EventFavoriteChanged, dto.FavoriteEvent{UserID: UserId, SkuID: SkuId, Action: dto.EventActionAdd})
+	}
 	return err
 }
 
@@ -1841,6 +1849,6 @@ func (P *ProductSkusService) DeleteFavouriteSku(UserId uint, SkuId string) error
 		Delete(nil).Error; err != nil {
 		return err
 	}
-
+	publishEvent(dto.EventFavoriteChanged, dto.FavoriteEvent{UserID: UserId, SkuID: SkuId, Action: dto.EventActionDelete})
 	return nil
 }
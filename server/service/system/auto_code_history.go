@@ -87,6 +87,20 @@ func (s *autoCodeHistory) RollBack(ctx context.Context, info request.SysAutoHist
 		}
 	} // 清除菜单表
 	if info.DeleteTable {
+		var meta request.AutoCode
+		if history.Request != "" && json.Unmarshal([]byte(history.Request), &meta) == nil {
+			for _, child := range meta.Children {
+				tables := []string{child.TableName}
+				if child.Association == request.ChildMany2Many && child.JoinTable != "" {
+					tables = append([]string{child.JoinTable}, tables...)
+				}
+				for _, table := range tables {
+					if err = s.DropTable(history.BusinessDB, table); err != nil {
+						return errors.Wrapf(err, "删除子表[%s]失败!", table)
+					}
+				}
+			}
+		} // 子表和中间表
 		err = s.DropTable(history.BusinessDB, history.Table)
 		if err != nil {
 			return errors.Wrap(err, "删除表失败!")
@@ -118,7 +132,11 @@ func (s *autoCodeHistory) RollBack(ctx context.Context, info request.SysAutoHist
 	history.Templates = templates
 	for key, value := range history.Injections {
 		var injection ast.Ast
-		switch key {
+		kind := key
+		if strings.HasPrefix(key, ast.TypeStructInjection+":") {
+			kind = ast.TypeStructInjection
+		} // 模板包的注入点以文件区分
+		switch kind {
 		case ast.TypePackageApiEnter, ast.TypePackageRouterEnter, ast.TypePackageServiceEnter:
 
 		case ast.TypePackageApiModuleEnter, ast.TypePackageRouterModuleEnter, ast.TypePackageServiceModuleEnter:
@@ -149,6 +167,10 @@ func (s *autoCodeHistory) RollBack(ctx context.Context, info request.SysAutoHist
 			var entity ast.PluginInitializeRouter
 			_ = json.Unmarshal([]byte(value), &entity)
 			injection = &entity
+		case ast.TypeStructInjection:
+			var entity ast.StructInjection
+			_ = json.Unmarshal([]byte(value), &entity)
+			injection = &entity
 		}
 		if injection == nil {
 			continue
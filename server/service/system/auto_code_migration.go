@@ -0,0 +1,232 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/autocode"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录DryRun模式下生成的SQL
+type sqlRecorder struct {
+	logger.Interface
+	sql []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.sql = append(r.sql, sql+";")
+}
+
+// autoCodeDB 生成代码所在的业务库
+func autoCodeDB(info request.AutoCode) *gorm.DB {
+	if info.BusinessDB != "" {
+		return global.MustGetGlobalDBByDBName(info.BusinessDB)
+	}
+	return global.GVA_DB
+}
+
+// recordSQL 使用DryRun记录迁移语句 不会执行
+func recordSQL(db *gorm.DB, table string, fn func(tx *gorm.DB) error) (sql string) {
+	// 部分方言修改列时需要读取现有列信息 DryRun下无法生成
+	defer func() {
+		if r := recover(); r != nil {
+			sql = fmt.Sprintf("%s: 当前数据库无法离线生成该语句(%v)", migrationManualMarker, r)
+		}
+	}()
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{DryRun: true, Logger: recorder}).Table(table)
+	if err := fn(tx); err != nil {
+		return fmt.Sprintf("%s: %s", migrationManualMarker, err.Error())
+	}
+	return strings.Join(recorder.sql, "\n")
+}
+
+// autoCodeCreateTable 按生成的模型生成建表SQL及回退SQL
+func autoCodeCreateTable(db *gorm.DB, info request.AutoCode) (up, down []string) {
+	fields := make([]reflect.StructField, 0, len(info.Fields)+5)
+	if info.GvaModel {
+		// reflect.StructOf 不支持多个带方法的匿名字段 使用gorm的embedded标签代替
+		fields = append(fields,
+			reflect.StructField{Name: "GVA_MODEL", Type: reflect.TypeOf(global.GVA_MODEL{}), Tag: `gorm:"embedded"`},
+			reflect.StructField{Name: "GVA_TENANT", Type: reflect.TypeOf(global.GVA_TENANT{}), Tag: `gorm:"embedded"`},
+		)
+	}
+	for _, f := range info.Fields {
+		fields = append(fields, columnField(f))
+	}
+	if info.AutoCreateResource {
+		fields = append(fields,
+			reflect.StructField{Name: "CreatedBy", Type: reflect.TypeOf(uint(0)), Tag: `gorm:"column:created_by;comment:创建者"`},
+			reflect.StructField{Name: "UpdatedBy", Type: reflect.TypeOf(uint(0)), Tag: `gorm:"column:updated_by;comment:更新者"`},
+			reflect.StructField{Name: "DeletedBy", Type: reflect.TypeOf(uint(0)), Tag: `gorm:"column:deleted_by;comment:删除者"`},
+		)
+	}
+	if info.IsTree {
+		fields = append(fields, reflect.StructField{Name: "ParentID", Type: reflect.TypeOf(0), Tag: `gorm:"column:parent_id;comment:父节点"`})
+	}
+	tables := []string{info.TableName}
+	values := []interface{}{reflect.New(reflect.StructOf(fields)).Interface()}
+	for _, child := range info.Children {
+		if info.OnlyTemplate {
+			break
+		}
+		tables = append(tables, child.TableName)
+		values = append(values, childTable(child))
+		if child.Association == request.ChildMany2Many {
+			tables = append(tables, child.JoinTable)
+			values = append(values, reflect.New(reflect.StructOf([]reflect.StructField{
+				{Name: child.ForeignKey, Type: reflect.TypeOf(uint(0)), Tag: `gorm:"primaryKey;autoIncrement:false"`},
+				{Name: child.ReferenceKey, Type: reflect.TypeOf(uint(0)), Tag: `gorm:"primaryKey;autoIncrement:false"`},
+			})).Interface())
+		}
+	}
+	for i := range tables {
+		up = append(up, recordSQL(db, tables[i], func(tx *gorm.DB) error { return tx.Migrator().CreateTable(values[i]) }))
+	}
+	// 先删除子表和中间表
+	for i := len(tables) - 1; i >= 0; i-- {
+		down = append(down, recordSQL(db, tables[i], func(tx *gorm.DB) error {
+			return tx.Exec("DROP TABLE IF EXISTS ?", clause.Table{Name: tables[i]}).Error
+		}))
+	}
+	return up, down
+}
+
+// childTable 子表模型 一对多时包含指向主表的字段
+func childTable(child *request.AutoCodeChild) interface{} {
+	fields := []reflect.StructField{
+		{Name: "GVA_MODEL", Type: reflect.TypeOf(global.GVA_MODEL{}), Tag: `gorm:"embedded"`},
+		{Name: "GVA_TENANT", Type: reflect.TypeOf(global.GVA_TENANT{}), Tag: `gorm:"embedded"`},
+	}
+	for _, f := range child.Fields {
+		fields = append(fields, columnField(f))
+	}
+	if child.Association == request.ChildHasMany {
+		fields = append(fields, reflect.StructField{
+			Name: child.ForeignKey,
+			Type: reflect.TypeOf(uint(0)),
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%s;index;comment:所属主表ID"`, child.ForeignColumn)),
+		})
+	}
+	return reflect.New(reflect.StructOf(fields)).Interface()
+}
+
+// autoCodeAlterTable 对比新旧字段 生成字段变更SQL及回退SQL
+func autoCodeAlterTable(db *gorm.DB, old, info request.AutoCode) (up, down []string) {
+	record := func(fn func(m gorm.Migrator) error) string {
+		return recordSQL(db, info.TableName, func(tx *gorm.DB) error { return fn(tx.Migrator()) })
+	}
+	add := func(f *request.AutoCodeField) string {
+		return record(func(m gorm.Migrator) error { return m.AddColumn(columnModel(f), f.FieldName) })
+	}
+	drop := func(f *request.AutoCodeField) string {
+		return record(func(m gorm.Migrator) error { return m.DropColumn(columnModel(f), f.FieldName) })
+	}
+	alter := func(f *request.AutoCodeField) string {
+		return record(func(m gorm.Migrator) error { return m.AlterColumn(columnModel(f), f.FieldName) })
+	}
+	rename := func(from, to string) string {
+		return record(func(m gorm.Migrator) error { return m.RenameColumn(&struct{}{}, from, to) })
+	}
+
+	oldFields := make(map[string]*request.AutoCodeField, len(old.Fields))
+	for _, f := range old.Fields {
+		oldFields[f.FieldName] = f
+	}
+	kept := make(map[string]bool, len(info.Fields))
+	for _, f := range info.Fields {
+		o, ok := oldFields[f.FieldName]
+		if !ok {
+			up, down = append(up, add(f)), append(down, drop(f))
+			continue
+		}
+		kept[f.FieldName] = true
+		if o.ColumnName != f.ColumnName {
+			up, down = append(up, rename(o.ColumnName, f.ColumnName)), append(down, rename(f.ColumnName, o.ColumnName))
+		}
+		if columnDefinition(o) != columnDefinition(f) {
+			// 回退时列名仍为新列名 先改回旧定义再改名
+			restore := *o
+			restore.ColumnName = f.ColumnName
+			up, down = append(up, alter(f)), append(down, alter(&restore))
+		}
+	}
+	for _, o := range old.Fields {
+		if !kept[o.FieldName] {
+			up, down = append(up, drop(o)), append(down, add(o))
+		}
+	}
+	for i, j := 0, len(down)-1; i < j; i, j = i+1, j-1 {
+		down[i], down[j] = down[j], down[i]
+	}
+	return up, down
+}
+
+// columnDefinition 字段的Go类型和gorm标签 决定数据库列定义
+func columnDefinition(f *request.AutoCodeField) string {
+	return columnType(f).String() + " " + columnTag(f)
+}
+
+// columnModel 按字段定义构造只包含该字段的结构体 供gorm解析列定义
+func columnModel(f *request.AutoCodeField) interface{} {
+	return reflect.New(reflect.StructOf([]reflect.StructField{columnField(f)})).Interface()
+}
+
+func columnField(f *request.AutoCodeField) reflect.StructField {
+	return reflect.StructField{
+		Name: f.FieldName,
+		Type: columnType(f),
+		Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"%s"`, columnTag(f))),
+	}
+}
+
+func columnType(f *request.AutoCodeField) reflect.Type {
+	switch f.FieldType {
+	case "int":
+		return reflect.TypeOf(int(0))
+	case "bool":
+		return reflect.TypeOf(false)
+	case "float64":
+		return reflect.TypeOf(float64(0))
+	case "time.Time":
+		return reflect.TypeOf(time.Time{})
+	case "file", "pictures", "array", "json":
+		return reflect.TypeOf(datatypes.JSON{})
+	default:
+		return reflect.TypeOf("")
+	}
+}
+
+// columnTag 取生成代码中的gorm标签 与模型文件保持一致
+func columnTag(f *request.AutoCodeField) string {
+	line := autocode.GenerateField(*f)
+	start, end := strings.Index(line, "`"), strings.LastIndex(line, "`")
+	if start == -1 || end <= start {
+		return "column:" + f.ColumnName
+	}
+	tag := reflect.StructTag(line[start+1 : end]).Get("gorm")
+	switch f.FieldType {
+	case "enum":
+	case "richtext":
+		tag += "type:text;"
+	default:
+		if f.DataTypeLong != "" {
+			tag += "size:" + f.DataTypeLong + ";"
+		}
+	}
+	return tag
+}
@@ -0,0 +1,119 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/autocode"
+	"github.com/pkg/errors"
+)
+
+// readOpenAPI 读取项目目录下的OpenAPI文档
+func (s *autoCodeTemplate) readOpenAPI(path string) (*autocode.OpenAPI, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil, errors.New("仅支持 .yaml/.yml/.json 格式的OpenAPI文档")
+	}
+	root := global.GVA_CONFIG.AutoCode.Root
+	file := filepath.Join(root, path)
+	if rel, err := filepath.Rel(root, file); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, errors.New("文档路径必须位于项目目录下")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "读取文档[%s]失败!", path)
+	}
+	return autocode.ParseOpenAPI(data)
+}
+
+// OpenAPISchemas 列出OpenAPI文档中的Schema
+func (s *autoCodeTemplate) OpenAPISchemas(path string) ([]response.OpenAPISchema, error) {
+	doc, err := s.readOpenAPI(path)
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]response.OpenAPISchema, 0, len(doc.Components.Schemas))
+	for _, schema := range doc.Components.Schemas {
+		schemas = append(schemas, response.OpenAPISchema{
+			Name:        schema.Name,
+			Title:       schema.Schema.Title,
+			Description: schema.Schema.Description,
+		})
+	}
+	return schemas, nil
+}
+
+// OpenAPI 将OpenAPI文档中的Schema转换为代码生成配置
+// mode 为 preview 时预览代码, 为 create 时创建缺少的字典并生成代码
+func (s *autoCodeTemplate) OpenAPI(ctx context.Context, req request.AutoCodeOpenAPI) (result response.AutoCodeOpenAPI, err error) {
+	doc, err := s.readOpenAPI(req.Path)
+	if err != nil {
+		return result, err
+	}
+	converted, err := doc.ConvertOpenAPISchema(req.Schema)
+	if err != nil {
+		return result, err
+	}
+	info := converted.AutoCode
+	info.Package = req.Package
+	info.PackageT = utils.FirstUpper(req.Package)
+	info.BusinessDB = req.BusinessDB
+	info.GenerateServer = true
+	info.GenerateWeb = true
+	info.AutoCreateApiToSql = true
+	result.AutoCode = info
+	result.Warnings = converted.Warnings
+	for _, dict := range converted.Dictionaries {
+		var count int64
+		err = global.GVA_DB.WithContext(ctx).Model(&model.SysDictionary{}).Where("type = ?", dict.Type).Count(&count).Error
+		if err != nil {
+			return result, errors.Wrap(err, "查询字典失败!")
+		}
+		result.Dictionaries = append(result.Dictionaries, response.OpenAPIDictionary{SysDictionary: dict, Exists: count > 0})
+	}
+	if req.Mode == "" {
+		return result, nil
+	}
+
+	if info.Package == "" {
+		return result, errors.New("请选择生成到的包")
+	}
+	err = utils.Verify(info, utils.AutoCodeVerify)
+	if err != nil {
+		return result, err
+	}
+	err = info.Pretreatment()
+	if err != nil {
+		return result, err
+	}
+	switch req.Mode {
+	case "preview":
+		result.Preview, err = s.Preview(ctx, info)
+		return result, err
+	case "create":
+		// 先检查重复 避免生成失败时留下字典
+		if AutocodeHistory.Repeat(info.BusinessDB, info.StructName, info.Abbreviation, info.Package) {
+			return result, errors.New("已经创建过此数据结构,请勿重复创建!")
+		}
+		for _, dict := range result.Dictionaries {
+			if dict.Exists {
+				continue
+			}
+			err = DictionaryServiceApp.CreateSysDictionary(dict.SysDictionary)
+			if err != nil {
+				return result, errors.Wrapf(err, "创建字典[%s]失败!", dict.Type)
+			}
+		}
+		return result, s.Create(ctx, info)
+	default:
+		return result, errors.Errorf("不支持的模式: %s", req.Mode)
+	}
+}
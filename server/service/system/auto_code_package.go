@@ -9,7 +9,6 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
-	"github.com/flipped-aurora/gin-vue-admin/server/utils/autocode"
 	"github.com/pkg/errors"
 	"go/token"
 	"gorm.io/gorm"
@@ -36,13 +35,22 @@ func (s *autoCodePackage) Create(ctx context.Context, info *request.SysAutoCodeP
 		return errors.New("PackageName不能为空!")
 	case token.IsKeyword(info.PackageName):
 		return errors.Errorf("%s为go的关键字!", info.PackageName)
-	case info.Template == "package":
+	case s.baseTemplate(info.Template) == "package":
 		if info.PackageName == "system" || info.PackageName == "example" {
 			return errors.New("不能使用已保留的package name")
 		}
 	default:
 		break
 	}
+	pack, err := s.pack(info.Template)
+	if err != nil {
+		return err
+	}
+	if pack != nil {
+		if problems := pack.Validate(s.templateDir(pack.Extends)); len(problems) > 0 {
+			return errors.Errorf("模板包[%s]不可用:\n%s", pack.Name, strings.Join(problems, "\n"))
+		}
+	}
 	if !errors.Is(global.GVA_DB.Where("package_name = ? and template = ?", info.PackageName, info.Template).First(&model.SysAutoCodePackage{}).Error, gorm.ErrRecordNotFound) {
 		return errors.New("存在相同PackageName")
 	}
@@ -57,9 +65,13 @@ func (s *autoCodePackage) Create(ctx context.Context, info *request.SysAutoCodeP
 		if err != nil {
 			return err
 		}
+		funcs, err := s.funcMap(create.Template)
+		if err != nil {
+			return err
+		}
 		for key, value := range creates { // key 为 模版绝对路径
 			var files *template.Template
-			files, err = template.New(filepath.Base(key)).Funcs(autocode.GetTemplateFuncMap()).ParseFiles(key)
+			files, err = template.New(filepath.Base(key)).Funcs(funcs).ParseFiles(key)
 			if err != nil {
 				return errors.Wrapf(err, "[filepath:%s]读取模版文件失败!", key)
 			}
@@ -243,7 +255,14 @@ func (s *autoCodePackage) templates(ctx context.Context, entity model.SysAutoCod
 	code = make(map[string]string)
 	asts = make(map[string]ast.Ast)
 	creates = make(map[string]string)
-	templateDir := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "resource", entity.Template)
+	pack, err := s.pack(entity.Template)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if pack != nil {
+		return s.packTemplates(ctx, pack, entity, info, isPackage)
+	}
+	templateDir := s.templateDir(entity.Template)
 	templateDirs, err := os.ReadDir(templateDir)
 	if err != nil {
 		return nil, nil, nil, errors.Wrapf(err, "读取模版文件夹[%s]失败!", templateDir)
@@ -311,10 +330,17 @@ func (s *autoCodePackage) templates(ctx context.Context, entity model.SysAutoCod
 						if router == -1 && api == -1 && service == -1 && hasEnter == -1 {
 							return nil, nil, nil, errors.Errorf("[filpath:%s]非法模版文件!", four)
 						}
+						filename := info.HumpPackageName + ".go"
+						if strings.HasSuffix(strings.TrimSuffix(threeDirs[k].Name(), ext), "_test.go") {
+							if !info.GenerateTest || info.OnlyTemplate {
+								continue
+							} // 测试文件按需生成
+							filename = info.HumpPackageName + "_test.go"
+						}
 						if entity.Template == "package" {
-							create := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, secondDirs[j].Name(), entity.PackageName, info.HumpPackageName+".go")
+							create := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, secondDirs[j].Name(), entity.PackageName, filename)
 							if api != -1 {
-								create = filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, secondDirs[j].Name(), "v1", entity.PackageName, info.HumpPackageName+".go")
+								create = filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, secondDirs[j].Name(), "v1", entity.PackageName, filename)
 							}
 							if hasEnter != -1 {
 								isApi := strings.Index(secondDirs[j].Name(), "api")
@@ -452,7 +478,7 @@ func (s *autoCodePackage) templates(ctx context.Context, entity model.SysAutoCod
 							}
 							continue
 						} // enter.go
-						create := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin", entity.PackageName, secondDirs[j].Name(), info.HumpPackageName+".go")
+						create := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin", entity.PackageName, secondDirs[j].Name(), filename)
 						code[four] = create
 					}
 				case "gen", "config", "initialize", "plugin", "response":
@@ -509,6 +535,7 @@ func (s *autoCodePackage) templates(ctx context.Context, entity model.SysAutoCod
 								Path:        filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin", entity.PackageName, secondDirs[j].Name(), strings.TrimSuffix(threeDirs[k].Name(), ext)),
 								ImportPath:  fmt.Sprintf(`"%s/plugin/%s/model"`, global.GVA_CONFIG.AutoCode.Module, entity.PackageName),
 								StructName:  info.StructName,
+								Children:    info.ChildStructNames(),
 								PackageName: "model",
 								IsNew:       true,
 							}
@@ -588,6 +615,7 @@ func (s *autoCodePackage) templates(ctx context.Context, entity model.SysAutoCod
 								ImportPath:  fmt.Sprintf(`"%s/model/%s"`, global.GVA_CONFIG.AutoCode.Module, entity.PackageName),
 								Business:    info.BusinessDB,
 								StructName:  info.StructName,
+								Children:    info.ChildStructNames(),
 								PackageName: entity.PackageName,
 								IsNew:       true,
 							}
@@ -3,6 +3,7 @@ package system
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
@@ -152,11 +153,18 @@ func (s *autoCodePlugin) PubPlug(plugName string) (zipPath string, err error) {
 		return "", errors.New("server路径不存在")
 	}
 
+	manifestPath, err := s.packManifest(plugName, serverPath)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(manifestPath)
+
 	fileName := plugName + ".zip"
 	// 创建一个新的zip文件
 	files, err := archiver.FilesFromDisk(nil, map[string]string{
-		webPath:    plugName + "/web/plugin/" + plugName,
-		serverPath: plugName + "/server/plugin/" + plugName,
+		webPath:      plugName + "/web/plugin/" + plugName,
+		serverPath:   plugName + "/server/plugin/" + plugName,
+		manifestPath: plugName + "/" + pluginManifestFile,
 	})
 
 	// create the output file we'll write to
@@ -181,6 +189,36 @@ func (s *autoCodePlugin) PubPlug(plugName string) (zipPath string, err error) {
 	return filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, fileName), nil
 }
 
+// packManifest 生成打包用的插件清单临时文件
+// 插件目录下有 plugin.json 时校验后使用, 否则生成只包含插件名和版本的清单
+func (s *autoCodePlugin) packManifest(plugName, serverPath string) (string, error) {
+	manifest := system.PluginManifest{Name: plugName, Version: "v1.0.0", CoreVersion: global.GVA_VERSION}
+	data, err := os.ReadFile(filepath.Join(serverPath, pluginManifestFile))
+	if err == nil {
+		if manifest, err = ParsePluginManifest(data); err != nil {
+			return "", err
+		}
+		if manifest.Name != plugName {
+			return "", errors.Errorf("%s 中的插件名 %s 与插件目录 %s 不一致", pluginManifestFile, manifest.Name, plugName)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	data, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp("", "gva-plugin-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err = file.Write(data); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
 func (s *autoCodePlugin) InitMenu(menuInfo request.InitMenu) (err error) {
 	menuPath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin", menuInfo.PlugName, "initialize", "menu.go")
 	src, err := os.ReadFile(menuPath)
@@ -0,0 +1,522 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	goast "go/ast"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
+	pluginV2 "github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/v2"
+	cp "github.com/otiai10/copy"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+	"gorm.io/gorm"
+)
+
+// pluginManifestFile 插件包根目录下的清单文件
+const pluginManifestFile = "plugin.json"
+
+var (
+	pluginNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	pluginTablePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	pluginApiMethods   = map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true}
+)
+
+// ParsePluginManifest 解析并校验插件清单
+func ParsePluginManifest(data []byte) (manifest system.PluginManifest, err error) {
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return manifest, errors.Wrap(err, "解析"+pluginManifestFile+"失败")
+	}
+	return manifest, validatePluginManifest(manifest)
+}
+
+// validatePluginManifest 校验清单本身 不涉及当前环境
+func validatePluginManifest(m system.PluginManifest) error {
+	if !pluginNamePattern.MatchString(m.Name) {
+		return errors.Errorf("插件名 %q 不合法, 只能包含字母、数字和下划线且不能以数字开头", m.Name)
+	}
+	if !semver.IsValid(m.Version) {
+		return errors.Errorf("插件版本 %q 不合法, 请使用 v1.0.0 格式的版本号", m.Version)
+	}
+	if !semver.IsValid(m.CoreVersion) {
+		return errors.Errorf("coreVersion %q 不合法, 请使用 v2.8.0 格式的版本号", m.CoreVersion)
+	}
+	if semver.Compare(m.CoreVersion, global.GVA_VERSION) > 0 {
+		return errors.Errorf("插件需要 gin-vue-admin %s 及以上版本, 当前版本为 %s", m.CoreVersion, global.GVA_VERSION)
+	}
+	tables := make(map[string]bool, len(m.Tables))
+	for _, table := range m.Tables {
+		if !pluginTablePattern.MatchString(table) {
+			return errors.Errorf("表名 %q 不合法", table)
+		}
+		if tables[table] {
+			return errors.Errorf("表 %s 重复", table)
+		}
+		tables[table] = true
+	}
+	menus := make(map[string]bool, len(m.Menus))
+	for _, menu := range m.Menus {
+		if menu.Name == "" || menu.Path == "" {
+			return errors.New("菜单的name和path不能为空")
+		}
+		if menus[menu.Name] {
+			return errors.Errorf("菜单 %s 重复", menu.Name)
+		}
+		menus[menu.Name] = true
+	}
+	apis := make(map[string]bool, len(m.Apis))
+	for _, api := range m.Apis {
+		if !strings.HasPrefix(api.Path, "/") || !pluginApiMethods[api.Method] {
+			return errors.Errorf("api %s %s 不合法", api.Method, api.Path)
+		}
+		if apis[rbacApiKey(api)] {
+			return errors.Errorf("api %s %s 重复", api.Method, api.Path)
+		}
+		apis[rbacApiKey(api)] = true
+	}
+	return nil
+}
+
+// InstallPackage 安装插件包 校验清单后复制代码、注入插件注册、执行插件迁移并创建菜单和api
+func (s *autoCodePlugin) InstallPackage(ctx context.Context, file *multipart.FileHeader) (result systemRes.PluginSteps, err error) {
+	dir, err := os.MkdirTemp("", "gva-plugin-")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, filepath.Base(file.Filename))
+	if err = saveMultipartFile(file, archive); err != nil {
+		return result, err
+	}
+	if _, err = utils.Unzip(archive, filepath.Join(dir, "src")); err != nil {
+		return result, errors.Wrap(err, "解压插件包失败")
+	}
+	root, err := findPluginRoot(filepath.Join(dir, "src"))
+	if err != nil {
+		return result, err
+	}
+	data, err := os.ReadFile(filepath.Join(root, pluginManifestFile))
+	if err != nil {
+		return result, err
+	}
+	result.Manifest, err = ParsePluginManifest(data)
+	if err != nil {
+		return result, err
+	}
+	err = s.install(ctx, root, &result)
+	return result, err
+}
+
+// install 从解压后的插件包目录安装插件 任一步失败时撤销已完成的步骤
+func (s *autoCodePlugin) install(ctx context.Context, root string, result *systemRes.PluginSteps) (err error) {
+	m := result.Manifest
+	serverFrom := filepath.Join(root, "server", "plugin", m.Name)
+	webFrom := filepath.Join(root, "web", "plugin", m.Name)
+	if _, err = os.Stat(filepath.Join(serverFrom, "plugin.go")); err != nil {
+		return errors.Errorf("插件包中缺少 server/plugin/%s/plugin.go, 只支持安装v2插件", m.Name)
+	}
+	_, err = os.Stat(webFrom)
+	web := err == nil
+	serverTo, webTo := pluginServerPath(m.Name), pluginWebPath(m.Name)
+	if err = s.checkInstall(ctx, m, web); err != nil {
+		return err
+	}
+
+	var undo []func()
+	defer func() {
+		if err != nil {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i]()
+			}
+			result.Steps = nil
+		}
+	}()
+	step := func(format string, args ...interface{}) {
+		result.Steps = append(result.Steps, fmt.Sprintf(format, args...))
+	}
+
+	if err = cp.Copy(serverFrom, serverTo, cp.Options{Skip: skipMacSpecialDocument}); err != nil {
+		return errors.Wrap(err, "复制后端代码失败")
+	}
+	undo = append(undo, func() { _ = os.RemoveAll(serverTo) })
+	step("复制后端代码到 %s", pluginRelativePath(serverTo))
+	if web {
+		if err = cp.Copy(webFrom, webTo, cp.Options{Skip: skipMacSpecialDocument}); err != nil {
+			return errors.Wrap(err, "复制前端代码失败")
+		}
+		undo = append(undo, func() { _ = os.RemoveAll(webTo) })
+		step("复制前端代码到 %s", pluginRelativePath(webTo))
+	}
+
+	register := pluginRegister(m.Name)
+	if err = applyAst(register, register.Injection); err != nil {
+		return errors.Wrap(err, "注册插件失败")
+	}
+	undo = append(undo, func() { _ = applyAst(register, register.Rollback) })
+	step("在 %s 中注册插件", pluginRelativePath(register.PluginPath))
+
+	module := "plugin/" + m.Name
+	done, err := MigrationServiceApp.Up(module)
+	if len(done) > 0 {
+		undo = append(undo, func() { _, _ = MigrationServiceApp.Down(module, len(done)) })
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range done {
+		step("执行迁移 %s", name)
+	}
+
+	manifest, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, _, err := RbacServiceApp.importMenus(tx, m.Menus, request.RbacImportSkip); err != nil {
+			return err
+		}
+		if err := RbacServiceApp.importApis(tx, m.Apis, request.RbacImportSkip); err != nil {
+			return err
+		}
+		return tx.Create(&system.SysPlugin{
+			Name:        m.Name,
+			Version:     m.Version,
+			Description: m.Description,
+			Manifest:    manifest,
+			Web:         web,
+			InstalledAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, "创建菜单和api失败")
+	}
+	for _, menu := range m.Menus {
+		step("创建菜单 %s", menu.Name)
+	}
+	for _, api := range m.Apis {
+		step("创建api %s %s", api.Method, api.Path)
+	}
+	step("重新编译并重启服务后插件生效")
+	return nil
+}
+
+// checkInstall 检查插件是否与当前环境冲突 安装不会覆盖已有的代码、表、菜单和api
+func (s *autoCodePlugin) checkInstall(ctx context.Context, m system.PluginManifest, web bool) error {
+	db := global.GVA_DB.WithContext(ctx)
+	var count int64
+	if err := db.Model(&system.SysPlugin{}).Where("name = ?", m.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.Errorf("插件 %s 已安装, 请先卸载", m.Name)
+	}
+	paths := []string{pluginServerPath(m.Name)}
+	if web {
+		paths = append(paths, pluginWebPath(m.Name))
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return errors.Errorf("%s 已存在同名插件", pluginRelativePath(path))
+		}
+	}
+	for _, table := range m.Tables {
+		if db.Migrator().HasTable(table) {
+			return errors.Errorf("表 %s 已存在", table)
+		}
+	}
+	menus := make(map[string]bool, len(m.Menus))
+	for _, menu := range m.Menus {
+		if err := db.Model(&system.SysBaseMenu{}).Where("name = ?", menu.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.Errorf("菜单 %s 已存在", menu.Name)
+		}
+		if menu.ParentName != "" && !menus[menu.ParentName] {
+			if err := db.Model(&system.SysBaseMenu{}).Where("name = ?", menu.ParentName).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return errors.Errorf("菜单 %s 的父菜单 %s 不存在", menu.Name, menu.ParentName)
+			}
+		}
+		menus[menu.Name] = true
+	}
+	for _, api := range m.Apis {
+		if err := db.Model(&system.SysApi{}).Where("path = ? AND method = ?", api.Path, api.Method).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.Errorf("api %s %s 已存在", api.Method, api.Path)
+		}
+	}
+	return nil
+}
+
+// GetInstalledPlugins 获取通过插件包安装的插件
+func (s *autoCodePlugin) GetInstalledPlugins(ctx context.Context) (list []system.SysPlugin, err error) {
+	err = global.GVA_DB.WithContext(ctx).Order("id desc").Find(&list).Error
+	return list, err
+}
+
+// GetPluginStatus 获取v2插件的启动顺序、生命周期状态及健康检查结果
+func (s *autoCodePlugin) GetPluginStatus(ctx context.Context) []pluginV2.Status {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return global.GVA_PLUGIN.Statuses(ctx)
+}
+
+// Uninstall 卸载通过插件包安装的插件 依次回滚插件迁移、删除表、菜单和api、移除注册与代码
+// DryRun 时只列出将要执行的操作
+func (s *autoCodePlugin) Uninstall(ctx context.Context, info request.PluginUninstall) (result systemRes.PluginSteps, err error) {
+	db := global.GVA_DB.WithContext(ctx)
+	var record system.SysPlugin
+	err = db.Where("name = ?", info.Name).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return result, errors.Errorf("插件 %s 不是通过插件包安装的, 无法卸载", info.Name)
+	}
+	if err != nil {
+		return result, err
+	}
+	if err = json.Unmarshal(record.Manifest, &result.Manifest); err != nil {
+		return result, errors.Wrap(err, "解析安装时的插件清单失败")
+	}
+	m := result.Manifest
+	result.DryRun = info.DryRun
+	step := func(format string, args ...interface{}) {
+		result.Steps = append(result.Steps, fmt.Sprintf(format, args...))
+	}
+
+	module := "plugin/" + m.Name
+	applied, err := MigrationServiceApp.applied(module)
+	if err != nil {
+		return result, err
+	}
+	if len(applied) > 0 {
+		step("回滚 %s 的 %d 个迁移", module, len(applied))
+	}
+	var tables []string
+	for _, table := range m.Tables {
+		if db.Migrator().HasTable(table) {
+			tables = append(tables, table)
+			step("删除表 %s", table)
+		}
+	}
+	names := make([]string, 0, len(m.Menus))
+	for _, menu := range m.Menus {
+		names = append(names, menu.Name)
+	}
+	var menus []system.SysBaseMenu
+	if len(names) > 0 {
+		if err = db.Where("name in ?", names).Find(&menus).Error; err != nil {
+			return result, err
+		}
+		var authorities []system.SysAuthority
+		if err = db.Where("default_router in ?", names).Limit(1).Find(&authorities).Error; err != nil {
+			return result, err
+		}
+		if len(authorities) > 0 {
+			return result, errors.Errorf("角色 %s 正在使用插件菜单 %s 作为首页, 请先修改角色首页", authorities[0].AuthorityName, authorities[0].DefaultRouter)
+		}
+	}
+	for _, menu := range menus {
+		step("删除菜单 %s 及其按钮、参数和角色授权", menu.Name)
+	}
+	var apis []system.SysApi
+	for _, api := range m.Apis {
+		var exists []system.SysApi
+		if err = db.Where("path = ? AND method = ?", api.Path, api.Method).Find(&exists).Error; err != nil {
+			return result, err
+		}
+		apis = append(apis, exists...)
+		if len(exists) > 0 {
+			step("删除api %s %s 及其角色授权", api.Method, api.Path)
+		}
+	}
+	register := pluginRegister(m.Name)
+	registered, err := pluginRegistered(register)
+	if err != nil {
+		return result, err
+	}
+	if registered {
+		step("从 %s 中移除插件注册", pluginRelativePath(register.PluginPath))
+	}
+	var paths []string
+	for _, path := range []string{pluginServerPath(m.Name), pluginWebPath(m.Name)} {
+		if _, err = os.Stat(path); err == nil {
+			paths = append(paths, path)
+			step("删除目录 %s", pluginRelativePath(path))
+		}
+	}
+	step("删除插件 %s 的安装记录", m.Name)
+	if info.DryRun {
+		return result, nil
+	}
+
+	// 迁移文件位于插件目录中 先回滚迁移再删除代码
+	if len(applied) > 0 {
+		if _, err = MigrationServiceApp.Down(module, len(applied)); err != nil {
+			return result, err
+		}
+	}
+	for _, table := range tables {
+		if err = db.Migrator().DropTable(table); err != nil {
+			return result, errors.Wrapf(err, "删除表 %s 失败", table)
+		}
+	}
+	var policies int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if len(menus) > 0 {
+			ids := make([]uint, 0, len(menus))
+			for _, menu := range menus {
+				ids = append(ids, menu.ID)
+			}
+			if err := tx.Delete(&system.SysAuthorityMenu{}, "sys_base_menu_id in ?", ids).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&system.SysAuthorityBtn{}, "sys_menu_id in ?", ids).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&system.SysBaseMenuBtn{}, "sys_base_menu_id in ?", ids).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&system.SysBaseMenuParameter{}, "sys_base_menu_id in ?", ids).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&system.SysBaseMenu{}, "id in ?", ids).Error; err != nil {
+				return err
+			}
+		}
+		for _, api := range apis {
+			if err := tx.Unscoped().Delete(&api).Error; err != nil {
+				return err
+			}
+			deleted := tx.Delete(&gormadapter.CasbinRule{}, "v1 = ? AND v2 = ?", api.Path, api.Method)
+			if deleted.Error != nil {
+				return deleted.Error
+			}
+			policies += deleted.RowsAffected
+		}
+		return tx.Delete(&record).Error
+	})
+	if err != nil {
+		return result, err
+	}
+	if policies > 0 {
+		if err = CasbinServiceApp.FreshCasbin(); err != nil {
+			return result, err
+		}
+	}
+	if registered {
+		if err = applyAst(register, register.Rollback); err != nil {
+			return result, errors.Wrap(err, "移除插件注册失败")
+		}
+	}
+	for _, path := range paths {
+		if err = os.RemoveAll(path); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// pluginRegister 插件在 initialize/plugin_biz_v2.go 中的注册
+func pluginRegister(name string) *ast.PluginInitializeV2 {
+	return &ast.PluginInitializeV2{
+		Type:        ast.TypePluginInitializeV2,
+		Path:        filepath.Join(pluginServerPath(name), "plugin.go"),
+		PluginPath:  filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "initialize", "plugin_biz_v2.go"),
+		ImportPath:  fmt.Sprintf(`"%s/plugin/%s"`, global.GVA_CONFIG.AutoCode.Module, name),
+		PackageName: name,
+	}
+}
+
+func pluginRegistered(register *ast.PluginInitializeV2) (bool, error) {
+	file, err := register.Parse("", nil)
+	if err != nil {
+		return false, err
+	}
+	return ast.CheckImport(file, strings.Trim(register.ImportPath, `"`)), nil
+}
+
+// applyAst 解析注入点文件 执行注入或回滚后写回
+func applyAst(a ast.Ast, fn func(file *goast.File) error) error {
+	file, err := a.Parse("", nil)
+	if err != nil {
+		return err
+	}
+	if err = fn(file); err != nil {
+		return err
+	}
+	return a.Format("", nil, file)
+}
+
+func pluginServerPath(name string) string {
+	return filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin", name)
+}
+
+func pluginWebPath(name string) string {
+	return filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Web, "plugin", name)
+}
+
+// pluginRelativePath 相对项目根目录的路径 用于展示
+func pluginRelativePath(path string) string {
+	if rel, err := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// findPluginRoot 查找清单所在目录 清单位于压缩包根目录或唯一的顶层目录中
+func findPluginRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, pluginManifestFile)); err == nil {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if ok, _ := skipMacSpecialDocument(nil, entry.Name(), ""); ok || !entry.IsDir() {
+			continue
+		}
+		dirs = append(dirs, filepath.Join(dir, entry.Name()))
+	}
+	if len(dirs) == 1 {
+		if _, err = os.Stat(filepath.Join(dirs[0], pluginManifestFile)); err == nil {
+			return dirs[0], nil
+		}
+	}
+	return "", errors.New("插件包中缺少" + pluginManifestFile + ", 请使用打包插件功能重新打包")
+}
+
+func saveMultipartFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, src)
+	return err
+}
@@ -0,0 +1,227 @@
+package system
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/stretchr/testify/assert"
+)
+
+const pluginRegisterSource = `package initialize
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func PluginInitV2(group *gin.Engine, plugins ...plugin.Plugin) {
+	global.GVA_PLUGIN.Add(plugins...)
+}
+func bizPluginV2(engine *gin.Engine) {
+}
+`
+
+func demoPluginManifest() system.PluginManifest {
+	return system.PluginManifest{
+		Name:        "demo",
+		Version:     "v1.0.0",
+		CoreVersion: "v2.8.0",
+		Tables:      []string{"demo_items"},
+		Menus:       []system.RbacMenu{{Name: "demo", Path: "demo", Component: "plugin/demo/view/index.vue"}},
+		Apis:        []system.RbacApi{{Path: "/demo/list", Method: "GET", ApiGroup: "demo"}},
+	}
+}
+
+// demoPluginFiles 插件包内容 prefix 为压缩包内的顶层目录
+func demoPluginFiles(prefix string, m system.PluginManifest) map[string]string {
+	manifest, _ := json.Marshal(m)
+	return map[string]string{
+		prefix + "plugin.json":                                      string(manifest),
+		prefix + "server/plugin/demo/plugin.go":                     "package demo\n",
+		prefix + "server/plugin/demo/migrations/0001_init.up.sql":   "CREATE TABLE demo_items (id integer);",
+		prefix + "server/plugin/demo/migrations/0001_init.down.sql": "DROP TABLE demo_items;",
+		prefix + "web/plugin/demo/view/index.vue":                   "<template></template>\n",
+		prefix + "__MACOSX/._plugin.json":                           "",
+	}
+}
+
+// pluginArchive 将文件打包为zip 并构造上传的文件
+func pluginArchive(t *testing.T, files map[string]string) *multipart.FileHeader {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.Nil(t, err)
+		_, _ = w.Write([]byte(content))
+	}
+	assert.Nil(t, zw.Close())
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	w, err := mw.CreateFormFile("file", "demo.zip")
+	assert.Nil(t, err)
+	_, _ = w.Write(archive.Bytes())
+	assert.Nil(t, mw.Close())
+	form, err := multipart.NewReader(&body, mw.Boundary()).ReadForm(1 << 20)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+// setupPluginInstallTest 使用临时项目目录和数据库 返回插件注册文件的路径
+func setupPluginInstallTest(t *testing.T) string {
+	newCasbinTestDB(t)
+	assert.Nil(t, global.GVA_DB.AutoMigrate(&system.SysPlugin{}, &system.SysApi{}, &system.SysBaseMenuParameter{},
+		&system.SysBaseMenuBtn{}, &system.SysAuthorityMenu{}, &system.SysSchemaMigration{}))
+	root := t.TempDir()
+	oldAutoCode, oldRegistered := global.GVA_CONFIG.AutoCode, registeredMigrations
+	global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, global.GVA_CONFIG.AutoCode.Web = root, "server", "web"
+	global.GVA_CONFIG.AutoCode.Module = "github.com/flipped-aurora/gin-vue-admin/server"
+	registeredMigrations = nil
+	t.Cleanup(func() { global.GVA_CONFIG.AutoCode, registeredMigrations = oldAutoCode, oldRegistered })
+
+	register := filepath.Join(root, "server", "initialize", "plugin_biz_v2.go")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(register), 0o755))
+	assert.Nil(t, os.WriteFile(register, []byte(pluginRegisterSource), 0o644))
+	return register
+}
+
+func countRows(t *testing.T, model interface{}) (n int64) {
+	assert.Nil(t, global.GVA_DB.Model(model).Count(&n).Error)
+	return n
+}
+
+func TestValidatePluginManifest(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(m *system.PluginManifest)
+		errMsg string
+	}{
+		{name: "合法", modify: func(m *system.PluginManifest) {}},
+		{name: "缺少插件名", modify: func(m *system.PluginManifest) { m.Name = "" }, errMsg: "插件名"},
+		{name: "插件名不合法", modify: func(m *system.PluginManifest) { m.Name = "1demo" }, errMsg: "插件名"},
+		{name: "版本号不是semver", modify: func(m *system.PluginManifest) { m.Version = "1.0" }, errMsg: "插件版本"},
+		{name: "coreVersion不是semver", modify: func(m *system.PluginManifest) { m.CoreVersion = "latest" }, errMsg: "coreVersion"},
+		{name: "coreVersion高于当前版本", modify: func(m *system.PluginManifest) { m.CoreVersion = "v99.0.0" }, errMsg: global.GVA_VERSION},
+		{name: "表名不合法", modify: func(m *system.PluginManifest) { m.Tables = []string{"demo;drop"} }, errMsg: "表名"},
+		{name: "菜单重复", modify: func(m *system.PluginManifest) { m.Menus = append(m.Menus, m.Menus[0]) }, errMsg: "菜单"},
+		{name: "api方法不合法", modify: func(m *system.PluginManifest) { m.Apis[0].Method = "FETCH" }, errMsg: "api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := demoPluginManifest()
+			tt.modify(&m)
+			data, _ := json.Marshal(m)
+			_, err := ParsePluginManifest(data)
+			if tt.errMsg == "" {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+		})
+	}
+
+	_, err := ParsePluginManifest([]byte("{"))
+	assert.NotNil(t, err)
+}
+
+func TestInstallPackageBadManifest(t *testing.T) {
+	register := setupPluginInstallTest(t)
+	m := demoPluginManifest()
+	m.CoreVersion = "v99.0.0"
+
+	_, err := AutoCodePlugin.InstallPackage(context.Background(), pluginArchive(t, demoPluginFiles("", m)))
+	assert.NotNil(t, err)
+	assert.NoDirExists(t, pluginServerPath("demo"))
+	source, _ := os.ReadFile(register)
+	assert.Equal(t, pluginRegisterSource, string(source))
+
+	// 压缩包中没有清单
+	files := demoPluginFiles("", demoPluginManifest())
+	delete(files, "plugin.json")
+	_, err = AutoCodePlugin.InstallPackage(context.Background(), pluginArchive(t, files))
+	assert.NotNil(t, err)
+}
+
+func TestInstallPackageNestedAndUninstall(t *testing.T) {
+	register := setupPluginInstallTest(t)
+	ctx := context.Background()
+
+	// 插件位于压缩包内唯一的顶层目录中
+	result, err := AutoCodePlugin.InstallPackage(ctx, pluginArchive(t, demoPluginFiles("demo-v1.0.0/", demoPluginManifest())))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "demo", result.Manifest.Name)
+	assert.FileExists(t, filepath.Join(pluginServerPath("demo"), "plugin.go"))
+	assert.FileExists(t, filepath.Join(pluginWebPath("demo"), "view", "index.vue"))
+	source, _ := os.ReadFile(register)
+	assert.Contains(t, string(source), "demo.Plugin")
+	assert.True(t, global.GVA_DB.Migrator().HasTable("demo_items"))
+	assert.Equal(t, int64(1), countRows(t, &system.SysPlugin{}))
+	assert.Equal(t, int64(1), countRows(t, &system.SysBaseMenu{}))
+	assert.Equal(t, int64(1), countRows(t, &system.SysApi{}))
+
+	// 重复安装被拒绝
+	_, err = AutoCodePlugin.InstallPackage(ctx, pluginArchive(t, demoPluginFiles("", demoPluginManifest())))
+	assert.NotNil(t, err)
+
+	// 预览卸载只列出操作 不做任何修改
+	assert.Nil(t, global.GVA_DB.Create(&gormadapter.CasbinRule{Ptype: "p", V0: "888", V1: "/demo/list", V2: "GET"}).Error)
+	preview, err := AutoCodePlugin.Uninstall(ctx, request.PluginUninstall{Name: "demo", DryRun: true})
+	assert.Nil(t, err)
+	assert.True(t, preview.DryRun)
+	steps := strings.Join(preview.Steps, "\n")
+	for _, want := range []string{"回滚 plugin/demo 的 1 个迁移", "删除菜单 demo", "删除api GET /demo/list", "移除插件注册", "删除目录 server/plugin/demo", "删除目录 web/plugin/demo"} {
+		assert.Contains(t, steps, want)
+	}
+	assert.DirExists(t, pluginServerPath("demo"))
+	assert.True(t, global.GVA_DB.Migrator().HasTable("demo_items"))
+	assert.Equal(t, int64(1), countRows(t, &system.SysPlugin{}))
+	assert.Equal(t, int64(1), countRows(t, &gormadapter.CasbinRule{}))
+
+	_, err = AutoCodePlugin.Uninstall(ctx, request.PluginUninstall{Name: "demo"})
+	assert.Nil(t, err)
+	assert.NoDirExists(t, pluginServerPath("demo"))
+	assert.NoDirExists(t, pluginWebPath("demo"))
+	assert.False(t, global.GVA_DB.Migrator().HasTable("demo_items"))
+	source, _ = os.ReadFile(register)
+	assert.NotContains(t, string(source), "demo")
+	for _, model := range []interface{}{&system.SysPlugin{}, &system.SysBaseMenu{}, &system.SysApi{}, &gormadapter.CasbinRule{}, &system.SysSchemaMigration{}} {
+		assert.Equal(t, int64(0), countRows(t, model))
+	}
+}
+
+func TestInstallPackageRollback(t *testing.T) {
+	register := setupPluginInstallTest(t)
+	// 第一个迁移成功后第二个迁移失败 已复制的代码、注册和已执行的迁移都需要撤销
+	files := demoPluginFiles("", demoPluginManifest())
+	files["server/plugin/demo/migrations/0002_seed.up.sql"] = "INSERT INTO missing_table VALUES (1);"
+
+	_, err := AutoCodePlugin.InstallPackage(context.Background(), pluginArchive(t, files))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "plugin/demo/0002_seed")
+	}
+	assert.NoDirExists(t, pluginServerPath("demo"))
+	assert.NoDirExists(t, pluginWebPath("demo"))
+	source, _ := os.ReadFile(register)
+	assert.NotContains(t, string(source), "demo")
+	assert.False(t, global.GVA_DB.Migrator().HasTable("demo_items"))
+	for _, model := range []interface{}{&system.SysPlugin{}, &system.SysBaseMenu{}, &system.SysApi{}, &system.SysSchemaMigration{}} {
+		assert.Equal(t, int64(0), countRows(t, model))
+	}
+}
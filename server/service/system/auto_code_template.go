@@ -1,6 +1,7 @@
 package system
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,12 +12,16 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
 	utilsAst "github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
@@ -62,7 +67,7 @@ func (s *autoCodeTemplate) Create(ctx context.Context, info request.AutoCode) er
 	if err != nil {
 		return errors.Wrap(err, "查询包失败!")
 	}
-	err = s.checkPackage(info.Package, autoPkg.Template)
+	err = s.checkPackage(info.Package, AutoCodePackage.baseTemplate(autoPkg.Template))
 	if err != nil {
 		return err
 	}
@@ -86,6 +91,14 @@ func (s *autoCodeTemplate) Create(ctx context.Context, info request.AutoCode) er
 		}
 	}
 
+	// 生成建表迁移文件 由 server migrate up 执行
+	if info.Migration && !info.OnlyTemplate {
+		_, err = s.writeMigration(info, AutoCodePackage.baseTemplate(autoPkg.Template), "create_"+info.TableName, autoCodeCreateTable)
+		if err != nil {
+			return err
+		}
+	}
+
 	// 自动创建api
 	if info.AutoCreateApiToSql && !info.OnlyTemplate {
 		apis := info.Apis()
@@ -119,7 +132,7 @@ func (s *autoCodeTemplate) Create(ctx context.Context, info request.AutoCode) er
 		if err == nil {
 			id = entity.ID
 		} else {
-			entity = info.Menu(autoPkg.Template)
+			entity = info.Menu(AutoCodePackage.baseTemplate(autoPkg.Template))
 			if info.AutoCreateBtnAuth && !info.OnlyTemplate {
 				entity.MenuBtn = []model.SysBaseMenuBtn{
 					{SysBaseMenuID: entity.ID, Name: "add", Desc: "新增"},
@@ -150,19 +163,12 @@ func (s *autoCodeTemplate) Create(ctx context.Context, info request.AutoCode) er
 		dbName := info.BusinessDB
 		name := info.Package + "_" + info.StructName
 		tableName := info.TableName
-		fieldsMap := make(map[string]string, len(info.Fields))
-		for _, field := range info.Fields {
-			if field.Excel {
-				fieldsMap[field.ColumnName] = field.FieldDesc
-			}
-		}
-		templateInfo, _ := json.Marshal(fieldsMap)
 		sysExportTemplate := model.SysExportTemplate{
 			DBName:       dbName,
 			Name:         name,
 			TableName:    tableName,
 			TemplateID:   name,
-			TemplateInfo: string(templateInfo),
+			TemplateInfo: exportTemplateInfo(info),
 		}
 		err = SysExportTemplateServiceApp.CreateSysExportTemplate(&sysExportTemplate)
 		if err != nil {
@@ -217,25 +223,198 @@ func (s *autoCodeTemplate) Preview(ctx context.Context, info request.AutoCode) (
 	return preview, nil
 }
 
-func (s *autoCodeTemplate) generate(ctx context.Context, info request.AutoCode, entity model.SysAutoCodePackage) (map[string]strings.Builder, map[string]string, map[string]utilsAst.Ast, error) {
-	templates, asts, _, err := AutoCodePackage.templates(ctx, entity, info, false)
+// Update 修改已生成结构的字段
+// 对比生成记录中的字段, 把模型和查询结构体的字段变化合并到现有代码中, 不覆盖手写代码;
+// 其他受影响的文件只列出由用户参照新模板调整, 并生成字段变更的迁移SQL
+func (s *autoCodeTemplate) Update(ctx context.Context, info request.AutoCode, dryRun bool) (result response.AutoCodeUpdate, err error) {
+	var autoPkg model.SysAutoCodePackage
+	err = global.GVA_DB.WithContext(ctx).Where("package_name = ?", info.Package).First(&autoPkg).Error
 	if err != nil {
-		return nil, nil, nil, err
+		return result, errors.Wrap(err, "查询包失败!")
+	}
+	if err = s.checkPackage(info.Package, AutoCodePackage.baseTemplate(autoPkg.Template)); err != nil {
+		return result, err
+	}
+	var history model.SysAutoCodeHistory
+	err = global.GVA_DB.WithContext(ctx).Where("package = ? AND struct_name = ? AND business_db = ? AND flag = ?", info.Package, info.StructName, info.BusinessDB, 0).
+		Order("id desc").First(&history).Error
+	if err != nil {
+		return result, errors.New("未找到该结构的生成记录,请先创建!")
+	}
+	var old request.AutoCode
+	if err = json.Unmarshal([]byte(history.Request), &old); err != nil {
+		return result, errors.Wrap(err, "解析生成记录失败!")
+	}
+	if err = old.Pretreatment(); err != nil {
+		return result, err
+	}
+	if old.TableName != info.TableName || old.Abbreviation != info.Abbreviation || old.PackageName != info.PackageName ||
+		old.HumpPackageName != info.HumpPackageName || old.GvaModel != info.GvaModel {
+		return result, errors.New("不支持修改表名、简称、文件名称和基础模型,请回滚后重新创建!")
+	}
+	old.IsAdd, info.IsAdd = false, false
+
+	oldFields := make(map[string]*request.AutoCodeField, len(old.Fields))
+	for _, field := range old.Fields {
+		oldFields[field.FieldName] = field
+	}
+	kept := make(map[string]bool, len(info.Fields))
+	for _, field := range info.Fields {
+		o, ok := oldFields[field.FieldName]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, field.FieldName)
+		case !reflect.DeepEqual(*o, *field):
+			result.Changed = append(result.Changed, field.FieldName)
+		}
+		kept[field.FieldName] = true
+	}
+	for _, field := range old.Fields {
+		if !kept[field.FieldName] {
+			result.Removed = append(result.Removed, field.FieldName)
+		}
 	}
-	code := make(map[string]strings.Builder)
+	if len(result.Added)+len(result.Changed)+len(result.Removed) == 0 {
+		return result, errors.New("字段没有变化!")
+	}
+
+	templates, _, _, err := AutoCodePackage.templates(ctx, autoPkg, info, false)
+	if err != nil {
+		return result, err
+	}
+	funcs, err := AutoCodePackage.funcMap(autoPkg.Template)
+	if err != nil {
+		return result, err
+	}
+	before, err := s.render(old, templates, funcs)
+	if err != nil {
+		return result, err
+	}
+	after, err := s.render(info, templates, funcs)
+	if err != nil {
+		return result, err
+	}
+	if err = AutoCodePackage.checkRules(autoPkg.Template, info, after); err != nil {
+		return result, err
+	}
+	writes := make(map[string][]byte)
 	for key, create := range templates {
-		var files *template.Template
-		files, err = template.New(filepath.Base(key)).Funcs(autocode.GetTemplateFuncMap()).ParseFiles(key)
+		rel, _ := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, create)
+		oldCode, newCode := before[create], after[create]
+		if oldCode.String() == newCode.String() {
+			continue
+		}
+		// 只有模型和查询结构体按字段合并 其余文件包含业务逻辑 交由用户处理
+		if !strings.Contains(filepath.ToSlash(key), "/server/model/") {
+			result.Manual = append(result.Manual, rel)
+			continue
+		}
+		current, err := os.ReadFile(create)
 		if err != nil {
-			return nil, nil, nil, errors.Wrapf(err, "[filpath:%s]读取模版文件失败!", key)
+			result.Manual = append(result.Manual, rel)
+			continue
 		}
-		var builder strings.Builder
-		err = files.Execute(&builder, info)
+		merged, conflicts, err := utilsAst.MergeStructFields(current, []byte(oldCode.String()), []byte(newCode.String()))
 		if err != nil {
-			return nil, nil, nil, errors.Wrapf(err, "[filpath:%s]生成文件失败!", create)
+			return result, errors.Wrapf(err, "[filepath:%s]合并失败!", rel)
 		}
-		code[create] = builder
+		for _, conflict := range conflicts {
+			result.Conflicts = append(result.Conflicts, rel+": "+conflict)
+		}
+		if !bytes.Equal(merged, current) {
+			writes[create] = merged
+			result.Files = append(result.Files, rel)
+		}
+	}
+	sort.Strings(result.Files)
+	sort.Strings(result.Manual)
+
+	up, down := autoCodeAlterTable(autoCodeDB(info), old, info)
+	result.MigrationUp, result.MigrationDown = strings.Join(up, "\n"), strings.Join(down, "\n")
+	if dryRun {
+		return result, nil
+	}
+
+	for create, code := range writes {
+		if err = os.WriteFile(create, code, 0666); err != nil {
+			return result, errors.Wrapf(err, "[filepath:%s]写入文件失败!", create)
+		}
+	}
+	if len(up) > 0 {
+		result.MigrationFiles, err = s.writeMigration(info, AutoCodePackage.baseTemplate(autoPkg.Template), "update_"+info.TableName, func(*gorm.DB, request.AutoCode) ([]string, []string) {
+			return up, down
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+	err = global.GVA_DB.WithContext(ctx).Model(&history).Updates(map[string]interface{}{
+		"request":     info.History().Request,
+		"description": info.Description,
+	}).Error
+	if err != nil {
+		return result, errors.Wrap(err, "更新生成记录失败!")
+	}
+	if history.ExportTemplateID != 0 {
+		err = global.GVA_DB.WithContext(ctx).Model(&model.SysExportTemplate{}).Where("id = ?", history.ExportTemplateID).
+			Update("template_info", exportTemplateInfo(info)).Error
+		if err != nil {
+			return result, errors.Wrap(err, "更新导出模板失败!")
+		}
+	}
+	return result, nil
+}
+
+// writeMigration 把迁移SQL写入模块的迁移目录 业务库的迁移位于以库名命名的子目录
+func (s *autoCodeTemplate) writeMigration(info request.AutoCode, template, name string, build func(db *gorm.DB, info request.AutoCode) (up, down []string)) ([]string, error) {
+	up, down := build(autoCodeDB(info), info)
+	dir := filepath.Join(MigrationDir(info.Package, template == "plugin"), info.BusinessDB)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "[filepath:%s]创建文件夹失败!", dir)
+	}
+	name = time.Now().Format("20060102150405") + "_" + name
+	files := make([]string, 0, 2)
+	for suffix, sql := range map[string][]string{".up.sql": up, ".down.sql": down} {
+		create := filepath.Join(dir, name+suffix)
+		if err := os.WriteFile(create, []byte(strings.Join(sql, "\n")+"\n"), 0666); err != nil {
+			return nil, errors.Wrapf(err, "[filepath:%s]写入文件失败!", create)
+		}
+		rel, _ := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, create)
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// exportTemplateInfo 导入/导出字段 列名对应中文名
+func exportTemplateInfo(info request.AutoCode) string {
+	fieldsMap := make(map[string]string, len(info.Fields))
+	for _, field := range info.Fields {
+		if field.Excel {
+			fieldsMap[field.ColumnName] = field.FieldDesc
+		}
+	}
+	templateInfo, _ := json.Marshal(fieldsMap)
+	return string(templateInfo)
+}
+
+func (s *autoCodeTemplate) generate(ctx context.Context, info request.AutoCode, entity model.SysAutoCodePackage) (map[string]strings.Builder, map[string]string, map[string]utilsAst.Ast, error) {
+	templates, asts, _, err := AutoCodePackage.templates(ctx, entity, info, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	funcs, err := AutoCodePackage.funcMap(entity.Template)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	code, err := s.render(info, templates, funcs)
+	if err != nil {
+		return nil, nil, nil, err
 	} // 生成文件
+	err = AutoCodePackage.checkRules(entity.Template, info, code)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	injections := make(map[string]utilsAst.Ast, len(asts))
 	for key, value := range asts {
 		keys := strings.Split(key, "=>")
@@ -248,7 +427,7 @@ func (s *autoCodeTemplate) generate(ctx context.Context, info request.AutoCode,
 					continue
 				}
 			}
-			if !info.AutoMigrate {
+			if !info.AutoMigrate || info.Migration {
 				if keys[1] == utilsAst.TypePackageInitializeGorm || keys[1] == utilsAst.TypePluginInitializeGorm {
 					continue
 				}
@@ -271,13 +450,39 @@ func (s *autoCodeTemplate) generate(ctx context.Context, info request.AutoCode,
 	return code, templates, injections, nil
 }
 
+// render 执行模板 返回生成路径与代码
+func (s *autoCodeTemplate) render(info request.AutoCode, templates map[string]string, funcs template.FuncMap) (map[string]strings.Builder, error) {
+	code := make(map[string]strings.Builder, len(templates))
+	for key, create := range templates {
+		builder, err := renderTemplate(key, create, funcs, info)
+		if err != nil {
+			return nil, err
+		}
+		code[create] = builder
+	}
+	return code, nil
+}
+
+// renderTemplate 执行单个模板
+func renderTemplate(key, create string, funcs template.FuncMap, data interface{}) (builder strings.Builder, err error) {
+	files, err := template.New(filepath.Base(key)).Funcs(funcs).ParseFiles(key)
+	if err != nil {
+		return builder, errors.Wrapf(err, "[filpath:%s]读取模版文件失败!", key)
+	}
+	err = files.Execute(&builder, data)
+	if err != nil {
+		return builder, errors.Wrapf(err, "[filpath:%s]生成文件失败!", create)
+	}
+	return builder, nil
+}
+
 func (s *autoCodeTemplate) AddFunc(info request.AutoFunc) error {
 	autoPkg := model.SysAutoCodePackage{}
 	err := global.GVA_DB.First(&autoPkg, "package_name = ?", info.Package).Error
 	if err != nil {
 		return err
 	}
-	if autoPkg.Template != "package" {
+	if AutoCodePackage.baseTemplate(autoPkg.Template) != "package" {
 		info.IsPlugin = true
 	}
 	err = s.addTemplateToFile("api.go", info)
@@ -301,7 +506,7 @@ func (s *autoCodeTemplate) GetApiAndServer(info request.AutoFunc) (map[string]st
 	if err != nil {
 		return nil, err
 	}
-	if autoPkg.Template != "package" {
+	if AutoCodePackage.baseTemplate(autoPkg.Template) != "package" {
 		info.IsPlugin = true
 	}
 
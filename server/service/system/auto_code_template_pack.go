@@ -0,0 +1,273 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/autocode"
+	"github.com/pkg/errors"
+)
+
+// templateDir 模板目录
+func (s *autoCodePackage) templateDir(template string) string {
+	return filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "resource", template)
+}
+
+// pack 读取自定义模板包 内置模板返回nil
+func (s *autoCodePackage) pack(template string) (*autocode.TemplatePack, error) {
+	if template == "" || template == "package" || template == "plugin" {
+		return nil, nil
+	}
+	return autocode.LoadTemplatePack(s.templateDir(template))
+}
+
+// baseTemplate 模板包继承的内置模板 决定包结构的校验、菜单路径等
+func (s *autoCodePackage) baseTemplate(template string) string {
+	pack, err := s.pack(template)
+	if err != nil || pack == nil {
+		return template
+	}
+	return pack.Extends
+}
+
+// funcMap 模板使用的函数
+func (s *autoCodePackage) funcMap(template string) (template.FuncMap, error) {
+	pack, err := s.pack(template)
+	if err != nil {
+		return nil, err
+	}
+	return pack.FuncMap()
+}
+
+func templatePackData(info request.AutoCode) autocode.TemplatePackData {
+	return autocode.TemplatePackData{
+		AutoCode: info,
+		Server:   filepath.ToSlash(global.GVA_CONFIG.AutoCode.Server),
+		Web:      filepath.ToSlash(global.GVA_CONFIG.AutoCode.WebRoot()),
+	}
+}
+
+// packTemplates 在继承的内置模板基础上应用模板包的排除、替换、新增文件和注入点
+func (s *autoCodePackage) packTemplates(ctx context.Context, pack *autocode.TemplatePack, entity model.SysAutoCodePackage, info request.AutoCode, isPackage bool) (code map[string]string, asts map[string]ast.Ast, creates map[string]string, err error) {
+	base := entity
+	base.Template = pack.Extends
+	code, asts, creates, err = s.templates(ctx, base, info, isPackage)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	baseDir := s.templateDir(pack.Extends)
+	for _, templates := range []map[string]string{code, creates} {
+		keys := make([]string, 0, len(templates))
+		for key := range templates {
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			rel, _ := filepath.Rel(baseDir, key)
+			rel = filepath.ToSlash(rel)
+			if pack.Excluded(rel) {
+				delete(templates, key)
+				continue
+			}
+			if override, ok := pack.Override(rel); ok {
+				templates[pack.TemplatePath(override.Template)] = templates[key]
+				delete(templates, key)
+			}
+		}
+	}
+
+	data := templatePackData(info)
+	root := global.GVA_CONFIG.AutoCode.Root
+	for _, file := range pack.Files {
+		if file.Override != "" {
+			continue
+		}
+		if strings.HasPrefix(file.Template, "server/") && !info.GenerateServer && !isPackage {
+			continue
+		}
+		if strings.HasPrefix(file.Template, "web/") && !info.GenerateWeb && !isPackage {
+			continue
+		}
+		var output string
+		output, err = pack.Render(file.Output, data)
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "[template:%s]生成路径解析失败!", file.Template)
+		}
+		create, ok := packTarget(root, output)
+		if !ok {
+			return nil, nil, nil, errors.Errorf("[template:%s]生成路径[%s]不在项目目录下!", file.Template, output)
+		}
+		if file.Package {
+			creates[pack.TemplatePath(file.Template)] = create
+		} else {
+			code[pack.TemplatePath(file.Template)] = create
+		}
+	}
+
+	// 同一文件的注入合并为一个 避免先后注入时互相覆盖
+	injections := make(map[string]*ast.StructInjection)
+	for i, declared := range pack.Injections {
+		var values [5]string
+		for j, value := range []string{declared.Path, declared.Import, declared.Struct, declared.FieldName, declared.FieldType} {
+			values[j], err = pack.Render(value, data)
+			if err != nil {
+				return nil, nil, nil, errors.Wrapf(err, "[injections[%d]]解析失败!", i)
+			}
+		}
+		file, ok := packTarget(root, values[0])
+		if !ok {
+			return nil, nil, nil, errors.Errorf("[injections[%d]]注入路径[%s]不在项目目录下!", i, values[0])
+		}
+		injection, ok := injections[file]
+		if !ok {
+			injection = &ast.StructInjection{Type: ast.TypeStructInjection, Path: file}
+			injections[file] = injection
+		}
+		if values[1] != "" {
+			importPath := values[1]
+			if _, e := strconv.Unquote(importPath); e != nil {
+				importPath = strconv.Quote(importPath)
+			}
+			injection.Imports = append(injection.Imports, importPath)
+		}
+		injection.Fields = append(injection.Fields, ast.StructField{Struct: values[2], Name: values[3], Type: values[4]})
+	}
+	for file, injection := range injections {
+		rel, _ := filepath.Rel(root, file)
+		// 历史记录按类型保存注入点 模板包可以声明多个 以文件区分
+		asts[file+"=>"+ast.TypeStructInjection+":"+filepath.ToSlash(rel)] = injection
+	}
+	return code, asts, creates, nil
+}
+
+// packTarget 模板包声明的生成或注入路径 相对于AutoCode.Root, 不允许指向项目目录之外
+func packTarget(root, path string) (string, bool) {
+	target := filepath.Join(root, filepath.FromSlash(path))
+	rel, err := filepath.Rel(root, target)
+	return target, err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkRules 检查生成的代码是否违反模板包的分层约束
+func (s *autoCodePackage) checkRules(template string, info request.AutoCode, code map[string]strings.Builder) error {
+	pack, err := s.pack(template)
+	if err != nil || pack == nil || len(pack.Rules) == 0 {
+		return err
+	}
+	files := make(map[string]string, len(code))
+	for key, builder := range code {
+		rel, _ := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, key)
+		files[filepath.ToSlash(rel)] = builder.String()
+	}
+	violations := pack.CheckRules(templatePackData(info), files)
+	if len(violations) > 0 {
+		return errors.Errorf("违反模板包[%s]的约束:\n%s", pack.Name, strings.Join(violations, "\n"))
+	}
+	return nil
+}
+
+// PreviewPack 使用示例结构渲染模板包, 返回生成的文件和清单、模板、约束中的问题
+func (s *autoCodePackage) PreviewPack(ctx context.Context, req request.TemplatePackPreview) (result response.TemplatePackPreview, err error) {
+	pack, err := s.pack(req.Template)
+	if err != nil {
+		return result, err
+	}
+	if pack == nil {
+		return result, errors.Errorf("模板[%s]没有%s, 不是自定义模板包!", req.Template, autocode.TemplatePackManifest)
+	}
+	result.Name, result.Description, result.Extends = pack.Name, pack.Description, pack.Extends
+	result.Problems = pack.Validate(s.templateDir(pack.Extends))
+	if len(result.Problems) > 0 {
+		return result, nil
+	}
+
+	info := templatePackSample()
+	if req.AutoCode != nil {
+		info = *req.AutoCode
+	}
+	info.Package = req.Package
+	if info.Package == "" {
+		info.Package = "example"
+	}
+	info.PackageT = utils.FirstUpper(info.Package)
+	if err = info.Pretreatment(); err != nil {
+		return result, err
+	}
+	entity := model.SysAutoCodePackage{Template: req.Template, PackageName: info.Package}
+	code, asts, creates, err := s.templates(ctx, entity, info, false)
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+		return result, nil
+	}
+	for key, create := range creates {
+		code[key] = create
+	}
+	funcs, err := pack.FuncMap()
+	if err != nil {
+		return result, err
+	}
+	rendered := make(map[string]strings.Builder, len(code))
+	for key, create := range code {
+		builder, e := renderTemplate(key, create, funcs, info)
+		if e != nil {
+			result.Problems = append(result.Problems, e.Error())
+			continue
+		}
+		rendered[create] = builder
+	}
+	result.Files = make(map[string]string, len(rendered))
+	for create, builder := range rendered {
+		rel, _ := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, create)
+		rel = filepath.ToSlash(rel)
+		if filepath.Ext(create) == ".go" {
+			if _, e := format.Source([]byte(builder.String())); e != nil {
+				result.Problems = append(result.Problems, fmt.Sprintf("%s: 生成的代码无法解析 %v", rel, e))
+			}
+		}
+		result.Files[rel] = builder.String()
+	}
+	result.Problems = append(result.Problems, pack.CheckRules(templatePackData(info), result.Files)...)
+	for _, value := range asts {
+		injection, ok := value.(*ast.StructInjection)
+		if !ok {
+			continue
+		}
+		rel, _ := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, injection.Path)
+		for _, field := range injection.Fields {
+			result.Injections = append(result.Injections, fmt.Sprintf("%s: %s{%s}", filepath.ToSlash(rel), field.Struct, strings.TrimSpace(field.Name+" "+field.Type)))
+		}
+	}
+	sort.Strings(result.Problems)
+	sort.Strings(result.Injections)
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
+
+// templatePackSample 预览模板包使用的示例结构
+func templatePackSample() request.AutoCode {
+	return request.AutoCode{
+		StructName:      "PackPreview",
+		TableName:       "pack_preview",
+		PackageName:     "packPreview",
+		HumpPackageName: "pack_preview",
+		Abbreviation:    "packPreview",
+		Description:     "模板包预览",
+		GvaModel:        true,
+		GenerateServer:  true,
+		GenerateWeb:     true,
+		Fields: []*request.AutoCodeField{
+			{FieldName: "Name", FieldDesc: "名称", FieldType: "string", FieldJson: "name", ColumnName: "name", FieldSearchType: "LIKE", Form: true, Table: true, Desc: true, Require: true, Clearable: true},
+			{FieldName: "Sort", FieldDesc: "排序", FieldType: "int", FieldJson: "sort", ColumnName: "sort", Form: true, Table: true, Desc: true, Clearable: true, Sort: true},
+		},
+	}
+}
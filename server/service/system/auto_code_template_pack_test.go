@@ -0,0 +1,55 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/autocode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackTemplatesTargetContainment(t *testing.T) {
+	root := t.TempDir()
+	oldAutoCode := global.GVA_CONFIG.AutoCode
+	global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server = root, "server"
+	t.Cleanup(func() { global.GVA_CONFIG.AutoCode = oldAutoCode })
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "server", "resource", "package"), 0o755))
+
+	info := request.AutoCode{Package: "example", StructName: "Book"}
+	injection := autocode.TemplatePackInjection{Path: "{{.Server}}/service/{{.Package}}/enter.go", Struct: "ServiceGroup", FieldType: "{{.StructName}}Service"}
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "项目目录内", path: injection.Path},
+		{name: "上级目录", path: "../outside/enter.go", wantErr: true},
+		{name: "渲染后跳出项目目录", path: "{{.Server}}/../../{{.Package}}/enter.go", wantErr: true},
+		{name: "名称以..开头的目录", path: "..{{.Package}}/enter.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			declared := injection
+			declared.Path = tt.path
+			pack := &autocode.TemplatePack{Name: "custom", Extends: "package", Injections: []autocode.TemplatePackInjection{declared}}
+			_, asts, _, err := AutoCodePackage.packTemplates(context.Background(), pack, model.SysAutoCodePackage{Template: "custom"}, info, false)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			if assert.Nil(t, err) {
+				assert.Len(t, asts, 1)
+			}
+		})
+	}
+
+	// 生成文件路径同样不能跳出项目目录
+	pack := &autocode.TemplatePack{Name: "custom", Extends: "package", Files: []autocode.TemplatePackFile{{Template: "server/repo.go.tpl", Output: "../{{.Package}}/repo.go"}}}
+	_, _, _, err := AutoCodePackage.packTemplates(context.Background(), pack, model.SysAutoCodePackage{Template: "custom"}, info, true)
+	assert.NotNil(t, err)
+}
@@ -15,8 +15,21 @@ type ServiceGroup struct {
 	OperationRecordService
 	DictionaryDetailService
 	AuthorityBtnService
+	AuthorityFieldService
 	SysExportTemplateService
 	SysParamsService
+	OidcService
+	LdapService
+	ApiTokenService
+	PasswordResetService
+	DataScopeService
+	DepartmentService
+	AuthorityGrantService
+	RbacService
+	TenantService
+	ApprovalService
+	NotificationService
+	WebhookService
 	AutoCodePlugin   autoCodePlugin
 	AutoCodePackage  autoCodePackage
 	AutoCodeHistory  autoCodeHistory
@@ -2,11 +2,13 @@ package system
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
 )
 
@@ -71,6 +73,40 @@ func (jwtService *JwtService) SetRedisJWT(jwt string, userName string) (err erro
 	return err
 }
 
+//@function: RevokeUserTokens
+//@description: 吊销用户在指定时间之前签发的全部jwt 用于重置密码等场景
+//@param: uuid string, userName string, at time.Time
+//@return: err error
+
+func (jwtService *JwtService) RevokeUserTokens(uuid string, userName string, at time.Time) (err error) {
+	dr, err := utils.ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime)
+	if err != nil {
+		return err
+	}
+	// 在此之前签发的jwt最迟也会在 at+过期时间 后自然失效
+	if ttl := time.Until(at.Add(dr)); ttl > 0 {
+		global.BlackCache.Set("jwt:revoked:"+uuid, at, ttl)
+	}
+	if global.GVA_CONFIG.System.UseMultipoint && global.GVA_REDIS != nil {
+		err = global.GVA_REDIS.Del(context.Background(), userName).Err()
+	}
+	return err
+}
+
+//@function: IsRevoked
+//@description: 判断jwt是否签发于用户会话被吊销之前
+//@param: claims *systemReq.CustomClaims
+//@return: bool
+
+func (jwtService *JwtService) IsRevoked(claims *systemReq.CustomClaims) bool {
+	v, ok := global.BlackCache.Get("jwt:revoked:" + claims.UUID.String())
+	if !ok {
+		return false
+	}
+	// 签发时间只精确到秒
+	return claims.IssuedAt == nil || claims.IssuedAt.Time.Before(v.(time.Time).Truncate(time.Second))
+}
+
 func LoadAll() {
 	var data []string
 	err := global.GVA_DB.Model(&system.JwtBlacklist{}).Select("jwt").Find(&data).Error
@@ -81,4 +117,47 @@ func LoadAll() {
 	for i := 0; i < len(data); i++ {
 		global.BlackCache.SetDefault(data[i], struct{}{})
 	} // jwt黑名单 加入 BlackCache 中
+	loadRevokedSessions()
+}
+
+// loadRevokedSessions 重启后恢复仍在jwt有效期内的会话吊销记录
+func loadRevokedSessions() {
+	dr, err := utils.ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime)
+	if err != nil {
+		return
+	}
+	var revoked []struct {
+		UUID   string
+		UsedAt time.Time
+	}
+	err = global.GVA_DB.Model(&system.SysPasswordReset{}).
+		Select("sys_users.uuid AS uuid, MAX(sys_password_resets.used_at) AS used_at").
+		Joins("JOIN sys_users ON sys_users.id = sys_password_resets.user_id").
+		Where("sys_password_resets.used_at > ?", time.Now().Add(-dr)).
+		Group("sys_users.uuid").Scan(&revoked).Error
+	if err != nil {
+		global.GVA_LOG.Error("加载会话吊销记录失败!", zap.Error(err))
+		return
+	}
+	// 收回限时授权或委托的角色时同样吊销了会话
+	var ended []struct {
+		UUID   string
+		UsedAt time.Time
+	}
+	err = global.GVA_DB.Model(&system.SysUserAuthorityGrant{}).
+		Select("sys_users.uuid AS uuid, MAX(sys_user_authority_grants.ended_at) AS used_at").
+		Joins("JOIN sys_users ON sys_users.id = sys_user_authority_grants.user_id").
+		Where("sys_user_authority_grants.ended_at > ?", time.Now().Add(-dr)).
+		Group("sys_users.uuid").Scan(&ended).Error
+	if err != nil {
+		global.GVA_LOG.Error("加载会话吊销记录失败!", zap.Error(err))
+	}
+	revoked = append(revoked, ended...)
+	for i := range revoked {
+		// 同一用户保留最晚的吊销时间
+		if v, ok := global.BlackCache.Get("jwt:revoked:" + revoked[i].UUID); ok && v.(time.Time).After(revoked[i].UsedAt) {
+			continue
+		}
+		global.BlackCache.Set("jwt:revoked:"+revoked[i].UUID, revoked[i].UsedAt, time.Until(revoked[i].UsedAt.Add(dr)))
+	}
 }
@@ -0,0 +1,58 @@
+package system
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+	"github.com/glebarez/sqlite"
+	"github.com/songzhibin97/gkit/cache/local_cache"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 使用临时 sqlite 文件替换 global.GVA_DB 并迁移 models 测试结束后恢复
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(0)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	oldDB, oldLog := global.GVA_DB, global.GVA_LOG
+	global.GVA_DB = db
+	if global.GVA_LOG == nil {
+		global.GVA_LOG = zap.NewNop()
+	}
+	t.Cleanup(func() {
+		global.GVA_DB, global.GVA_LOG = oldDB, oldLog
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// newTestCache 替换 global.BlackCache 为空的本地缓存 测试结束后恢复
+func newTestCache(t *testing.T) {
+	old := global.BlackCache
+	global.BlackCache = local_cache.NewCache()
+	t.Cleanup(func() { global.BlackCache = old })
+}
+
+// recordEvents 记录测试期间全局事件总线上发布的 topic 事件
+func recordEvents[T any](t *testing.T, topic event.Topic[T]) *[]T {
+	var events []T
+	unsubscribe := event.Subscribe(global.GVA_EVENT, topic, func(_ context.Context, payload T) error {
+		events = append(events, payload)
+		return nil
+	})
+	t.Cleanup(unsubscribe)
+	return &events
+}
@@ -0,0 +1,138 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ApiTokenPrefix 个人访问令牌前缀 便于在日志和密钥扫描中识别
+const ApiTokenPrefix = "gva_"
+
+var ErrApiTokenInvalid = errors.New("访问令牌无效、已过期或已吊销")
+
+type ApiTokenService struct{}
+
+var ApiTokenServiceApp = new(ApiTokenService)
+
+//@function: CreateApiToken
+//@description: 为用户创建个人访问令牌 明文只返回一次 库中仅保存哈希
+//@param: ctx context.Context, userId uint, req systemReq.CreateApiToken
+//@return: token string, apiToken system.SysApiToken, err error
+
+func (apiTokenService *ApiTokenService) CreateApiToken(ctx context.Context, userId uint, req systemReq.CreateApiToken) (token string, apiToken system.SysApiToken, err error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return "", apiToken, errors.New("令牌名称不能为空")
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return "", apiToken, errors.New("过期时间不能早于当前时间")
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if _, p, ok := strings.Cut(s, " "); !strings.HasPrefix(s, "/") && (!ok || !strings.HasPrefix(strings.TrimSpace(p), "/")) {
+			return "", apiToken, errors.New("令牌范围格式错误: " + s + " 应为 /path 或 METHOD /path")
+		}
+		scopes = append(scopes, s)
+	}
+	// 按请求上下文中的租户查询 不能为其他租户的用户创建令牌
+	if err = global.GVA_DB.WithContext(ctx).Where("id = ?", userId).First(&system.SysUser{}).Error; err != nil {
+		return "", apiToken, errors.New("用户不存在")
+	}
+	token = ApiTokenPrefix + utils.SecureRandomString(32)
+	apiToken = system.SysApiToken{
+		UserId:    userId,
+		Name:      req.Name,
+		Prefix:    token[:len(ApiTokenPrefix)+6],
+		TokenHash: utils.SHA256V([]byte(token)),
+		Scopes:    datatypes.NewJSONSlice(scopes),
+		ExpiresAt: req.ExpiresAt,
+	}
+	err = global.GVA_DB.Create(&apiToken).Error
+	return token, apiToken, err
+}
+
+//@function: RevokeApiToken
+//@description: 吊销令牌 userId 不为0时只能吊销自己的令牌
+//@param: ctx context.Context, id uint, userId uint
+//@return: err error
+
+func (apiTokenService *ApiTokenService) RevokeApiToken(ctx context.Context, id uint, userId uint) (err error) {
+	db := global.GVA_DB.Model(&system.SysApiToken{}).Scopes(scopeTenantUsers(ctx, "user_id")).Where("id = ? AND revoked_at IS NULL", id)
+	if userId != 0 {
+		db = db.Where("user_id = ?", userId)
+	}
+	res := db.Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("令牌不存在或已吊销")
+	}
+	return nil
+}
+
+//@function: GetApiTokenList
+//@description: 分页获取令牌列表
+//@param: ctx context.Context, info systemReq.ApiTokenSearch
+//@return: list []system.SysApiToken, total int64, err error
+
+func (apiTokenService *ApiTokenService) GetApiTokenList(ctx context.Context, info systemReq.ApiTokenSearch) (list []system.SysApiToken, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysApiToken{}).Scopes(scopeTenantUsers(ctx, "user_id"))
+	if info.UserId != 0 {
+		db = db.Where("user_id = ?", info.UserId)
+	}
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+//@function: Authenticate
+//@description: 校验令牌并返回令牌与所属用户 同时记录最后使用时间
+//@param: token string, ip string
+//@return: apiToken *system.SysApiToken, user *system.SysUser, err error
+
+func (apiTokenService *ApiTokenService) Authenticate(token, ip string) (apiToken *system.SysApiToken, user *system.SysUser, err error) {
+	if !strings.HasPrefix(token, ApiTokenPrefix) {
+		return nil, nil, ErrApiTokenInvalid
+	}
+	var t system.SysApiToken
+	if err = global.GVA_DB.Where("token_hash = ?", utils.SHA256V([]byte(token))).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrApiTokenInvalid
+		}
+		return nil, nil, err
+	}
+	if !t.Active() {
+		return nil, nil, ErrApiTokenInvalid
+	}
+	var u system.SysUser
+	if err = global.GVA_DB.Where("id = ?", t.UserId).First(&u).Error; err != nil {
+		return nil, nil, ErrApiTokenInvalid
+	}
+	if u.Enable != 1 {
+		return nil, nil, errors.New("令牌所属用户已被冻结")
+	}
+	// 一分钟内只记录一次 避免每个请求都写库
+	now := time.Now()
+	global.GVA_DB.Model(&system.SysApiToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", t.ID, now.Add(-time.Minute)).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+	return &t, &u, nil
+}
@@ -0,0 +1,130 @@
+package system
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupApiTokenTest(t *testing.T) (*gorm.DB, system.SysUser) {
+	db := newTestDB(t, &system.SysUser{}, &system.SysApiToken{})
+	user := system.SysUser{Username: "robot", AuthorityId: 888, Enable: 1}
+	assert.Nil(t, db.Create(&user).Error)
+	return db, user
+}
+
+func TestCreateApiToken(t *testing.T) {
+	db, user := setupApiTokenTest(t)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		userId  uint
+		req     systemReq.CreateApiToken
+		wantErr string
+	}{
+		{name: "名称为空", userId: user.ID, req: systemReq.CreateApiToken{Name: " "}, wantErr: "令牌名称不能为空"},
+		{name: "已过期", userId: user.ID, req: systemReq.CreateApiToken{Name: "ci", ExpiresAt: &past}, wantErr: "过期时间不能早于当前时间"},
+		{name: "范围格式错误", userId: user.ID, req: systemReq.CreateApiToken{Name: "ci", Scopes: []string{"GET sysParams"}}, wantErr: "令牌范围格式错误: GET sysParams 应为 /path 或 METHOD /path"},
+		{name: "用户不存在", userId: user.ID + 1, req: systemReq.CreateApiToken{Name: "ci"}, wantErr: "用户不存在"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ApiTokenServiceApp.CreateApiToken(context.Background(), tt.userId, tt.req)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	token, apiToken, err := ApiTokenServiceApp.CreateApiToken(context.Background(), user.ID, systemReq.CreateApiToken{Name: "ci", Scopes: []string{" GET /sysParams/* ", ""}})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(token, ApiTokenPrefix))
+	assert.Equal(t, token[:len(ApiTokenPrefix)+6], apiToken.Prefix)
+	assert.Equal(t, []string{"GET /sysParams/*"}, []string(apiToken.Scopes))
+
+	// 库中只保存哈希
+	var stored system.SysApiToken
+	assert.Nil(t, db.First(&stored, apiToken.ID).Error)
+	assert.Equal(t, utils.SHA256V([]byte(token)), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, token[len(ApiTokenPrefix):])
+	var plain int64
+	db.Model(&system.SysApiToken{}).Where("token_hash = ? OR prefix = ?", token, token).Count(&plain)
+	assert.Equal(t, int64(0), plain)
+
+	other, _, err := ApiTokenServiceApp.CreateApiToken(context.Background(), user.ID, systemReq.CreateApiToken{Name: "ci"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestApiTokenAuthenticate(t *testing.T) {
+	db, user := setupApiTokenTest(t)
+	token, apiToken, err := ApiTokenServiceApp.CreateApiToken(context.Background(), user.ID, systemReq.CreateApiToken{Name: "ci"})
+	assert.Nil(t, err)
+
+	got, owner, err := ApiTokenServiceApp.Authenticate(token, "10.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, apiToken.ID, got.ID)
+	assert.Equal(t, user.ID, owner.ID)
+	var stored system.SysApiToken
+	assert.Nil(t, db.First(&stored, apiToken.ID).Error)
+	assert.Equal(t, "10.0.0.1", stored.LastUsedIp)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	// 一分钟内再次使用不更新记录
+	_, _, err = ApiTokenServiceApp.Authenticate(token, "10.0.0.2")
+	assert.Nil(t, err)
+	assert.Nil(t, db.First(&stored, apiToken.ID).Error)
+	assert.Equal(t, "10.0.0.1", stored.LastUsedIp)
+
+	for _, invalid := range []string{"", "x" + token, ApiTokenPrefix + "unknown", strings.TrimPrefix(token, ApiTokenPrefix)} {
+		_, _, err = ApiTokenServiceApp.Authenticate(invalid, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrApiTokenInvalid, invalid)
+	}
+
+	// 所属用户被冻结
+	assert.Nil(t, db.Model(&user).Update("enable", 2).Error)
+	_, _, err = ApiTokenServiceApp.Authenticate(token, "10.0.0.1")
+	assert.EqualError(t, err, "令牌所属用户已被冻结")
+	assert.Nil(t, db.Model(&user).Update("enable", 1).Error)
+
+	// 已过期
+	assert.Nil(t, db.Model(&stored).Update("expires_at", time.Now().Add(-time.Second)).Error)
+	_, _, err = ApiTokenServiceApp.Authenticate(token, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrApiTokenInvalid)
+	assert.Nil(t, db.Model(&stored).Update("expires_at", time.Now().Add(time.Hour)).Error)
+	_, _, err = ApiTokenServiceApp.Authenticate(token, "10.0.0.1")
+	assert.Nil(t, err)
+
+	// 只能吊销自己的令牌 吊销后立即失效
+	assert.EqualError(t, ApiTokenServiceApp.RevokeApiToken(context.Background(), apiToken.ID, user.ID+1), "令牌不存在或已吊销")
+	assert.Nil(t, ApiTokenServiceApp.RevokeApiToken(context.Background(), apiToken.ID, user.ID))
+	assert.EqualError(t, ApiTokenServiceApp.RevokeApiToken(context.Background(), apiToken.ID, 0), "令牌不存在或已吊销")
+	_, _, err = ApiTokenServiceApp.Authenticate(token, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrApiTokenInvalid)
+}
+
+func TestApiTokenAllow(t *testing.T) {
+	token := system.SysApiToken{Scopes: []string{"GET /sysParams/*", "/user/getUserInfo", "* /report/list"}}
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/sysParams/getSysParam", true},
+		{"POST", "/sysParams/createSysParam", false},
+		{"POST", "/user/getUserInfo", true},
+		{"GET", "/user/getUserInfo/1", false},
+		{"DELETE", "/report/list", true},
+		{"GET", "/report", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, token.Allow(tt.method, tt.path), tt.method+" "+tt.path)
+	}
+	// 未限定范围时继承用户全部权限
+	assert.True(t, (&system.SysApiToken{}).Allow("DELETE", "/user/deleteUser"))
+}
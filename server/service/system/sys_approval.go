@@ -0,0 +1,604 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ApprovalService struct{}
+
+var ApprovalServiceApp = new(ApprovalService)
+
+//@function: GetActions
+//@description: 获取可配置审批流程的操作
+//@return: list []systemRes.ApprovalAction
+
+func (approvalService *ApprovalService) GetActions() (list []systemRes.ApprovalAction) {
+	for key, action := range approvalActions {
+		list = append(list, systemRes.ApprovalAction{Key: key, Name: action.Name})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+//@function: CreateFlow
+//@description: 创建审批流程 每个操作只能配置一个流程
+//@param: flow *system.SysApprovalFlow
+//@return: err error
+
+func (approvalService *ApprovalService) CreateFlow(flow *system.SysApprovalFlow) (err error) {
+	if err = approvalService.checkFlow(flow); err != nil {
+		return err
+	}
+	return global.GVA_DB.Create(flow).Error
+}
+
+//@function: UpdateFlow
+//@description: 更新审批流程 步骤整体替换, 已提交的审批单按提交时的步骤继续流转
+//@param: flow system.SysApprovalFlow
+//@return: err error
+
+func (approvalService *ApprovalService) UpdateFlow(flow system.SysApprovalFlow) (err error) {
+	if err = approvalService.checkFlow(&flow); err != nil {
+		return err
+	}
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&system.SysApprovalFlow{}).Where("id = ?", flow.ID).
+			Select("name", "action", "enable", "remark").
+			Updates(map[string]interface{}{"name": flow.Name, "action": flow.Action, "enable": flow.Enable, "remark": flow.Remark})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("审批流程不存在")
+		}
+		if err := tx.Where("flow_id = ?", flow.ID).Delete(&system.SysApprovalStep{}).Error; err != nil {
+			return err
+		}
+		for i := range flow.Steps {
+			flow.Steps[i].FlowId = flow.ID
+		}
+		return tx.Create(&flow.Steps).Error
+	})
+}
+
+//@function: DeleteFlow
+//@description: 删除审批流程 删除后对应操作不再需要审批
+//@param: id uint
+//@return: err error
+
+func (approvalService *ApprovalService) DeleteFlow(id uint) (err error) {
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("flow_id = ?", id).Delete(&system.SysApprovalStep{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&system.SysApprovalFlow{}, "id = ?", id).Error
+	})
+}
+
+//@function: GetFlow
+//@description: 获取审批流程及步骤
+//@param: id uint
+//@return: flow system.SysApprovalFlow, err error
+
+func (approvalService *ApprovalService) GetFlow(id uint) (flow system.SysApprovalFlow, err error) {
+	err = global.GVA_DB.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort")
+	}).Where("id = ?", id).First(&flow).Error
+	return
+}
+
+//@function: GetFlowList
+//@description: 分页获取审批流程列表
+//@param: info systemReq.ApprovalFlowSearch
+//@return: list []system.SysApprovalFlow, total int64, err error
+
+func (approvalService *ApprovalService) GetFlowList(info systemReq.ApprovalFlowSearch) (list []system.SysApprovalFlow, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysApprovalFlow{})
+	if info.Action != "" {
+		db = db.Where("action = ?", info.Action)
+	}
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort")
+	}).Order("id").Find(&list).Error
+	return list, total, err
+}
+
+// checkFlow 校验流程配置并补全步骤默认值
+func (approvalService *ApprovalService) checkFlow(flow *system.SysApprovalFlow) error {
+	if flow.Name == "" {
+		return errors.New("流程名称不能为空")
+	}
+	if _, ok := approvalActions[flow.Action]; !ok {
+		return errUnknownApprovalAction
+	}
+	if len(flow.Steps) == 0 {
+		return errors.New("至少需要一个审批步骤")
+	}
+	for i := range flow.Steps {
+		step := &flow.Steps[i]
+		step.ID, step.FlowId, step.Sort = 0, 0, i
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("第%d步", i+1)
+		}
+		switch step.ApproverType {
+		case system.ApproverUser, system.ApproverAuthority:
+			if len(step.ApproverIds) == 0 {
+				return fmt.Errorf("%s未指定审批人", step.Name)
+			}
+		case system.ApproverLeader:
+			step.ApproverIds = nil
+		default:
+			return fmt.Errorf("%s审批人类型错误", step.Name)
+		}
+		switch step.Mode {
+		case "":
+			step.Mode = system.ApprovalModeAny
+		case system.ApprovalModeAny, system.ApprovalModeAll:
+		default:
+			return fmt.Errorf("%s审批方式错误", step.Name)
+		}
+	}
+	var count int64
+	err := global.GVA_DB.Model(&system.SysApprovalFlow{}).Where("action = ? AND id <> ?", flow.Action, flow.ID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("该操作已配置审批流程")
+	}
+	return nil
+}
+
+//@function: Submit
+//@description: 操作配置了启用的审批流程时创建审批单代替直接执行, guarded 为false时调用方应直接执行原操作
+//@description: 各步骤的审批人在提交时确定 申请人不能审批自己的申请
+//@param: ctx context.Context, action string, payload interface{}, summary string
+//@return: req *system.SysApprovalRequest, guarded bool, err error
+
+func (approvalService *ApprovalService) Submit(ctx context.Context, action string, payload interface{}, summary string) (req *system.SysApprovalRequest, guarded bool, err error) {
+	var flow system.SysApprovalFlow
+	err = global.GVA_DB.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort")
+	}).Where("action = ? AND enable = ?", action, true).First(&flow).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	claims := utils.GetClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, true, errors.New("无法获取申请人信息")
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, true, err
+	}
+	scope, _ := utils.GetTenantFromContext(ctx)
+	req = &system.SysApprovalRequest{
+		FlowId:               flow.ID,
+		FlowName:             flow.Name,
+		Action:               action,
+		Summary:              summary,
+		Payload:              body,
+		Steps:                flow.Steps,
+		Status:               system.ApprovalPending,
+		RequesterId:          claims.BaseClaims.ID,
+		RequesterName:        claims.Username,
+		RequesterAuthorityId: claims.AuthorityId,
+		TenantAll:            scope.All,
+	}
+	req.TenantId = scope.Id
+	for i, step := range flow.Steps {
+		approvers, err := approvalService.resolveApprovers(step, req)
+		if err != nil {
+			return nil, true, err
+		}
+		if len(approvers) == 0 {
+			return nil, true, fmt.Errorf("%s没有可用的审批人", step.Name)
+		}
+		status := system.ApprovalTaskWaiting
+		if i == 0 {
+			status = system.ApprovalTaskPending
+		}
+		for _, id := range approvers {
+			req.Tasks = append(req.Tasks, system.SysApprovalTask{Step: i, StepName: step.Name, ApproverId: id, Status: status})
+		}
+	}
+	if err = global.GVA_DB.WithContext(ctx).Create(req).Error; err != nil {
+		return nil, true, err
+	}
+	approvalService.notifyApprovers(req.ID)
+	return req, true, nil
+}
+
+// resolveApprovers 解析步骤的审批人 排除申请人自己
+func (approvalService *ApprovalService) resolveApprovers(step system.SysApprovalStep, req *system.SysApprovalRequest) (ids []uint, err error) {
+	switch step.ApproverType {
+	case system.ApproverUser:
+		err = global.GVA_DB.Model(&system.SysUser{}).Where("id IN ?", []uint(step.ApproverIds)).Pluck("id", &ids).Error
+	case system.ApproverAuthority:
+		db := global.GVA_DB.Model(&system.SysUserAuthority{}).
+			Joins("JOIN sys_users ON sys_users.id = sys_user_authority.sys_user_id AND sys_users.deleted_at IS NULL").
+			Where("sys_user_authority.sys_authority_authority_id IN ?", []uint(step.ApproverIds))
+		if global.GVA_CONFIG.Tenant.Enable && !req.TenantAll {
+			// 只由本租户或平台的用户审批
+			db = db.Where("sys_users.tenant_id IN ?", []uint{req.TenantId, 0})
+		}
+		err = db.Distinct().Pluck("sys_user_authority.sys_user_id", &ids).Error
+	case system.ApproverLeader:
+		ids, err = DepartmentServiceApp.GetUserLeaders(req.RequesterId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := ids[:0]
+	for _, id := range ids {
+		if id != req.RequesterId {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+//@function: Approve
+//@description: 同意审批 或签时任一审批人同意即进入下一步, 会签时需全部审批人同意, 最后一步通过后以申请人身份执行原操作
+//@param: ctx context.Context, info systemReq.ApprovalAct
+//@return: req system.SysApprovalRequest, err error
+
+func (approvalService *ApprovalService) Approve(ctx context.Context, info systemReq.ApprovalAct) (req system.SysApprovalRequest, err error) {
+	var advanced, finished bool
+	err = approvalService.act(ctx, info.ID, func(tx *gorm.DB, r *system.SysApprovalRequest, task *system.SysApprovalTask, now time.Time) error {
+		if err := approvalService.finishTask(tx, task, system.ApprovalTaskApproved, info.Comment, now); err != nil {
+			return err
+		}
+		step := r.Steps[r.CurrentStep]
+		if step.Mode == system.ApprovalModeAll {
+			var remaining int64
+			err := tx.Model(&system.SysApprovalTask{}).
+				Where("request_id = ? AND step = ? AND status = ?", r.ID, r.CurrentStep, system.ApprovalTaskPending).
+				Count(&remaining).Error
+			if err != nil || remaining > 0 {
+				return err
+			}
+		}
+		// 本步骤通过 其余审批人无需再处理
+		err := tx.Model(&system.SysApprovalTask{}).
+			Where("request_id = ? AND step = ? AND status = ?", r.ID, r.CurrentStep, system.ApprovalTaskPending).
+			Update("status", system.ApprovalTaskSkipped).Error
+		if err != nil {
+			return err
+		}
+		if r.CurrentStep+1 < len(r.Steps) {
+			advanced = true
+			r.CurrentStep++
+			if err = tx.Model(&system.SysApprovalTask{}).
+				Where("request_id = ? AND step = ?", r.ID, r.CurrentStep).
+				Update("status", system.ApprovalTaskPending).Error; err != nil {
+				return err
+			}
+			return tx.Model(r).Update("current_step", r.CurrentStep).Error
+		}
+		finished = true
+		r.Status = system.ApprovalApproved
+		return tx.Model(r).Update("status", r.Status).Error
+	})
+	if err != nil || !finished {
+		if advanced {
+			approvalService.notifyApprovers(info.ID)
+		}
+		return req, err
+	}
+	if req, err = approvalService.execute(info.ID); err != nil {
+		// 审批已提交 执行由定时任务补偿
+		global.GVA_LOG.Error("审批通过后执行操作失败, 等待定时任务重试!", zap.Uint("request", info.ID), zap.Error(err))
+		req.ID, req.Status = info.ID, system.ApprovalApproved
+	}
+	return req, nil
+}
+
+//@function: Reject
+//@description: 驳回审批 任一审批人驳回即结束审批单
+//@param: ctx context.Context, info systemReq.ApprovalAct
+//@return: err error
+
+func (approvalService *ApprovalService) Reject(ctx context.Context, info systemReq.ApprovalAct) (err error) {
+	if info.Comment == "" {
+		return errors.New("请填写驳回原因")
+	}
+	var rejected system.SysApprovalRequest
+	err = approvalService.act(ctx, info.ID, func(tx *gorm.DB, r *system.SysApprovalRequest, task *system.SysApprovalTask, now time.Time) error {
+		if err := approvalService.finishTask(tx, task, system.ApprovalTaskRejected, info.Comment, now); err != nil {
+			return err
+		}
+		rejected = *r
+		rejected.Status, rejected.Result = system.ApprovalRejected, info.Comment
+		return approvalService.close(tx, r, system.ApprovalRejected, info.Comment, now)
+	})
+	if err == nil {
+		approvalService.notifyRequester(rejected)
+	}
+	return err
+}
+
+//@function: Cancel
+//@description: 申请人撤回审批中的审批单
+//@param: ctx context.Context, id uint
+//@return: err error
+
+func (approvalService *ApprovalService) Cancel(ctx context.Context, id uint) (err error) {
+	claims := utils.GetClaimsFromContext(ctx)
+	if claims == nil {
+		return errors.New("无法获取当前用户")
+	}
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var r system.SysApprovalRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&r).Error; err != nil {
+			return err
+		}
+		if r.RequesterId != claims.BaseClaims.ID {
+			return errors.New("只能撤回自己提交的审批单")
+		}
+		if r.Status != system.ApprovalPending {
+			return errors.New("审批单已结束")
+		}
+		return approvalService.close(tx, &r, system.ApprovalCanceled, "申请人撤回", time.Now())
+	})
+}
+
+// act 锁定审批单并取出当前用户在当前步骤的待处理任务
+func (approvalService *ApprovalService) act(ctx context.Context, id uint, fn func(tx *gorm.DB, r *system.SysApprovalRequest, task *system.SysApprovalTask, now time.Time) error) error {
+	claims := utils.GetClaimsFromContext(ctx)
+	if claims == nil {
+		return errors.New("无法获取当前用户")
+	}
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var r system.SysApprovalRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&r).Error; err != nil {
+			return err
+		}
+		if r.Status != system.ApprovalPending {
+			return errors.New("审批单已结束")
+		}
+		if r.CurrentStep >= len(r.Steps) {
+			return errors.New("审批单步骤异常")
+		}
+		var task system.SysApprovalTask
+		err := tx.Where("request_id = ? AND step = ? AND approver_id = ? AND status = ?",
+			r.ID, r.CurrentStep, claims.BaseClaims.ID, system.ApprovalTaskPending).First(&task).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("没有需要您处理的审批任务")
+		}
+		if err != nil {
+			return err
+		}
+		return fn(tx, &r, &task, time.Now())
+	})
+}
+
+func (approvalService *ApprovalService) finishTask(tx *gorm.DB, task *system.SysApprovalTask, status, comment string, now time.Time) error {
+	return tx.Model(&system.SysApprovalTask{}).Where("id = ? AND status = ?", task.ID, system.ApprovalTaskPending).
+		Updates(map[string]interface{}{"status": status, "comment": comment, "acted_at": now}).Error
+}
+
+// close 结束审批单 未处理的任务标记为无需处理
+func (approvalService *ApprovalService) close(tx *gorm.DB, r *system.SysApprovalRequest, status, result string, now time.Time) error {
+	err := tx.Model(&system.SysApprovalTask{}).
+		Where("request_id = ? AND status IN ?", r.ID, []string{system.ApprovalTaskPending, system.ApprovalTaskWaiting}).
+		Update("status", system.ApprovalTaskSkipped).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&system.SysApprovalRequest{}).Where("id = ?", r.ID).
+		Updates(map[string]interface{}{"status": status, "result": result, "finished_at": now}).Error
+}
+
+// execute 以申请人提交时的身份和租户执行原操作并记录结果
+// 先通过条件更新将审批单从已通过领取为执行中 避免审批人与定时任务或多实例间重复执行
+// 已被领取时返回审批单当前状态
+func (approvalService *ApprovalService) execute(id uint) (req system.SysApprovalRequest, err error) {
+	res := global.GVA_DB.Model(&system.SysApprovalRequest{}).Where("id = ? AND status = ?", id, system.ApprovalApproved).
+		Update("status", system.ApprovalExecuting)
+	if res.Error != nil {
+		return req, res.Error
+	}
+	if err = global.GVA_DB.Where("id = ?", id).First(&req).Error; err != nil || res.RowsAffected == 0 {
+		return req, err
+	}
+	ctx := utils.ContextWithClaims(context.Background(), &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{
+		ID:          req.RequesterId,
+		Username:    req.RequesterName,
+		AuthorityId: req.RequesterAuthorityId,
+		TenantId:    req.TenantId,
+	}})
+	if global.GVA_CONFIG.Tenant.Enable {
+		ctx = utils.ContextWithTenant(ctx, utils.TenantScope{Id: req.TenantId, All: req.TenantAll})
+	}
+	req.Status, req.Result = system.ApprovalExecuted, "执行成功"
+	if action, ok := approvalActions[req.Action]; !ok {
+		req.Status, req.Result = system.ApprovalFailed, errUnknownApprovalAction.Error()
+	} else if execErr := action.Exec(ctx, req.Payload); execErr != nil {
+		global.GVA_LOG.Error("审批通过后执行操作失败!", zap.Uint("request", req.ID), zap.String("action", req.Action), zap.Error(execErr))
+		req.Status, req.Result = system.ApprovalFailed, execErr.Error()
+	}
+	if err = approvalService.finish(req); err != nil {
+		return req, err
+	}
+	now := time.Now()
+	req.FinishedAt = &now
+	approvalService.notifyRequester(req)
+	return req, nil
+}
+
+// finish 记录执行中的审批单的执行结果
+func (approvalService *ApprovalService) finish(req system.SysApprovalRequest) error {
+	return global.GVA_DB.Model(&system.SysApprovalRequest{}).Where("id = ? AND status = ?", req.ID, system.ApprovalExecuting).
+		Updates(map[string]interface{}{"status": req.Status, "result": req.Result, "finished_at": time.Now()}).Error
+}
+
+// approvalExecuteTimeout 执行中的审批单超过该时长未结束 视为执行过程中进程退出
+const approvalExecuteTimeout = 10 * time.Minute
+
+//@function: ResumeApproved
+//@description: 执行已通过但尚未执行的审批单(如审批提交后进程退出), 执行超时的审批单无法确认是否已生效 标记为执行失败由申请人确认后重新提交, 由定时任务调用
+//@return: err error
+
+func (approvalService *ApprovalService) ResumeApproved() (err error) {
+	if global.GVA_DB == nil {
+		return nil
+	}
+	var ids []uint
+	if err = global.GVA_DB.Model(&system.SysApprovalRequest{}).Where("status = ?", system.ApprovalApproved).Order("id").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err = approvalService.execute(id); err != nil {
+			return err
+		}
+	}
+	var stale []system.SysApprovalRequest
+	err = global.GVA_DB.Omit("payload").Where("status = ? AND updated_at < ?", system.ApprovalExecuting, time.Now().Add(-approvalExecuteTimeout)).
+		Find(&stale).Error
+	if err != nil {
+		return err
+	}
+	for _, req := range stale {
+		req.Status, req.Result = system.ApprovalFailed, "执行中断, 请确认操作是否已生效后重新提交"
+		if err = approvalService.finish(req); err != nil {
+			return err
+		}
+		approvalService.notifyRequester(req)
+	}
+	return nil
+}
+
+// notifyApprovers 通知当前步骤的审批人 通知失败不影响审批
+func (approvalService *ApprovalService) notifyApprovers(id uint) {
+	var req system.SysApprovalRequest
+	if err := global.GVA_DB.Omit("payload").Where("id = ?", id).First(&req).Error; err != nil {
+		return
+	}
+	var approvers []uint
+	global.GVA_DB.Model(&system.SysApprovalTask{}).Where("request_id = ? AND status = ?", id, system.ApprovalTaskPending).Pluck("approver_id", &approvers)
+	if len(approvers) == 0 {
+		return
+	}
+	err := NotificationServiceApp.Push(approvers, system.SysNotification{
+		Category: system.NotificationCategoryApproval,
+		Title:    fmt.Sprintf("待审批: %s", req.FlowName),
+		Content:  fmt.Sprintf("%s 提交了审批单: %s", req.RequesterName, req.Summary),
+	})
+	if err != nil {
+		global.GVA_LOG.Error("发送审批通知失败!", zap.Error(err))
+	}
+}
+
+// notifyRequester 通知申请人审批结果
+func (approvalService *ApprovalService) notifyRequester(req system.SysApprovalRequest) {
+	status := map[string]string{
+		system.ApprovalExecuted: "已通过并执行",
+		system.ApprovalFailed:   "已通过, 执行失败",
+		system.ApprovalRejected: "已驳回",
+	}[req.Status]
+	err := NotificationServiceApp.Push([]uint{req.RequesterId}, system.SysNotification{
+		Category: system.NotificationCategoryApproval,
+		Title:    fmt.Sprintf("审批结果: %s %s", req.FlowName, status),
+		Content:  fmt.Sprintf("%s\n%s", req.Summary, req.Result),
+	})
+	if err != nil {
+		global.GVA_LOG.Error("发送审批通知失败!", zap.Error(err))
+	}
+}
+
+//@function: GetRequest
+//@description: 获取审批单详情及审批记录
+//@param: ctx context.Context, id uint
+//@return: req system.SysApprovalRequest, err error
+
+func (approvalService *ApprovalService) GetRequest(ctx context.Context, id uint) (req system.SysApprovalRequest, err error) {
+	err = global.GVA_DB.WithContext(ctx).Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("step, id")
+	}).Where("id = ?", id).First(&req).Error
+	return
+}
+
+//@function: GetRequestList
+//@description: 分页获取审批单列表
+//@param: ctx context.Context, info systemReq.ApprovalRequestSearch
+//@return: list []system.SysApprovalRequest, total int64, err error
+
+func (approvalService *ApprovalService) GetRequestList(ctx context.Context, info systemReq.ApprovalRequestSearch) (list []system.SysApprovalRequest, total int64, err error) {
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysApprovalRequest{})
+	if info.Action != "" {
+		db = db.Where("action = ?", info.Action)
+	}
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if info.RequesterId != 0 {
+		db = db.Where("requester_id = ?", info.RequesterId)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Omit("payload").Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+//@function: GetMyRequests
+//@description: 分页获取当前用户提交的审批单
+//@param: ctx context.Context, info systemReq.ApprovalRequestSearch
+//@return: list []system.SysApprovalRequest, total int64, err error
+
+func (approvalService *ApprovalService) GetMyRequests(ctx context.Context, info systemReq.ApprovalRequestSearch) (list []system.SysApprovalRequest, total int64, err error) {
+	claims := utils.GetClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, 0, errors.New("无法获取当前用户")
+	}
+	info.RequesterId = claims.BaseClaims.ID
+	return approvalService.GetRequestList(ctx, info)
+}
+
+//@function: GetMyTasks
+//@description: 分页获取等待当前用户审批的审批单
+//@param: ctx context.Context, info request.PageInfo
+//@return: list []system.SysApprovalRequest, total int64, err error
+
+func (approvalService *ApprovalService) GetMyTasks(ctx context.Context, info request.PageInfo) (list []system.SysApprovalRequest, total int64, err error) {
+	claims := utils.GetClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, 0, errors.New("无法获取当前用户")
+	}
+	db := global.GVA_DB.Model(&system.SysApprovalRequest{}).Where("status = ? AND id IN (?)", system.ApprovalPending,
+		global.GVA_DB.Model(&system.SysApprovalTask{}).Select("request_id").
+			Where("approver_id = ? AND status = ?", claims.BaseClaims.ID, system.ApprovalTaskPending))
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Omit("payload").Order("id").Find(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,82 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+)
+
+// approvalAction 可纳入审批的操作 Exec 在审批通过后以申请人身份执行原操作
+type approvalAction struct {
+	Name string
+	Exec func(ctx context.Context, payload []byte) error
+}
+
+// approvalActions 受审批控制的操作 新增操作时在此注册并在对应接口中调用 submitApproval
+var approvalActions = map[string]approvalAction{
+	system.ApprovalActionDeleteUser: {
+		Name: "删除用户",
+		Exec: func(ctx context.Context, payload []byte) error {
+			var p request.GetById
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return err
+			}
+			return UserServiceApp.DeleteUser(ctx, p.ID)
+		},
+	},
+	system.ApprovalActionSetUserAuthorities: {
+		Name: "设置用户角色",
+		Exec: func(ctx context.Context, payload []byte) error {
+			var p systemReq.SetUserAuthorities
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return err
+			}
+			return UserServiceApp.SetUserAuthorities(ctx, requesterAuthorityId(ctx), p.ID, p.AuthorityIds)
+		},
+	},
+	system.ApprovalActionUpdateCasbin: {
+		Name: "更新角色api权限",
+		Exec: func(ctx context.Context, payload []byte) error {
+			var p systemReq.CasbinInReceive
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return err
+			}
+			return CasbinServiceApp.UpdateCasbin(requesterAuthorityId(ctx), p.AuthorityId, p.CasbinInfos)
+		},
+	},
+	system.ApprovalActionRollBackAutoCode: {
+		Name: "回滚自动生成代码",
+		Exec: func(ctx context.Context, payload []byte) error {
+			var p systemReq.SysAutoHistoryRollBack
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return err
+			}
+			return AutocodeHistory.RollBack(ctx, p)
+		},
+	},
+	system.ApprovalActionImportExcel: {
+		Name: "导入表格",
+		Exec: func(ctx context.Context, payload []byte) error {
+			var p systemReq.ApprovalImportExcel
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return err
+			}
+			return SysExportTemplateServiceApp.ImportExcelData(p.TemplateID, bytes.NewReader(p.File))
+		},
+	},
+}
+
+func requesterAuthorityId(ctx context.Context) uint {
+	if claims := utils.GetClaimsFromContext(ctx); claims != nil {
+		return claims.AuthorityId
+	}
+	return 0
+}
+
+var errUnknownApprovalAction = errors.New("不支持审批的操作")
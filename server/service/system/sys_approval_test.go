@@ -0,0 +1,93 @@
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const approvalTestAction = "test.count"
+
+// setupApprovalTest 注册一个记录执行次数的操作 并配置由用户 2 审批的流程
+func setupApprovalTest(t *testing.T) *int {
+	db := newTestDB(t, &system.SysApprovalFlow{}, &system.SysApprovalStep{}, &system.SysApprovalRequest{},
+		&system.SysApprovalTask{}, &system.SysUser{}, &system.SysNotification{})
+	executed := new(int)
+	approvalActions[approvalTestAction] = approvalAction{Name: "计数", Exec: func(ctx context.Context, payload []byte) error {
+		*executed++
+		return nil
+	}}
+	t.Cleanup(func() { delete(approvalActions, approvalTestAction) })
+
+	assert.Nil(t, db.Create(&[]system.SysUser{{Username: "requester"}, {Username: "approver"}}).Error)
+	assert.Nil(t, ApprovalServiceApp.CreateFlow(&system.SysApprovalFlow{Name: "计数审批", Action: approvalTestAction, Enable: true,
+		Steps: []system.SysApprovalStep{{ApproverType: system.ApproverUser, ApproverIds: []uint{2}}}}))
+	return executed
+}
+
+func approvalContext(userId uint) context.Context {
+	return utils.ContextWithClaims(context.Background(), &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{ID: userId, AuthorityId: 888}})
+}
+
+func TestApprovalExecuteOnce(t *testing.T) {
+	executed := setupApprovalTest(t)
+
+	req, guarded, err := ApprovalServiceApp.Submit(approvalContext(1), approvalTestAction, map[string]int{"n": 1}, "计数")
+	assert.Nil(t, err)
+	assert.True(t, guarded)
+
+	result, err := ApprovalServiceApp.Approve(approvalContext(2), systemReq.ApprovalAct{ID: req.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, system.ApprovalExecuted, result.Status)
+	assert.Equal(t, 1, *executed)
+
+	// 已执行的审批单不会被定时任务或重复调用再次执行
+	assert.Nil(t, ApprovalServiceApp.ResumeApproved())
+	again, err := ApprovalServiceApp.execute(req.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, system.ApprovalExecuted, again.Status)
+	assert.Equal(t, 1, *executed)
+}
+
+func TestApprovalResumeApproved(t *testing.T) {
+	executed := setupApprovalTest(t)
+
+	// 审批提交后、执行前进程退出
+	approved := system.SysApprovalRequest{Action: approvalTestAction, Status: system.ApprovalApproved, RequesterId: 1, Payload: []byte("{}")}
+	assert.Nil(t, global.GVA_DB.Create(&approved).Error)
+	// 执行过程中进程退出 无法确认是否已生效
+	interrupted := system.SysApprovalRequest{Action: approvalTestAction, Status: system.ApprovalExecuting, RequesterId: 1, Payload: []byte("{}")}
+	assert.Nil(t, global.GVA_DB.Create(&interrupted).Error)
+	assert.Nil(t, global.GVA_DB.Model(&interrupted).UpdateColumn("updated_at", time.Now().Add(-2*approvalExecuteTimeout)).Error)
+	// 刚开始执行的审批单不受影响
+	running := system.SysApprovalRequest{Action: approvalTestAction, Status: system.ApprovalExecuting, RequesterId: 1, Payload: []byte("{}")}
+	assert.Nil(t, global.GVA_DB.Create(&running).Error)
+
+	assert.Nil(t, ApprovalServiceApp.ResumeApproved())
+	assert.Equal(t, 1, *executed)
+
+	status := func(id uint) system.SysApprovalRequest {
+		var r system.SysApprovalRequest
+		assert.Nil(t, global.GVA_DB.First(&r, id).Error)
+		return r
+	}
+	assert.Equal(t, system.ApprovalExecuted, status(approved.ID).Status)
+	assert.Equal(t, system.ApprovalFailed, status(interrupted.ID).Status)
+	assert.NotNil(t, status(interrupted.ID).FinishedAt)
+	assert.Equal(t, system.ApprovalExecuting, status(running.ID).Status)
+
+	// 申请人收到执行结果通知
+	var notifications int64
+	global.GVA_DB.Model(&system.SysNotification{}).Where("user_id = ?", 1).Count(&notifications)
+	assert.Equal(t, int64(2), notifications)
+
+	// 数据库尚未初始化时定时任务直接跳过
+	global.GVA_DB = nil
+	assert.Nil(t, ApprovalServiceApp.ResumeApproved())
+}
@@ -1,7 +1,9 @@
 package system
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strconv"
 
 	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
@@ -15,6 +17,17 @@ import (
 
 var ErrRoleExistence = errors.New("存在相同角色id")
 
+// ErrCasbinRefresh 角色已保存 但重新加载casbin策略失败
+var ErrCasbinRefresh = errors.New("权限刷新失败")
+
+// refreshCasbin 角色增删改后重新加载策略 并按角色表同步继承(g)与租户域(g2)规则
+func refreshCasbin() error {
+	if err := CasbinServiceApp.FreshCasbin(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCasbinRefresh, err)
+	}
+	return nil
+}
+
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: CreateAuthority
 //@description: 创建一个角色
@@ -41,6 +54,9 @@ func (authorityService *AuthorityService) CreateAuthority(auth system.SysAuthori
 		if err = tx.Model(&auth).Association("SysBaseMenus").Replace(&auth.SysBaseMenus); err != nil {
 			return err
 		}
+		if err = CasbinServiceApp.addTenantDomain(tx, auth); err != nil {
+			return err
+		}
 		casbinInfos := systemReq.DefaultCasbin()
 		authorityId := strconv.Itoa(int(auth.AuthorityId))
 		rules := [][]string{}
@@ -49,8 +65,11 @@ func (authorityService *AuthorityService) CreateAuthority(auth system.SysAuthori
 		}
 		return CasbinServiceApp.AddPolicies(tx, rules)
 	})
-
-	return auth, e
+	if e != nil {
+		return auth, e
+	}
+	authorityChanged(auth.AuthorityId, system.AuthorityCreated)
+	return auth, refreshCasbin()
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -76,7 +95,12 @@ func (authorityService *AuthorityService) CopyAuthority(adminAuthorityID uint, c
 		baseMenu = append(baseMenu, v.SysBaseMenu)
 	}
 	copyInfo.Authority.SysBaseMenus = baseMenu
-	err = global.GVA_DB.Create(&copyInfo.Authority).Error
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&copyInfo.Authority).Error; err != nil {
+			return err
+		}
+		return CasbinServiceApp.addTenantDomain(tx, copyInfo.Authority)
+	})
 	if err != nil {
 		return
 	}
@@ -97,11 +121,18 @@ func (authorityService *AuthorityService) CopyAuthority(adminAuthorityID uint, c
 			return
 		}
 	}
+	// 先建立新角色的继承关系 避免复制上级角色已有的权限
+	if err = refreshCasbin(); err != nil {
+		_ = authorityService.DeleteAuthority(&copyInfo.Authority)
+		return copyInfo.Authority, err
+	}
 	paths := CasbinServiceApp.GetPolicyPathByAuthorityId(copyInfo.OldAuthorityId)
 	err = CasbinServiceApp.UpdateCasbin(adminAuthorityID, copyInfo.Authority.AuthorityId, paths)
 	if err != nil {
 		_ = authorityService.DeleteAuthority(&copyInfo.Authority)
+		return copyInfo.Authority, err
 	}
+	authorityChanged(copyInfo.Authority.AuthorityId, system.AuthorityCopied)
 	return copyInfo.Authority, err
 }
 
@@ -119,7 +150,12 @@ func (authorityService *AuthorityService) UpdateAuthority(auth system.SysAuthori
 		return system.SysAuthority{}, errors.New("查询角色数据失败")
 	}
 	err = global.GVA_DB.Model(&oldAuthority).Updates(&auth).Error
-	return auth, err
+	if err != nil {
+		return auth, err
+	}
+	authorityChanged(auth.AuthorityId, system.AuthorityUpdated)
+	// 父角色变更后需要同步角色继承关系
+	return auth, refreshCasbin()
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -142,7 +178,7 @@ func (authorityService *AuthorityService) DeleteAuthority(auth *system.SysAuthor
 		return errors.New("此角色存在子角色不允许删除")
 	}
 
-	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+	err := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
 		var err error
 		if err = tx.Preload("SysBaseMenus").Preload("DataAuthorityId").Where("authority_id = ?", auth.AuthorityId).First(auth).Unscoped().Delete(auth).Error; err != nil {
 			return err
@@ -169,12 +205,19 @@ func (authorityService *AuthorityService) DeleteAuthority(auth *system.SysAuthor
 
 		authorityId := strconv.Itoa(int(auth.AuthorityId))
 
+		// 同时删除该角色的策略(p)、继承(g)与租户域(g2)规则
 		if err = CasbinServiceApp.RemoveFilteredPolicy(tx, authorityId); err != nil {
 			return err
 		}
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	authorityChanged(auth.AuthorityId, system.AuthorityDeleted)
+	// 清理内存中该角色的策略及继承、租户域规则
+	return refreshCasbin()
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -304,9 +347,17 @@ func (authorityService *AuthorityService) SetMenuAuthority(auth *system.SysAutho
 	var s system.SysAuthority
 	global.GVA_DB.Preload("SysBaseMenus").First(&s, "authority_id = ?", auth.AuthorityId)
 	err := global.GVA_DB.Model(&s).Association("SysBaseMenus").Replace(&auth.SysBaseMenus)
+	if err == nil {
+		authorityChanged(auth.AuthorityId, system.AuthorityMenus)
+	}
 	return err
 }
 
+// authorityChanged 发布角色变更事件
+func authorityChanged(authorityId uint, action string) {
+	publishEvent(context.Background(), system.EventAuthorityChanged, system.AuthorityEvent{AuthorityId: authorityId, Action: action})
+}
+
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: findChildrenAuthority
 //@description: 查询子角色
@@ -0,0 +1,114 @@
+package system
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"gorm.io/gorm"
+)
+
+type AuthorityFieldService struct{}
+
+var AuthorityFieldServiceApp = new(AuthorityFieldService)
+
+var (
+	authorityFieldMu    sync.RWMutex
+	authorityFieldRules map[uint]map[string]map[string]string // 角色ID -> 资源 -> 字段 -> 动作 nil表示未加载
+)
+
+func (a *AuthorityFieldService) GetAuthorityField(adminAuthorityID uint, req request.SysAuthorityFieldReq) (list []system.SysAuthorityField, err error) {
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, req.AuthorityId); err != nil {
+		return nil, err
+	}
+	db := global.GVA_DB.Where("authority_id = ?", req.AuthorityId)
+	if req.Resource != "" {
+		db = db.Where("resource = ?", req.Resource)
+	}
+	err = db.Order("resource, field").Find(&list).Error
+	return list, err
+}
+
+func (a *AuthorityFieldService) SetAuthorityField(adminAuthorityID uint, req request.SysAuthorityFieldReq) (err error) {
+	if req.Resource == "" {
+		return fmt.Errorf("资源不能为空")
+	}
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, req.AuthorityId); err != nil {
+		return err
+	}
+	fields := make([]system.SysAuthorityField, 0, len(req.Fields))
+	for _, v := range req.Fields {
+		if v.Field == "" {
+			continue
+		}
+		if v.Action != utils.FieldHide && v.Action != utils.FieldMask {
+			return fmt.Errorf("字段 %s 的动作 %s 不合法 仅支持 hide/mask", v.Field, v.Action)
+		}
+		fields = append(fields, system.SysAuthorityField{
+			AuthorityId: req.AuthorityId,
+			Resource:    req.Resource,
+			Field:       v.Field,
+			Action:      v.Action,
+		})
+	}
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&[]system.SysAuthorityField{}, "authority_id = ? and resource = ?", req.AuthorityId, req.Resource).Error; err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			return tx.Create(&fields).Error
+		}
+		return nil
+	})
+	authorityFieldMu.Lock()
+	authorityFieldRules = nil
+	authorityFieldMu.Unlock()
+	if err == nil {
+		authorityChanged(req.AuthorityId, system.AuthorityFields)
+	}
+	return err
+}
+
+// AuthorityFieldRules 获取角色在某个接口路径或导出表上的字段规则 资源上的规则优先于 * 规则
+func AuthorityFieldRules(authorityId uint, resource string) map[string]string {
+	authorityFieldMu.RLock()
+	rules := authorityFieldRules
+	authorityFieldMu.RUnlock()
+	if rules == nil {
+		var list []system.SysAuthorityField
+		if err := global.GVA_DB.Find(&list).Error; err != nil {
+			return nil
+		}
+		rules = make(map[uint]map[string]map[string]string)
+		for _, v := range list {
+			if rules[v.AuthorityId] == nil {
+				rules[v.AuthorityId] = make(map[string]map[string]string)
+			}
+			if rules[v.AuthorityId][v.Resource] == nil {
+				rules[v.AuthorityId][v.Resource] = make(map[string]string)
+			}
+			rules[v.AuthorityId][v.Resource][v.Field] = v.Action
+		}
+		authorityFieldMu.Lock()
+		authorityFieldRules = rules
+		authorityFieldMu.Unlock()
+	}
+	all, specific := rules[authorityId]["*"], rules[authorityId][resource]
+	if len(all) == 0 {
+		return specific
+	}
+	if len(specific) == 0 {
+		return all
+	}
+	merged := make(map[string]string, len(all)+len(specific))
+	for k, v := range all {
+		merged[k] = v
+	}
+	for k, v := range specific {
+		merged[k] = v
+	}
+	return merged
+}
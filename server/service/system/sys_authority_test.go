@@ -0,0 +1,117 @@
+package system
+
+import (
+	"sync"
+	"testing"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newCasbinTestDB 迁移角色相关表 并让 Casbin() 基于测试库重新创建 enforcer
+func newCasbinTestDB(t *testing.T) *gorm.DB {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysBaseMenu{}, &system.SysAuthorityBtn{}, &system.SysUser{},
+		&system.SysUserAuthority{}, &gormadapter.CasbinRule{})
+	resetEnforcer := func() {
+		once = sync.Once{}
+		syncedCachedEnforcer = nil
+	}
+	resetEnforcer()
+	t.Cleanup(resetEnforcer)
+	return db
+}
+
+func authorityWithParent(id, parent uint) system.SysAuthority {
+	return system.SysAuthority{AuthorityId: id, AuthorityName: "role", ParentId: &parent}
+}
+
+func TestAuthorityRoleInheritanceSync(t *testing.T) {
+	newCasbinTestDB(t)
+	old := global.GVA_CONFIG.System
+	global.GVA_CONFIG.System.UseRoleInheritance = true
+	global.GVA_CONFIG.System.UseStrictAuth = false
+	t.Cleanup(func() { global.GVA_CONFIG.System = old })
+
+	_, err := AuthorityServiceApp.CreateAuthority(authorityWithParent(100, 0))
+	assert.Nil(t, err)
+	assert.Nil(t, CasbinServiceApp.UpdateCasbin(100, 100, []request.CasbinInfo{{Path: "/report/list", Method: "GET"}}))
+
+	// 新建的子角色无需刷新即可继承上级权限
+	_, err = AuthorityServiceApp.CreateAuthority(authorityWithParent(101, 100))
+	assert.Nil(t, err)
+	e := CasbinServiceApp.Casbin()
+	ok, err := e.Enforce("101", "/report/list", "GET")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	groupings, _ := e.GetGroupingPolicy()
+	assert.Equal(t, [][]string{{"101", "100"}}, groupings)
+
+	// 复制的子角色同样继承
+	_, err = AuthorityServiceApp.CopyAuthority(100, response.SysAuthorityCopyResponse{Authority: authorityWithParent(102, 100), OldAuthorityId: 101})
+	assert.Nil(t, err)
+	ok, _ = e.Enforce("102", "/report/list", "GET")
+	assert.True(t, ok)
+
+	// 删除角色后继承规则随之移除
+	assert.Nil(t, AuthorityServiceApp.DeleteAuthority(&system.SysAuthority{AuthorityId: 101}))
+	assert.Nil(t, AuthorityServiceApp.DeleteAuthority(&system.SysAuthority{AuthorityId: 102}))
+	groupings, _ = e.GetGroupingPolicy()
+	assert.Empty(t, groupings)
+	ok, _ = e.Enforce("101", "/report/list", "GET")
+	assert.False(t, ok)
+	var rules int64
+	global.GVA_DB.Model(&gormadapter.CasbinRule{}).Where("v0 IN ?", []string{"101", "102"}).Count(&rules)
+	assert.Equal(t, int64(0), rules)
+}
+
+func TestAuthorityTenantDomainSync(t *testing.T) {
+	newCasbinTestDB(t)
+	oldSystem, oldTenant := global.GVA_CONFIG.System, global.GVA_CONFIG.Tenant
+	global.GVA_CONFIG.System.UseRoleInheritance = false
+	global.GVA_CONFIG.System.UseStrictAuth = false
+	global.GVA_CONFIG.Tenant.Enable = true
+	t.Cleanup(func() { global.GVA_CONFIG.System, global.GVA_CONFIG.Tenant = oldSystem, oldTenant })
+
+	g2Rules := func(sub string) int64 {
+		var count int64
+		global.GVA_DB.Model(&gormadapter.CasbinRule{}).Where("ptype = ? AND v0 = ?", "g2", sub).Count(&count)
+		return count
+	}
+
+	auth := authorityWithParent(200, 0)
+	auth.TenantId = 1
+	_, err := AuthorityServiceApp.CreateAuthority(auth)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), g2Rules("200"))
+	assert.Nil(t, CasbinServiceApp.UpdateCasbin(200, 200, []request.CasbinInfo{{Path: "/report/list", Method: "GET"}}))
+
+	// 新建的角色无需重启即只在所属租户内生效
+	e := CasbinServiceApp.Casbin()
+	ok, err := e.Enforce(CasbinServiceApp.EnforceArgs("200", 1, "/report/list", "GET")...)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, _ = e.Enforce(CasbinServiceApp.EnforceArgs("200", 2, "/report/list", "GET")...)
+	assert.False(t, ok)
+
+	// 复制的角色归属新的租户
+	copied := authorityWithParent(201, 0)
+	copied.TenantId = 2
+	_, err = AuthorityServiceApp.CopyAuthority(200, response.SysAuthorityCopyResponse{Authority: copied, OldAuthorityId: 200})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), g2Rules("201"))
+	ok, _ = e.Enforce(CasbinServiceApp.EnforceArgs("201", 2, "/report/list", "GET")...)
+	assert.True(t, ok)
+	ok, _ = e.Enforce(CasbinServiceApp.EnforceArgs("201", 1, "/report/list", "GET")...)
+	assert.False(t, ok)
+
+	// 删除角色后租户域规则随之移除
+	assert.Nil(t, AuthorityServiceApp.DeleteAuthority(&system.SysAuthority{AuthorityId: 201}))
+	assert.Equal(t, int64(0), g2Rules("201"))
+	domains, _ := e.GetNamedGroupingPolicy("g2")
+	assert.Equal(t, [][]string{{"200", TenantDomain(1)}}, domains)
+}
@@ -3,6 +3,7 @@ package system
 import (
 	"errors"
 	"strconv"
+	"strings"
 	"sync"
 
 	"gorm.io/gorm"
@@ -11,6 +12,7 @@ import (
 	"github.com/casbin/casbin/v2/model"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	_ "github.com/go-sql-driver/mysql"
 	"go.uber.org/zap"
@@ -54,18 +56,24 @@ func (casbinService *CasbinService) UpdateCasbin(adminAuthorityID, AuthorityID u
 	}
 
 	authorityId := strconv.Itoa(int(AuthorityID))
+	// 开启角色继承后 上级角色已有的权限无需重复存储
+	inherited := casbinService.inheritedPolicies(casbinService.Casbin(), authorityId)
 	casbinService.ClearCasbin(0, authorityId)
 	rules := [][]string{}
 	//做权限去重处理
 	deduplicateMap := make(map[string]bool)
 	for _, v := range casbinInfos {
 		key := authorityId + v.Path + v.Method
+		if _, ok := inherited[v.Path+" "+v.Method]; ok {
+			continue
+		}
 		if _, ok := deduplicateMap[key]; !ok {
 			deduplicateMap[key] = true
 			rules = append(rules, []string{authorityId, v.Path, v.Method})
 		}
 	}
 	if len(rules) == 0 {
+		authorityChanged(AuthorityID, system.AuthorityApis)
 		return nil
 	} // 设置空权限无需调用 AddPolicies 方法
 	e := casbinService.Casbin()
@@ -73,6 +81,7 @@ func (casbinService *CasbinService) UpdateCasbin(adminAuthorityID, AuthorityID u
 	if !success {
 		return errors.New("存在相同api,添加失败,请联系管理员")
 	}
+	authorityChanged(AuthorityID, system.AuthorityApis)
 	return nil
 }
 
@@ -105,12 +114,19 @@ func (casbinService *CasbinService) GetPolicyPathByAuthorityId(AuthorityID uint)
 	e := casbinService.Casbin()
 	authorityId := strconv.Itoa(int(AuthorityID))
 	list, _ := e.GetFilteredPolicy(0, authorityId)
+	direct := make(map[string]bool, len(list))
 	for _, v := range list {
+		direct[v[1]+" "+v[2]] = true
 		pathMaps = append(pathMaps, request.CasbinInfo{
 			Path:   v[1],
 			Method: v[2],
 		})
 	}
+	for _, v := range casbinService.inheritedPolicyList(e, authorityId) {
+		if !direct[v.Path+" "+v.Method] {
+			pathMaps = append(pathMaps, v)
+		}
+	}
 	return pathMaps
 }
 
@@ -173,7 +189,10 @@ func (casbinService *CasbinService) AddPolicies(db *gorm.DB, rules [][]string) e
 func (casbinService *CasbinService) FreshCasbin() (err error) {
 	e := casbinService.Casbin()
 	err = e.LoadPolicy()
-	return err
+	if err != nil {
+		return err
+	}
+	return casbinService.syncRoleGroupings(e)
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -209,6 +228,16 @@ func (casbinService *CasbinService) Casbin() *casbin.SyncedCachedEnforcer {
 		[matchers]
 		m = r.sub == p.sub && keyMatch2(r.obj,p.obj) && r.act == p.act
 		`
+		if global.GVA_CONFIG.System.UseRoleInheritance {
+			// 角色继承模式下 子角色通过 g 规则匹配上级角色的策略
+			text = strings.Replace(text, "r.sub == p.sub", "g(r.sub, p.sub)", 1)
+		}
+		if global.GVA_CONFIG.Tenant.Enable {
+			// 多租户模式下 角色通过 g2 规则归属租户域 只能在所属租户内生效 * 为全部租户共用
+			text = strings.Replace(text, "r = sub, obj, act", "r = sub, dom, obj, act", 1)
+			text = strings.Replace(text, "g = _, _", "g = _, _\n\t\tg2 = _, _", 1)
+			text = strings.Replace(text, "r.act == p.act", `r.act == p.act && (g2(r.sub, r.dom) || g2(r.sub, "*"))`, 1)
+		}
 		m, err := model.NewModelFromString(text)
 		if err != nil {
 			zap.L().Error("字符串加载模型失败!", zap.Error(err))
@@ -217,6 +246,9 @@ func (casbinService *CasbinService) Casbin() *casbin.SyncedCachedEnforcer {
 		syncedCachedEnforcer, _ = casbin.NewSyncedCachedEnforcer(m, a)
 		syncedCachedEnforcer.SetExpireTime(60 * 60)
 		_ = syncedCachedEnforcer.LoadPolicy()
+		if err = casbinService.syncRoleGroupings(syncedCachedEnforcer); err != nil {
+			zap.L().Error("同步角色继承关系失败!", zap.Error(err))
+		}
 	})
 	return syncedCachedEnforcer
 }
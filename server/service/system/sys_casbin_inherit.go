@@ -0,0 +1,144 @@
+package system
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
+//@function: syncRoleGroupings
+//@description: 按照 SysAuthority.ParentId 重建 casbin g 规则 子角色 -> 父角色, 未开启角色继承时不做处理
+//@description: 开启多租户时同时重建 g2 租户域规则
+//@param: e *casbin.SyncedCachedEnforcer
+//@return: error
+
+func (casbinService *CasbinService) syncRoleGroupings(e *casbin.SyncedCachedEnforcer) error {
+	if e == nil {
+		return nil
+	}
+	if err := casbinService.syncTenantDomains(e); err != nil {
+		return err
+	}
+	if !global.GVA_CONFIG.System.UseRoleInheritance {
+		return nil
+	}
+	var authorities []system.SysAuthority
+	if err := global.GVA_DB.Select("authority_id", "parent_id").Find(&authorities).Error; err != nil {
+		return err
+	}
+	want := make(map[string][]string)
+	for _, a := range authorities {
+		if a.ParentId == nil || *a.ParentId == 0 {
+			continue
+		}
+		child, parent := strconv.Itoa(int(a.AuthorityId)), strconv.Itoa(int(*a.ParentId))
+		want[child+" "+parent] = []string{child, parent}
+	}
+	existing, err := e.GetGroupingPolicy()
+	if err != nil {
+		return err
+	}
+	var stale [][]string
+	for _, g := range existing {
+		key := g[0] + " " + g[1]
+		if _, ok := want[key]; ok {
+			delete(want, key)
+		} else {
+			stale = append(stale, g)
+		}
+	}
+	if len(stale) > 0 {
+		if _, err = e.RemoveGroupingPolicies(stale); err != nil {
+			return err
+		}
+	}
+	if len(want) > 0 {
+		rules := make([][]string, 0, len(want))
+		for _, v := range want {
+			rules = append(rules, v)
+		}
+		if _, err = e.AddGroupingPolicies(rules); err != nil {
+			return err
+		}
+	}
+	return e.InvalidateCache()
+}
+
+//@function: inheritedPolicyList
+//@description: 获取角色从上级角色继承的权限, 离自身越近的上级越靠前, 未开启角色继承时返回空
+//@param: e *casbin.SyncedCachedEnforcer, authorityId string
+//@return: []request.CasbinInfo
+
+func (casbinService *CasbinService) inheritedPolicyList(e *casbin.SyncedCachedEnforcer, authorityId string) (list []request.CasbinInfo) {
+	if e == nil || !global.GVA_CONFIG.System.UseRoleInheritance {
+		return nil
+	}
+	roles, _ := e.GetImplicitRolesForUser(authorityId)
+	seen := make(map[string]bool)
+	for _, role := range roles {
+		from, _ := strconv.Atoi(role)
+		policies, _ := e.GetFilteredPolicy(0, role)
+		for _, p := range policies {
+			key := p[1] + " " + p[2]
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			list = append(list, request.CasbinInfo{Path: p[1], Method: p[2], Inherited: true, InheritFrom: uint(from)})
+		}
+	}
+	return list
+}
+
+// inheritedPolicies 继承权限的 "path method" 集合, 值为来源角色id
+func (casbinService *CasbinService) inheritedPolicies(e *casbin.SyncedCachedEnforcer, authorityId string) map[string]uint {
+	set := make(map[string]uint)
+	for _, v := range casbinService.inheritedPolicyList(e, authorityId) {
+		set[v.Path+" "+v.Method] = v.InheritFrom
+	}
+	return set
+}
+
+//@function: MigrateRoleInheritance
+//@description: 开启角色继承后同步 g 规则, 并删除子角色中与上级角色重复的策略
+//@return: removed int, err error
+
+func (casbinService *CasbinService) MigrateRoleInheritance() (removed int, err error) {
+	if !global.GVA_CONFIG.System.UseRoleInheritance {
+		return 0, errors.New("未开启角色继承, 请先配置 system.use-role-inheritance")
+	}
+	e := casbinService.Casbin()
+	if e == nil {
+		return 0, errors.New("Casbin 加载失败，请检查配置")
+	}
+	if err = casbinService.syncRoleGroupings(e); err != nil {
+		return 0, err
+	}
+	var authorities []system.SysAuthority
+	if err = global.GVA_DB.Where("parent_id <> ?", 0).Find(&authorities).Error; err != nil {
+		return 0, err
+	}
+	for _, a := range authorities {
+		sub := strconv.Itoa(int(a.AuthorityId))
+		inherited := casbinService.inheritedPolicies(e, sub)
+		direct, _ := e.GetFilteredPolicy(0, sub)
+		var duplicated [][]string
+		for _, p := range direct {
+			if _, ok := inherited[p[1]+" "+p[2]]; ok {
+				duplicated = append(duplicated, p)
+			}
+		}
+		if len(duplicated) == 0 {
+			continue
+		}
+		if _, err = e.RemovePolicies(duplicated); err != nil {
+			return removed, err
+		}
+		removed += len(duplicated)
+	}
+	return removed, nil
+}
@@ -0,0 +1,168 @@
+package system
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2/util"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+)
+
+//@function: SimulateCasbin
+//@description: 按照 CasbinHandler 的规则模拟一次请求, 返回各角色的判定结果和命中的策略
+//@param: req request.CasbinSimulate
+//@return: res systemRes.CasbinSimulateResponse, err error
+
+func (casbinService *CasbinService) SimulateCasbin(req request.CasbinSimulate) (res systemRes.CasbinSimulateResponse, err error) {
+	if req.Path == "" || req.Method == "" {
+		return res, errors.New("请求路径和方法不能为空")
+	}
+	e := casbinService.Casbin()
+	if e == nil {
+		return res, errors.New("Casbin 加载失败，请检查配置")
+	}
+	res.Path = strings.TrimPrefix(req.Path, global.GVA_CONFIG.System.RouterPrefix)
+	if !strings.HasPrefix(res.Path, "/") {
+		res.Path = "/" + res.Path
+	}
+	res.Method = strings.ToUpper(req.Method)
+
+	var current uint
+	var authorityIds []uint
+	// 多租户模式下按用户所属租户判定, 只指定角色时按角色所属租户判定
+	var userTenant *uint
+	if req.UserId != 0 {
+		var user system.SysUser
+		if err = global.GVA_DB.Preload("Authorities").First(&user, req.UserId).Error; err != nil {
+			return res, errors.New("用户不存在")
+		}
+		current = user.AuthorityId
+		userTenant = &user.TenantId
+		authorityIds = append(authorityIds, user.AuthorityId)
+		for _, a := range user.Authorities {
+			if a.AuthorityId != user.AuthorityId {
+				authorityIds = append(authorityIds, a.AuthorityId)
+			}
+		}
+	} else if req.AuthorityId != 0 {
+		current = req.AuthorityId
+		authorityIds = append(authorityIds, req.AuthorityId)
+	} else {
+		return res, errors.New("请指定用户或角色")
+	}
+
+	var authorities []system.SysAuthority
+	if err = global.GVA_DB.Where("authority_id in ?", authorityIds).Find(&authorities).Error; err != nil {
+		return res, err
+	}
+	names := make(map[uint]string, len(authorities))
+	tenants := make(map[uint]uint, len(authorities))
+	for _, a := range authorities {
+		names[a.AuthorityId] = a.AuthorityName
+		tenants[a.AuthorityId] = a.TenantId
+	}
+
+	for _, id := range authorityIds {
+		sub := strconv.Itoa(int(id))
+		result := systemRes.CasbinSimulateResult{
+			AuthorityId:   id,
+			AuthorityName: names[id],
+			Current:       id == current,
+		}
+		tenantId := tenants[id]
+		if userTenant != nil {
+			tenantId = *userTenant
+		}
+		result.Allowed, result.Matched, err = e.EnforceEx(casbinService.EnforceArgs(sub, tenantId, res.Path, res.Method)...)
+		if err != nil {
+			return res, err
+		}
+		policies, err := e.GetFilteredPolicy(0, sub)
+		if err != nil {
+			return res, err
+		}
+		for _, v := range casbinService.inheritedPolicyList(e, sub) {
+			policies = append(policies, []string{strconv.Itoa(int(v.InheritFrom)), v.Path, v.Method})
+		}
+		for _, p := range policies {
+			if len(p) > 2 && p[2] != res.Method && util.KeyMatch2(res.Path, p[1]) {
+				result.Candidates = append(result.Candidates, p)
+			}
+		}
+		switch {
+		case result.Allowed:
+			result.Reason = "命中策略 " + strings.Join(result.Matched, ", ")
+		case names[id] == "":
+			result.Reason = "角色不存在"
+		case len(policies) == 0:
+			result.Reason = "角色未分配任何api权限"
+		case len(result.Candidates) > 0:
+			result.Reason = "路径已授权, 但未授权 " + res.Method + " 方法"
+		default:
+			result.Reason = "角色缺少该接口的策略"
+		}
+		if result.Current {
+			res.Allowed = result.Allowed
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	// 逐个角色判定, 角色继承模式下子角色同样计入
+	var all []system.SysAuthority
+	if err = global.GVA_DB.Select("authority_id", "tenant_id").Find(&all).Error; err != nil {
+		return res, err
+	}
+	for _, a := range all {
+		ok, err := e.Enforce(casbinService.EnforceArgs(strconv.Itoa(int(a.AuthorityId)), a.TenantId, res.Path, res.Method)...)
+		if err != nil {
+			return res, err
+		}
+		if ok {
+			res.GrantedBy = append(res.GrantedBy, a.AuthorityId)
+		}
+	}
+
+	var count int64
+	err = global.GVA_DB.Model(&system.SysApi{}).Where("path = ? AND method = ?", res.Path, res.Method).Count(&count).Error
+	res.ApiRegistered = count > 0
+	return res, err
+}
+
+//@function: DiffCasbin
+//@description: 预览 UpdateCasbin 执行后角色将新增和移除的api权限, 不做任何修改
+//@param: adminAuthorityID, AuthorityID uint, casbinInfos []request.CasbinInfo
+//@return: res systemRes.CasbinDiffResponse, err error
+
+func (casbinService *CasbinService) DiffCasbin(adminAuthorityID, AuthorityID uint, casbinInfos []request.CasbinInfo) (res systemRes.CasbinDiffResponse, err error) {
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, AuthorityID); err != nil {
+		return res, err
+	}
+	existing := make(map[string]bool)
+	for _, v := range casbinService.GetPolicyPathByAuthorityId(AuthorityID) {
+		existing[v.Path+" "+v.Method] = true
+	}
+	seen := make(map[string]bool)
+	for _, v := range casbinInfos {
+		key := v.Path + " " + v.Method
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if existing[key] {
+			res.Unchanged++
+		} else {
+			res.Added = append(res.Added, v)
+		}
+	}
+	for _, v := range casbinService.GetPolicyPathByAuthorityId(AuthorityID) {
+		// 继承的权限不会因更新本角色而移除
+		if !v.Inherited && !seen[v.Path+" "+v.Method] {
+			res.Removed = append(res.Removed, v)
+		}
+	}
+	return res, nil
+}
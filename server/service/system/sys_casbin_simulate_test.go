@@ -0,0 +1,152 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCasbinSimulateTest 角色 888 拥有 GET /user/list 与 DELETE /user/:id, 9528 拥有 GET /report/list, 777 没有任何权限
+// 用户 1 当前角色为 888 同时拥有 9528
+func setupCasbinSimulateTest(t *testing.T) {
+	db := newCasbinTestDB(t)
+	assert.Nil(t, db.AutoMigrate(&system.SysApi{}))
+	old, oldTenant := global.GVA_CONFIG.System, global.GVA_CONFIG.Tenant
+	global.GVA_CONFIG.System.UseRoleInheritance = false
+	global.GVA_CONFIG.System.UseStrictAuth = false
+	global.GVA_CONFIG.System.RouterPrefix = "/api"
+	global.GVA_CONFIG.Tenant.Enable = false
+	t.Cleanup(func() { global.GVA_CONFIG.System, global.GVA_CONFIG.Tenant = old, oldTenant })
+
+	root := uint(0)
+	assert.Nil(t, db.Create(&[]system.SysAuthority{
+		{AuthorityId: 888, AuthorityName: "管理员", ParentId: &root},
+		{AuthorityId: 9528, AuthorityName: "测试", ParentId: &root},
+		{AuthorityId: 777, AuthorityName: "访客", ParentId: &root},
+	}).Error)
+	assert.Nil(t, db.Create(&system.SysUser{Username: "alice", AuthorityId: 888}).Error)
+	assert.Nil(t, db.Create(&[]system.SysUserAuthority{{SysUserId: 1, SysAuthorityAuthorityId: 888}, {SysUserId: 1, SysAuthorityAuthorityId: 9528}}).Error)
+	assert.Nil(t, db.Create(&system.SysApi{Path: "/user/list", Method: "GET"}).Error)
+	assert.Nil(t, CasbinServiceApp.UpdateCasbin(888, 888, []request.CasbinInfo{{Path: "/user/list", Method: "GET"}, {Path: "/user/:id", Method: "DELETE"}}))
+	assert.Nil(t, CasbinServiceApp.UpdateCasbin(888, 9528, []request.CasbinInfo{{Path: "/report/list", Method: "GET"}}))
+}
+
+func TestSimulateCasbinAuthority(t *testing.T) {
+	setupCasbinSimulateTest(t)
+
+	// 路由前缀和方法大小写按 CasbinHandler 的规则处理
+	res, err := CasbinServiceApp.SimulateCasbin(request.CasbinSimulate{AuthorityId: 888, Path: "/api/user/list", Method: "get"})
+	assert.Nil(t, err)
+	assert.Equal(t, "/user/list", res.Path)
+	assert.Equal(t, "GET", res.Method)
+	assert.True(t, res.Allowed)
+	assert.True(t, res.ApiRegistered)
+	assert.Equal(t, []uint{888}, res.GrantedBy)
+	require.Len(t, res.Results, 1)
+	assert.True(t, res.Results[0].Current)
+	assert.Equal(t, "管理员", res.Results[0].AuthorityName)
+	assert.Equal(t, []string{"888", "/user/list", "GET"}, res.Results[0].Matched)
+	assert.Equal(t, "命中策略 888, /user/list, GET", res.Results[0].Reason)
+
+	tests := []struct {
+		name       string
+		req        request.CasbinSimulate
+		reason     string
+		candidates [][]string
+		registered bool
+		grantedBy  []uint
+		wantErr    string
+	}{
+		{name: "缺少方法", req: request.CasbinSimulate{AuthorityId: 888, Path: "/user/5", Method: "GET"}, reason: "路径已授权, 但未授权 GET 方法", candidates: [][]string{{"888", "/user/:id", "DELETE"}}},
+		{name: "缺少策略", req: request.CasbinSimulate{AuthorityId: 9528, Path: "/user/list", Method: "GET"}, reason: "角色缺少该接口的策略", registered: true, grantedBy: []uint{888}},
+		{name: "没有任何权限", req: request.CasbinSimulate{AuthorityId: 777, Path: "/report/list", Method: "GET"}, reason: "角色未分配任何api权限", grantedBy: []uint{9528}},
+		{name: "角色不存在", req: request.CasbinSimulate{AuthorityId: 1234, Path: "/report/list", Method: "GET"}, reason: "角色不存在", grantedBy: []uint{9528}},
+		{name: "路径为空", req: request.CasbinSimulate{AuthorityId: 888, Method: "GET"}, wantErr: "请求路径和方法不能为空"},
+		{name: "未指定用户或角色", req: request.CasbinSimulate{Path: "/user/list", Method: "GET"}, wantErr: "请指定用户或角色"},
+		{name: "用户不存在", req: request.CasbinSimulate{UserId: 99, Path: "/user/list", Method: "GET"}, wantErr: "用户不存在"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := CasbinServiceApp.SimulateCasbin(tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, tt.registered, res.ApiRegistered)
+			assert.Equal(t, tt.grantedBy, res.GrantedBy)
+			require.Len(t, res.Results, 1)
+			assert.False(t, res.Results[0].Allowed)
+			assert.Equal(t, tt.reason, res.Results[0].Reason)
+			assert.Equal(t, tt.candidates, res.Results[0].Candidates)
+		})
+	}
+}
+
+func TestSimulateCasbinUser(t *testing.T) {
+	setupCasbinSimulateTest(t)
+
+	// 用户的其它角色有权限 但实际请求只按当前角色判定
+	res, err := CasbinServiceApp.SimulateCasbin(request.CasbinSimulate{UserId: 1, Path: "/report/list", Method: "GET"})
+	assert.Nil(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, []uint{9528}, res.GrantedBy)
+	require.Len(t, res.Results, 2)
+	assert.Equal(t, uint(888), res.Results[0].AuthorityId)
+	assert.True(t, res.Results[0].Current)
+	assert.False(t, res.Results[0].Allowed)
+	assert.Equal(t, uint(9528), res.Results[1].AuthorityId)
+	assert.False(t, res.Results[1].Current)
+	assert.True(t, res.Results[1].Allowed)
+}
+
+func TestDiffCasbin(t *testing.T) {
+	setupCasbinSimulateTest(t)
+
+	res, err := CasbinServiceApp.DiffCasbin(888, 888, []request.CasbinInfo{
+		{Path: "/user/list", Method: "GET"},
+		{Path: "/user/list", Method: "GET"},
+		{Path: "/report/list", Method: "GET"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []request.CasbinInfo{{Path: "/report/list", Method: "GET"}}, res.Added)
+	assert.Equal(t, []request.CasbinInfo{{Path: "/user/:id", Method: "DELETE"}}, res.Removed)
+	assert.Equal(t, 1, res.Unchanged)
+
+	// 预览不修改策略
+	policies, err := CasbinServiceApp.Casbin().GetFilteredPolicy(0, "888")
+	assert.Nil(t, err)
+	assert.Len(t, policies, 2)
+
+	res, err = CasbinServiceApp.DiffCasbin(888, 777, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, res.Added)
+	assert.Empty(t, res.Removed)
+	assert.Equal(t, 0, res.Unchanged)
+}
+
+func TestDiffCasbinInherited(t *testing.T) {
+	newCasbinTestDB(t)
+	old := global.GVA_CONFIG.System
+	global.GVA_CONFIG.System.UseRoleInheritance = true
+	global.GVA_CONFIG.System.UseStrictAuth = false
+	t.Cleanup(func() { global.GVA_CONFIG.System = old })
+
+	_, err := AuthorityServiceApp.CreateAuthority(authorityWithParent(100, 0))
+	assert.Nil(t, err)
+	_, err = AuthorityServiceApp.CreateAuthority(authorityWithParent(101, 100))
+	assert.Nil(t, err)
+	assert.Nil(t, CasbinServiceApp.UpdateCasbin(100, 100, []request.CasbinInfo{{Path: "/report/list", Method: "GET"}}))
+	assert.Nil(t, CasbinServiceApp.UpdateCasbin(101, 101, []request.CasbinInfo{{Path: "/a", Method: "GET"}}))
+
+	// 继承自上级的权限不会因更新本角色而移除
+	res, err := CasbinServiceApp.DiffCasbin(101, 101, []request.CasbinInfo{{Path: "/b", Method: "GET"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []request.CasbinInfo{{Path: "/b", Method: "GET"}}, res.Added)
+	assert.Equal(t, []request.CasbinInfo{{Path: "/a", Method: "GET"}}, res.Removed)
+}
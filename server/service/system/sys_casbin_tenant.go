@@ -0,0 +1,101 @@
+package system
+
+import (
+	"strconv"
+
+	"github.com/casbin/casbin/v2"
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"gorm.io/gorm"
+)
+
+// tenantDomainAll 全部租户共用的角色所在的域
+const tenantDomainAll = "*"
+
+// TenantDomain 租户对应的casbin域
+func TenantDomain(tenantId uint) string {
+	return "tenant:" + strconv.Itoa(int(tenantId))
+}
+
+//@function: EnforceArgs
+//@description: 组装 Enforce 参数, 开启多租户时在角色后带上租户域
+//@param: sub string, tenantId uint, obj string, act string
+//@return: []interface{}
+
+func (casbinService *CasbinService) EnforceArgs(sub string, tenantId uint, obj, act string) []interface{} {
+	if global.GVA_CONFIG.Tenant.Enable {
+		return []interface{}{sub, TenantDomain(tenantId), obj, act}
+	}
+	return []interface{}{sub, obj, act}
+}
+
+// tenantDomainRule 角色 -> 所属租户域的 g2 规则, 未归属租户的角色属于全部租户
+func tenantDomainRule(a system.SysAuthority) []string {
+	dom := tenantDomainAll
+	if a.TenantId != 0 {
+		dom = TenantDomain(a.TenantId)
+	}
+	return []string{strconv.Itoa(int(a.AuthorityId)), dom}
+}
+
+//@function: addTenantDomain
+//@description: 随角色一起写入其所属租户域的 g2 规则, 保证刷新失败或重启后角色仍归属正确的租户, 未开启多租户时不做处理
+//@param: db *gorm.DB, auth system.SysAuthority
+//@return: error
+
+func (casbinService *CasbinService) addTenantDomain(db *gorm.DB, auth system.SysAuthority) error {
+	if !global.GVA_CONFIG.Tenant.Enable {
+		return nil
+	}
+	rule := tenantDomainRule(auth)
+	return db.Create(&gormadapter.CasbinRule{Ptype: "g2", V0: rule[0], V1: rule[1]}).Error
+}
+
+//@function: syncTenantDomains
+//@description: 按照 SysAuthority.TenantId 重建 casbin g2 规则 角色 -> 租户域, 未开启多租户时不做处理
+//@param: e *casbin.SyncedCachedEnforcer
+//@return: error
+
+func (casbinService *CasbinService) syncTenantDomains(e *casbin.SyncedCachedEnforcer) error {
+	if !global.GVA_CONFIG.Tenant.Enable {
+		return nil
+	}
+	var authorities []system.SysAuthority
+	if err := global.GVA_DB.Select("authority_id", "tenant_id").Find(&authorities).Error; err != nil {
+		return err
+	}
+	want := make(map[string][]string, len(authorities))
+	for _, a := range authorities {
+		rule := tenantDomainRule(a)
+		want[rule[0]+" "+rule[1]] = rule
+	}
+	existing, err := e.GetNamedGroupingPolicy("g2")
+	if err != nil {
+		return err
+	}
+	var stale [][]string
+	for _, g := range existing {
+		key := g[0] + " " + g[1]
+		if _, ok := want[key]; ok {
+			delete(want, key)
+		} else {
+			stale = append(stale, g)
+		}
+	}
+	if len(stale) > 0 {
+		if _, err = e.RemoveNamedGroupingPolicies("g2", stale); err != nil {
+			return err
+		}
+	}
+	if len(want) > 0 {
+		rules := make([][]string, 0, len(want))
+		for _, v := range want {
+			rules = append(rules, v)
+		}
+		if _, err = e.AddNamedGroupingPolicies("g2", rules); err != nil {
+			return err
+		}
+	}
+	return e.InvalidateCache()
+}
@@ -0,0 +1,275 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DataScopeService struct{}
+
+var DataScopeServiceApp = new(DataScopeService)
+
+var (
+	dataScopeMu    sync.RWMutex
+	dataScopeRules map[uint]map[string]system.SysDataScope // 角色ID -> 表名 -> 规则 nil表示未加载
+
+	// DataScopeDeptUsers 返回与该用户同部门的用户ID 由部门模块注册 未注册时本部门范围退化为仅本人
+	DataScopeDeptUsers func(userId uint) ([]uint, error)
+)
+
+// dataScopeIdentifier 表名和字段名 只允许字母、数字和下划线
+var dataScopeIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var dataScopes = map[string]struct{}{
+	system.DataScopeAll:               {},
+	system.DataScopeSelf:              {},
+	system.DataScopeDept:              {},
+	system.DataScopeAuthorityChildren: {},
+	system.DataScopeCustom:            {},
+}
+
+//@function: SetDataScope
+//@description: 新增或更新角色在某张表上的数据范围
+//@param: adminAuthorityID uint, rule system.SysDataScope
+//@return: err error
+
+func (dataScopeService *DataScopeService) SetDataScope(adminAuthorityID uint, rule system.SysDataScope) (err error) {
+	if _, ok := dataScopes[rule.Scope]; !ok {
+		return fmt.Errorf("不支持的数据范围: %s", rule.Scope)
+	}
+	if rule.Table == "" {
+		return errors.New("表名不能为空")
+	}
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, rule.AuthorityId); err != nil {
+		return err
+	}
+	if err = checkDataScopeColumn(rule.Table, rule.Column); err != nil {
+		return err
+	}
+	var old system.SysDataScope
+	err = global.GVA_DB.Where("authority_id = ? AND table_name = ?", rule.AuthorityId, rule.Table).First(&old).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = global.GVA_DB.Create(&rule).Error
+	case err == nil:
+		err = global.GVA_DB.Model(&old).Updates(map[string]interface{}{"scope": rule.Scope, "column": rule.Column}).Error
+	}
+	resetDataScopeRules()
+	if err == nil {
+		authorityChanged(rule.AuthorityId, system.AuthorityDataScope)
+	}
+	return err
+}
+
+//@function: DeleteDataScope
+//@description: 删除数据范围规则 删除后该表恢复为不限制
+//@param: adminAuthorityID uint, id uint
+//@return: err error
+
+func (dataScopeService *DataScopeService) DeleteDataScope(adminAuthorityID uint, id uint) (err error) {
+	var rule system.SysDataScope
+	if err = global.GVA_DB.First(&rule, id).Error; err != nil {
+		return err
+	}
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, rule.AuthorityId); err != nil {
+		return err
+	}
+	err = global.GVA_DB.Unscoped().Delete(&rule).Error
+	resetDataScopeRules()
+	if err == nil {
+		authorityChanged(rule.AuthorityId, system.AuthorityDataScope)
+	}
+	return err
+}
+
+//@function: GetDataScopes
+//@description: 获取角色配置的数据范围规则
+//@param: adminAuthorityID uint, authorityId uint
+//@return: list []system.SysDataScope, err error
+
+func (dataScopeService *DataScopeService) GetDataScopes(adminAuthorityID, authorityId uint) (list []system.SysDataScope, err error) {
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, authorityId); err != nil {
+		return nil, err
+	}
+	err = global.GVA_DB.Where("authority_id = ?", authorityId).Order("table_name").Find(&list).Error
+	return list, err
+}
+
+// checkDataScopeColumn 规则中的表和字段会拼入查询 必须是已存在的表和字段
+// * 规则的字段在查询时按表检查 不存在该字段的表不做限制
+func checkDataScopeColumn(table, column string) error {
+	if column == "" {
+		column = "created_by"
+	}
+	if !dataScopeIdentifier.MatchString(column) {
+		return fmt.Errorf("字段名不合法: %s", column)
+	}
+	if table == system.DataScopeAllTables {
+		return nil
+	}
+	if !dataScopeIdentifier.MatchString(table) {
+		return fmt.Errorf("表名不合法: %s", table)
+	}
+	migrator := global.GVA_DB.Migrator()
+	if !migrator.HasTable(table) {
+		return fmt.Errorf("表 %s 不存在", table)
+	}
+	if !migrator.HasColumn(table, column) {
+		return fmt.Errorf("表 %s 中不存在字段 %s", table, column)
+	}
+	return nil
+}
+
+func resetDataScopeRules() {
+	dataScopeMu.Lock()
+	dataScopeRules = nil
+	dataScopeMu.Unlock()
+}
+
+// getDataScopeRule 按角色和表名获取规则 未单独配置时使用该角色的 * 规则
+func getDataScopeRule(authorityId uint, table string) (system.SysDataScope, bool) {
+	dataScopeMu.RLock()
+	rules := dataScopeRules
+	dataScopeMu.RUnlock()
+	if rules == nil {
+		var list []system.SysDataScope
+		if err := global.GVA_DB.Find(&list).Error; err != nil {
+			return system.SysDataScope{}, false
+		}
+		rules = make(map[uint]map[string]system.SysDataScope)
+		for _, r := range list {
+			if rules[r.AuthorityId] == nil {
+				rules[r.AuthorityId] = make(map[string]system.SysDataScope)
+			}
+			rules[r.AuthorityId][r.Table] = r
+		}
+		dataScopeMu.Lock()
+		dataScopeRules = rules
+		dataScopeMu.Unlock()
+	}
+	if rule, ok := rules[authorityId][table]; ok {
+		return rule, true
+	}
+	rule, ok := rules[authorityId][system.DataScopeAllTables]
+	return rule, ok
+}
+
+// DataScope 行级数据权限 GORM scope
+// 根据 ctx 中的用户角色和查询的表名应用数据范围 用法: db.Scopes(DataScope(ctx))
+// ctx 中没有用户信息(定时任务等内部调用)或角色未配置规则时不做限制
+// 同时将 ctx 带入语句 开启多租户时由租户回调按 ctx 中的租户过滤
+func DataScope(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if ctx != nil {
+			db.Statement.Context = ctx
+		}
+		claims := utils.GetClaimsFromContext(ctx)
+		if claims == nil {
+			return db
+		}
+		table := dataScopeTable(db)
+		if table == "" {
+			return db
+		}
+		rule, ok := getDataScopeRule(claims.AuthorityId, table)
+		if !ok || rule.Scope == system.DataScopeAll {
+			return db
+		}
+		name := rule.Column
+		if name == "" {
+			name = "created_by"
+		}
+		// * 规则只作用于存在创建者字段的表
+		if rule.Table == system.DataScopeAllTables && !dataScopeHasColumn(db, table, name) {
+			return db
+		}
+		column := clause.Column{Table: table, Name: name}
+
+		switch rule.Scope {
+		case system.DataScopeDept:
+			if DataScopeDeptUsers != nil {
+				ids, err := DataScopeDeptUsers(claims.BaseClaims.ID)
+				if err != nil {
+					_ = db.AddError(err)
+					return db
+				}
+				return db.Where("? IN ?", column, append(ids, claims.BaseClaims.ID))
+			}
+		case system.DataScopeAuthorityChildren:
+			ids, err := authorityWithChildren(claims.AuthorityId)
+			if err != nil {
+				_ = db.AddError(err)
+				return db
+			}
+			return db.Where("? IN (?)", column, authorityUsers(ids))
+		case system.DataScopeCustom:
+			auth, err := AuthorityServiceApp.GetAuthorityInfo(system.SysAuthority{AuthorityId: claims.AuthorityId})
+			if err != nil {
+				_ = db.AddError(err)
+				return db
+			}
+			ids := make([]uint, 0, len(auth.DataAuthorityId))
+			for _, v := range auth.DataAuthorityId {
+				ids = append(ids, v.AuthorityId)
+			}
+			if len(ids) == 0 {
+				break
+			}
+			return db.Where("? IN (?)", column, authorityUsers(ids))
+		}
+		return db.Where("? = ?", column, claims.BaseClaims.ID)
+	}
+}
+
+// dataScopeTable 获取当前语句操作的表名
+func dataScopeTable(db *gorm.DB) string {
+	stmt := db.Statement
+	if stmt.Table != "" {
+		return stmt.Table
+	}
+	model := stmt.Model
+	if model == nil {
+		model = stmt.Dest
+	}
+	if model == nil || stmt.Parse(model) != nil {
+		return ""
+	}
+	return stmt.Table
+}
+
+func dataScopeHasColumn(db *gorm.DB, table, column string) bool {
+	if db.Statement.Schema != nil && db.Statement.Schema.Table == table {
+		return db.Statement.Schema.LookUpField(column) != nil
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Migrator().HasColumn(table, column)
+}
+
+// authorityWithChildren 角色及其全部子孙角色ID
+func authorityWithChildren(authorityId uint) ([]uint, error) {
+	ids := []uint{authorityId}
+	parents := []uint{authorityId}
+	for len(parents) > 0 {
+		var children []uint
+		err := global.GVA_DB.Model(&system.SysAuthority{}).Where("parent_id IN ?", parents).Pluck("authority_id", &children).Error
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		parents = children
+	}
+	return ids, nil
+}
+
+// authorityUsers 拥有这些角色的用户ID子查询
+func authorityUsers(authorityIds []uint) *gorm.DB {
+	return global.GVA_DB.Model(&system.SysUserAuthority{}).Select("sys_user_id").Where("sys_authority_authority_id IN ?", authorityIds)
+}
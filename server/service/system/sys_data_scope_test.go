@@ -0,0 +1,105 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type dataScopeRecord struct {
+	ID        uint
+	Name      string
+	CreatedBy uint
+}
+
+func TestSetDataScopeValidation(t *testing.T) {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysDataScope{}, &dataScopeRecord{})
+	t.Cleanup(resetDataScopeRules)
+	changed := recordEvents(t, system.EventAuthorityChanged)
+
+	tests := []struct {
+		name    string
+		rule    system.SysDataScope
+		wantErr string
+	}{
+		{name: "默认字段", rule: system.SysDataScope{AuthorityId: 888, Table: "data_scope_records", Scope: system.DataScopeSelf}},
+		{name: "全部表", rule: system.SysDataScope{AuthorityId: 888, Table: system.DataScopeAllTables, Scope: system.DataScopeSelf, Column: "owner_id"}},
+		{name: "注入字段", rule: system.SysDataScope{AuthorityId: 888, Table: "data_scope_records", Scope: system.DataScopeSelf, Column: "created_by = created_by OR 1"}, wantErr: "字段名不合法: created_by = created_by OR 1"},
+		{name: "全部表注入字段", rule: system.SysDataScope{AuthorityId: 888, Table: system.DataScopeAllTables, Scope: system.DataScopeSelf, Column: "id) OR (1=1"}, wantErr: "字段名不合法: id) OR (1=1"},
+		{name: "注入表名", rule: system.SysDataScope{AuthorityId: 888, Table: "sys_users;--", Scope: system.DataScopeSelf}, wantErr: "表名不合法: sys_users;--"},
+		{name: "表不存在", rule: system.SysDataScope{AuthorityId: 888, Table: "missing", Scope: system.DataScopeSelf}, wantErr: "表 missing 不存在"},
+		{name: "字段不存在", rule: system.SysDataScope{AuthorityId: 888, Table: "data_scope_records", Scope: system.DataScopeSelf, Column: "owner_id"}, wantErr: "表 data_scope_records 中不存在字段 owner_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DataScopeServiceApp.SetDataScope(888, tt.rule)
+			if tt.wantErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	// 只有保存成功的规则发布角色变更事件 删除同样发布
+	scopeChanged := system.AuthorityEvent{AuthorityId: 888, Action: system.AuthorityDataScope}
+	assert.Equal(t, []system.AuthorityEvent{scopeChanged, scopeChanged}, *changed)
+	var rule system.SysDataScope
+	assert.Nil(t, db.First(&rule).Error)
+	assert.Nil(t, DataScopeServiceApp.DeleteDataScope(888, rule.ID))
+	assert.Len(t, *changed, 3)
+}
+
+func TestGetDataScopesStrictAuth(t *testing.T) {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysDataScope{})
+	t.Cleanup(resetDataScopeRules)
+	old := global.GVA_CONFIG.System.UseStrictAuth
+	global.GVA_CONFIG.System.UseStrictAuth = true
+	t.Cleanup(func() { global.GVA_CONFIG.System.UseStrictAuth = old })
+
+	root, child := uint(0), uint(888)
+	assert.Nil(t, db.Create(&[]system.SysAuthority{
+		{AuthorityId: 888, AuthorityName: "admin", ParentId: &root},
+		{AuthorityId: 8881, AuthorityName: "child", ParentId: &child},
+		{AuthorityId: 9528, AuthorityName: "other", ParentId: &root},
+	}).Error)
+	assert.Nil(t, db.Create(&system.SysDataScope{AuthorityId: 888, Table: system.DataScopeAllTables, Scope: system.DataScopeSelf}).Error)
+
+	list, err := DataScopeServiceApp.GetDataScopes(888, 888)
+	assert.Nil(t, err)
+	assert.Len(t, list, 1)
+	_, err = DataScopeServiceApp.GetDataScopes(888, 8881)
+	assert.Nil(t, err)
+	_, err = DataScopeServiceApp.GetDataScopes(9528, 888)
+	assert.EqualError(t, err, "您提交的角色ID不合法")
+}
+
+func TestDataScopeSelf(t *testing.T) {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysDataScope{}, &dataScopeRecord{})
+	t.Cleanup(resetDataScopeRules)
+	assert.Nil(t, db.Create(&[]dataScopeRecord{{Name: "a", CreatedBy: 1}, {Name: "b", CreatedBy: 2}, {Name: "c", CreatedBy: 1}}).Error)
+	assert.Nil(t, DataScopeServiceApp.SetDataScope(888, system.SysDataScope{AuthorityId: 888, Table: "data_scope_records", Scope: system.DataScopeSelf}))
+
+	ctx := utils.ContextWithClaims(context.Background(), &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{ID: 1, AuthorityId: 888}})
+	var list []dataScopeRecord
+	assert.Nil(t, db.Scopes(DataScope(ctx)).Order("id").Find(&list).Error)
+	require.Len(t, list, 2)
+	assert.Equal(t, []string{"a", "c"}, []string{list[0].Name, list[1].Name})
+
+	// 没有用户信息时不限制
+	assert.Nil(t, db.Scopes(DataScope(context.Background())).Find(&list).Error)
+	assert.Len(t, list, 3)
+
+	// 字段名作为标识符引用 不会被当作SQL片段
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(DataScope(ctx)).Find(&[]dataScopeRecord{})
+	})
+	assert.Contains(t, sql, "`data_scope_records`.`created_by` = 1")
+}
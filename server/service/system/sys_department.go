@@ -0,0 +1,384 @@
+package system
+
+import (
+	"context"
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"gorm.io/gorm"
+)
+
+type DepartmentService struct{}
+
+var DepartmentServiceApp = new(DepartmentService)
+
+func init() {
+	// 行级数据权限的"本部门"范围
+	DataScopeDeptUsers = DepartmentServiceApp.GetColleagueIds
+}
+
+//@function: CreateDepartment
+//@description: 创建部门
+//@param: dept *system.SysDepartment
+//@return: err error
+
+func (departmentService *DepartmentService) CreateDepartment(dept *system.SysDepartment) (err error) {
+	if dept.ParentId != 0 {
+		if err = global.GVA_DB.First(&system.SysDepartment{}, dept.ParentId).Error; err != nil {
+			return errors.New("父部门不存在")
+		}
+	}
+	if !errors.Is(global.GVA_DB.Where("parent_id = ? AND name = ?", dept.ParentId, dept.Name).First(&system.SysDepartment{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("同级下已存在同名部门")
+	}
+	return global.GVA_DB.Create(dept).Error
+}
+
+//@function: UpdateDepartment
+//@description: 更新部门名称和排序 调整层级请使用 MoveDepartment
+//@param: dept system.SysDepartment
+//@return: err error
+
+func (departmentService *DepartmentService) UpdateDepartment(dept system.SysDepartment) (err error) {
+	var old system.SysDepartment
+	if err = global.GVA_DB.First(&old, dept.ID).Error; err != nil {
+		return err
+	}
+	if !errors.Is(global.GVA_DB.Where("parent_id = ? AND name = ? AND id <> ?", old.ParentId, dept.Name, dept.ID).First(&system.SysDepartment{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("同级下已存在同名部门")
+	}
+	return global.GVA_DB.Model(&old).Updates(map[string]interface{}{"name": dept.Name, "sort": dept.Sort}).Error
+}
+
+//@function: DeleteDepartment
+//@description: 删除部门 存在子部门或成员时不允许删除
+//@param: id uint
+//@return: err error
+
+func (departmentService *DepartmentService) DeleteDepartment(id uint) (err error) {
+	var count int64
+	if err = global.GVA_DB.Model(&system.SysDepartment{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("此部门存在子部门不允许删除")
+	}
+	if err = global.GVA_DB.Model(&system.SysUserDepartment{}).Where("department_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("此部门存在成员不允许删除")
+	}
+	return global.GVA_DB.Delete(&system.SysDepartment{}, id).Error
+}
+
+//@function: MoveDepartment
+//@description: 移动部门到新的父部门下 不能移动到自身或子部门下
+//@param: req systemReq.MoveDepartment
+//@return: err error
+
+func (departmentService *DepartmentService) MoveDepartment(req systemReq.MoveDepartment) (err error) {
+	if err = global.GVA_DB.First(&system.SysDepartment{}, req.ID).Error; err != nil {
+		return errors.New("部门不存在")
+	}
+	if req.ParentId != 0 {
+		if err = global.GVA_DB.First(&system.SysDepartment{}, req.ParentId).Error; err != nil {
+			return errors.New("父部门不存在")
+		}
+		ids, err := departmentService.GetDepartmentIdsWithChildren(req.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if id == req.ParentId {
+				return errors.New("不能移动到自身或子部门下")
+			}
+		}
+	}
+	return global.GVA_DB.Model(&system.SysDepartment{}).Where("id = ?", req.ID).Update("parent_id", req.ParentId).Error
+}
+
+//@function: MergeDepartment
+//@description: 合并部门 源部门的子部门和成员并入目标部门 随后删除源部门
+//@param: req systemReq.MergeDepartment
+//@return: err error
+
+func (departmentService *DepartmentService) MergeDepartment(req systemReq.MergeDepartment) (err error) {
+	if req.SourceId == req.TargetId {
+		return errors.New("源部门和目标部门不能相同")
+	}
+	ids, err := departmentService.GetDepartmentIdsWithChildren(req.SourceId)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if id == req.TargetId {
+			return errors.New("不能合并到源部门的子部门")
+		}
+	}
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&system.SysDepartment{}, req.TargetId).Error; err != nil {
+			return errors.New("目标部门不存在")
+		}
+		if err := tx.Model(&system.SysDepartment{}).Where("parent_id = ?", req.SourceId).Update("parent_id", req.TargetId).Error; err != nil {
+			return err
+		}
+		var members []system.SysUserDepartment
+		if err := tx.Where("department_id = ?", req.SourceId).Find(&members).Error; err != nil {
+			return err
+		}
+		for _, m := range members {
+			var exist system.SysUserDepartment
+			err := tx.Where("user_id = ? AND department_id = ?", m.UserId, req.TargetId).First(&exist).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err = tx.Model(&m).Update("department_id", req.TargetId).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			// 已是目标部门成员 合并主部门和负责人标记
+			err = tx.Model(&exist).Updates(map[string]interface{}{
+				"is_primary": exist.IsPrimary || m.IsPrimary,
+				"is_leader":  exist.IsLeader || m.IsLeader,
+			}).Error
+			if err != nil {
+				return err
+			}
+			if err = tx.Delete(&m).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&system.SysDepartment{}, req.SourceId).Error
+	})
+}
+
+//@function: GetDepartmentTree
+//@description: 获取部门树 附带各部门负责人
+//@param: ctx context.Context
+//@return: list []system.SysDepartment, err error
+
+func (departmentService *DepartmentService) GetDepartmentTree(ctx context.Context) (list []system.SysDepartment, err error) {
+	if err = global.GVA_DB.Where("parent_id = ?", 0).Order("sort").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for k := range list {
+		if err = departmentService.findChildrenDepartment(ctx, &list[k]); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+//@function: findChildrenDepartment
+//@description: 查询子部门
+//@param: ctx context.Context, dept *system.SysDepartment
+//@return: err error
+
+func (departmentService *DepartmentService) findChildrenDepartment(ctx context.Context, dept *system.SysDepartment) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Where("id IN (?)", global.GVA_DB.Model(&system.SysUserDepartment{}).Select("user_id").
+		Where("department_id = ? AND is_leader = ?", dept.ID, true)).Find(&dept.Leaders).Error
+	if err != nil {
+		return err
+	}
+	if err = global.GVA_DB.Where("parent_id = ?", dept.ID).Order("sort").Find(&dept.Children).Error; err != nil {
+		return err
+	}
+	for k := range dept.Children {
+		if err = departmentService.findChildrenDepartment(ctx, &dept.Children[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//@function: GetDepartmentIdsWithChildren
+//@description: 获取部门及其全部子部门ID
+//@param: id uint
+//@return: ids []uint, err error
+
+func (departmentService *DepartmentService) GetDepartmentIdsWithChildren(id uint) (ids []uint, err error) {
+	ids = []uint{id}
+	parents := []uint{id}
+	for len(parents) > 0 {
+		var children []uint
+		if err = global.GVA_DB.Model(&system.SysDepartment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		parents = children
+	}
+	return ids, nil
+}
+
+//@function: SetUserDepartments
+//@description: 设置用户的主部门和兼职部门 保留在仍所属部门中的负责人身份
+//@param: ctx context.Context, req systemReq.SetUserDepartments
+//@return: err error
+
+func (departmentService *DepartmentService) SetUserDepartments(ctx context.Context, req systemReq.SetUserDepartments) (err error) {
+	deptIds := make([]uint, 0, len(req.DepartmentIds)+1)
+	if req.PrimaryId != 0 {
+		deptIds = append(deptIds, req.PrimaryId)
+	}
+	for _, id := range req.DepartmentIds {
+		if id != 0 && id != req.PrimaryId {
+			deptIds = append(deptIds, id)
+		}
+	}
+	// 按请求上下文中的租户查询 不能设置其他租户的用户
+	if err = global.GVA_DB.WithContext(ctx).Where("id = ?", req.UserId).First(&system.SysUser{}).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	var count int64
+	if len(deptIds) > 0 {
+		if err = global.GVA_DB.Model(&system.SysDepartment{}).Where("id IN ?", deptIds).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(deptIds) {
+			return errors.New("部门不存在")
+		}
+	}
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var old []system.SysUserDepartment
+		if err := tx.Where("user_id = ?", req.UserId).Find(&old).Error; err != nil {
+			return err
+		}
+		leaders := make(map[uint]bool, len(old))
+		for _, v := range old {
+			leaders[v.DepartmentId] = v.IsLeader
+		}
+		if err := tx.Delete(&[]system.SysUserDepartment{}, "user_id = ?", req.UserId).Error; err != nil {
+			return err
+		}
+		if len(deptIds) == 0 {
+			return nil
+		}
+		members := make([]system.SysUserDepartment, 0, len(deptIds))
+		for _, id := range deptIds {
+			members = append(members, system.SysUserDepartment{
+				UserId:       req.UserId,
+				DepartmentId: id,
+				IsPrimary:    id == req.PrimaryId,
+				IsLeader:     leaders[id],
+			})
+		}
+		return tx.Create(&members).Error
+	})
+}
+
+//@function: SetDepartmentLeaders
+//@description: 设置部门负责人 负责人必须是该部门成员
+//@param: ctx context.Context, req systemReq.SetDepartmentLeaders
+//@return: err error
+
+func (departmentService *DepartmentService) SetDepartmentLeaders(ctx context.Context, req systemReq.SetDepartmentLeaders) (err error) {
+	// 只处理当前租户的成员
+	members := scopeTenantUsers(ctx, "user_id")
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if len(req.UserIds) > 0 {
+			var count int64
+			err := tx.Model(&system.SysUserDepartment{}).Scopes(members).Where("department_id = ? AND user_id IN ?", req.DepartmentId, req.UserIds).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if int(count) != len(req.UserIds) {
+				return errors.New("负责人必须是部门成员")
+			}
+		}
+		err := tx.Model(&system.SysUserDepartment{}).Scopes(members).Where("department_id = ?", req.DepartmentId).Update("is_leader", false).Error
+		if err != nil || len(req.UserIds) == 0 {
+			return err
+		}
+		return tx.Model(&system.SysUserDepartment{}).Scopes(members).Where("department_id = ? AND user_id IN ?", req.DepartmentId, req.UserIds).Update("is_leader", true).Error
+	})
+}
+
+//@function: GetDepartmentUsers
+//@description: 分页获取部门成员
+//@param: ctx context.Context, info systemReq.DepartmentUserSearch
+//@return: list []systemRes.DepartmentUser, total int64, err error
+
+func (departmentService *DepartmentService) GetDepartmentUsers(ctx context.Context, info systemReq.DepartmentUserSearch) (list []systemRes.DepartmentUser, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysUserDepartment{}).Scopes(scopeTenantUsers(ctx, "user_id")).Where("department_id = ?", info.DepartmentId)
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	var members []system.SysUserDepartment
+	if err = db.Scopes(info.Paginate()).Order("is_leader desc, id").Find(&members).Error; err != nil {
+		return
+	}
+	userIds := make([]uint, 0, len(members))
+	for _, m := range members {
+		userIds = append(userIds, m.UserId)
+	}
+	var users []system.SysUser
+	if err = global.GVA_DB.WithContext(ctx).Preload("Authorities").Preload("Authority").Where("id IN ?", userIds).Find(&users).Error; err != nil {
+		return
+	}
+	userMap := make(map[uint]system.SysUser, len(users))
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+	list = make([]systemRes.DepartmentUser, 0, len(members))
+	for _, m := range members {
+		if u, ok := userMap[m.UserId]; ok {
+			list = append(list, systemRes.DepartmentUser{SysUser: u, IsPrimary: m.IsPrimary, IsLeader: m.IsLeader})
+		}
+	}
+	return list, total, nil
+}
+
+//@function: GetUserDepartments
+//@description: 获取用户所属部门
+//@param: userId uint
+//@return: list []system.SysUserDepartment, err error
+
+func (departmentService *DepartmentService) GetUserDepartments(userId uint) (list []system.SysUserDepartment, err error) {
+	err = global.GVA_DB.Where("user_id = ?", userId).Order("is_primary desc, id").Find(&list).Error
+	return list, err
+}
+
+//@function: GetColleagueIds
+//@description: 获取与用户同部门(含兼职部门)的全部用户ID
+//@param: userId uint
+//@return: ids []uint, err error
+
+func (departmentService *DepartmentService) GetColleagueIds(userId uint) (ids []uint, err error) {
+	err = global.GVA_DB.Model(&system.SysUserDepartment{}).Distinct("user_id").
+		Where("department_id IN (?)", global.GVA_DB.Model(&system.SysUserDepartment{}).Select("department_id").Where("user_id = ?", userId)).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+//@function: GetUserLeaders
+//@description: 获取用户主部门的负责人 主部门没有负责人时逐级向上查找
+//@param: userId uint
+//@return: leaders []uint, err error
+
+func (departmentService *DepartmentService) GetUserLeaders(userId uint) (leaders []uint, err error) {
+	var primary system.SysUserDepartment
+	if err = global.GVA_DB.Where("user_id = ? AND is_primary = ?", userId, true).First(&primary).Error; err != nil {
+		return nil, err
+	}
+	deptId := primary.DepartmentId
+	for depth := 0; deptId != 0 && depth < 32; depth++ {
+		leaders = leaders[:0]
+		err = global.GVA_DB.Model(&system.SysUserDepartment{}).
+			Where("department_id = ? AND is_leader = ? AND user_id <> ?", deptId, true, userId).Pluck("user_id", &leaders).Error
+		if err != nil || len(leaders) > 0 {
+			return leaders, err
+		}
+		var dept system.SysDepartment
+		if err = global.GVA_DB.First(&dept, deptId).Error; err != nil {
+			return nil, err
+		}
+		deptId = dept.ParentId
+	}
+	return leaders, nil
+}
@@ -0,0 +1,136 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupDepartmentTest 创建部门树 总部(1) > 研发(2) > 后端(3), 销售(4)
+func setupDepartmentTest(t *testing.T) *gorm.DB {
+	db := newTestDB(t, &system.SysDepartment{}, &system.SysUserDepartment{}, &system.SysUser{})
+	for _, d := range []system.SysDepartment{{Name: "总部"}, {Name: "研发", ParentId: 1}, {Name: "后端", ParentId: 2}, {Name: "销售", Sort: 1}} {
+		assert.Nil(t, DepartmentServiceApp.CreateDepartment(&d))
+	}
+	for _, name := range []string{"u1", "u2", "u3"} {
+		assert.Nil(t, db.Create(&system.SysUser{Username: name}).Error)
+	}
+	return db
+}
+
+func departmentParent(t *testing.T, db *gorm.DB, id uint) uint {
+	var dept system.SysDepartment
+	assert.Nil(t, db.First(&dept, id).Error)
+	return dept.ParentId
+}
+
+func TestMoveDepartment(t *testing.T) {
+	db := setupDepartmentTest(t)
+
+	tests := []struct {
+		name    string
+		req     systemReq.MoveDepartment
+		wantErr string
+	}{
+		{name: "部门不存在", req: systemReq.MoveDepartment{ID: 99, ParentId: 1}, wantErr: "部门不存在"},
+		{name: "部门不存在且移到顶级", req: systemReq.MoveDepartment{ID: 99}, wantErr: "部门不存在"},
+		{name: "父部门不存在", req: systemReq.MoveDepartment{ID: 2, ParentId: 99}, wantErr: "父部门不存在"},
+		{name: "移到自身下", req: systemReq.MoveDepartment{ID: 2, ParentId: 2}, wantErr: "不能移动到自身或子部门下"},
+		{name: "移到子部门下", req: systemReq.MoveDepartment{ID: 1, ParentId: 3}, wantErr: "不能移动到自身或子部门下"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, DepartmentServiceApp.MoveDepartment(tt.req), tt.wantErr)
+		})
+	}
+	assert.Equal(t, uint(1), departmentParent(t, db, 2))
+
+	assert.Nil(t, DepartmentServiceApp.MoveDepartment(systemReq.MoveDepartment{ID: 3, ParentId: 4}))
+	assert.Equal(t, uint(4), departmentParent(t, db, 3))
+	assert.Nil(t, DepartmentServiceApp.MoveDepartment(systemReq.MoveDepartment{ID: 2}))
+	assert.Equal(t, uint(0), departmentParent(t, db, 2))
+}
+
+func TestMergeDepartment(t *testing.T) {
+	db := setupDepartmentTest(t)
+	// u1 同时属于研发(主部门)和销售(负责人) u2 只属于研发
+	assert.Nil(t, DepartmentServiceApp.SetUserDepartments(context.Background(), systemReq.SetUserDepartments{UserId: 1, PrimaryId: 2, DepartmentIds: []uint{4}}))
+	assert.Nil(t, DepartmentServiceApp.SetDepartmentLeaders(context.Background(), systemReq.SetDepartmentLeaders{DepartmentId: 4, UserIds: []uint{1}}))
+	assert.Nil(t, DepartmentServiceApp.SetUserDepartments(context.Background(), systemReq.SetUserDepartments{UserId: 2, PrimaryId: 2}))
+
+	assert.EqualError(t, DepartmentServiceApp.MergeDepartment(systemReq.MergeDepartment{SourceId: 2, TargetId: 2}), "源部门和目标部门不能相同")
+	assert.EqualError(t, DepartmentServiceApp.MergeDepartment(systemReq.MergeDepartment{SourceId: 2, TargetId: 3}), "不能合并到源部门的子部门")
+	assert.EqualError(t, DepartmentServiceApp.MergeDepartment(systemReq.MergeDepartment{SourceId: 2, TargetId: 99}), "目标部门不存在")
+
+	assert.Nil(t, DepartmentServiceApp.MergeDepartment(systemReq.MergeDepartment{SourceId: 2, TargetId: 4}))
+	assert.ErrorIs(t, db.First(&system.SysDepartment{}, 2).Error, gorm.ErrRecordNotFound)
+	assert.Equal(t, uint(4), departmentParent(t, db, 3))
+
+	// 已是目标部门成员的合并主部门和负责人标记 其余成员直接并入
+	list, err := DepartmentServiceApp.GetUserDepartments(1)
+	assert.Nil(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, uint(4), list[0].DepartmentId)
+	assert.True(t, list[0].IsPrimary)
+	assert.True(t, list[0].IsLeader)
+	list, err = DepartmentServiceApp.GetUserDepartments(2)
+	assert.Nil(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, uint(4), list[0].DepartmentId)
+	assert.True(t, list[0].IsPrimary)
+	assert.False(t, list[0].IsLeader)
+}
+
+func TestGetUserLeaders(t *testing.T) {
+	setupDepartmentTest(t)
+	for userId, primary := range map[uint]uint{1: 1, 2: 3, 3: 3} {
+		assert.Nil(t, DepartmentServiceApp.SetUserDepartments(context.Background(), systemReq.SetUserDepartments{UserId: userId, PrimaryId: primary}))
+	}
+	assert.Nil(t, DepartmentServiceApp.SetDepartmentLeaders(context.Background(), systemReq.SetDepartmentLeaders{DepartmentId: 1, UserIds: []uint{1}}))
+
+	// 后端和研发都没有负责人 逐级向上找到总部
+	leaders, err := DepartmentServiceApp.GetUserLeaders(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1}, leaders)
+
+	// 负责人本人不是自己的上级
+	assert.Nil(t, DepartmentServiceApp.SetDepartmentLeaders(context.Background(), systemReq.SetDepartmentLeaders{DepartmentId: 3, UserIds: []uint{2}}))
+	leaders, err = DepartmentServiceApp.GetUserLeaders(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1}, leaders)
+	leaders, err = DepartmentServiceApp.GetUserLeaders(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{2}, leaders)
+	leaders, err = DepartmentServiceApp.GetUserLeaders(1)
+	assert.Nil(t, err)
+	assert.Empty(t, leaders)
+
+	// 没有主部门
+	_, err = DepartmentServiceApp.GetUserLeaders(99)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetDepartmentTree(t *testing.T) {
+	setupDepartmentTest(t)
+	assert.Nil(t, DepartmentServiceApp.SetUserDepartments(context.Background(), systemReq.SetUserDepartments{UserId: 1, PrimaryId: 2}))
+	assert.Nil(t, DepartmentServiceApp.SetDepartmentLeaders(context.Background(), systemReq.SetDepartmentLeaders{DepartmentId: 2, UserIds: []uint{1}}))
+	assert.EqualError(t, DepartmentServiceApp.SetDepartmentLeaders(context.Background(), systemReq.SetDepartmentLeaders{DepartmentId: 2, UserIds: []uint{2}}), "负责人必须是部门成员")
+
+	tree, err := DepartmentServiceApp.GetDepartmentTree(context.Background())
+	assert.Nil(t, err)
+	require.Len(t, tree, 2)
+	assert.Equal(t, "总部", tree[0].Name)
+	assert.Equal(t, "销售", tree[1].Name)
+	require.Len(t, tree[0].Children, 1)
+	dev := tree[0].Children[0]
+	assert.Equal(t, "研发", dev.Name)
+	require.Len(t, dev.Leaders, 1)
+	assert.Equal(t, "u1", dev.Leaders[0].Username)
+	require.Len(t, dev.Children, 1)
+	assert.Equal(t, "后端", dev.Children[0].Name)
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"errors"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
@@ -23,6 +24,9 @@ func (dictionaryService *DictionaryService) CreateSysDictionary(sysDictionary sy
 		return errors.New("存在相同的type，不允许创建")
 	}
 	err = global.GVA_DB.Create(&sysDictionary).Error
+	if err == nil {
+		dictionaryChanged(sysDictionary.ID, 0, system.DictionaryCreated)
+	}
 	return err
 }
 
@@ -46,7 +50,10 @@ func (dictionaryService *DictionaryService) DeleteSysDictionary(sysDictionary sy
 	}
 
 	if sysDictionary.SysDictionaryDetails != nil {
-		return global.GVA_DB.Where("sys_dictionary_id=?", sysDictionary.ID).Delete(sysDictionary.SysDictionaryDetails).Error
+		err = global.GVA_DB.Where("sys_dictionary_id=?", sysDictionary.ID).Delete(sysDictionary.SysDictionaryDetails).Error
+	}
+	if err == nil {
+		dictionaryChanged(sysDictionary.ID, 0, system.DictionaryDeleted)
 	}
 	return
 }
@@ -76,6 +83,9 @@ func (dictionaryService *DictionaryService) UpdateSysDictionary(sysDictionary *s
 		}
 	}
 	err = global.GVA_DB.Model(&dict).Updates(sysDictionaryMap).Error
+	if err == nil {
+		dictionaryChanged(dict.ID, 0, system.DictionaryUpdated)
+	}
 	return err
 }
 
@@ -110,3 +120,21 @@ func (dictionaryService *DictionaryService) GetSysDictionaryInfoList() (list int
 	err = global.GVA_DB.Find(&sysDictionarys).Error
 	return sysDictionarys, err
 }
+
+// dictionaryChanged 发布字典变更事件 字典详情变更时 dictionaryId 可为0
+func dictionaryChanged(dictionaryId, detailId uint, action string) {
+	db := global.GVA_DB.Unscoped()
+	if dictionaryId == 0 && detailId != 0 {
+		var detail system.SysDictionaryDetail
+		db.Select("sys_dictionary_id").Where("id = ?", detailId).Limit(1).Find(&detail)
+		dictionaryId = uint(detail.SysDictionaryID)
+	}
+	var dict system.SysDictionary
+	db.Select("type").Where("id = ?", dictionaryId).Limit(1).Find(&dict)
+	publishEvent(context.Background(), system.EventDictionaryChanged, system.DictionaryEvent{
+		DictionaryId: dictionaryId,
+		Type:         dict.Type,
+		DetailId:     detailId,
+		Action:       action,
+	})
+}
@@ -18,6 +18,9 @@ var DictionaryDetailServiceApp = new(DictionaryDetailService)
 
 func (dictionaryDetailService *DictionaryDetailService) CreateSysDictionaryDetail(sysDictionaryDetail system.SysDictionaryDetail) (err error) {
 	err = global.GVA_DB.Create(&sysDictionaryDetail).Error
+	if err == nil {
+		dictionaryChanged(uint(sysDictionaryDetail.SysDictionaryID), sysDictionaryDetail.ID, system.DictionaryCreated)
+	}
 	return err
 }
 
@@ -29,6 +32,9 @@ func (dictionaryDetailService *DictionaryDetailService) CreateSysDictionaryDetai
 
 func (dictionaryDetailService *DictionaryDetailService) DeleteSysDictionaryDetail(sysDictionaryDetail system.SysDictionaryDetail) (err error) {
 	err = global.GVA_DB.Delete(&sysDictionaryDetail).Error
+	if err == nil {
+		dictionaryChanged(uint(sysDictionaryDetail.SysDictionaryID), sysDictionaryDetail.ID, system.DictionaryDeleted)
+	}
 	return err
 }
 
@@ -40,6 +46,9 @@ func (dictionaryDetailService *DictionaryDetailService) DeleteSysDictionaryDetai
 
 func (dictionaryDetailService *DictionaryDetailService) UpdateSysDictionaryDetail(sysDictionaryDetail *system.SysDictionaryDetail) (err error) {
 	err = global.GVA_DB.Save(sysDictionaryDetail).Error
+	if err == nil {
+		dictionaryChanged(uint(sysDictionaryDetail.SysDictionaryID), sysDictionaryDetail.ID, system.DictionaryUpdated)
+	}
 	return err
 }
 
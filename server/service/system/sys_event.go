@@ -0,0 +1,22 @@
+package system
+
+import (
+	"context"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+	"go.uber.org/zap"
+)
+
+// publishEvent 发布事件, 订阅方的错误只记录日志不影响业务
+func publishEvent[T any](ctx context.Context, topic event.Topic[T], payload T) {
+	if err := event.Publish(ctx, global.GVA_EVENT, topic, payload); err != nil {
+		global.GVA_LOG.Error("事件处理失败!", zap.String("event", string(topic)), zap.Error(err))
+	}
+}
+
+// userCreated 发布用户创建事件 需在创建用户的事务提交后调用
+func userCreated(ctx context.Context, u system.SysUser) {
+	publishEvent(ctx, system.EventUserCreated, system.UserEvent{ID: u.ID, Username: u.Username, AuthorityId: u.AuthorityId})
+}
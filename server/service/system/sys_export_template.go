@@ -2,8 +2,10 @@ package system
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/url"
 	"strconv"
@@ -126,7 +128,7 @@ func (sysExportTemplateService *SysExportTemplateService) GetSysExportTemplateIn
 
 // ExportExcel 导出Excel
 // Author [piexlmax](https://github.com/piexlmax)
-func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID string, values url.Values) (file *bytes.Buffer, name string, err error) {
+func (sysExportTemplateService *SysExportTemplateService) ExportExcel(ctx context.Context, templateID string, values url.Values) (file *bytes.Buffer, name string, err error) {
 	var template system.SysExportTemplate
 	err = global.GVA_DB.Preload("Conditions").Preload("JoinTemplate").First(&template, "template_id = ?", templateID).Error
 	if err != nil {
@@ -153,6 +155,20 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 	if err != nil {
 		return nil, "", err
 	}
+	// 按导出人的角色应用字段权限 隐藏的列不查询 脱敏的列在写入时处理
+	var fieldRules map[string]string
+	if claims := utils.GetClaimsFromContext(ctx); claims != nil {
+		fieldRules = AuthorityFieldRules(claims.AuthorityId, template.TableName)
+	}
+	if len(fieldRules) > 0 {
+		visible := columns[:0]
+		for _, key := range columns {
+			if exportFieldRule(fieldRules, key) != utils.FieldHide {
+				visible = append(visible, key)
+			}
+		}
+		columns = visible
+	}
 	var tableTitle []string
 	var selectKeyFmt []string
 	for _, key := range columns {
@@ -173,7 +189,8 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 		}
 	}
 
-	db = db.Select(selects).Table(template.TableName)
+	// 按导出人的角色应用行级数据权限
+	db = db.Select(selects).Table(template.TableName).Scopes(DataScope(ctx))
 
 	filterDeleted := false
 
@@ -280,6 +297,7 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 	for _, exTable := range tableMap {
 		var row []string
 		for _, column := range columns {
+			mask := exportFieldRule(fieldRules, column) == utils.FieldMask
 			column = strings.ReplaceAll(column, "\"", "")
 			column = strings.ReplaceAll(column, "`", "")
 			if len(template.JoinTemplate) > 0 {
@@ -294,11 +312,16 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 				}
 			}
 			// 需要对时间类型特殊处理
+			var cell string
 			if t, ok := exTable[column].(time.Time); ok {
-				row = append(row, t.Format("2006-01-02 15:04:05"))
+				cell = t.Format("2006-01-02 15:04:05")
 			} else {
-				row = append(row, fmt.Sprintf("%v", exTable[column]))
+				cell = fmt.Sprintf("%v", exTable[column])
+			}
+			if mask {
+				cell = utils.MaskString(cell)
 			}
+			row = append(row, cell)
 		}
 		rows = append(rows, row)
 	}
@@ -319,6 +342,23 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 	return file, template.Name, nil
 }
 
+// exportFieldRule 获取导出列对应的字段权限 列可以是 table.column 或 expr as alias 形式
+func exportFieldRule(rules map[string]string, key string) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	key = strings.NewReplacer("\"", "", "`", "").Replace(key)
+	if action, ok := rules[key]; ok {
+		return action
+	}
+	if i := strings.LastIndex(key, " as "); i >= 0 {
+		key = strings.TrimSpace(key[i+4:])
+	} else if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	return rules[key]
+}
+
 // ExportTemplate 导出Excel模板
 // Author [piexlmax](https://github.com/piexlmax)
 func (sysExportTemplateService *SysExportTemplateService) ExportTemplate(templateID string) (file *bytes.Buffer, name string, err error) {
@@ -377,17 +417,21 @@ func (s *SysExportTemplateService) hasDeletedAtColumn(tableName string) bool {
 // ImportExcel 导入Excel
 // Author [piexlmax](https://github.com/piexlmax)
 func (sysExportTemplateService *SysExportTemplateService) ImportExcel(templateID string, file *multipart.FileHeader) (err error) {
-	var template system.SysExportTemplate
-	err = global.GVA_DB.First(&template, "template_id = ?", templateID).Error
+	src, err := file.Open()
 	if err != nil {
 		return err
 	}
+	defer src.Close()
+	return sysExportTemplateService.ImportExcelData(templateID, src)
+}
 
-	src, err := file.Open()
+// ImportExcelData 从文件内容导入Excel 供审批通过后执行导入使用
+func (sysExportTemplateService *SysExportTemplateService) ImportExcelData(templateID string, src io.Reader) (err error) {
+	var template system.SysExportTemplate
+	err = global.GVA_DB.First(&template, "template_id = ?", templateID).Error
 	if err != nil {
 		return err
 	}
-	defer src.Close()
 
 	f, err := excelize.OpenReader(src)
 	if err != nil {
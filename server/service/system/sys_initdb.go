@@ -122,6 +122,9 @@ func (initDBService *InitDBService) InitDB(conf request.InitDB) (err error) {
 
 	db := ctx.Value("db").(*gorm.DB)
 	global.GVA_DB = db
+	if err = RegisterTenantCallbacks(db); err != nil {
+		return err
+	}
 
 	if err = initHandler.InitTables(ctx, initializers); err != nil {
 		return err
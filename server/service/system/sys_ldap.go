@@ -0,0 +1,346 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/ldap"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LdapProvider 目录账号在 sys_user_identities 中的 provider
+const LdapProvider = "ldap"
+
+var (
+	ErrLdapDisabled     = errors.New("未开启LDAP认证")
+	ErrLdapNotProvision = errors.New("该目录账号未绑定本地用户")
+)
+
+// ldapDirectory 目录查询 测试时替换为内存实现
+type ldapDirectory interface {
+	Authenticate(username, password string) (*ldap.Entry, error)
+	Users() ([]ldap.Entry, error)
+}
+
+var newLdapDirectory = func(cfg config.Ldap) ldapDirectory {
+	return ldap.NewClient(cfg)
+}
+
+type LdapService struct{}
+
+var LdapServiceApp = new(LdapService)
+
+//@function: Login
+//@description: 使用目录账号登录 目录中不存在的用户、目录不可用时未绑定目录的用户回退到本地账号登录
+//@param: u *system.SysUser
+//@return: userInter *system.SysUser, err error
+
+func (ldapService *LdapService) Login(u *system.SysUser) (userInter *system.SysUser, err error) {
+	cfg := global.GVA_CONFIG.Ldap
+	if !cfg.Enable {
+		return nil, ErrLdapDisabled
+	}
+	if nil == global.GVA_DB {
+		return nil, fmt.Errorf("db not init")
+	}
+	entry, err := newLdapDirectory(cfg).Authenticate(u.Username, u.Password)
+	if errors.Is(err, ldap.ErrUserNotFound) {
+		return UserServiceApp.Login(u)
+	}
+	if err != nil && !errors.Is(err, ldap.ErrInvalidPassword) {
+		// 目录不可用时 内置管理员等本地账号仍需能登录 已绑定目录的账号没有可用的本地密码
+		var count int64
+		if e := global.GVA_DB.Model(&system.SysUserIdentity{}).Where("provider = ? AND subject = ?", LdapProvider, u.Username).Count(&count).Error; e != nil {
+			return nil, e
+		}
+		if count == 0 {
+			global.GVA_LOG.Warn("LDAP不可用, 回退到本地账号登录", zap.String("username", u.Username), zap.Error(err))
+			return UserServiceApp.Login(u)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var user system.SysUser
+	var created bool
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var identity system.SysUserIdentity
+		e := tx.Where("provider = ? AND subject = ?", LdapProvider, entry.Username).First(&identity).Error
+		if e != nil && !errors.Is(e, gorm.ErrRecordNotFound) {
+			return e
+		}
+		authorityIds := mapGroupAuthorities(cfg.GroupMapping, entry.Groups)
+		if errors.Is(e, gorm.ErrRecordNotFound) {
+			if !cfg.AutoProvision {
+				return ErrLdapNotProvision
+			}
+			if !errors.Is(tx.Where("username = ?", entry.Username).First(&system.SysUser{}).Error, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("用户名 %s 已被本地账号占用", entry.Username)
+			}
+			user, e = ldapService.createUser(tx, *entry, authorityIds)
+			created = e == nil
+			return e
+		}
+		if e = tx.Where("id = ?", identity.UserId).First(&user).Error; e != nil {
+			return e
+		}
+		if changes := ldapProfileChanges(user, *entry); len(changes) > 0 {
+			if e = tx.Model(&system.SysUser{}).Where("id = ?", user.ID).Updates(changes).Error; e != nil {
+				return e
+			}
+		}
+		if len(authorityIds) == 0 {
+			return nil
+		}
+		return syncUserAuthorities(tx, &user, authorityIds)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		userCreated(context.Background(), user)
+	}
+	if err = global.GVA_DB.Preload("Authorities").Preload("Authority").First(&user, user.ID).Error; err != nil {
+		return nil, err
+	}
+	global.GVA_DB.Create(&system.UserLoginHistory{
+		UserID:   uint64(user.ID),
+		DateTime: time.Now(),
+	})
+	MenuServiceApp.UserAuthorityDefaultRouter(&user)
+	return &user, nil
+}
+
+//@function: Sync
+//@description: 将目录用户及其用户组同步到 sys_users 与 sys_user_authority dryRun 时仅返回差异
+//@param: dryRun bool
+//@return: diff systemRes.LdapSyncDiff, err error
+
+func (ldapService *LdapService) Sync(dryRun bool) (diff systemRes.LdapSyncDiff, err error) {
+	cfg := global.GVA_CONFIG.Ldap
+	diff.DryRun = dryRun
+	if !cfg.Enable {
+		return diff, ErrLdapDisabled
+	}
+	entries, err := newLdapDirectory(cfg).Users()
+	if err != nil {
+		return diff, err
+	}
+
+	var identities []system.SysUserIdentity
+	if err = global.GVA_DB.Where("provider = ?", LdapProvider).Find(&identities).Error; err != nil {
+		return diff, err
+	}
+	userIds := make([]uint, 0, len(identities))
+	for i := range identities {
+		userIds = append(userIds, identities[i].UserId)
+	}
+	var linkedUsers []system.SysUser
+	if err = global.GVA_DB.Where("id in ?", userIds).Find(&linkedUsers).Error; err != nil {
+		return diff, err
+	}
+	users := make(map[uint]system.SysUser, len(linkedUsers))
+	for i := range linkedUsers {
+		users[linkedUsers[i].ID] = linkedUsers[i]
+	}
+	var userAuthorities []system.SysUserAuthority
+	if err = global.GVA_DB.Where("sys_user_id in ?", userIds).Find(&userAuthorities).Error; err != nil {
+		return diff, err
+	}
+	current := make(map[uint][]uint)
+	for _, ua := range userAuthorities {
+		current[ua.SysUserId] = append(current[ua.SysUserId], ua.SysAuthorityAuthorityId)
+	}
+	linked := make(map[string]uint, len(identities))
+	for i := range identities {
+		if _, ok := users[identities[i].UserId]; ok {
+			linked[identities[i].Subject] = identities[i].UserId
+		}
+	}
+
+	var newEntries []ldap.Entry
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		seen[entry.Username] = struct{}{}
+		authorityIds := mapGroupAuthorities(cfg.GroupMapping, entry.Groups)
+		item := systemRes.LdapSyncUser{Username: entry.Username, NickName: entry.NickName, Email: entry.Email, Phone: entry.Phone, AuthorityIds: authorityIds}
+		id, ok := linked[entry.Username]
+		if !ok {
+			if cfg.AutoProvision {
+				newEntries = append(newEntries, entry)
+			}
+			continue
+		}
+		user := users[id]
+		item.UserId = id
+		for field := range ldapProfileChanges(user, entry) {
+			item.Changes = append(item.Changes, field)
+		}
+		sort.Strings(item.Changes)
+		if len(authorityIds) > 0 && !sameAuthorities(current[id], authorityIds) {
+			item.Changes = append(item.Changes, "authorities")
+		} else {
+			item.AuthorityIds = current[id]
+		}
+		if len(item.Changes) > 0 {
+			diff.Update = append(diff.Update, item)
+		}
+	}
+
+	if len(newEntries) > 0 {
+		names := make([]string, 0, len(newEntries))
+		for i := range newEntries {
+			names = append(names, newEntries[i].Username)
+		}
+		var taken []string
+		if err = global.GVA_DB.Model(&system.SysUser{}).Where("username in ?", names).Pluck("username", &taken).Error; err != nil {
+			return diff, err
+		}
+		takenSet := make(map[string]struct{}, len(taken))
+		for _, name := range taken {
+			takenSet[name] = struct{}{}
+		}
+		for _, entry := range newEntries {
+			authorityIds := mapGroupAuthorities(cfg.GroupMapping, entry.Groups)
+			if len(authorityIds) == 0 {
+				authorityIds = []uint{cfg.DefaultAuthorityId}
+			}
+			item := systemRes.LdapSyncUser{Username: entry.Username, NickName: entry.NickName, Email: entry.Email, Phone: entry.Phone, AuthorityIds: authorityIds}
+			if _, ok := takenSet[entry.Username]; ok {
+				item.Reason = "用户名已被未绑定的本地账号占用"
+				diff.Conflicts = append(diff.Conflicts, item)
+				continue
+			}
+			diff.Create = append(diff.Create, item)
+		}
+	}
+
+	if cfg.Sync.DisableStale && len(entries) <= cfg.Sync.MinUsers {
+		// 搜索根节点或过滤条件配置错误时目录会返回空结果 此时冻结会锁住全部目录用户
+		diff.DisableSkipped = fmt.Sprintf("目录仅返回 %d 个用户, 不超过 min-users(%d), 已跳过冻结", len(entries), cfg.Sync.MinUsers)
+		global.GVA_LOG.Warn("LDAP同步跳过冻结", zap.Int("users", len(entries)), zap.Int("min-users", cfg.Sync.MinUsers))
+	} else if cfg.Sync.DisableStale {
+		for subject, id := range linked {
+			if _, ok := seen[subject]; ok || users[id].Enable != 1 {
+				continue
+			}
+			user := users[id]
+			diff.Disable = append(diff.Disable, systemRes.LdapSyncUser{UserId: id, Username: user.Username, NickName: user.NickName, Email: user.Email, Phone: user.Phone, Changes: []string{"enable"}})
+		}
+		sort.Slice(diff.Disable, func(i, j int) bool { return diff.Disable[i].UserId < diff.Disable[j].UserId })
+	}
+
+	if dryRun {
+		return diff, nil
+	}
+	entryMap := make(map[string]ldap.Entry, len(entries))
+	for _, entry := range entries {
+		entryMap[entry.Username] = entry
+	}
+	var created []system.SysUser
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range diff.Create {
+			user, e := ldapService.createUser(tx, entryMap[item.Username], item.AuthorityIds)
+			if e != nil {
+				return e
+			}
+			created = append(created, user)
+		}
+		for _, item := range diff.Update {
+			user := users[item.UserId]
+			if changes := ldapProfileChanges(user, entryMap[item.Username]); len(changes) > 0 {
+				if e := tx.Model(&system.SysUser{}).Where("id = ?", user.ID).Updates(changes).Error; e != nil {
+					return e
+				}
+			}
+			if len(item.AuthorityIds) > 0 && !sameAuthorities(current[user.ID], item.AuthorityIds) {
+				if e := syncUserAuthorities(tx, &user, item.AuthorityIds); e != nil {
+					return e
+				}
+			}
+		}
+		for _, item := range diff.Disable {
+			if e := tx.Model(&system.SysUser{}).Where("id = ?", item.UserId).Update("enable", 2).Error; e != nil {
+				return e
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return diff, err
+	}
+	for _, user := range created {
+		userCreated(context.Background(), user)
+	}
+	return diff, nil
+}
+
+// createUser 根据目录账号创建本地用户并绑定身份
+func (ldapService *LdapService) createUser(tx *gorm.DB, entry ldap.Entry, authorityIds []uint) (system.SysUser, error) {
+	if len(authorityIds) == 0 {
+		authorityIds = []uint{global.GVA_CONFIG.Ldap.DefaultAuthorityId}
+	}
+	user := system.SysUser{
+		UUID:        uuid.New(),
+		Username:    entry.Username,
+		NickName:    entry.NickName,
+		Email:       entry.Email,
+		Phone:       entry.Phone,
+		Password:    utils.BcryptHash(utils.SecureRandomString(32)), // 目录账号不使用本地密码登录
+		AuthorityId: authorityIds[0],
+		Enable:      1,
+	}
+	if user.NickName == "" {
+		user.NickName = entry.Username
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return user, err
+	}
+	identity := system.SysUserIdentity{UserId: user.ID, Provider: LdapProvider, Subject: entry.Username, Email: entry.Email}
+	if err := tx.Create(&identity).Error; err != nil {
+		return user, err
+	}
+	return user, syncUserAuthorities(tx, &user, authorityIds)
+}
+
+// ldapProfileChanges 目录中非空且与本地不同的资料字段
+func ldapProfileChanges(user system.SysUser, entry ldap.Entry) map[string]interface{} {
+	changes := make(map[string]interface{})
+	if entry.NickName != "" && entry.NickName != user.NickName {
+		changes["nick_name"] = entry.NickName
+	}
+	if entry.Email != "" && entry.Email != user.Email {
+		changes["email"] = entry.Email
+	}
+	if entry.Phone != "" && entry.Phone != user.Phone {
+		changes["phone"] = entry.Phone
+	}
+	return changes
+}
+
+func sameAuthorities(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[uint]struct{}, len(a))
+	for _, v := range a {
+		set[v] = struct{}{}
+	}
+	for _, v := range b {
+		if _, ok := set[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+package system
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/ldap"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeDirectory 内存目录 err 不为空时模拟目录不可用
+type fakeDirectory struct {
+	users     map[string]ldap.Entry
+	passwords map[string]string
+	err       error
+}
+
+func (d *fakeDirectory) Authenticate(username, password string) (*ldap.Entry, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	entry, ok := d.users[username]
+	if !ok {
+		return nil, ldap.ErrUserNotFound
+	}
+	if d.passwords[username] != password {
+		return nil, ldap.ErrInvalidPassword
+	}
+	return &entry, nil
+}
+
+func (d *fakeDirectory) Users() ([]ldap.Entry, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	entries := make([]ldap.Entry, 0, len(d.users))
+	for _, e := range d.users {
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func setupLdapTest(t *testing.T, cfg config.Ldap) (*gorm.DB, *fakeDirectory) {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysUser{}, &system.SysUserAuthority{}, &system.SysUserIdentity{}, &system.UserLoginHistory{})
+	for _, id := range []uint{888, 9528, 100} {
+		assert.Nil(t, db.Create(&system.SysAuthority{AuthorityId: id, AuthorityName: "role"}).Error)
+	}
+	dir := &fakeDirectory{users: map[string]ldap.Entry{}, passwords: map[string]string{}}
+	oldCfg, oldDir := global.GVA_CONFIG.Ldap, newLdapDirectory
+	cfg.Enable = true
+	global.GVA_CONFIG.Ldap = cfg
+	newLdapDirectory = func(config.Ldap) ldapDirectory { return dir }
+	t.Cleanup(func() {
+		global.GVA_CONFIG.Ldap, newLdapDirectory = oldCfg, oldDir
+	})
+	return db, dir
+}
+
+func userAuthorityIds(t *testing.T, db *gorm.DB, userId uint) []uint {
+	var ids []uint
+	assert.Nil(t, db.Model(&system.SysUserAuthority{}).Where("sys_user_id = ?", userId).Order("sys_authority_authority_id").Pluck("sys_authority_authority_id", &ids).Error)
+	return ids
+}
+
+func TestLdapLoginGroupSync(t *testing.T) {
+	db, dir := setupLdapTest(t, config.Ldap{
+		AutoProvision:      true,
+		DefaultAuthorityId: 888,
+		GroupMapping: []config.GroupAuthorityMapping{
+			{Group: "admins", AuthorityId: 9528},
+			{Group: "ops", AuthorityId: 100},
+		},
+	})
+	dir.users["alice"] = ldap.Entry{Username: "alice", NickName: "Alice", Email: "alice@example.com", Groups: []string{"ops", "admins", "unknown"}}
+	dir.passwords["alice"] = "secret"
+	created := recordEvents(t, system.EventUserCreated)
+
+	_, err := LdapServiceApp.Login(&system.SysUser{Username: "alice", Password: "wrong"})
+	assert.ErrorIs(t, err, ldap.ErrInvalidPassword)
+
+	// 首次登录自动创建 角色按映射顺序 第一个为当前角色
+	user, err := LdapServiceApp.Login(&system.SysUser{Username: "alice", Password: "secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Alice", user.NickName)
+	assert.Equal(t, uint(9528), user.AuthorityId)
+	assert.Equal(t, []uint{100, 9528}, userAuthorityIds(t, db, user.ID))
+	assert.Equal(t, []system.UserEvent{{ID: user.ID, Username: "alice", AuthorityId: 9528}}, *created)
+
+	// 目录中的组和资料变化后再次登录同步
+	dir.users["alice"] = ldap.Entry{Username: "alice", NickName: "Alice L", Email: "alice@example.com", Groups: []string{"ops"}}
+	user, err = LdapServiceApp.Login(&system.SysUser{Username: "alice", Password: "secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Alice L", user.NickName)
+	assert.Equal(t, uint(100), user.AuthorityId)
+	assert.Equal(t, []uint{100}, userAuthorityIds(t, db, user.ID))
+
+	// 没有映射到任何角色时保留现有角色
+	dir.users["alice"] = ldap.Entry{Username: "alice", Groups: nil}
+	_, err = LdapServiceApp.Login(&system.SysUser{Username: "alice", Password: "secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{100}, userAuthorityIds(t, db, user.ID))
+	// 已绑定的账号再次登录不再发布创建事件
+	assert.Len(t, *created, 1)
+}
+
+func TestLdapLoginFallback(t *testing.T) {
+	db, dir := setupLdapTest(t, config.Ldap{AutoProvision: true, DefaultAuthorityId: 888})
+	admin := system.SysUser{Username: "admin", Password: utils.BcryptHash("123456"), AuthorityId: 888, Enable: 1}
+	assert.Nil(t, db.Create(&admin).Error)
+	dir.users["bob"] = ldap.Entry{Username: "bob"}
+	dir.passwords["bob"] = "secret"
+	_, err := LdapServiceApp.Login(&system.SysUser{Username: "bob", Password: "secret"})
+	assert.Nil(t, err)
+
+	// 目录中不存在的用户使用本地账号
+	user, err := LdapServiceApp.Login(&system.SysUser{Username: "admin", Password: "123456"})
+	assert.Nil(t, err)
+	assert.Equal(t, admin.ID, user.ID)
+
+	// 目录不可用时 本地账号仍可登录 目录账号不能
+	dir.err = errors.New("connection refused")
+	user, err = LdapServiceApp.Login(&system.SysUser{Username: "admin", Password: "123456"})
+	assert.Nil(t, err)
+	assert.Equal(t, admin.ID, user.ID)
+	_, err = LdapServiceApp.Login(&system.SysUser{Username: "admin", Password: "bad"})
+	assert.EqualError(t, err, "密码错误")
+	_, err = LdapServiceApp.Login(&system.SysUser{Username: "bob", Password: "secret"})
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestLdapSyncDiff(t *testing.T) {
+	db, dir := setupLdapTest(t, config.Ldap{
+		AutoProvision:      true,
+		DefaultAuthorityId: 888,
+		GroupMapping:       []config.GroupAuthorityMapping{{Group: "admins", AuthorityId: 9528}},
+		Sync:               config.LdapSync{DisableStale: true},
+	})
+	for _, name := range []string{"alice", "bob", "carol"} {
+		dir.users[name] = ldap.Entry{Username: name, NickName: name}
+		dir.passwords[name] = "secret"
+		_, err := LdapServiceApp.Login(&system.SysUser{Username: name, Password: "secret"})
+		assert.Nil(t, err)
+	}
+	local := system.SysUser{Username: "dave", Password: utils.BcryptHash("x"), AuthorityId: 888, Enable: 1}
+	assert.Nil(t, db.Create(&local).Error)
+
+	dir.users["alice"] = ldap.Entry{Username: "alice", NickName: "Alice", Email: "alice@example.com", Groups: []string{"admins"}}
+	delete(dir.users, "bob")
+	dir.users["erin"] = ldap.Entry{Username: "erin", NickName: "Erin", Groups: []string{"admins"}}
+	dir.users["dave"] = ldap.Entry{Username: "dave"}
+
+	diff, err := LdapServiceApp.Sync(true)
+	assert.Nil(t, err)
+	assert.True(t, diff.DryRun)
+	if assert.Len(t, diff.Update, 1) {
+		assert.Equal(t, "alice", diff.Update[0].Username)
+		assert.Equal(t, []string{"email", "nick_name", "authorities"}, diff.Update[0].Changes)
+		assert.Equal(t, []uint{9528}, diff.Update[0].AuthorityIds)
+	}
+	if assert.Len(t, diff.Create, 1) {
+		assert.Equal(t, "erin", diff.Create[0].Username)
+		assert.Equal(t, []uint{9528}, diff.Create[0].AuthorityIds)
+	}
+	if assert.Len(t, diff.Conflicts, 1) {
+		assert.Equal(t, "dave", diff.Conflicts[0].Username)
+	}
+	if assert.Len(t, diff.Disable, 1) {
+		assert.Equal(t, "bob", diff.Disable[0].Username)
+	}
+	// 预览不落库
+	var count int64
+	db.Model(&system.SysUser{}).Where("username = ?", "erin").Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	created := recordEvents(t, system.EventUserCreated)
+	diff, err = LdapServiceApp.Sync(false)
+	assert.Nil(t, err)
+	var alice, bob, erin system.SysUser
+	assert.Nil(t, db.Where("username = ?", "alice").First(&alice).Error)
+	assert.Equal(t, "Alice", alice.NickName)
+	assert.Equal(t, uint(9528), alice.AuthorityId)
+	assert.Nil(t, db.Where("username = ?", "bob").First(&bob).Error)
+	assert.Equal(t, 2, bob.Enable)
+	assert.Nil(t, db.Where("username = ?", "erin").First(&erin).Error)
+	assert.Equal(t, []uint{9528}, userAuthorityIds(t, db, erin.ID))
+	assert.Equal(t, []system.UserEvent{{ID: erin.ID, Username: "erin", AuthorityId: 9528}}, *created)
+
+	// 再次同步没有差异
+	diff, err = LdapServiceApp.Sync(true)
+	assert.Nil(t, err)
+	assert.Empty(t, diff.Update)
+	assert.Empty(t, diff.Create)
+	assert.Empty(t, diff.Disable)
+}
+
+func TestLdapSyncSkipDisableOnEmptyResult(t *testing.T) {
+	db, dir := setupLdapTest(t, config.Ldap{
+		AutoProvision:      true,
+		DefaultAuthorityId: 888,
+		Sync:               config.LdapSync{DisableStale: true, MinUsers: 1},
+	})
+	for _, name := range []string{"alice", "bob"} {
+		dir.users[name] = ldap.Entry{Username: name}
+		dir.passwords[name] = "secret"
+		_, err := LdapServiceApp.Login(&system.SysUser{Username: name, Password: "secret"})
+		assert.Nil(t, err)
+	}
+
+	// 搜索条件错误时目录返回空结果 不冻结任何用户
+	dir.users = map[string]ldap.Entry{}
+	diff, err := LdapServiceApp.Sync(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diff.Disable)
+	assert.NotEmpty(t, diff.DisableSkipped)
+
+	// 不超过 min-users 时同样跳过
+	dir.users["alice"] = ldap.Entry{Username: "alice"}
+	diff, err = LdapServiceApp.Sync(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diff.Disable)
+	assert.NotEmpty(t, diff.DisableSkipped)
+	var disabled int64
+	db.Model(&system.SysUser{}).Where("enable = ?", 2).Count(&disabled)
+	assert.Equal(t, int64(0), disabled)
+
+	dir.users["carol"] = ldap.Entry{Username: "carol"}
+	diff, err = LdapServiceApp.Sync(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diff.DisableSkipped)
+	if assert.Len(t, diff.Disable, 1) {
+		assert.Equal(t, "bob", diff.Disable[0].Username)
+	}
+}
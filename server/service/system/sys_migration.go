@@ -0,0 +1,421 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"gorm.io/gorm"
+)
+
+// Migration 一个版本的数据库迁移 同一模块按版本号顺序执行, Up/Down 在同一事务中执行
+type Migration struct {
+	Module  string // 模块名 插件为 plugin/{插件名}
+	Version string // 数字版本号 生成代码使用时间戳
+	Name    string
+	DB      string // 业务库名 空为主库
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+func (m Migration) String() string {
+	return m.Module + "/" + m.Version + "_" + m.Name
+}
+
+var registeredMigrations []Migration
+
+// RegisterMigration 注册Go代码编写的迁移 在init中调用, 与迁移文件一起排序执行
+func RegisterMigration(m Migration) {
+	registeredMigrations = append(registeredMigrations, m)
+}
+
+// migrationManualMarker 生成代码无法生成的语句以该注释占位 处理前拒绝执行
+const migrationManualMarker = "-- 请手动处理"
+
+// migrationFile 迁移文件名 {版本号}_{名称}.up.sql / {版本号}_{名称}.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([^.]+)\.(up|down)\.sql$`)
+
+// MigrationDir 模块的迁移文件目录 插件的迁移文件位于插件目录下
+func MigrationDir(module string, plugin bool) string {
+	server := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server)
+	if plugin {
+		return filepath.Join(server, "plugin", module, "migrations")
+	}
+	dir := global.GVA_CONFIG.Migration.Dir
+	if dir == "" {
+		dir = "migrations"
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(server, dir)
+	}
+	return filepath.Join(dir, module)
+}
+
+type MigrationService struct{}
+
+var MigrationServiceApp = new(MigrationService)
+
+//@function: Status
+//@description: 获取迁移执行状态
+//@param: module string
+//@return: list []systemRes.MigrationStatus, err error
+
+func (migrationService *MigrationService) Status(module string) (list []systemRes.MigrationStatus, err error) {
+	all, err := migrationService.load(module)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := migrationService.applied(module)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range all {
+		status := systemRes.MigrationStatus{Module: m.Module, Version: m.Version, Name: m.Name, DB: m.DB}
+		if record, ok := applied[migrationKey(m.Module, m.Version)]; ok {
+			status.Applied, status.AppliedAt = true, &record.AppliedAt
+			delete(applied, migrationKey(m.Module, m.Version))
+		}
+		list = append(list, status)
+	}
+	missing := make([]system.SysSchemaMigration, 0, len(applied))
+	for _, record := range applied {
+		missing = append(missing, record)
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].ID < missing[j].ID })
+	for i := range missing {
+		list = append(list, systemRes.MigrationStatus{
+			Module: missing[i].Module, Version: missing[i].Version, Name: missing[i].Name,
+			Applied: true, AppliedAt: &missing[i].AppliedAt, Missing: true,
+		})
+	}
+	return list, nil
+}
+
+//@function: Up
+//@description: 按版本号顺序执行待执行的迁移 遇到错误停止
+//@param: module string
+//@return: done []string, err error
+
+func (migrationService *MigrationService) Up(module string) (done []string, err error) {
+	all, err := migrationService.load(module)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := migrationService.applied(module)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range all {
+		if _, ok := applied[migrationKey(m.Module, m.Version)]; ok {
+			continue
+		}
+		record := system.SysSchemaMigration{Module: m.Module, Version: m.Version, Name: m.Name}
+		err = migrationService.run(m, m.Up, func(tx *gorm.DB) error {
+			record.AppliedAt = time.Now()
+			return tx.Create(&record).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %s 失败: %w", m, err)
+		}
+		done = append(done, m.String())
+	}
+	return done, nil
+}
+
+//@function: Down
+//@description: 按执行顺序倒序回滚最近执行的迁移
+//@param: module string, steps int
+//@return: done []string, err error
+
+func (migrationService *MigrationService) Down(module string, steps int) (done []string, err error) {
+	if steps <= 0 {
+		return nil, errors.New("回滚数量必须大于0")
+	}
+	all, err := migrationService.load(module)
+	if err != nil {
+		return nil, err
+	}
+	if err = migrationService.ensureTable(); err != nil {
+		return nil, err
+	}
+	var records []system.SysSchemaMigration
+	db := global.GVA_DB.Order("id desc").Limit(steps)
+	if module != "" {
+		db = db.Where("module = ?", module)
+	}
+	if err = db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	index := make(map[string]Migration, len(all))
+	for _, m := range all {
+		index[migrationKey(m.Module, m.Version)] = m
+	}
+	for _, record := range records {
+		m, ok := index[migrationKey(record.Module, record.Version)]
+		if !ok {
+			return done, fmt.Errorf("迁移 %s/%s_%s 的文件不存在, 无法回滚", record.Module, record.Version, record.Name)
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("迁移 %s 没有down脚本, 无法回滚", m)
+		}
+		err = migrationService.run(m, m.Down, func(tx *gorm.DB) error {
+			return tx.Delete(&system.SysSchemaMigration{}, "id = ?", record.ID).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("回滚迁移 %s 失败: %w", m, err)
+		}
+		done = append(done, m.String())
+	}
+	return done, nil
+}
+
+// run 执行迁移并更新执行记录 主库的迁移与记录在同一事务中
+func (migrationService *MigrationService) run(m Migration, fn func(tx *gorm.DB) error, record func(tx *gorm.DB) error) error {
+	if m.DB == "" {
+		return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+			if err := fn(tx); err != nil {
+				return err
+			}
+			return record(tx)
+		})
+	}
+	db := global.GetGlobalDBByDBName(m.DB)
+	if db == nil {
+		return fmt.Errorf("业务库 %s 未配置", m.DB)
+	}
+	if err := db.Transaction(fn); err != nil {
+		return err
+	}
+	return record(global.GVA_DB)
+}
+
+func (migrationService *MigrationService) ensureTable() error {
+	if global.GVA_DB == nil {
+		return errors.New("数据库未初始化")
+	}
+	return global.GVA_DB.AutoMigrate(&system.SysSchemaMigration{})
+}
+
+func (migrationService *MigrationService) applied(module string) (map[string]system.SysSchemaMigration, error) {
+	if err := migrationService.ensureTable(); err != nil {
+		return nil, err
+	}
+	var records []system.SysSchemaMigration
+	db := global.GVA_DB.Model(&system.SysSchemaMigration{})
+	if module != "" {
+		db = db.Where("module = ?", module)
+	}
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[string]system.SysSchemaMigration, len(records))
+	for _, record := range records {
+		applied[migrationKey(record.Module, record.Version)] = record
+	}
+	return applied, nil
+}
+
+// load 汇总注册的迁移和迁移文件 按版本号排序
+func (migrationService *MigrationService) load(module string) ([]Migration, error) {
+	all := append([]Migration(nil), registeredMigrations...)
+	root := MigrationDir("", false)
+	entries, err := os.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			list, err := loadMigrationDir(filepath.Join(root, entry.Name()), entry.Name(), "")
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, list...)
+		}
+	}
+	plugins, err := os.ReadDir(filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range plugins {
+		dir := MigrationDir(entry.Name(), true)
+		if _, err = os.Stat(dir); !entry.IsDir() || err != nil {
+			continue
+		}
+		list, err := loadMigrationDir(dir, "plugin/"+entry.Name(), "")
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, list...)
+	}
+
+	list := all[:0]
+	seen := make(map[string]bool, len(all))
+	for _, m := range all {
+		if module != "" && m.Module != module {
+			continue
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("迁移 %s 缺少up脚本", m)
+		}
+		key := migrationKey(m.Module, m.Version)
+		if seen[key] {
+			return nil, fmt.Errorf("迁移 %s 版本号重复", m)
+		}
+		seen[key] = true
+		list = append(list, m)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if c := compareVersion(list[i].Version, list[j].Version); c != 0 {
+			return c < 0
+		}
+		return list[i].Module < list[j].Module
+	})
+	return list, nil
+}
+
+// loadMigrationDir 读取目录下的迁移文件 子目录名为业务库名
+func loadMigrationDir(dir, module, db string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var list []Migration
+	versions := make(map[string]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if db == "" {
+				sub, err := loadMigrationDir(filepath.Join(dir, entry.Name()), module, entry.Name())
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, sub...)
+			}
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := versions[match[1]]
+		if !ok {
+			m = &Migration{Module: module, Version: match[1], Name: match[2], DB: db}
+			versions[match[1]] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("迁移 %s/%s 存在多个名称: %s, %s", module, match[1], m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = sqlMigration(string(content))
+		} else {
+			m.Down = sqlMigration(string(content))
+		}
+	}
+	for _, m := range versions {
+		list = append(list, *m)
+	}
+	return list, nil
+}
+
+// sqlMigration 逐条执行SQL 语句以分号结束, 忽略注释以及引号内的分号
+func sqlMigration(content string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if strings.Contains(content, migrationManualMarker) {
+			return errors.New("迁移文件中存在需要手动处理的语句, 请修改后再执行")
+		}
+		for _, statement := range splitSQL(content) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// splitSQL 按分号拆分语句 去掉 -- 与 /* */ 注释
+// 单双引号、反引号与 postgres 的 $$ 内的内容原样保留, 引号内支持连写两次引号与反斜杠两种转义
+func splitSQL(content string) []string {
+	var statements []string
+	var builder strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(builder.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		builder.Reset()
+	}
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for ; j < len(content); j++ {
+				if content[j] == '\\' && c != '`' {
+					j++
+					continue
+				}
+				if content[j] == c {
+					if j+1 < len(content) && content[j+1] == c {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			j = min(j, len(content)-1)
+			builder.WriteString(content[i : j+1])
+			i = j
+		case strings.HasPrefix(content[i:], "$$"):
+			j := len(content)
+			if end := strings.Index(content[i+2:], "$$"); end >= 0 {
+				j = i + 2 + end + 2
+			}
+			builder.WriteString(content[i:j])
+			i = j - 1
+		case strings.HasPrefix(content[i:], "--"):
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			builder.WriteByte('\n')
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				i = len(content)
+				break
+			}
+			i += end + 3
+			builder.WriteByte(' ')
+		case c == ';':
+			flush()
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+func migrationKey(module, version string) string {
+	return module + "@" + version
+}
+
+// compareVersion 按数值比较版本号 避免 0002 与 20240101 等不同长度的版本号按字符串排序出错
+func compareVersion(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,169 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "空内容", content: "\n  \n", want: nil},
+		{name: "多条语句", content: "CREATE TABLE a (id int);\nDROP TABLE b;", want: []string{"CREATE TABLE a (id int)", "DROP TABLE b"}},
+		{name: "同一行多条语句", content: "DELETE FROM a; DELETE FROM b;", want: []string{"DELETE FROM a", "DELETE FROM b"}},
+		{name: "最后一条没有分号", content: "SELECT 1;\nSELECT 2", want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "跨行语句", content: "CREATE TABLE a (\n  id int\n);", want: []string{"CREATE TABLE a (\n  id int\n)"}},
+		{name: "行注释", content: "-- 说明;\nSELECT 1; -- 行尾注释;\nSELECT 2;", want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "块注释", content: "/* 说明; */SELECT 1;/* 多行\n注释; */SELECT 2;", want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "单引号内的分号", content: "INSERT INTO a VALUES ('x;y');", want: []string{"INSERT INTO a VALUES ('x;y')"}},
+		{name: "跨行字符串内的分号", content: "INSERT INTO a VALUES ('x;\ny');", want: []string{"INSERT INTO a VALUES ('x;\ny')"}},
+		{name: "引号转义", content: "INSERT INTO a VALUES ('it''s;', 'it\\'s;');", want: []string{"INSERT INTO a VALUES ('it''s;', 'it\\'s;')"}},
+		{name: "引号内的注释", content: "INSERT INTO a VALUES ('-- x; /* y */');", want: []string{"INSERT INTO a VALUES ('-- x; /* y */')"}},
+		{name: "双引号与反引号", content: "SELECT \"a;b\", `c;d` FROM t;", want: []string{"SELECT \"a;b\", `c;d` FROM t"}},
+		{name: "postgres函数体", content: "CREATE FUNCTION f() RETURNS void AS $$ BEGIN DELETE FROM a; END; $$ LANGUAGE plpgsql;",
+			want: []string{"CREATE FUNCTION f() RETURNS void AS $$ BEGIN DELETE FROM a; END; $$ LANGUAGE plpgsql"}},
+		{name: "未闭合的引号", content: "SELECT 'a;", want: []string{"SELECT 'a;"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitSQL(tt.content))
+		})
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1", 0},
+		{"0001", "1", 0},
+		{"0002", "0010", -1},
+		{"0010", "0002", 1},
+		{"0002", "20240101120000", -1},
+		{"20240102000000", "20240101235959", 1},
+		{"0", "", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareVersion(tt.a, tt.b), "%s <=> %s", tt.a, tt.b)
+	}
+
+	versions := []string{"20240101000000", "0010", "0002", "1"}
+	sort.Slice(versions, func(i, j int) bool { return compareVersion(versions[i], versions[j]) < 0 })
+	assert.Equal(t, []string{"1", "0002", "0010", "20240101000000"}, versions)
+}
+
+func writeMigrationFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestLoadMigrationDir(t *testing.T) {
+	dir := t.TempDir()
+	writeMigrationFiles(t, dir, map[string]string{
+		"0001_init.up.sql":         "CREATE TABLE a (id int);",
+		"0001_init.down.sql":       "DROP TABLE a;",
+		"0002_seed.up.sql":         "INSERT INTO a VALUES (1);",
+		"README.md":                "说明",
+		"0003_bad.sql":             "SELECT 1;",
+		"biz/0004_report.up.sql":   "CREATE TABLE r (id int);",
+		"biz/nested/0005_x.up.sql": "SELECT 1;",
+	})
+	list, err := loadMigrationDir(dir, "demo", "")
+	assert.Nil(t, err)
+	sort.Slice(list, func(i, j int) bool { return compareVersion(list[i].Version, list[j].Version) < 0 })
+	if assert.Len(t, list, 3) {
+		assert.Equal(t, "demo/0001_init", list[0].String())
+		assert.NotNil(t, list[0].Up)
+		assert.NotNil(t, list[0].Down)
+		assert.Equal(t, "", list[0].DB)
+		assert.Equal(t, "demo/0002_seed", list[1].String())
+		assert.Nil(t, list[1].Down)
+		// 子目录为业务库 只读取一层
+		assert.Equal(t, "demo/0004_report", list[2].String())
+		assert.Equal(t, "biz", list[2].DB)
+	}
+
+	// 同一版本号的up和down名称不一致
+	writeMigrationFiles(t, dir, map[string]string{"0002_other.down.sql": "DELETE FROM a;"})
+	_, err = loadMigrationDir(dir, "demo", "")
+	assert.NotNil(t, err)
+
+	_, err = loadMigrationDir(filepath.Join(dir, "missing"), "demo", "")
+	assert.NotNil(t, err)
+}
+
+func TestMigrationUpDown(t *testing.T) {
+	db := newTestDB(t)
+	root := t.TempDir()
+	oldAutoCode, oldMigration, oldRegistered := global.GVA_CONFIG.AutoCode, global.GVA_CONFIG.Migration, registeredMigrations
+	global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server = root, "server"
+	global.GVA_CONFIG.Migration.Dir = ""
+	registeredMigrations = nil
+	t.Cleanup(func() {
+		global.GVA_CONFIG.AutoCode, global.GVA_CONFIG.Migration, registeredMigrations = oldAutoCode, oldMigration, oldRegistered
+	})
+	writeMigrationFiles(t, MigrationDir("demo", false), map[string]string{
+		"0001_create.up.sql":   "CREATE TABLE demo (id integer, name text);",
+		"0001_create.down.sql": "DROP TABLE demo;",
+		"0002_seed.up.sql":     "-- 初始数据\nINSERT INTO demo VALUES (1, 'a;b');\nINSERT INTO demo VALUES (2, 'c');",
+		"0002_seed.down.sql":   "DELETE FROM demo;",
+	})
+	count := func() (n int64) {
+		db.Table("demo").Count(&n)
+		return
+	}
+
+	done, err := MigrationServiceApp.Up("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"demo/0001_create", "demo/0002_seed"}, done)
+	assert.Equal(t, int64(2), count())
+	done, err = MigrationServiceApp.Up("")
+	assert.Nil(t, err)
+	assert.Empty(t, done)
+
+	// 失败的迁移整体回滚 不写入执行记录
+	writeMigrationFiles(t, MigrationDir("demo", false), map[string]string{
+		"0003_broken.up.sql": "INSERT INTO demo VALUES (3, 'd');\nINSERT INTO missing VALUES (1);",
+	})
+	done, err = MigrationServiceApp.Up("demo")
+	assert.NotNil(t, err)
+	assert.Empty(t, done)
+	assert.Equal(t, int64(2), count())
+	status, err := MigrationServiceApp.Status("demo")
+	assert.Nil(t, err)
+	if assert.Len(t, status, 3) {
+		assert.True(t, status[0].Applied)
+		assert.True(t, status[1].Applied)
+		assert.False(t, status[2].Applied)
+	}
+	assert.Nil(t, os.Remove(filepath.Join(MigrationDir("demo", false), "0003_broken.up.sql")))
+
+	// 按执行顺序倒序回滚
+	done, err = MigrationServiceApp.Down("demo", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"demo/0002_seed"}, done)
+	assert.Equal(t, int64(0), count())
+	done, err = MigrationServiceApp.Down("", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"demo/0001_create"}, done)
+	assert.False(t, db.Migrator().HasTable("demo"))
+	var records int64
+	db.Model(&system.SysSchemaMigration{}).Count(&records)
+	assert.Equal(t, int64(0), records)
+
+	_, err = MigrationServiceApp.Down("", 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,284 @@
+package system
+
+import (
+	"bytes"
+	"errors"
+	"text/template"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"gorm.io/gorm"
+)
+
+type NotificationService struct{}
+
+var NotificationServiceApp = new(NotificationService)
+
+//@function: Send
+//@description: 按模板给用户发送站内信 供其他模块调用, 如 NotificationServiceApp.Send(userId, "approval.todo", data)
+//@param: userId uint, templateCode string, data interface{}
+//@return: err error
+
+func (notificationService *NotificationService) Send(userId uint, templateCode string, data interface{}) (err error) {
+	return notificationService.SendToUsers([]uint{userId}, templateCode, data)
+}
+
+//@function: SendToUsers
+//@description: 按模板给多个用户发送站内信
+//@param: userIds []uint, templateCode string, data interface{}
+//@return: err error
+
+func (notificationService *NotificationService) SendToUsers(userIds []uint, templateCode string, data interface{}) (err error) {
+	var tpl system.SysNotificationTemplate
+	if err = global.GVA_DB.Where("code = ?", templateCode).First(&tpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("站内信模板不存在: " + templateCode)
+		}
+		return err
+	}
+	n := system.SysNotification{Category: tpl.Category, TemplateCode: tpl.Code}
+	if n.Title, err = renderNotification(tpl.Title, data); err != nil {
+		return err
+	}
+	if n.Content, err = renderNotification(tpl.Content, data); err != nil {
+		return err
+	}
+	if n.Link, err = renderNotification(tpl.Link, data); err != nil {
+		return err
+	}
+	return notificationService.Push(userIds, n)
+}
+
+//@function: Push
+//@description: 不使用模板直接发送站内信 保存后实时推送给在线用户
+//@param: userIds []uint, n system.SysNotification
+//@return: err error
+
+func (notificationService *NotificationService) Push(userIds []uint, n system.SysNotification) (err error) {
+	if n.Title == "" {
+		return errors.New("站内信标题不能为空")
+	}
+	if n.Category == "" {
+		n.Category = system.NotificationCategorySystem
+	}
+	seen := make(map[uint]struct{}, len(userIds))
+	list := make([]system.SysNotification, 0, len(userIds))
+	for _, id := range userIds {
+		if _, ok := seen[id]; ok || id == 0 {
+			continue
+		}
+		seen[id] = struct{}{}
+		item := n
+		item.ID, item.UserId, item.IsRead, item.ReadAt = 0, id, false, nil
+		list = append(list, item)
+	}
+	if len(list) == 0 {
+		return errors.New("没有接收人")
+	}
+	if err = global.GVA_DB.CreateInBatches(&list, 200).Error; err != nil {
+		return err
+	}
+	notifyHub.publish(list)
+	return nil
+}
+
+func renderNotification(text string, data interface{}) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	t, err := template.New("notification").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+//@function: Subscribe
+//@description: 订阅用户的实时站内信 返回的取消函数必须在连接结束时调用
+//@param: userId uint
+//@return: <-chan system.SysNotification, func()
+
+func (notificationService *NotificationService) Subscribe(userId uint) (<-chan system.SysNotification, func()) {
+	return notifyHub.subscribe(userId)
+}
+
+//@function: GetMyNotifications
+//@description: 分页获取用户的站内信
+//@param: userId uint, info systemReq.NotificationSearch
+//@return: list []system.SysNotification, total int64, err error
+
+func (notificationService *NotificationService) GetMyNotifications(userId uint, info systemReq.NotificationSearch) (list []system.SysNotification, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysNotification{}).Where("user_id = ?", userId)
+	if info.Category != "" {
+		db = db.Where("category = ?", info.Category)
+	}
+	if info.Unread {
+		db = db.Where("is_read = ?", false)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+//@function: GetUnread
+//@description: 获取用户未读站内信数量
+//@param: userId uint
+//@return: unread systemRes.NotificationUnread, err error
+
+func (notificationService *NotificationService) GetUnread(userId uint) (unread systemRes.NotificationUnread, err error) {
+	var rows []struct {
+		Category string
+		Count    int64
+	}
+	err = global.GVA_DB.Model(&system.SysNotification{}).Select("category, count(*) as count").
+		Where("user_id = ? AND is_read = ?", userId, false).Group("category").Scan(&rows).Error
+	unread.Categories = make(map[string]int64, len(rows))
+	for _, r := range rows {
+		unread.Categories[r.Category] = r.Count
+		unread.Total += r.Count
+	}
+	return unread, err
+}
+
+//@function: MarkRead
+//@description: 标记站内信已读 只能标记自己的站内信
+//@param: userId uint, info systemReq.NotificationRead
+//@return: err error
+
+func (notificationService *NotificationService) MarkRead(userId uint, info systemReq.NotificationRead) (err error) {
+	db, err := notificationService.mine(userId, info)
+	if err != nil {
+		return err
+	}
+	return db.Where("is_read = ?", false).Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()}).Error
+}
+
+//@function: DeleteMyNotifications
+//@description: 删除站内信 只能删除自己的站内信
+//@param: userId uint, info systemReq.NotificationRead
+//@return: err error
+
+func (notificationService *NotificationService) DeleteMyNotifications(userId uint, info systemReq.NotificationRead) (err error) {
+	db, err := notificationService.mine(userId, info)
+	if err != nil {
+		return err
+	}
+	return db.Delete(&system.SysNotification{}).Error
+}
+
+func (notificationService *NotificationService) mine(userId uint, info systemReq.NotificationRead) (*gorm.DB, error) {
+	db := global.GVA_DB.Model(&system.SysNotification{}).Where("user_id = ?", userId)
+	switch {
+	case len(info.Ids) > 0:
+		db = db.Where("id IN ?", info.Ids)
+	case !info.All:
+		return nil, errors.New("请选择站内信")
+	}
+	if info.Category != "" {
+		db = db.Where("category = ?", info.Category)
+	}
+	return db, nil
+}
+
+//@function: CreateTemplate
+//@description: 创建站内信模板
+//@param: t *system.SysNotificationTemplate
+//@return: err error
+
+func (notificationService *NotificationService) CreateTemplate(t *system.SysNotificationTemplate) (err error) {
+	if err = checkNotificationTemplate(t); err != nil {
+		return err
+	}
+	if !errors.Is(global.GVA_DB.Where("code = ?", t.Code).First(&system.SysNotificationTemplate{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("模板编码已存在")
+	}
+	return global.GVA_DB.Create(t).Error
+}
+
+//@function: UpdateTemplate
+//@description: 更新站内信模板 模板编码创建后不可修改
+//@param: t system.SysNotificationTemplate
+//@return: err error
+
+func (notificationService *NotificationService) UpdateTemplate(t system.SysNotificationTemplate) (err error) {
+	if err = checkNotificationTemplate(&t); err != nil {
+		return err
+	}
+	res := global.GVA_DB.Model(&system.SysNotificationTemplate{}).Where("id = ?", t.ID).
+		Select("name", "category", "title", "content", "link", "remark").
+		Updates(map[string]interface{}{"name": t.Name, "category": t.Category, "title": t.Title, "content": t.Content, "link": t.Link, "remark": t.Remark})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("模板不存在")
+	}
+	return nil
+}
+
+//@function: DeleteTemplate
+//@description: 删除站内信模板 已发送的站内信不受影响
+//@param: id uint
+//@return: err error
+
+func (notificationService *NotificationService) DeleteTemplate(id uint) (err error) {
+	return global.GVA_DB.Delete(&system.SysNotificationTemplate{}, "id = ?", id).Error
+}
+
+//@function: GetTemplate
+//@description: 获取站内信模板
+//@param: id uint
+//@return: t system.SysNotificationTemplate, err error
+
+func (notificationService *NotificationService) GetTemplate(id uint) (t system.SysNotificationTemplate, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&t).Error
+	return
+}
+
+//@function: GetTemplateList
+//@description: 分页获取站内信模板列表
+//@param: info systemReq.NotificationTemplateSearch
+//@return: list []system.SysNotificationTemplate, total int64, err error
+
+func (notificationService *NotificationService) GetTemplateList(info systemReq.NotificationTemplateSearch) (list []system.SysNotificationTemplate, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysNotificationTemplate{})
+	if info.Code != "" {
+		db = db.Where("code LIKE ?", "%"+info.Code+"%")
+	}
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if info.Category != "" {
+		db = db.Where("category = ?", info.Category)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id").Find(&list).Error
+	return list, total, err
+}
+
+// checkNotificationTemplate 校验必填项和模板语法
+func checkNotificationTemplate(t *system.SysNotificationTemplate) error {
+	if t.Code == "" || t.Name == "" || t.Title == "" {
+		return errors.New("模板编码、名称和标题不能为空")
+	}
+	if t.Category == "" {
+		t.Category = system.NotificationCategorySystem
+	}
+	for _, text := range []string{t.Title, t.Content, t.Link} {
+		if _, err := template.New("notification").Parse(text); err != nil {
+			return errors.New("模板语法错误: " + err.Error())
+		}
+	}
+	return nil
+}
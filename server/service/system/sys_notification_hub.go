@@ -0,0 +1,93 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"go.uber.org/zap"
+)
+
+// notificationChannel 多实例部署时通过redis广播新站内信 由持有对应用户连接的实例推送
+const notificationChannel = "gva:notification"
+
+// notificationBuffer 单个连接的待推送缓冲 客户端消费过慢时丢弃 前端可通过未读数量补偿
+const notificationBuffer = 16
+
+type notificationHub struct {
+	mu     sync.RWMutex
+	subs   map[uint]map[chan system.SysNotification]struct{}
+	listen sync.Once
+}
+
+var notifyHub = &notificationHub{subs: make(map[uint]map[chan system.SysNotification]struct{})}
+
+// subscribe 注册用户的实时连接 返回的取消函数必须在连接结束时调用
+func (h *notificationHub) subscribe(userId uint) (<-chan system.SysNotification, func()) {
+	if global.GVA_REDIS != nil {
+		h.listen.Do(h.listenRedis)
+	}
+	ch := make(chan system.SysNotification, notificationBuffer)
+	h.mu.Lock()
+	if h.subs[userId] == nil {
+		h.subs[userId] = make(map[chan system.SysNotification]struct{})
+	}
+	h.subs[userId][ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs[userId], ch)
+		if len(h.subs[userId]) == 0 {
+			delete(h.subs, userId)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// publish 推送新站内信 开启redis时经redis广播到所有实例 否则只推送本实例的连接
+func (h *notificationHub) publish(list []system.SysNotification) {
+	if len(list) == 0 {
+		return
+	}
+	if global.GVA_REDIS != nil {
+		data, err := json.Marshal(list)
+		if err == nil {
+			err = global.GVA_REDIS.Publish(context.Background(), notificationChannel, data).Err()
+		}
+		if err == nil {
+			return
+		}
+		global.GVA_LOG.Error("站内信广播失败, 仅推送本实例连接!", zap.Error(err))
+	}
+	h.dispatch(list)
+}
+
+func (h *notificationHub) dispatch(list []system.SysNotification) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, n := range list {
+		for ch := range h.subs[n.UserId] {
+			select {
+			case ch <- n:
+			default:
+			}
+		}
+	}
+}
+
+// listenRedis 订阅redis广播 go-redis 会在断线后自动重新订阅
+func (h *notificationHub) listenRedis() {
+	ps := global.GVA_REDIS.Subscribe(context.Background(), notificationChannel)
+	go func() {
+		for msg := range ps.Channel() {
+			var list []system.SysNotification
+			if err := json.Unmarshal([]byte(msg.Payload), &list); err != nil {
+				global.GVA_LOG.Error("站内信广播解析失败!", zap.Error(err))
+				continue
+			}
+			h.dispatch(list)
+		}
+	}()
+}
@@ -0,0 +1,50 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/stretchr/testify/assert"
+)
+
+// drain 取出通道中已缓冲的全部站内信
+func drain(ch <-chan system.SysNotification) (list []system.SysNotification) {
+	for {
+		select {
+		case n := <-ch:
+			list = append(list, n)
+		default:
+			return list
+		}
+	}
+}
+
+func TestNotificationHub(t *testing.T) {
+	h := &notificationHub{subs: make(map[uint]map[chan system.SysNotification]struct{})}
+	web, cancelWeb := h.subscribe(1)
+	app, cancelApp := h.subscribe(1)
+	other, cancelOther := h.subscribe(2)
+	defer cancelOther()
+
+	// 同一用户的多个连接都能收到 其他用户收不到
+	h.publish([]system.SysNotification{{UserId: 1, Title: "a"}, {UserId: 3, Title: "b"}})
+	assert.Equal(t, []system.SysNotification{{UserId: 1, Title: "a"}}, drain(web))
+	assert.Equal(t, []system.SysNotification{{UserId: 1, Title: "a"}}, drain(app))
+	assert.Empty(t, drain(other))
+
+	// 取消后不再推送 最后一个连接取消时移除用户
+	cancelWeb()
+	h.publish([]system.SysNotification{{UserId: 1, Title: "c"}})
+	assert.Empty(t, drain(web))
+	assert.Len(t, drain(app), 1)
+	cancelApp()
+	assert.NotContains(t, h.subs, uint(1))
+
+	// 客户端消费过慢时丢弃 不阻塞发送方
+	list := make([]system.SysNotification, notificationBuffer+4)
+	for i := range list {
+		list[i].UserId = 2
+	}
+	h.publish(list)
+	assert.Len(t, drain(other), notificationBuffer)
+}
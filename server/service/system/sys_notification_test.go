@@ -0,0 +1,105 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupNotificationTest(t *testing.T) *gorm.DB {
+	db := newTestDB(t, &system.SysNotificationTemplate{}, &system.SysNotification{})
+	assert.Nil(t, NotificationServiceApp.CreateTemplate(&system.SysNotificationTemplate{
+		Code:     "approval.todo",
+		Name:     "待审批",
+		Category: system.NotificationCategoryApproval,
+		Title:    "{{.Requester}} 提交了 {{.Action}}",
+		Content:  "请尽快处理",
+		Link:     "/approval/{{.Id}}",
+	}))
+	return db
+}
+
+func TestSendToUsers(t *testing.T) {
+	db := setupNotificationTest(t)
+	ch, cancel := NotificationServiceApp.Subscribe(2)
+	defer cancel()
+
+	// 重复和无效的接收人只发送一次
+	err := NotificationServiceApp.SendToUsers([]uint{1, 2, 1, 0}, "approval.todo", map[string]interface{}{"Requester": "alice", "Action": "删除用户", "Id": 7})
+	assert.Nil(t, err)
+	var list []system.SysNotification
+	assert.Nil(t, db.Order("user_id").Find(&list).Error)
+	require.Len(t, list, 2)
+	assert.Equal(t, uint(1), list[0].UserId)
+	assert.Equal(t, uint(2), list[1].UserId)
+	for _, n := range list {
+		assert.Equal(t, "alice 提交了 删除用户", n.Title)
+		assert.Equal(t, "请尽快处理", n.Content)
+		assert.Equal(t, "/approval/7", n.Link)
+		assert.Equal(t, system.NotificationCategoryApproval, n.Category)
+		assert.Equal(t, "approval.todo", n.TemplateCode)
+		assert.False(t, n.IsRead)
+	}
+
+	// 在线用户实时收到
+	select {
+	case n := <-ch:
+		assert.Equal(t, list[1].ID, n.ID)
+		assert.Equal(t, "alice 提交了 删除用户", n.Title)
+	default:
+		t.Fatal("未收到实时推送")
+	}
+
+	assert.EqualError(t, NotificationServiceApp.SendToUsers([]uint{1}, "missing", nil), "站内信模板不存在: missing")
+	assert.EqualError(t, NotificationServiceApp.SendToUsers([]uint{0}, "approval.todo", nil), "没有接收人")
+	assert.EqualError(t, NotificationServiceApp.Push([]uint{1}, system.SysNotification{}), "站内信标题不能为空")
+	assert.EqualError(t, NotificationServiceApp.CreateTemplate(&system.SysNotificationTemplate{Code: "bad", Name: "bad", Title: "{{.Name"}), "模板语法错误: template: notification:1: unclosed action")
+}
+
+func TestNotificationReadState(t *testing.T) {
+	db := setupNotificationTest(t)
+	// 直接发送时默认为系统通知 已读状态不会被复制给接收人
+	assert.Nil(t, NotificationServiceApp.Push([]uint{1, 2}, system.SysNotification{Title: "系统升级", IsRead: true}))
+	assert.Nil(t, NotificationServiceApp.Send(1, "approval.todo", nil))
+	assert.Nil(t, NotificationServiceApp.Send(1, "approval.todo", nil))
+
+	unread, err := NotificationServiceApp.GetUnread(1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), unread.Total)
+	assert.Equal(t, map[string]int64{system.NotificationCategorySystem: 1, system.NotificationCategoryApproval: 2}, unread.Categories)
+
+	var other system.SysNotification
+	assert.Nil(t, db.Where("user_id = ?", 2).First(&other).Error)
+	assert.EqualError(t, NotificationServiceApp.MarkRead(1, systemReq.NotificationRead{}), "请选择站内信")
+	// 不能标记其他用户的站内信
+	assert.Nil(t, NotificationServiceApp.MarkRead(1, systemReq.NotificationRead{Ids: []uint{other.ID}}))
+	assert.Nil(t, db.First(&other, other.ID).Error)
+	assert.False(t, other.IsRead)
+
+	assert.Nil(t, NotificationServiceApp.MarkRead(1, systemReq.NotificationRead{All: true, Category: system.NotificationCategoryApproval}))
+	list, total, err := NotificationServiceApp.GetMyNotifications(1, systemReq.NotificationSearch{Unread: true})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, list, 1)
+	assert.Equal(t, system.NotificationCategorySystem, list[0].Category)
+
+	list, total, err = NotificationServiceApp.GetMyNotifications(1, systemReq.NotificationSearch{Category: system.NotificationCategoryApproval})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	for _, n := range list {
+		assert.True(t, n.IsRead)
+		assert.NotNil(t, n.ReadAt)
+	}
+
+	assert.Nil(t, NotificationServiceApp.DeleteMyNotifications(1, systemReq.NotificationRead{Ids: []uint{list[0].ID, other.ID}}))
+	unread, err = NotificationServiceApp.GetUnread(2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), unread.Total)
+	_, total, err = NotificationServiceApp.GetMyNotifications(1, systemReq.NotificationSearch{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+}
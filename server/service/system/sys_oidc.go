@@ -0,0 +1,192 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/oidc"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	ErrOidcDisabled     = errors.New("未开启OIDC单点登录")
+	ErrOidcState        = errors.New("登录状态已失效,请重新登录")
+	ErrOidcNotProvision = errors.New("该外部账号未绑定本地用户")
+)
+
+type OidcService struct{}
+
+var OidcServiceApp = new(OidcService)
+
+var (
+	oidcMu          sync.Mutex
+	oidcProvider    *oidc.Provider
+	oidcProviderKey string // 创建 oidcProvider 时使用的配置
+)
+
+type oidcSession struct {
+	Nonce    string
+	Verifier string
+}
+
+// getProvider 懒加载Provider 配置的issuer、client或回调地址变化时重新发现
+func (oidcService *OidcService) getProvider(ctx context.Context) (*oidc.Provider, error) {
+	cfg := global.GVA_CONFIG.Oidc
+	if !cfg.Enable {
+		return nil, ErrOidcDisabled
+	}
+	key := fmt.Sprintf("%s|%s|%s|%s|%v", cfg.Issuer, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes)
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+	if oidcProvider != nil && oidcProviderKey == key {
+		return oidcProvider, nil
+	}
+	p, err := oidc.NewProvider(ctx, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	oidcProvider, oidcProviderKey = p, key
+	return p, nil
+}
+
+//@function: AuthCodeURL
+//@description: 生成跳转至身份提供方的授权地址 state/nonce/code_verifier 暂存于本地缓存
+//@param: ctx context.Context
+//@return: authURL string, err error
+
+func (oidcService *OidcService) AuthCodeURL(ctx context.Context) (authURL string, err error) {
+	p, err := oidcService.getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	state := utils.SecureRandomString(24)
+	session := oidcSession{Nonce: utils.SecureRandomString(24), Verifier: utils.SecureRandomString(48)}
+	global.BlackCache.Set("oidc:state:"+state, session, oidcStateTTL)
+	return p.AuthCodeURL(state, session.Nonce, session.Verifier), nil
+}
+
+//@function: Login
+//@description: 处理身份提供方回调 校验id_token并映射为本地用户
+//@param: ctx context.Context, state string, code string
+//@return: userInter *system.SysUser, err error
+
+func (oidcService *OidcService) Login(ctx context.Context, state, code string) (userInter *system.SysUser, err error) {
+	p, err := oidcService.getProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := global.BlackCache.Get("oidc:state:" + state)
+	if !ok {
+		return nil, ErrOidcState
+	}
+	// state 只能使用一次
+	global.BlackCache.Delete("oidc:state:" + state)
+	session := v.(oidcSession)
+
+	token, err := p.Exchange(ctx, code, session.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, session.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	user, err := oidcService.bindUser(p.Issuer, claims)
+	if err != nil {
+		return nil, err
+	}
+	global.GVA_DB.Create(&system.UserLoginHistory{
+		UserID:   uint64(user.ID),
+		DateTime: time.Now(),
+	})
+	MenuServiceApp.UserAuthorityDefaultRouter(user)
+	return user, nil
+}
+
+// bindUser 根据外部身份查找本地用户 未找到时按配置自动创建 并同步用户组对应的角色
+func (oidcService *OidcService) bindUser(provider string, claims oidc.Claims) (*system.SysUser, error) {
+	cfg := global.GVA_CONFIG.Oidc
+	subject := claims.Subject()
+	if subject == "" {
+		return nil, errors.New("id_token 缺少 sub")
+	}
+	usernameClaim := cfg.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "preferred_username"
+	}
+	groupsClaim := cfg.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+	authorityIds := mapGroupAuthorities(cfg.GroupMapping, claims.Strings(groupsClaim))
+
+	var user system.SysUser
+	var created bool
+	err := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var identity system.SysUserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			if err = tx.Where("id = ?", identity.UserId).First(&user).Error; err != nil {
+				return err
+			}
+		} else {
+			if !cfg.AutoProvision {
+				return ErrOidcNotProvision
+			}
+			username := claims.String(usernameClaim)
+			if username == "" {
+				username = subject
+			}
+			if !errors.Is(tx.Where("username = ?", username).First(&system.SysUser{}).Error, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("用户名 %s 已被本地账号占用", username)
+			}
+			if len(authorityIds) == 0 {
+				authorityIds = []uint{cfg.DefaultAuthorityId}
+			}
+			user = system.SysUser{
+				UUID:        uuid.New(),
+				Username:    username,
+				NickName:    claims.String("name"),
+				Email:       claims.String("email"),
+				Password:    utils.BcryptHash(utils.SecureRandomString(32)), // 外部账号不使用本地密码登录
+				AuthorityId: authorityIds[0],
+				Enable:      1,
+			}
+			if user.NickName == "" {
+				user.NickName = username
+			}
+			if err = tx.Create(&user).Error; err != nil {
+				return err
+			}
+			identity = system.SysUserIdentity{UserId: user.ID, Provider: provider, Subject: subject, Email: user.Email}
+			if err = tx.Create(&identity).Error; err != nil {
+				return err
+			}
+			created = true
+		}
+		if len(authorityIds) == 0 {
+			return nil
+		}
+		return syncUserAuthorities(tx, &user, authorityIds)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		userCreated(context.Background(), user)
+	}
+	err = global.GVA_DB.Preload("Authorities").Preload("Authority").First(&user, user.ID).Error
+	return &user, err
+}
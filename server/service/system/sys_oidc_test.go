@@ -0,0 +1,86 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/oidc"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const testIssuer = "https://idp.example.com"
+
+func setupOidcTest(t *testing.T, cfg config.Oidc) *gorm.DB {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysUser{}, &system.SysUserAuthority{}, &system.SysUserIdentity{})
+	for _, id := range []uint{888, 9528, 100} {
+		assert.Nil(t, db.Create(&system.SysAuthority{AuthorityId: id, AuthorityName: "role"}).Error)
+	}
+	old := global.GVA_CONFIG.Oidc
+	global.GVA_CONFIG.Oidc = cfg
+	t.Cleanup(func() { global.GVA_CONFIG.Oidc = old })
+	return db
+}
+
+func TestOidcBindUserProvision(t *testing.T) {
+	db := setupOidcTest(t, config.Oidc{
+		AutoProvision:      true,
+		DefaultAuthorityId: 888,
+		GroupMapping: []config.GroupAuthorityMapping{
+			{Group: "admins", AuthorityId: 9528},
+			{Group: "ops", AuthorityId: 100},
+		},
+	})
+	created := recordEvents(t, system.EventUserCreated)
+
+	// 首次登录自动创建 角色按映射顺序 第一个为当前角色
+	claims := oidc.Claims{"sub": "s-1", "preferred_username": "alice", "name": "Alice", "email": "alice@example.com", "groups": []interface{}{"ops", "admins", "unknown"}}
+	user, err := OidcServiceApp.bindUser(testIssuer, claims)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, "Alice", user.NickName)
+	assert.Equal(t, uint(9528), user.AuthorityId)
+	assert.Equal(t, []uint{100, 9528}, userAuthorityIds(t, db, user.ID))
+	assert.Equal(t, []system.UserEvent{{ID: user.ID, Username: "alice", AuthorityId: 9528}}, *created)
+	var identity system.SysUserIdentity
+	assert.Nil(t, db.Where("provider = ? AND subject = ?", testIssuer, "s-1").First(&identity).Error)
+	assert.Equal(t, user.ID, identity.UserId)
+
+	// 再次登录按 sub 找到同一用户 并以身份提供方的组为准同步角色
+	claims["groups"] = "ops"
+	claims["preferred_username"] = "alice2"
+	again, err := OidcServiceApp.bindUser(testIssuer, claims)
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, again.ID)
+	assert.Equal(t, "alice", again.Username)
+	assert.Equal(t, uint(100), again.AuthorityId)
+	assert.Equal(t, []uint{100}, userAuthorityIds(t, db, user.ID))
+	assert.Len(t, *created, 1)
+
+	// 没有映射到角色时使用默认角色 缺少用户名时使用 sub
+	user, err = OidcServiceApp.bindUser(testIssuer, oidc.Claims{"sub": "s-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "s-2", user.Username)
+	assert.Equal(t, uint(888), user.AuthorityId)
+	assert.Equal(t, []uint{888}, userAuthorityIds(t, db, user.ID))
+	assert.Len(t, *created, 2)
+}
+
+func TestOidcBindUserRejected(t *testing.T) {
+	db := setupOidcTest(t, config.Oidc{AutoProvision: true, DefaultAuthorityId: 888})
+	assert.Nil(t, db.Create(&system.SysUser{Username: "bob", AuthorityId: 888, Enable: 1}).Error)
+
+	_, err := OidcServiceApp.bindUser(testIssuer, oidc.Claims{"preferred_username": "carol"})
+	assert.EqualError(t, err, "id_token 缺少 sub")
+	_, err = OidcServiceApp.bindUser(testIssuer, oidc.Claims{"sub": "s-1", "preferred_username": "bob"})
+	assert.EqualError(t, err, "用户名 bob 已被本地账号占用")
+
+	global.GVA_CONFIG.Oidc.AutoProvision = false
+	_, err = OidcServiceApp.bindUser(testIssuer, oidc.Claims{"sub": "s-2", "preferred_username": "carol"})
+	assert.ErrorIs(t, err, ErrOidcNotProvision)
+	var count int64
+	db.Model(&system.SysUser{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
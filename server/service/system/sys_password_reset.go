@@ -0,0 +1,159 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	emailUtils "github.com/flipped-aurora/gin-vue-admin/server/plugin/email/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPasswordResetDisabled = errors.New("未开启找回密码功能")
+	ErrPasswordResetLimited  = errors.New("操作过于频繁,请稍后再试")
+	ErrPasswordResetToken    = errors.New("重置链接无效或已过期,请重新申请")
+)
+
+type PasswordResetService struct{}
+
+var PasswordResetServiceApp = new(PasswordResetService)
+
+// passwordResetLimited 按IP和账号分别计数 超过限流次数后拒绝
+func passwordResetLimited(keys ...string) bool {
+	cfg := global.GVA_CONFIG.PasswordReset
+	if cfg.LimitCount <= 0 {
+		return false
+	}
+	limited := false
+	for _, key := range keys {
+		key = "pwdreset:limit:" + key
+		// 计数存在时累加并保持原有过期时间 不存在或已过期时重新计数
+		// local_cache 的 Add 在键已存在时会删除该键 不能用于计数
+		n, err := global.BlackCache.IncrementInt(key, 1)
+		if err != nil {
+			n = 1
+			global.BlackCache.Set(key, n, time.Duration(cfg.LimitTime)*time.Second)
+		}
+		if n > cfg.LimitCount {
+			limited = true
+		}
+	}
+	return limited
+}
+
+//@function: SendResetLink
+//@description: 申请找回密码 账号存在且绑定邮箱时发送一次性重置链接 无论账号是否存在都返回相同结果
+//@param: account string, ip string
+//@return: err error
+
+func (passwordResetService *PasswordResetService) SendResetLink(account, ip string) (err error) {
+	cfg := global.GVA_CONFIG.PasswordReset
+	if !cfg.Enable {
+		return ErrPasswordResetDisabled
+	}
+	account = strings.TrimSpace(account)
+	if passwordResetLimited("ip:"+ip, "account:"+strings.ToLower(account)) {
+		return ErrPasswordResetLimited
+	}
+	var user system.SysUser
+	err = global.GVA_DB.Where("username = ? OR (email = ? AND email <> '')", account, account).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Enable != 1 || user.Email == "" {
+		return nil
+	}
+	token := utils.SecureRandomString(32)
+	expire := cfg.ExpireMinutes
+	if expire <= 0 {
+		expire = 30
+	}
+	reset := system.SysPasswordReset{
+		UserId:    user.ID,
+		TokenHash: utils.SHA256V([]byte(token)),
+		ExpiresAt: time.Now().Add(time.Duration(expire) * time.Minute),
+		RequestIp: ip,
+	}
+	if err = global.GVA_DB.Create(&reset).Error; err != nil {
+		return err
+	}
+	link := cfg.LinkURL
+	if strings.Contains(link, "?") {
+		link += "&token=" + token
+	} else {
+		link += "?token=" + token
+	}
+	body := fmt.Sprintf(`<p>%s 您好:</p><p>我们收到了重置您账号密码的申请,请在 %d 分钟内点击下方链接设置新密码:</p><p><a href="%s">%s</a></p><p>如果这不是您本人的操作,请忽略本邮件,您的密码不会被修改。</p>`,
+		html.EscapeString(user.NickName), expire, html.EscapeString(link), html.EscapeString(link))
+	// 异步发送 避免根据响应耗时判断账号是否存在
+	go func() {
+		if err := emailUtils.Email(user.Email, "重置密码", body); err != nil {
+			global.GVA_LOG.Error("发送重置密码邮件失败!", zap.String("username", user.Username), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+//@function: ResetPasswordByToken
+//@description: 使用邮件中的一次性令牌重置密码 成功后吊销该用户的全部会话
+//@param: token string, password string
+//@return: err error
+
+func (passwordResetService *PasswordResetService) ResetPasswordByToken(token, password string) (err error) {
+	if !global.GVA_CONFIG.PasswordReset.Enable {
+		return ErrPasswordResetDisabled
+	}
+	var user system.SysUser
+	now := time.Now()
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var reset system.SysPasswordReset
+		err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", utils.SHA256V([]byte(token)), now).First(&reset).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPasswordResetToken
+			}
+			return err
+		}
+		if err = tx.Where("id = ?", reset.UserId).First(&user).Error; err != nil {
+			return ErrPasswordResetToken
+		}
+		if user.Enable != 1 {
+			return ErrPasswordResetToken
+		}
+		if err = checkNewPassword(tx, user, password); err != nil {
+			return err
+		}
+		// 条件更新保证令牌只能被使用一次 同时作废该用户其它未使用的令牌
+		res := tx.Model(&system.SysPasswordReset{}).Where("user_id = ? AND used_at IS NULL", user.ID).Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPasswordResetToken
+		}
+		hash := utils.BcryptHash(password)
+		err = tx.Model(&system.SysUser{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"password":              hash,
+			"password_updated_at":   now,
+			"force_change_password": false,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordPasswordHistory(tx, user.ID, hash)
+	})
+	if err != nil {
+		return err
+	}
+	return JwtServiceApp.RevokeUserTokens(user.UUID.String(), user.Username, now)
+}
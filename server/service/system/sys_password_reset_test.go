@@ -0,0 +1,127 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupPasswordResetTest(t *testing.T) (*gorm.DB, system.SysUser) {
+	db := newTestDB(t, &system.SysUser{}, &system.SysPasswordReset{}, &system.SysUserPasswordHistory{})
+	newTestCache(t)
+	oldReset, oldJWT, oldPolicy, oldSystem := global.GVA_CONFIG.PasswordReset, global.GVA_CONFIG.JWT, global.GVA_CONFIG.PasswordPolicy, global.GVA_CONFIG.System
+	global.GVA_CONFIG.PasswordReset = config.PasswordReset{Enable: true, LimitCount: 2, LimitTime: 60}
+	global.GVA_CONFIG.JWT.ExpiresTime = "1h"
+	global.GVA_CONFIG.PasswordPolicy = config.PasswordPolicy{}
+	global.GVA_CONFIG.System.UseMultipoint = false
+	t.Cleanup(func() {
+		global.GVA_CONFIG.PasswordReset, global.GVA_CONFIG.JWT, global.GVA_CONFIG.PasswordPolicy, global.GVA_CONFIG.System = oldReset, oldJWT, oldPolicy, oldSystem
+	})
+	user := system.SysUser{UUID: uuid.New(), Username: "alice", Password: utils.BcryptHash("old-pass"), Email: "alice@example.com", AuthorityId: 888, Enable: 1}
+	assert.Nil(t, db.Create(&user).Error)
+	return db, user
+}
+
+// newResetToken 直接写入一条重置记录 返回明文令牌
+func newResetToken(t *testing.T, db *gorm.DB, userId uint, expiresAt time.Time) string {
+	token := utils.SecureRandomString(32)
+	assert.Nil(t, db.Create(&system.SysPasswordReset{UserId: userId, TokenHash: utils.SHA256V([]byte(token)), ExpiresAt: expiresAt}).Error)
+	return token
+}
+
+func issuedAt(id uuid.UUID, at time.Time) *systemReq.CustomClaims {
+	return &systemReq.CustomClaims{
+		BaseClaims:       systemReq.BaseClaims{UUID: id},
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(at)},
+	}
+}
+
+func TestResetPasswordByToken(t *testing.T) {
+	db, user := setupPasswordResetTest(t)
+	token := newResetToken(t, db, user.ID, time.Now().Add(time.Hour))
+	pending := newResetToken(t, db, user.ID, time.Now().Add(time.Hour))
+	expired := newResetToken(t, db, user.ID, time.Now().Add(-time.Second))
+	before := issuedAt(user.UUID, time.Now().Add(-time.Minute))
+
+	assert.ErrorIs(t, PasswordResetServiceApp.ResetPasswordByToken(expired, "new-pass"), ErrPasswordResetToken)
+	assert.ErrorIs(t, PasswordResetServiceApp.ResetPasswordByToken("unknown", "new-pass"), ErrPasswordResetToken)
+	assert.False(t, JwtServiceApp.IsRevoked(before))
+
+	assert.Nil(t, PasswordResetServiceApp.ResetPasswordByToken(token, "new-pass"))
+	var updated system.SysUser
+	assert.Nil(t, db.First(&updated, user.ID).Error)
+	assert.True(t, utils.BcryptCheck("new-pass", updated.Password))
+	assert.NotNil(t, updated.PasswordUpdatedAt)
+	// 重置前签发的会话全部失效
+	assert.True(t, JwtServiceApp.IsRevoked(before))
+
+	// 令牌只能使用一次 同一用户其它未使用的令牌一并作废
+	assert.ErrorIs(t, PasswordResetServiceApp.ResetPasswordByToken(token, "other-pass"), ErrPasswordResetToken)
+	assert.ErrorIs(t, PasswordResetServiceApp.ResetPasswordByToken(pending, "other-pass"), ErrPasswordResetToken)
+	var unused int64
+	db.Model(&system.SysPasswordReset{}).Where("used_at IS NULL AND expires_at > ?", time.Now()).Count(&unused)
+	assert.Equal(t, int64(0), unused)
+
+	// 冻结用户的令牌无效
+	frozen := newResetToken(t, db, user.ID, time.Now().Add(time.Hour))
+	assert.Nil(t, db.Model(&user).Update("enable", 2).Error)
+	assert.ErrorIs(t, PasswordResetServiceApp.ResetPasswordByToken(frozen, "other-pass"), ErrPasswordResetToken)
+
+	global.GVA_CONFIG.PasswordReset.Enable = false
+	assert.ErrorIs(t, PasswordResetServiceApp.ResetPasswordByToken(frozen, "other-pass"), ErrPasswordResetDisabled)
+}
+
+func TestSendResetLinkLimited(t *testing.T) {
+	db, user := setupPasswordResetTest(t)
+
+	// 不存在的账号与存在的账号返回相同结果 同一IP或账号超过次数后限流
+	assert.Nil(t, PasswordResetServiceApp.SendResetLink("nobody", "10.0.0.1"))
+	assert.Nil(t, PasswordResetServiceApp.SendResetLink("NOBODY", "10.0.0.1"))
+	assert.ErrorIs(t, PasswordResetServiceApp.SendResetLink("nobody", "10.0.0.1"), ErrPasswordResetLimited)
+	assert.ErrorIs(t, PasswordResetServiceApp.SendResetLink("nobody", "10.0.0.2"), ErrPasswordResetLimited)
+	assert.ErrorIs(t, PasswordResetServiceApp.SendResetLink("someone", "10.0.0.1"), ErrPasswordResetLimited)
+	assert.Nil(t, PasswordResetServiceApp.SendResetLink("someone", "10.0.0.2"))
+
+	// 冻结或未绑定邮箱的账号不发送
+	assert.Nil(t, db.Model(&user).Update("email", "").Error)
+	assert.Nil(t, PasswordResetServiceApp.SendResetLink("alice", "10.0.0.3"))
+	var count int64
+	db.Model(&system.SysPasswordReset{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// 不配置次数时不限流
+	global.GVA_CONFIG.PasswordReset.LimitCount = 0
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, PasswordResetServiceApp.SendResetLink("nobody", "10.0.0.1"))
+	}
+	global.GVA_CONFIG.PasswordReset.Enable = false
+	assert.ErrorIs(t, PasswordResetServiceApp.SendResetLink("nobody", "10.0.0.1"), ErrPasswordResetDisabled)
+}
+
+func TestRevokeUserTokensBoundary(t *testing.T) {
+	_, user := setupPasswordResetTest(t)
+	at := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+	assert.Nil(t, JwtServiceApp.RevokeUserTokens(user.UUID.String(), user.Username, at))
+
+	// 签发时间只精确到秒 吊销所在秒之前签发的拒绝 之后签发的放行
+	assert.True(t, JwtServiceApp.IsRevoked(issuedAt(user.UUID, at.Add(-time.Second))))
+	assert.True(t, JwtServiceApp.IsRevoked(&systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{UUID: user.UUID}}))
+	assert.False(t, JwtServiceApp.IsRevoked(issuedAt(user.UUID, at)))
+	assert.False(t, JwtServiceApp.IsRevoked(issuedAt(user.UUID, at.Add(time.Second))))
+	assert.False(t, JwtServiceApp.IsRevoked(issuedAt(uuid.New(), at.Add(-time.Second))))
+
+	// 吊销时间早于 jwt 有效期时 之前签发的令牌已自然过期 无需记录
+	other := uuid.New()
+	assert.Nil(t, JwtServiceApp.RevokeUserTokens(other.String(), "bob", at.Add(-2*time.Hour)))
+	_, ok := global.BlackCache.Get("jwt:revoked:" + other.String())
+	assert.False(t, ok)
+}
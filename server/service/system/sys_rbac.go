@@ -0,0 +1,626 @@
+package system
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+type RbacService struct{}
+
+var RbacServiceApp = new(RbacService)
+
+//@function: ExportRbac
+//@description: 导出当前环境的角色、菜单、按钮、api 与 casbin 策略
+//@return: bundle system.RbacBundle, err error
+
+func (rbacService *RbacService) ExportRbac() (bundle system.RbacBundle, err error) {
+	return rbacService.export(global.GVA_DB)
+}
+
+//@function: EncodeRbacBundle
+//@description: 将配置包编码为 json 或 yaml
+//@param: bundle system.RbacBundle, format string
+//@return: []byte, error
+
+func (rbacService *RbacService) EncodeRbacBundle(bundle system.RbacBundle, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil || format != "yaml" {
+		return data, err
+	}
+	// 经由 json 转换 保证 yaml 与 json 使用相同的字段名
+	var v interface{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+//@function: DecodeRbacBundle
+//@description: 解析 json 或 yaml 格式的配置包
+//@param: data []byte
+//@return: bundle system.RbacBundle, err error
+
+func (rbacService *RbacService) DecodeRbacBundle(data []byte) (bundle system.RbacBundle, err error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		var v interface{}
+		if err = yaml.Unmarshal(data, &v); err != nil {
+			return bundle, fmt.Errorf("配置包格式错误: %w", err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return bundle, fmt.Errorf("配置包格式错误: %w", err)
+		}
+	}
+	if err = json.Unmarshal(data, &bundle); err != nil {
+		return bundle, fmt.Errorf("配置包格式错误: %w", err)
+	}
+	if bundle.Version == 0 || bundle.Version > system.RbacBundleVersion {
+		return bundle, fmt.Errorf("不支持的配置包版本: %d", bundle.Version)
+	}
+	return bundle, nil
+}
+
+//@function: ImportRbac
+//@description: 对比配置包与当前环境的差异 apply 为 true 时按策略在事务中导入 导入不会删除当前环境独有的配置
+//@param: bundle system.RbacBundle, strategy string, apply bool
+//@return: result systemRes.RbacImportResult, err error
+
+func (rbacService *RbacService) ImportRbac(bundle system.RbacBundle, strategy string, apply bool) (result systemRes.RbacImportResult, err error) {
+	switch strategy {
+	case request.RbacImportSkip, request.RbacImportOverwrite, request.RbacImportMerge:
+	case "":
+		strategy = request.RbacImportSkip
+	default:
+		return result, errors.New("未知的冲突处理策略: " + strategy)
+	}
+	result.Strategy = strategy
+	current, err := rbacService.export(global.GVA_DB)
+	if err != nil {
+		return result, err
+	}
+	rbacService.diff(&result, current, bundle)
+	if !apply {
+		return result, nil
+	}
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := rbacService.importApis(tx, bundle.Apis, strategy); err != nil {
+			return err
+		}
+		menuIds, btnIds, err := rbacService.importMenus(tx, bundle.Menus, strategy)
+		if err != nil {
+			return err
+		}
+		return rbacService.importAuthorities(tx, bundle.Authorities, strategy, menuIds, btnIds)
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Applied = true
+	for _, a := range bundle.Authorities {
+		authorityChanged(a.AuthorityId, system.AuthorityImported)
+	}
+	// 导入已提交 刷新失败时返回ErrCasbinRefresh 由调用方提示导入成功但策略未生效
+	return result, refreshCasbin()
+}
+
+func (rbacService *RbacService) export(db *gorm.DB) (bundle system.RbacBundle, err error) {
+	bundle.Version = system.RbacBundleVersion
+	bundle.ExportedAt = time.Now()
+
+	var apis []system.SysApi
+	if err = db.Order("api_group, path, method").Find(&apis).Error; err != nil {
+		return
+	}
+	for _, a := range apis {
+		bundle.Apis = append(bundle.Apis, system.RbacApi{Path: a.Path, Method: a.Method, ApiGroup: a.ApiGroup, Description: a.Description})
+	}
+
+	var menus []system.SysBaseMenu
+	if err = db.Preload("Parameters").Preload("MenuBtn").Order("sort, id").Find(&menus).Error; err != nil {
+		return
+	}
+	menuNames := make(map[uint]string, len(menus))
+	btnNames := make(map[uint]string)
+	children := make(map[uint][]system.SysBaseMenu)
+	for _, m := range menus {
+		menuNames[m.ID] = m.Name
+		children[m.ParentId] = append(children[m.ParentId], m)
+		for _, b := range m.MenuBtn {
+			btnNames[b.ID] = b.Name
+		}
+	}
+	// 按树的先序导出 保证导入时父菜单先于子菜单创建
+	visited := make(map[uint]bool, len(menus))
+	var walk func(parentId uint)
+	walk = func(parentId uint) {
+		for _, m := range children[parentId] {
+			if visited[m.ID] {
+				continue
+			}
+			visited[m.ID] = true
+			bundle.Menus = append(bundle.Menus, rbacMenuOf(m, menuNames[m.ParentId]))
+			walk(m.ID)
+		}
+	}
+	walk(0)
+	for _, m := range menus {
+		if !visited[m.ID] {
+			// 父菜单已不存在的菜单按顶级菜单导出
+			visited[m.ID] = true
+			bundle.Menus = append(bundle.Menus, rbacMenuOf(m, ""))
+		}
+	}
+
+	var authorities []system.SysAuthority
+	if err = db.Preload("DataAuthorityId").Preload("SysBaseMenus").Order("authority_id").Find(&authorities).Error; err != nil {
+		return
+	}
+	var btns []system.SysAuthorityBtn
+	if err = db.Find(&btns).Error; err != nil {
+		return
+	}
+	var rules []gormadapter.CasbinRule
+	if err = db.Where("ptype = ?", "p").Order("v1, v2").Find(&rules).Error; err != nil {
+		return
+	}
+	for _, a := range authorities {
+		ra := system.RbacAuthority{
+			AuthorityId:   a.AuthorityId,
+			AuthorityName: a.AuthorityName,
+			DefaultRouter: a.DefaultRouter,
+		}
+		if a.ParentId != nil {
+			ra.ParentId = *a.ParentId
+		}
+		for _, d := range a.DataAuthorityId {
+			ra.DataAuthorityIds = append(ra.DataAuthorityIds, d.AuthorityId)
+		}
+		for _, m := range a.SysBaseMenus {
+			ra.Menus = append(ra.Menus, m.Name)
+		}
+		for _, b := range btns {
+			if b.AuthorityId == a.AuthorityId && menuNames[b.SysMenuID] != "" && btnNames[b.SysBaseMenuBtnID] != "" {
+				ra.Buttons = append(ra.Buttons, system.RbacAuthorityBtn{Menu: menuNames[b.SysMenuID], Button: btnNames[b.SysBaseMenuBtnID]})
+			}
+		}
+		sub := strconv.Itoa(int(a.AuthorityId))
+		for _, r := range rules {
+			if r.V0 == sub {
+				ra.Policies = append(ra.Policies, system.RbacPolicy{Path: r.V1, Method: r.V2})
+			}
+		}
+		normalizeRbacAuthority(&ra)
+		bundle.Authorities = append(bundle.Authorities, ra)
+	}
+	return bundle, nil
+}
+
+func rbacMenuOf(m system.SysBaseMenu, parentName string) system.RbacMenu {
+	rm := system.RbacMenu{
+		Name:       m.Name,
+		ParentName: parentName,
+		Path:       m.Path,
+		Hidden:     m.Hidden,
+		Component:  m.Component,
+		Sort:       m.Sort,
+		Meta:       m.Meta,
+	}
+	for _, p := range m.Parameters {
+		rm.Parameters = append(rm.Parameters, system.RbacMenuParameter{Type: p.Type, Key: p.Key, Value: p.Value})
+	}
+	for _, b := range m.MenuBtn {
+		rm.Buttons = append(rm.Buttons, system.RbacMenuButton{Name: b.Name, Desc: b.Desc})
+	}
+	normalizeRbacMenu(&rm)
+	return rm
+}
+
+// normalizeRbacMenu 排序并去掉空切片 使比较结果与导出顺序无关
+func normalizeRbacMenu(m *system.RbacMenu) {
+	sort.Slice(m.Parameters, func(i, j int) bool {
+		return m.Parameters[i].Type+m.Parameters[i].Key < m.Parameters[j].Type+m.Parameters[j].Key
+	})
+	sort.Slice(m.Buttons, func(i, j int) bool { return m.Buttons[i].Name < m.Buttons[j].Name })
+	if len(m.Parameters) == 0 {
+		m.Parameters = nil
+	}
+	if len(m.Buttons) == 0 {
+		m.Buttons = nil
+	}
+}
+
+func normalizeRbacAuthority(a *system.RbacAuthority) {
+	sort.Slice(a.DataAuthorityIds, func(i, j int) bool { return a.DataAuthorityIds[i] < a.DataAuthorityIds[j] })
+	sort.Strings(a.Menus)
+	sort.Slice(a.Buttons, func(i, j int) bool {
+		return a.Buttons[i].Menu+" "+a.Buttons[i].Button < a.Buttons[j].Menu+" "+a.Buttons[j].Button
+	})
+	sort.Slice(a.Policies, func(i, j int) bool {
+		return a.Policies[i].Path+" "+a.Policies[i].Method < a.Policies[j].Path+" "+a.Policies[j].Method
+	})
+	if len(a.DataAuthorityIds) == 0 {
+		a.DataAuthorityIds = nil
+	}
+	if len(a.Menus) == 0 {
+		a.Menus = nil
+	}
+	if len(a.Buttons) == 0 {
+		a.Buttons = nil
+	}
+	if len(a.Policies) == 0 {
+		a.Policies = nil
+	}
+}
+
+func rbacApiKey(a system.RbacApi) string {
+	return a.Method + " " + a.Path
+}
+
+func rbacAuthorityKey(a system.RbacAuthority) string {
+	return strconv.Itoa(int(a.AuthorityId)) + "(" + a.AuthorityName + ")"
+}
+
+func (rbacService *RbacService) diff(result *systemRes.RbacImportResult, current, bundle system.RbacBundle) {
+	apis := make(map[string]system.RbacApi, len(current.Apis))
+	for _, a := range current.Apis {
+		apis[rbacApiKey(a)] = a
+	}
+	for _, a := range bundle.Apis {
+		key := rbacApiKey(a)
+		old, ok := apis[key]
+		switch {
+		case !ok:
+			result.Apis.Added = append(result.Apis.Added, key)
+		case old != a:
+			result.Apis.Changed = append(result.Apis.Changed, key)
+		default:
+			result.Apis.Unchanged++
+		}
+		delete(apis, key)
+	}
+	for key := range apis {
+		result.Apis.Missing = append(result.Apis.Missing, key)
+	}
+	sort.Strings(result.Apis.Missing)
+
+	menus := make(map[string]system.RbacMenu, len(current.Menus))
+	for _, m := range current.Menus {
+		menus[m.Name] = m
+	}
+	for _, m := range bundle.Menus {
+		normalizeRbacMenu(&m)
+		old, ok := menus[m.Name]
+		switch {
+		case !ok:
+			result.Menus.Added = append(result.Menus.Added, m.Name)
+		case !reflect.DeepEqual(old, m):
+			result.Menus.Changed = append(result.Menus.Changed, m.Name)
+		default:
+			result.Menus.Unchanged++
+		}
+		delete(menus, m.Name)
+	}
+	for name := range menus {
+		result.Menus.Missing = append(result.Menus.Missing, name)
+	}
+	sort.Strings(result.Menus.Missing)
+
+	authorities := make(map[uint]system.RbacAuthority, len(current.Authorities))
+	for _, a := range current.Authorities {
+		authorities[a.AuthorityId] = a
+	}
+	for _, a := range bundle.Authorities {
+		normalizeRbacAuthority(&a)
+		old, ok := authorities[a.AuthorityId]
+		if !ok {
+			result.Authorities.Added = append(result.Authorities.Added, rbacAuthorityKey(a))
+			continue
+		}
+		delete(authorities, a.AuthorityId)
+		var parts []string
+		if old.AuthorityName != a.AuthorityName || old.ParentId != a.ParentId || old.DefaultRouter != a.DefaultRouter {
+			parts = append(parts, "基本信息")
+		}
+		if !reflect.DeepEqual(old.DataAuthorityIds, a.DataAuthorityIds) {
+			parts = append(parts, "数据权限")
+		}
+		if !reflect.DeepEqual(old.Menus, a.Menus) {
+			parts = append(parts, "菜单")
+		}
+		if !reflect.DeepEqual(old.Buttons, a.Buttons) {
+			parts = append(parts, "按钮")
+		}
+		if !reflect.DeepEqual(old.Policies, a.Policies) {
+			parts = append(parts, "api权限")
+		}
+		if len(parts) == 0 {
+			result.Authorities.Unchanged++
+			continue
+		}
+		result.Authorities.Changed = append(result.Authorities.Changed, rbacAuthorityKey(a)+": "+strings.Join(parts, ","))
+	}
+	for _, a := range authorities {
+		result.Authorities.Missing = append(result.Authorities.Missing, rbacAuthorityKey(a))
+	}
+	sort.Strings(result.Authorities.Missing)
+}
+
+func (rbacService *RbacService) importApis(tx *gorm.DB, apis []system.RbacApi, strategy string) error {
+	for _, a := range apis {
+		var old system.SysApi
+		err := tx.Where("path = ? AND method = ?", a.Path, a.Method).First(&old).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err = tx.Create(&system.SysApi{Path: a.Path, Method: a.Method, ApiGroup: a.ApiGroup, Description: a.Description}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if strategy == request.RbacImportSkip {
+			continue
+		}
+		err = tx.Model(&old).Updates(map[string]interface{}{"api_group": a.ApiGroup, "description": a.Description}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importMenus 导入菜单 返回 路由name -> 菜单ID 以及 "路由name 按钮key" -> 按钮ID
+func (rbacService *RbacService) importMenus(tx *gorm.DB, menus []system.RbacMenu, strategy string) (menuIds map[string]uint, btnIds map[string]uint, err error) {
+	var existing []system.SysBaseMenu
+	if err = tx.Preload("Parameters").Preload("MenuBtn").Find(&existing).Error; err != nil {
+		return
+	}
+	menuIds = make(map[string]uint, len(existing))
+	btnIds = make(map[string]uint)
+	byName := make(map[string]system.SysBaseMenu, len(existing))
+	for _, m := range existing {
+		menuIds[m.Name] = m.ID
+		byName[m.Name] = m
+		for _, b := range m.MenuBtn {
+			btnIds[m.Name+" "+b.Name] = b.ID
+		}
+	}
+	for _, rm := range menus {
+		old, ok := byName[rm.Name]
+		if !ok {
+			menu := system.SysBaseMenu{
+				ParentId:  menuIds[rm.ParentName],
+				Path:      rm.Path,
+				Name:      rm.Name,
+				Hidden:    rm.Hidden,
+				Component: rm.Component,
+				Sort:      rm.Sort,
+				Meta:      rm.Meta,
+			}
+			for _, p := range rm.Parameters {
+				menu.Parameters = append(menu.Parameters, system.SysBaseMenuParameter{Type: p.Type, Key: p.Key, Value: p.Value})
+			}
+			for _, b := range rm.Buttons {
+				menu.MenuBtn = append(menu.MenuBtn, system.SysBaseMenuBtn{Name: b.Name, Desc: b.Desc})
+			}
+			if err = tx.Create(&menu).Error; err != nil {
+				return
+			}
+			menuIds[rm.Name] = menu.ID
+			for _, b := range menu.MenuBtn {
+				btnIds[rm.Name+" "+b.Name] = b.ID
+			}
+			continue
+		}
+		if strategy == request.RbacImportSkip {
+			continue
+		}
+		err = tx.Model(&old).Updates(map[string]interface{}{
+			"parent_id":       menuIds[rm.ParentName],
+			"path":            rm.Path,
+			"hidden":          rm.Hidden,
+			"component":       rm.Component,
+			"sort":            rm.Sort,
+			"active_name":     rm.Meta.ActiveName,
+			"keep_alive":      rm.Meta.KeepAlive,
+			"default_menu":    rm.Meta.DefaultMenu,
+			"title":           rm.Meta.Title,
+			"icon":            rm.Meta.Icon,
+			"close_tab":       rm.Meta.CloseTab,
+			"transition_type": rm.Meta.TransitionType,
+		}).Error
+		if err != nil {
+			return
+		}
+		if strategy == request.RbacImportOverwrite {
+			if err = tx.Where("sys_base_menu_id = ?", old.ID).Delete(&system.SysBaseMenuParameter{}).Error; err != nil {
+				return
+			}
+			old.Parameters = nil
+		}
+		for _, p := range rm.Parameters {
+			found := false
+			for _, op := range old.Parameters {
+				if op.Type == p.Type && op.Key == p.Key {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+			if err = tx.Create(&system.SysBaseMenuParameter{SysBaseMenuID: old.ID, Type: p.Type, Key: p.Key, Value: p.Value}).Error; err != nil {
+				return
+			}
+		}
+		wanted := make(map[string]bool, len(rm.Buttons))
+		for _, b := range rm.Buttons {
+			wanted[b.Name] = true
+			if _, ok := btnIds[rm.Name+" "+b.Name]; ok {
+				continue
+			}
+			btn := system.SysBaseMenuBtn{Name: b.Name, Desc: b.Desc, SysBaseMenuID: old.ID}
+			if err = tx.Create(&btn).Error; err != nil {
+				return
+			}
+			btnIds[rm.Name+" "+b.Name] = btn.ID
+		}
+		if strategy != request.RbacImportOverwrite {
+			continue
+		}
+		// 覆盖模式下删除导入包中没有的按钮及其授权
+		for _, b := range old.MenuBtn {
+			if wanted[b.Name] {
+				continue
+			}
+			if err = tx.Where("sys_base_menu_btn_id = ?", b.ID).Delete(&system.SysAuthorityBtn{}).Error; err != nil {
+				return
+			}
+			if err = tx.Unscoped().Delete(&b).Error; err != nil {
+				return
+			}
+			delete(btnIds, rm.Name+" "+b.Name)
+		}
+	}
+	return menuIds, btnIds, nil
+}
+
+func (rbacService *RbacService) importAuthorities(tx *gorm.DB, authorities []system.RbacAuthority, strategy string, menuIds, btnIds map[string]uint) error {
+	// 数据权限可能引用包内靠后的角色 待全部角色写入后再设置
+	var dataAuthorities []system.RbacAuthority
+	for _, ra := range authorities {
+		sub := strconv.Itoa(int(ra.AuthorityId))
+		parentId := ra.ParentId
+		replace := strategy == request.RbacImportOverwrite
+		var old system.SysAuthority
+		err := tx.Where("authority_id = ?", ra.AuthorityId).First(&old).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			auth := system.SysAuthority{AuthorityId: ra.AuthorityId, AuthorityName: ra.AuthorityName, ParentId: &parentId, DefaultRouter: ra.DefaultRouter}
+			if err = tx.Create(&auth).Error; err != nil {
+				return err
+			}
+			replace = true
+		} else if err != nil {
+			return err
+		} else if strategy == request.RbacImportSkip {
+			continue
+		} else {
+			err = tx.Model(&old).Updates(map[string]interface{}{
+				"authority_name": ra.AuthorityName,
+				"parent_id":      parentId,
+				"default_router": ra.DefaultRouter,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		dataAuthorities = append(dataAuthorities, ra)
+
+		if replace {
+			if err = tx.Where("sys_authority_authority_id = ?", sub).Delete(&system.SysAuthorityMenu{}).Error; err != nil {
+				return err
+			}
+			if err = tx.Where("authority_id = ?", ra.AuthorityId).Delete(&system.SysAuthorityBtn{}).Error; err != nil {
+				return err
+			}
+			if err = CasbinServiceApp.RemoveFilteredPolicy(tx.Where("ptype = ?", "p"), sub); err != nil {
+				return err
+			}
+		}
+
+		var menus []system.SysAuthorityMenu
+		if err = tx.Where("sys_authority_authority_id = ?", sub).Find(&menus).Error; err != nil {
+			return err
+		}
+		hasMenu := make(map[string]bool, len(menus))
+		for _, m := range menus {
+			hasMenu[m.MenuId] = true
+		}
+		for _, name := range ra.Menus {
+			id, ok := menuIds[name]
+			if !ok || hasMenu[strconv.Itoa(int(id))] {
+				continue
+			}
+			hasMenu[strconv.Itoa(int(id))] = true
+			if err = tx.Create(&system.SysAuthorityMenu{MenuId: strconv.Itoa(int(id)), AuthorityId: sub}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, b := range ra.Buttons {
+			menuId, btnId := menuIds[b.Menu], btnIds[b.Menu+" "+b.Button]
+			if menuId == 0 || btnId == 0 {
+				continue
+			}
+			err = tx.Where("authority_id = ? AND sys_menu_id = ? AND sys_base_menu_btn_id = ?", ra.AuthorityId, menuId, btnId).First(&system.SysAuthorityBtn{}).Error
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err = tx.Create(&system.SysAuthorityBtn{AuthorityId: ra.AuthorityId, SysMenuID: menuId, SysBaseMenuBtnID: btnId}).Error; err != nil {
+				return err
+			}
+		}
+
+		var rules []gormadapter.CasbinRule
+		if err = tx.Where("ptype = ? AND v0 = ?", "p", sub).Find(&rules).Error; err != nil {
+			return err
+		}
+		hasRule := make(map[string]bool, len(rules))
+		for _, r := range rules {
+			hasRule[r.V1+" "+r.V2] = true
+		}
+		var newRules [][]string
+		for _, p := range ra.Policies {
+			if hasRule[p.Path+" "+p.Method] {
+				continue
+			}
+			hasRule[p.Path+" "+p.Method] = true
+			newRules = append(newRules, []string{sub, p.Path, p.Method})
+		}
+		if len(newRules) > 0 {
+			if err = CasbinServiceApp.AddPolicies(tx, newRules); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ra := range dataAuthorities {
+		var targets []*system.SysAuthority
+		if len(ra.DataAuthorityIds) > 0 {
+			if err := tx.Where("authority_id IN ?", ra.DataAuthorityIds).Find(&targets).Error; err != nil {
+				return err
+			}
+		}
+		association := tx.Model(&system.SysAuthority{AuthorityId: ra.AuthorityId}).Association("DataAuthorityId")
+		var err error
+		if strategy == request.RbacImportMerge {
+			if len(targets) > 0 {
+				err = association.Append(targets)
+			}
+		} else {
+			err = association.Replace(targets)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
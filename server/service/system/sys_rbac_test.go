@@ -0,0 +1,115 @@
+package system
+
+import (
+	"testing"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupRbacTest 准备一个角色 888 拥有 dashboard 菜单与 GET /a 策略 返回基于当前环境修改后的导入包
+func setupRbacTest(t *testing.T) system.RbacBundle {
+	db := newCasbinTestDB(t)
+	assert.Nil(t, db.AutoMigrate(&system.SysApi{}, &system.SysBaseMenuParameter{}, &system.SysBaseMenuBtn{}, &system.SysAuthorityMenu{}))
+
+	assert.Nil(t, db.Create(&system.SysApi{Path: "/a", Method: "GET", ApiGroup: "a", Description: "旧描述"}).Error)
+	menu := system.SysBaseMenu{Name: "dashboard", Path: "dashboard", Component: "view/dashboard/index.vue", MenuBtn: []system.SysBaseMenuBtn{{Name: "add"}}}
+	assert.Nil(t, db.Create(&menu).Error)
+	parent := uint(0)
+	assert.Nil(t, db.Create(&system.SysAuthority{AuthorityId: 888, AuthorityName: "管理员", ParentId: &parent}).Error)
+	assert.Nil(t, db.Create(&system.SysAuthorityMenu{MenuId: "1", AuthorityId: "888"}).Error)
+	assert.Nil(t, CasbinServiceApp.AddPolicies(db, [][]string{{"888", "/a", "GET"}}))
+
+	bundle, err := RbacServiceApp.ExportRbac()
+	assert.Nil(t, err)
+	bundle.Apis[0].Description = "新描述"
+	bundle.Apis = append(bundle.Apis, system.RbacApi{Path: "/b", Method: "POST", ApiGroup: "b"})
+	bundle.Authorities[0].Policies = []system.RbacPolicy{{Path: "/b", Method: "POST"}}
+	bundle.Authorities = append(bundle.Authorities, system.RbacAuthority{AuthorityId: 999, AuthorityName: "访客", Menus: []string{"dashboard"}})
+	return bundle
+}
+
+func rbacPolicies(t *testing.T, sub string) []string {
+	var rules []gormadapter.CasbinRule
+	assert.Nil(t, global.GVA_DB.Where("ptype = ? AND v0 = ?", "p", sub).Order("v1").Find(&rules).Error)
+	var policies []string
+	for _, r := range rules {
+		policies = append(policies, r.V2+" "+r.V1)
+	}
+	return policies
+}
+
+func TestImportRbacDryRun(t *testing.T) {
+	bundle := setupRbacTest(t)
+
+	result, err := RbacServiceApp.ImportRbac(bundle, "", false)
+	assert.Nil(t, err)
+	assert.False(t, result.Applied)
+	assert.Equal(t, request.RbacImportSkip, result.Strategy)
+	assert.Equal(t, []string{"POST /b"}, result.Apis.Added)
+	assert.Equal(t, []string{"GET /a"}, result.Apis.Changed)
+	assert.Equal(t, 1, result.Menus.Unchanged)
+	assert.Equal(t, []string{"999(访客)"}, result.Authorities.Added)
+	assert.Equal(t, []string{"888(管理员): api权限"}, result.Authorities.Changed)
+
+	// 预览不写入任何数据
+	var apis int64
+	global.GVA_DB.Model(&system.SysApi{}).Count(&apis)
+	assert.Equal(t, int64(1), apis)
+	assert.Equal(t, []string{"GET /a"}, rbacPolicies(t, "888"))
+
+	_, err = RbacServiceApp.ImportRbac(bundle, "replace", false)
+	assert.NotNil(t, err)
+}
+
+func TestImportRbacStrategies(t *testing.T) {
+	tests := []struct {
+		strategy    string
+		description string
+		policies    []string
+	}{
+		{strategy: request.RbacImportSkip, description: "旧描述", policies: []string{"GET /a"}},
+		{strategy: request.RbacImportMerge, description: "新描述", policies: []string{"GET /a", "POST /b"}},
+		{strategy: request.RbacImportOverwrite, description: "新描述", policies: []string{"POST /b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			bundle := setupRbacTest(t)
+			changed := recordEvents(t, system.EventAuthorityChanged)
+
+			result, err := RbacServiceApp.ImportRbac(bundle, tt.strategy, true)
+			assert.Nil(t, err)
+			assert.True(t, result.Applied)
+			assert.Equal(t, []system.AuthorityEvent{
+				{AuthorityId: 888, Action: system.AuthorityImported},
+				{AuthorityId: 999, Action: system.AuthorityImported},
+			}, *changed)
+
+			var api system.SysApi
+			assert.Nil(t, global.GVA_DB.Where("path = ? AND method = ?", "/a", "GET").First(&api).Error)
+			assert.Equal(t, tt.description, api.Description)
+			var apis int64
+			global.GVA_DB.Model(&system.SysApi{}).Count(&apis)
+			assert.Equal(t, int64(2), apis)
+			assert.Equal(t, tt.policies, rbacPolicies(t, "888"))
+
+			// 包内新增的角色在任何策略下都会创建 并获得菜单
+			var menus int64
+			global.GVA_DB.Model(&system.SysAuthorityMenu{}).Where("sys_authority_authority_id = ?", "999").Count(&menus)
+			assert.Equal(t, int64(1), menus)
+
+			// 导入后策略已重新加载
+			ok, _ := CasbinServiceApp.Casbin().Enforce("888", "/b", "POST")
+			assert.Equal(t, tt.strategy != request.RbacImportSkip, ok)
+
+			// 再次预览时已无差异
+			result, err = RbacServiceApp.ImportRbac(bundle, tt.strategy, false)
+			assert.Nil(t, err)
+			assert.Empty(t, result.Apis.Added)
+			assert.Empty(t, result.Authorities.Added)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package system
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"gorm.io/gorm"
+)
+
+// 租户编码同时作为子域名 只允许小写字母、数字和中划线
+var tenantCodePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,62}[a-z0-9])?$`)
+
+// tenantCacheTTL 租户信息在每个请求中都会用到 短暂缓存 多实例部署时停用租户最多延迟该时间生效
+const tenantCacheTTL = time.Minute
+
+type TenantService struct{}
+
+var TenantServiceApp = new(TenantService)
+
+//@function: CreateTenant
+//@description: 创建租户
+//@param: t *system.SysTenant
+//@return: err error
+
+func (tenantService *TenantService) CreateTenant(t *system.SysTenant) (err error) {
+	t.Code = strings.ToLower(strings.TrimSpace(t.Code))
+	if t.Name == "" {
+		return errors.New("租户名称不能为空")
+	}
+	if !tenantCodePattern.MatchString(t.Code) {
+		return errors.New("租户编码只能包含小写字母、数字和中划线")
+	}
+	if !errors.Is(global.GVA_DB.Where("code = ?", t.Code).First(&system.SysTenant{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("租户编码已存在")
+	}
+	if t.Enable == 0 {
+		t.Enable = 1
+	}
+	return global.GVA_DB.Create(t).Error
+}
+
+//@function: UpdateTenant
+//@description: 更新租户名称、状态和备注 租户编码创建后不可修改
+//@param: t system.SysTenant
+//@return: err error
+
+func (tenantService *TenantService) UpdateTenant(t system.SysTenant) (err error) {
+	if t.Name == "" {
+		return errors.New("租户名称不能为空")
+	}
+	if t.Enable != 1 && t.Enable != 2 {
+		return errors.New("租户状态错误")
+	}
+	res := global.GVA_DB.Model(&system.SysTenant{}).Where("id = ?", t.ID).
+		Select("name", "enable", "remark").
+		Updates(map[string]interface{}{"name": t.Name, "enable": t.Enable, "remark": t.Remark})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("租户不存在")
+	}
+	tenantService.forget(t.ID)
+	return nil
+}
+
+//@function: DeleteTenant
+//@description: 删除租户 租户下仍有用户时不允许删除
+//@param: id uint
+//@return: err error
+
+func (tenantService *TenantService) DeleteTenant(id uint) (err error) {
+	var count int64
+	if err = global.GVA_DB.Model(&system.SysUser{}).Where("tenant_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("租户下仍有用户,无法删除")
+	}
+	if err = global.GVA_DB.Delete(&system.SysTenant{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	tenantService.forget(id)
+	return nil
+}
+
+//@function: GetTenant
+//@description: 获取租户
+//@param: id uint
+//@return: tenant system.SysTenant, err error
+
+func (tenantService *TenantService) GetTenant(id uint) (tenant system.SysTenant, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&tenant).Error
+	return
+}
+
+//@function: GetTenantList
+//@description: 分页获取租户列表
+//@param: info systemReq.TenantSearch
+//@return: list []system.SysTenant, total int64, err error
+
+func (tenantService *TenantService) GetTenantList(info systemReq.TenantSearch) (list []system.SysTenant, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysTenant{})
+	if info.Code != "" {
+		db = db.Where("code LIKE ?", "%"+info.Code+"%")
+	}
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id").Find(&list).Error
+	return list, total, err
+}
+
+//@function: ResolveScope
+//@description: 根据jwt中的角色、租户和请求域名确定本次请求的租户范围
+//@description: 超级管理员可通过 switchTo(租户ID) 或子域名切换租户, 均未指定时可访问全部租户
+//@param: authorityId uint, tenantId uint, host string, switchTo string
+//@return: scope utils.TenantScope, err error
+
+func (tenantService *TenantService) ResolveScope(authorityId, tenantId uint, host, switchTo string) (scope utils.TenantScope, err error) {
+	var hostTenant *system.SysTenant
+	if code := tenantCodeFromHost(host); code != "" {
+		if hostTenant, err = tenantService.enabledTenant("code:"+code, "code = ?", code); err != nil {
+			return scope, err
+		}
+	}
+	if global.GVA_CONFIG.Tenant.IsSuperAuthority(authorityId) {
+		switch {
+		case switchTo != "":
+			id, convErr := strconv.ParseUint(switchTo, 10, 64)
+			if convErr != nil {
+				return scope, errors.New("租户ID格式错误")
+			}
+			if id != 0 {
+				if _, err = tenantService.enabledTenant("id:"+strconv.FormatUint(id, 10), "id = ?", id); err != nil {
+					return scope, err
+				}
+			}
+			return utils.TenantScope{Id: uint(id)}, nil
+		case hostTenant != nil:
+			return utils.TenantScope{Id: hostTenant.ID}, nil
+		default:
+			return utils.TenantScope{All: true}, nil
+		}
+	}
+	if hostTenant != nil && hostTenant.ID != tenantId {
+		return scope, errors.New("当前账号不属于该租户")
+	}
+	if tenantId != 0 {
+		if _, err = tenantService.enabledTenant("id:"+strconv.Itoa(int(tenantId)), "id = ?", tenantId); err != nil {
+			return scope, err
+		}
+	}
+	return utils.TenantScope{Id: tenantId}, nil
+}
+
+// enabledTenant 查询启用中的租户 结果短暂缓存
+func (tenantService *TenantService) enabledTenant(key string, query string, arg interface{}) (*system.SysTenant, error) {
+	if v, ok := global.BlackCache.Get("tenant:" + key); ok {
+		t := v.(system.SysTenant)
+		return &t, nil
+	}
+	var t system.SysTenant
+	if err := global.GVA_DB.Where(query, arg).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("租户不存在")
+		}
+		return nil, err
+	}
+	if t.Enable != 1 {
+		return nil, errors.New("租户已停用")
+	}
+	global.BlackCache.Set("tenant:"+key, t, tenantCacheTTL)
+	return &t, nil
+}
+
+// forget 租户变更后清除缓存
+func (tenantService *TenantService) forget(id uint) {
+	var t system.SysTenant
+	if global.GVA_DB.Unscoped().Where("id = ?", id).First(&t).Error == nil {
+		global.BlackCache.Delete("tenant:code:" + t.Code)
+	}
+	global.BlackCache.Delete("tenant:id:" + strconv.Itoa(int(id)))
+}
+
+// tenantCodeFromHost 从 {租户编码}.{主域名} 中取出租户编码 未配置主域名或不匹配时返回空
+func tenantCodeFromHost(host string) string {
+	domain := strings.ToLower(strings.Trim(global.GVA_CONFIG.Tenant.Domain, "."))
+	if domain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	code, ok := strings.CutSuffix(strings.ToLower(host), "."+domain)
+	if !ok {
+		return ""
+	}
+	return code
+}
@@ -0,0 +1,150 @@
+package system
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantColumn 嵌入 global.GVA_TENANT 的模型使用的租户字段
+const tenantColumn = "tenant_id"
+
+// tenantModels 缓存模型是否需要按租户隔离
+var tenantModels sync.Map
+
+//@function: RegisterTenantCallbacks
+//@description: 开启多租户时注册gorm回调, 嵌入 global.GVA_TENANT 的模型按请求上下文中的租户自动过滤和填充 tenant_id
+//@description: 需通过 WithContext 或 DataScope 传入请求上下文, 定时任务等无租户上下文的查询以及原生SQL不受影响
+//@param: db *gorm.DB
+//@return: error
+
+func RegisterTenantCallbacks(db *gorm.DB) error {
+	if db == nil || !global.GVA_CONFIG.Tenant.Enable {
+		return nil
+	}
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("gva:tenant_create", tenantCreate); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("gva:tenant_query", tenantWhere); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("gva:tenant_row", tenantWhere); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("gva:tenant_update", tenantUpdate); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("gva:tenant_delete", tenantGuardedWhere)
+}
+
+// tenantOf 返回语句需要限定的租户 模型未嵌入租户字段、无租户上下文或超级管理员查看全部租户时 ok 为false
+func tenantOf(db *gorm.DB) (id uint, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil || !isTenantModel(db.Statement.Schema) {
+		return 0, false
+	}
+	scope, ok := utils.GetTenantFromContext(db.Statement.Context)
+	if !ok || scope.All {
+		return 0, false
+	}
+	return scope.Id, true
+}
+
+func isTenantModel(s *schema.Schema) bool {
+	if v, ok := tenantModels.Load(s.ModelType); ok {
+		return v.(bool)
+	}
+	_, scoped := reflect.New(s.ModelType).Interface().(interface{ TenantScoped() })
+	scoped = scoped && s.LookUpField(tenantColumn) != nil
+	tenantModels.Store(s.ModelType, scoped)
+	return scoped
+}
+
+func tenantCreate(db *gorm.DB) {
+	id, ok := tenantOf(db)
+	if !ok {
+		return
+	}
+	db.Statement.SetColumn(tenantColumn, id, true)
+	// Save 更新不到数据时会回退为 upsert 冲突时更新可能覆盖其他租户的同主键数据 改为忽略
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && (onConflict.UpdateAll || len(onConflict.DoUpdates) > 0) {
+			db.Statement.AddClause(clause.OnConflict{Columns: onConflict.Columns, DoNothing: true})
+		}
+	}
+}
+
+func tenantWhere(db *gorm.DB) {
+	id, ok := tenantOf(db)
+	if !ok {
+		return
+	}
+	// 同一个 *gorm.DB 先 Count 再 Find 时语句会被复用 避免重复添加条件
+	if c, ok := db.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			for _, expr := range where.Exprs {
+				if eq, ok := expr.(clause.Eq); ok && eq.Column == tenantClauseColumn {
+					return
+				}
+			}
+		}
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: tenantClauseColumn, Value: id},
+	}})
+}
+
+var tenantClauseColumn = clause.Column{Table: clause.CurrentTable, Name: tenantColumn}
+
+// tenantGuardedWhere 没有任何条件的批量更新和删除仍交由gorm拦截 不因追加了租户条件而放行
+func tenantGuardedWhere(db *gorm.DB) {
+	if _, ok := db.Statement.Clauses["WHERE"]; !ok && !db.AllowGlobalUpdate && !hasPrimaryKeyValue(db) {
+		return
+	}
+	tenantWhere(db)
+}
+
+func tenantUpdate(db *gorm.DB) {
+	id, ok := tenantOf(db)
+	if !ok {
+		return
+	}
+	tenantGuardedWhere(db)
+	// 防止通过提交 tenantId 把数据移动到其他租户
+	if _, isMap := db.Statement.Dest.(map[string]interface{}); isMap || db.Statement.ReflectValue.Kind() == reflect.Struct {
+		db.Statement.SetColumn(tenantColumn, id, true)
+	}
+}
+
+func hasPrimaryKeyValue(db *gorm.DB) bool {
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv.Len() > 0
+	case reflect.Struct:
+		for _, f := range db.Statement.Schema.PrimaryFields {
+			if _, zero := f.ValueOf(db.Statement.Context, rv); !zero {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopeTenantUsers 关联用户但本身未嵌入租户字段的表(如授权、部门成员、访问令牌) 按用户所属租户过滤 column 为用户ID字段
+// 无租户上下文或可访问全部租户时不过滤
+func scopeTenantUsers(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if scope, ok := utils.GetTenantFromContext(ctx); !ok || scope.All {
+			return db
+		}
+		return db.Where(column+" IN (?)", global.GVA_DB.WithContext(ctx).Model(&system.SysUser{}).Select("id"))
+	}
+}
@@ -0,0 +1,176 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTenantScopeTest 开启多租户并注册回调 alice(1) 属于租户1 bob(2) 属于租户2
+func setupTenantScopeTest(t *testing.T, models ...interface{}) *gorm.DB {
+	db := newTestDB(t, append([]interface{}{&system.SysUser{}, &system.SysTenant{}}, models...)...)
+	old := global.GVA_CONFIG.Tenant
+	global.GVA_CONFIG.Tenant.Enable = true
+	t.Cleanup(func() { global.GVA_CONFIG.Tenant = old })
+	require.Nil(t, RegisterTenantCallbacks(db))
+	assert.Nil(t, db.Create(&system.SysUser{Username: "alice", AuthorityId: 888, GVA_TENANT: global.GVA_TENANT{TenantId: 1}}).Error)
+	assert.Nil(t, db.Create(&system.SysUser{Username: "bob", AuthorityId: 888, GVA_TENANT: global.GVA_TENANT{TenantId: 2}}).Error)
+	return db
+}
+
+func tenantCtx(id uint) context.Context {
+	return utils.ContextWithTenant(context.Background(), utils.TenantScope{Id: id})
+}
+
+func usernames(t *testing.T, db *gorm.DB) []string {
+	var names []string
+	assert.Nil(t, db.Model(&system.SysUser{}).Order("id").Pluck("username", &names).Error)
+	return names
+}
+
+func TestTenantWhere(t *testing.T) {
+	db := setupTenantScopeTest(t)
+
+	assert.Equal(t, []string{"alice"}, usernames(t, db.WithContext(tenantCtx(1))))
+	assert.Equal(t, []string{"bob"}, usernames(t, db.WithContext(tenantCtx(2))))
+	// 超级管理员查看全部租户 以及无租户上下文的定时任务不受影响
+	all := utils.ContextWithTenant(context.Background(), utils.TenantScope{All: true})
+	assert.Equal(t, []string{"alice", "bob"}, usernames(t, db.WithContext(all)))
+	assert.Equal(t, []string{"alice", "bob"}, usernames(t, db))
+
+	// 先 Count 再 Find 复用同一语句
+	var total int64
+	var list []system.SysUser
+	q := db.WithContext(tenantCtx(2)).Model(&system.SysUser{})
+	assert.Nil(t, q.Count(&total).Error)
+	assert.Nil(t, q.Find(&list).Error)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, list, 1)
+	assert.Equal(t, "bob", list[0].Username)
+
+	assert.ErrorIs(t, db.WithContext(tenantCtx(1)).First(&system.SysUser{}, 2).Error, gorm.ErrRecordNotFound)
+	assert.Nil(t, db.WithContext(tenantCtx(1)).Model(&system.SysUser{}).Select("count(*)").Row().Scan(&total))
+	assert.Equal(t, int64(1), total)
+
+	// 未嵌入租户字段的模型不受影响
+	assert.Nil(t, db.Create(&system.SysTenant{Code: "acme", Name: "acme"}).Error)
+	assert.Nil(t, db.WithContext(tenantCtx(2)).First(&system.SysTenant{}).Error)
+}
+
+func TestTenantCreate(t *testing.T) {
+	db := setupTenantScopeTest(t)
+
+	// 提交的 tenantId 被忽略 使用当前租户
+	carol := system.SysUser{Username: "carol", GVA_TENANT: global.GVA_TENANT{TenantId: 2}}
+	assert.Nil(t, db.WithContext(tenantCtx(1)).Create(&carol).Error)
+	assert.Equal(t, uint(1), carol.TenantId)
+	assert.Equal(t, []string{"alice", "carol"}, usernames(t, db.WithContext(tenantCtx(1))))
+
+	// Save 其他租户的数据时 更新不到数据回退为插入 主键冲突时忽略而不是覆盖
+	assert.Nil(t, db.WithContext(tenantCtx(1)).Save(&system.SysUser{GVA_MODEL: global.GVA_MODEL{ID: 2}, Username: "mallory"}).Error)
+	var bob system.SysUser
+	assert.Nil(t, db.First(&bob, 2).Error)
+	assert.Equal(t, "bob", bob.Username)
+	assert.Equal(t, uint(2), bob.TenantId)
+}
+
+func TestTenantUpdate(t *testing.T) {
+	db := setupTenantScopeTest(t)
+	t1 := db.WithContext(tenantCtx(1))
+
+	res := t1.Model(&system.SysUser{}).Where("id = ?", 2).Update("nick_name", "x")
+	assert.Nil(t, res.Error)
+	assert.Equal(t, int64(0), res.RowsAffected)
+
+	// 不能通过提交 tenantId 把数据移动到其他租户
+	alice := system.SysUser{GVA_MODEL: global.GVA_MODEL{ID: 1}}
+	assert.Nil(t, t1.Model(&alice).Updates(map[string]interface{}{"nick_name": "a", "tenant_id": 2}).Error)
+	assert.Nil(t, t1.Model(&alice).Updates(system.SysUser{NickName: "b", GVA_TENANT: global.GVA_TENANT{TenantId: 2}}).Error)
+	var saved system.SysUser
+	assert.Nil(t, db.First(&saved, 1).Error)
+	assert.Equal(t, "b", saved.NickName)
+	assert.Equal(t, uint(1), saved.TenantId)
+}
+
+func TestTenantGuardedWhere(t *testing.T) {
+	db := setupTenantScopeTest(t)
+	t1 := db.WithContext(tenantCtx(1))
+
+	// 没有条件的批量更新和删除仍被拦截
+	assert.ErrorIs(t, t1.Model(&system.SysUser{}).Update("nick_name", "x").Error, gorm.ErrMissingWhereClause)
+	assert.ErrorIs(t, t1.Delete(&system.SysUser{}).Error, gorm.ErrMissingWhereClause)
+
+	res := t1.Delete(&system.SysUser{}, 2)
+	assert.Nil(t, res.Error)
+	assert.Equal(t, int64(0), res.RowsAffected)
+	res = t1.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&system.SysUser{})
+	assert.Nil(t, res.Error)
+	assert.Equal(t, int64(1), res.RowsAffected)
+	assert.Equal(t, []string{"bob"}, usernames(t, db))
+}
+
+func TestTenantUserServices(t *testing.T) {
+	db := setupTenantScopeTest(t, &system.SysAuthority{}, &system.SysUserAuthority{}, &system.SysUserAuthorityGrant{},
+		&system.SysApiToken{}, &system.SysDepartment{}, &system.SysUserDepartment{})
+	oldSystem := global.GVA_CONFIG.System
+	global.GVA_CONFIG.System.UseStrictAuth = false
+	t.Cleanup(func() { global.GVA_CONFIG.System = oldSystem })
+	assert.Nil(t, db.Create(&system.SysAuthority{AuthorityId: 888}).Error)
+	t1, t2 := tenantCtx(1), tenantCtx(2)
+
+	// 限时授权
+	_, err := AuthorityGrantServiceApp.CreateGrant(t1, 888, 1, grantFor(2, 888))
+	assert.EqualError(t, err, "用户不存在")
+	_, err = AuthorityGrantServiceApp.CreateGrant(t1, 888, 1, grantFor(1, 888))
+	assert.Nil(t, err)
+	grant, err := AuthorityGrantServiceApp.CreateGrant(t2, 888, 2, grantFor(2, 888))
+	assert.Nil(t, err)
+	list, total, err := AuthorityGrantServiceApp.GetGrantList(t1, systemReq.AuthorityGrantSearch{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, list, 1)
+	assert.Equal(t, uint(1), list[0].UserId)
+	assert.EqualError(t, AuthorityGrantServiceApp.RevokeGrant(t1, grant.ID, 0, ""), "授权不存在或已结束")
+
+	// 部门
+	assert.Nil(t, DepartmentServiceApp.CreateDepartment(&system.SysDepartment{Name: "研发"}))
+	assert.EqualError(t, DepartmentServiceApp.SetUserDepartments(t1, systemReq.SetUserDepartments{UserId: 2, PrimaryId: 1}), "用户不存在")
+	assert.Nil(t, DepartmentServiceApp.SetUserDepartments(t1, systemReq.SetUserDepartments{UserId: 1, PrimaryId: 1}))
+	assert.Nil(t, DepartmentServiceApp.SetUserDepartments(t2, systemReq.SetUserDepartments{UserId: 2, PrimaryId: 1}))
+	assert.Nil(t, DepartmentServiceApp.SetDepartmentLeaders(t2, systemReq.SetDepartmentLeaders{DepartmentId: 1, UserIds: []uint{2}}))
+	assert.EqualError(t, DepartmentServiceApp.SetDepartmentLeaders(t1, systemReq.SetDepartmentLeaders{DepartmentId: 1, UserIds: []uint{2}}), "负责人必须是部门成员")
+	// 设置本租户负责人不影响其他租户
+	assert.Nil(t, DepartmentServiceApp.SetDepartmentLeaders(t1, systemReq.SetDepartmentLeaders{DepartmentId: 1, UserIds: []uint{1}}))
+	depts, err := DepartmentServiceApp.GetUserDepartments(2)
+	assert.Nil(t, err)
+	require.Len(t, depts, 1)
+	assert.True(t, depts[0].IsLeader)
+	users, total, err := DepartmentServiceApp.GetDepartmentUsers(t1, systemReq.DepartmentUserSearch{DepartmentId: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "alice", users[0].Username)
+	tree, err := DepartmentServiceApp.GetDepartmentTree(t1)
+	assert.Nil(t, err)
+	require.Len(t, tree, 1)
+	require.Len(t, tree[0].Leaders, 1)
+	assert.Equal(t, "alice", tree[0].Leaders[0].Username)
+
+	// 访问令牌
+	_, _, err = ApiTokenServiceApp.CreateApiToken(t1, 2, systemReq.CreateApiToken{Name: "ci"})
+	assert.EqualError(t, err, "用户不存在")
+	_, apiToken, err := ApiTokenServiceApp.CreateApiToken(t2, 2, systemReq.CreateApiToken{Name: "ci"})
+	assert.Nil(t, err)
+	_, total, err = ApiTokenServiceApp.GetApiTokenList(t1, systemReq.ApiTokenSearch{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.EqualError(t, ApiTokenServiceApp.RevokeApiToken(t1, apiToken.ID, 0), "令牌不存在或已吊销")
+	assert.Nil(t, ApiTokenServiceApp.RevokeApiToken(t2, apiToken.ID, 0))
+}
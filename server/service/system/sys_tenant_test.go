@@ -0,0 +1,67 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveScope(t *testing.T) {
+	db := newTestDB(t, &system.SysTenant{}, &system.SysUser{})
+	newTestCache(t)
+	old := global.GVA_CONFIG.Tenant
+	global.GVA_CONFIG.Tenant = config.Tenant{Enable: true, Domain: "example.com", SuperAuthorityIds: []uint{888}}
+	t.Cleanup(func() { global.GVA_CONFIG.Tenant = old })
+	assert.Nil(t, db.Create(&system.SysTenant{Code: "acme", Name: "acme", Enable: 1}).Error)
+	assert.Nil(t, db.Create(&system.SysTenant{Code: "beta", Name: "beta", Enable: 2}).Error)
+
+	tests := []struct {
+		name        string
+		authorityId uint
+		tenantId    uint
+		host        string
+		switchTo    string
+		want        utils.TenantScope
+		wantErr     string
+	}{
+		{name: "超级管理员未指定租户", authorityId: 888, want: utils.TenantScope{All: true}},
+		{name: "超级管理员切换租户", authorityId: 888, switchTo: "1", want: utils.TenantScope{Id: 1}},
+		{name: "超级管理员切换到平台", authorityId: 888, switchTo: "0", want: utils.TenantScope{Id: 0}},
+		{name: "超级管理员通过子域名切换", authorityId: 888, host: "acme.example.com:8080", want: utils.TenantScope{Id: 1}},
+		{name: "切换请求头优先于子域名", authorityId: 888, host: "acme.example.com", switchTo: "0", want: utils.TenantScope{Id: 0}},
+		{name: "租户ID格式错误", authorityId: 888, switchTo: "acme", wantErr: "租户ID格式错误"},
+		{name: "切换到不存在的租户", authorityId: 888, switchTo: "99", wantErr: "租户不存在"},
+		{name: "切换到停用的租户", authorityId: 888, switchTo: "2", wantErr: "租户已停用"},
+		{name: "普通用户", authorityId: 9528, tenantId: 1, want: utils.TenantScope{Id: 1}},
+		{name: "普通用户访问本租户子域名", authorityId: 9528, tenantId: 1, host: "ACME.example.com", want: utils.TenantScope{Id: 1}},
+		{name: "非主域名不识别租户", authorityId: 9528, tenantId: 1, host: "beta.other.com", want: utils.TenantScope{Id: 1}},
+		{name: "普通用户忽略切换请求头", authorityId: 9528, tenantId: 1, switchTo: "0", want: utils.TenantScope{Id: 1}},
+		{name: "平台用户", authorityId: 9528, want: utils.TenantScope{Id: 0}},
+		{name: "普通用户访问其他租户子域名", authorityId: 9528, tenantId: 2, host: "acme.example.com", wantErr: "当前账号不属于该租户"},
+		{name: "子域名租户停用", authorityId: 9528, tenantId: 2, host: "beta.example.com", wantErr: "租户已停用"},
+		{name: "所属租户停用", authorityId: 9528, tenantId: 2, wantErr: "租户已停用"},
+		{name: "子域名租户不存在", authorityId: 888, host: "nope.example.com", wantErr: "租户不存在"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := TenantServiceApp.ResolveScope(tt.authorityId, tt.tenantId, tt.host, tt.switchTo)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, scope)
+		})
+	}
+
+	// 停用租户后清除缓存 立即生效
+	assert.Nil(t, TenantServiceApp.UpdateTenant(system.SysTenant{GVA_MODEL: global.GVA_MODEL{ID: 1}, Name: "acme", Enable: 2}))
+	_, err := TenantServiceApp.ResolveScope(9528, 1, "", "")
+	assert.EqualError(t, err, "租户已停用")
+	_, err = TenantServiceApp.ResolveScope(9528, 1, "acme.example.com", "")
+	assert.EqualError(t, err, "租户已停用")
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -18,22 +19,36 @@ import (
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: Register
 //@description: 用户注册
-//@param: u model.SysUser
+//@param: ctx context.Context, u model.SysUser
 //@return: userInter system.SysUser, err error
 
 type UserService struct{}
 
 var UserServiceApp = new(UserService)
 
-func (userService *UserService) Register(u system.SysUser) (userInter system.SysUser, err error) {
+func (userService *UserService) Register(ctx context.Context, u system.SysUser) (userInter system.SysUser, err error) {
 	var user system.SysUser
+	// 用户名全局唯一 不按租户过滤
 	if !errors.Is(global.GVA_DB.Where("username = ?", u.Username).First(&user).Error, gorm.ErrRecordNotFound) { // 判断用户名是否注册
 		return userInter, errors.New("用户名已注册")
 	}
+	if err = utils.CheckPasswordPolicy(global.GVA_CONFIG.PasswordPolicy, u.Username, u.Password); err != nil {
+		return userInter, err
+	}
 	// 否则 附加uuid 密码hash加密 注册
+	now := time.Now()
 	u.Password = utils.BcryptHash(u.Password)
+	u.PasswordUpdatedAt = &now
 	u.UUID = uuid.New()
-	err = global.GVA_DB.Create(&u).Error
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&u).Error; err != nil {
+			return err
+		}
+		return recordPasswordHistory(tx, u.ID, u.Password)
+	})
+	if err == nil {
+		userCreated(ctx, u)
+	}
 	return u, err
 }
 
@@ -68,11 +83,31 @@ func (userService *UserService) Login(u *system.SysUser) (userInter *system.SysU
 			DateTime: time.Now(),
 		})
 
+		// 密码有效期从首次记录修改时间开始计算
+		if user.PasswordUpdatedAt == nil {
+			now := time.Now()
+			user.PasswordUpdatedAt = &now
+			global.GVA_DB.Model(&system.SysUser{}).Where("id = ?", user.ID).Update("password_updated_at", now)
+		}
+		// 密码过期或被管理员重置后 需先修改密码
+		user.PasswordExpired = user.CheckPasswordExpired(global.GVA_CONFIG.PasswordPolicy.ExpireDays)
 		MenuServiceApp.UserAuthorityDefaultRouter(&user)
 	}
 	return &user, err
 }
 
+//@function: LoginSucceeded
+//@description: 登录成功并签发token后发布登录事件
+//@param: ctx context.Context, u system.SysUser, ip string, agent string
+
+func (userService *UserService) LoginSucceeded(ctx context.Context, u system.SysUser, ip, agent string) {
+	publishEvent(ctx, system.EventUserLogin, system.UserLoginEvent{
+		UserEvent: system.UserEvent{ID: u.ID, Username: u.Username, AuthorityId: u.AuthorityId},
+		Ip:        ip,
+		Agent:     agent,
+	})
+}
+
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: ChangePassword
 //@description: 修改用户密码
@@ -87,8 +122,19 @@ func (userService *UserService) ChangePassword(u *system.SysUser, newPassword st
 	if ok := utils.BcryptCheck(u.Password, user.Password); !ok {
 		return nil, errors.New("原密码错误")
 	}
-	user.Password = utils.BcryptHash(newPassword)
-	err = global.GVA_DB.Save(&user).Error
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkNewPassword(tx, user, newPassword); err != nil {
+			return err
+		}
+		now := time.Now()
+		user.Password = utils.BcryptHash(newPassword)
+		user.PasswordUpdatedAt = &now
+		user.ForceChangePassword = false
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return recordPasswordHistory(tx, user.ID, user.Password)
+	})
 	return &user, err
 
 }
@@ -96,13 +142,13 @@ func (userService *UserService) ChangePassword(u *system.SysUser, newPassword st
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetUserInfoList
 //@description: 分页获取数据
-//@param: info request.PageInfo
+//@param: ctx context.Context, info request.PageInfo
 //@return: err error, list interface{}, total int64
 
-func (userService *UserService) GetUserInfoList(info systemReq.GetUserList) (list interface{}, total int64, err error) {
+func (userService *UserService) GetUserInfoList(ctx context.Context, info systemReq.GetUserList) (list interface{}, total int64, err error) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
-	db := global.GVA_DB.Model(&system.SysUser{})
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysUser{})
 	var userList []system.SysUser
 
 	if info.NickName != "" {
@@ -178,11 +224,11 @@ func (userService *UserService) SetUserAuthority(id uint, authorityId uint) (err
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: SetUserAuthorities
 //@description: 设置一个用户的权限
-//@param: id uint, authorityIds []string
+//@param: ctx context.Context, adminAuthorityID uint, id uint, authorityIds []uint
 //@return: err error
 
-func (userService *UserService) SetUserAuthorities(adminAuthorityID, id uint, authorityIds []uint) (err error) {
-	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+func (userService *UserService) SetUserAuthorities(ctx context.Context, adminAuthorityID, id uint, authorityIds []uint) (err error) {
+	return global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var user system.SysUser
 		TxErr := tx.Where("id = ?", id).First(&user).Error
 		if TxErr != nil {
@@ -207,6 +253,11 @@ func (userService *UserService) SetUserAuthorities(adminAuthorityID, id uint, au
 		if TxErr != nil {
 			return TxErr
 		}
+		// 生效中的限时授权不受影响 到期后仍会收回
+		TxErr = AuthorityGrantServiceApp.reapplyActiveGrants(tx, id, authorityIds)
+		if TxErr != nil {
+			return TxErr
+		}
 		TxErr = tx.Model(&user).Update("authority_id", authorityIds[0]).Error
 		if TxErr != nil {
 			return TxErr
@@ -219,29 +270,48 @@ func (userService *UserService) SetUserAuthorities(adminAuthorityID, id uint, au
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: DeleteUser
 //@description: 删除用户
-//@param: id float64
+//@param: ctx context.Context, id int
 //@return: err error
 
-func (userService *UserService) DeleteUser(id int) (err error) {
-	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("id = ?", id).Delete(&system.SysUser{}).Error; err != nil {
+func (userService *UserService) DeleteUser(ctx context.Context, id int) (err error) {
+	var user system.SysUser
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id", "username", "authority_id").Where("id = ?", id).Limit(1).Find(&user).Error; err != nil {
 			return err
 		}
+		res := tx.Where("id = ?", id).Delete(&system.SysUser{})
+		if res.Error != nil {
+			return res.Error
+		}
+		// 多租户模式下其他租户的用户不可见 不能继续清理其关联数据
+		if res.RowsAffected == 0 {
+			return errors.New("用户不存在")
+		}
 		if err := tx.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ?", id).Error; err != nil {
 			return err
 		}
+		if err := tx.Delete(&[]system.SysUserDepartment{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&[]system.SysUserAuthorityGrant{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
 		return nil
 	})
+	if err == nil {
+		publishEvent(ctx, system.EventUserDeleted, system.UserEvent{ID: user.ID, Username: user.Username, AuthorityId: user.AuthorityId})
+	}
+	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: SetUserInfo
 //@description: 设置用户信息
-//@param: reqUser model.SysUser
+//@param: ctx context.Context, reqUser model.SysUser
 //@return: err error, user model.SysUser
 
-func (userService *UserService) SetUserInfo(req system.SysUser) error {
-	return global.GVA_DB.Model(&system.SysUser{}).
+func (userService *UserService) SetUserInfo(ctx context.Context, req system.SysUser) error {
+	return global.GVA_DB.WithContext(ctx).Model(&system.SysUser{}).
 		Select("updated_at", "nick_name", "header_img", "phone", "email", "enable").
 		Where("id=?", req.ID).
 		Updates(map[string]interface{}{
@@ -322,12 +392,29 @@ func (userService *UserService) FindUserByUuid(uuid string) (user *system.SysUse
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: ResetPassword
 //@description: 修改用户密码
-//@param: ID uint
+//@param: ctx context.Context, ID uint, password string
 //@return: err error
 
-func (userService *UserService) ResetPassword(ID uint, password string) (err error) {
-	err = global.GVA_DB.Model(&system.SysUser{}).Where("id = ?", ID).Update("password", utils.BcryptHash(password)).Error
-	return err
+func (userService *UserService) ResetPassword(ctx context.Context, ID uint, password string) (err error) {
+	return global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user system.SysUser
+		if err := tx.Where("id = ?", ID).First(&user).Error; err != nil {
+			return err
+		}
+		if err := checkNewPassword(tx, user, password); err != nil {
+			return err
+		}
+		hash := utils.BcryptHash(password)
+		err := tx.Model(&system.SysUser{}).Where("id = ?", ID).Updates(map[string]interface{}{
+			"password":              hash,
+			"password_updated_at":   time.Now(),
+			"force_change_password": global.GVA_CONFIG.PasswordPolicy.ForceChangeAfterReset,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordPasswordHistory(tx, ID, hash)
+	})
 }
 
 func (userService *UserService) GetLoginHistory(id int, startTime, endTime time.Time) (userLoginHistory []system.UserLoginHistory, err error) {
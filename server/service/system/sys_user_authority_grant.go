@@ -0,0 +1,316 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AuthorityGrantService struct{}
+
+var AuthorityGrantServiceApp = new(AuthorityGrantService)
+
+//@function: CreateGrant
+//@description: 管理员为用户授予限时角色
+//@param: ctx context.Context, adminAuthorityID uint, operatorId uint, req systemReq.CreateAuthorityGrant
+//@return: grant system.SysUserAuthorityGrant, err error
+
+func (grantService *AuthorityGrantService) CreateGrant(ctx context.Context, adminAuthorityID, operatorId uint, req systemReq.CreateAuthorityGrant) (grant system.SysUserAuthorityGrant, err error) {
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, req.AuthorityId); err != nil {
+		return grant, err
+	}
+	return grantService.createGrant(ctx, 0, operatorId, req)
+}
+
+//@function: Delegate
+//@description: 用户将自己拥有的角色在一段时间内委托给其他用户 需填写原因
+//@param: ctx context.Context, delegatorId uint, req systemReq.CreateAuthorityGrant
+//@return: grant system.SysUserAuthorityGrant, err error
+
+func (grantService *AuthorityGrantService) Delegate(ctx context.Context, delegatorId uint, req systemReq.CreateAuthorityGrant) (grant system.SysUserAuthorityGrant, err error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return grant, errors.New("委托原因不能为空")
+	}
+	if req.UserId == delegatorId {
+		return grant, errors.New("不能委托给自己")
+	}
+	if errors.Is(global.GVA_DB.Where("sys_user_id = ? AND sys_authority_authority_id = ?", delegatorId, req.AuthorityId).First(&system.SysUserAuthority{}).Error, gorm.ErrRecordNotFound) {
+		return grant, errors.New("您没有该角色,无法委托")
+	}
+	// 不允许转授 委托得到的角色只能本人使用
+	var count int64
+	global.GVA_DB.Model(&system.SysUserAuthorityGrant{}).
+		Where("user_id = ? AND authority_id = ? AND delegator_id <> 0 AND applied = ? AND status = ?", delegatorId, req.AuthorityId, true, system.AuthorityGrantActive).
+		Count(&count)
+	if count > 0 {
+		return grant, errors.New("委托获得的角色不能再次委托")
+	}
+	return grantService.createGrant(ctx, delegatorId, delegatorId, req)
+}
+
+func (grantService *AuthorityGrantService) createGrant(ctx context.Context, delegatorId, operatorId uint, req systemReq.CreateAuthorityGrant) (grant system.SysUserAuthorityGrant, err error) {
+	now := time.Now()
+	validFrom := now
+	if req.ValidFrom != nil && req.ValidFrom.After(now) {
+		validFrom = *req.ValidFrom
+	}
+	if !req.ValidUntil.After(validFrom) {
+		return grant, errors.New("失效时间必须晚于生效时间和当前时间")
+	}
+	// 按请求上下文中的租户查询 不能为其他租户的用户授权
+	if err = global.GVA_DB.WithContext(ctx).Where("id = ?", req.UserId).First(&system.SysUser{}).Error; err != nil {
+		return grant, errors.New("用户不存在")
+	}
+	if err = global.GVA_DB.Where("authority_id = ?", req.AuthorityId).First(&system.SysAuthority{}).Error; err != nil {
+		return grant, errors.New("角色不存在")
+	}
+	grant = system.SysUserAuthorityGrant{
+		UserId:      req.UserId,
+		AuthorityId: req.AuthorityId,
+		DelegatorId: delegatorId,
+		OperatorId:  operatorId,
+		ValidFrom:   validFrom,
+		ValidUntil:  req.ValidUntil,
+		Reason:      req.Reason,
+		Status:      system.AuthorityGrantPending,
+	}
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&grant).Error; err != nil {
+			return err
+		}
+		if validFrom.After(now) {
+			return nil
+		}
+		return grantService.activate(tx, &grant)
+	})
+	return grant, err
+}
+
+//@function: RevokeGrant
+//@description: 提前撤销授权 delegatorId 不为0时只能撤销自己发起的委托
+//@param: ctx context.Context, id uint, delegatorId uint, reason string
+//@return: err error
+
+func (grantService *AuthorityGrantService) RevokeGrant(ctx context.Context, id, delegatorId uint, reason string) (err error) {
+	var grant system.SysUserAuthorityGrant
+	db := global.GVA_DB.Scopes(scopeTenantUsers(ctx, "user_id")).Where("id = ? AND status IN ?", id, []string{system.AuthorityGrantPending, system.AuthorityGrantActive})
+	if delegatorId != 0 {
+		db = db.Where("delegator_id = ?", delegatorId)
+	}
+	if err = db.First(&grant).Error; err != nil {
+		return errors.New("授权不存在或已结束")
+	}
+	if reason == "" {
+		reason = "手动撤销"
+	}
+	return grantService.end(&grant, system.AuthorityGrantRevoked, reason)
+}
+
+//@function: GetGrantList
+//@description: 分页获取授权列表
+//@param: ctx context.Context, info systemReq.AuthorityGrantSearch
+//@return: list []system.SysUserAuthorityGrant, total int64, err error
+
+func (grantService *AuthorityGrantService) GetGrantList(ctx context.Context, info systemReq.AuthorityGrantSearch) (list []system.SysUserAuthorityGrant, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysUserAuthorityGrant{}).Scopes(scopeTenantUsers(ctx, "user_id"))
+	if info.UserId != 0 {
+		db = db.Where("user_id = ?", info.UserId)
+	}
+	if info.DelegatorId != 0 {
+		db = db.Where("delegator_id = ?", info.DelegatorId)
+	}
+	if info.AuthorityId != 0 {
+		db = db.Where("authority_id = ?", info.AuthorityId)
+	}
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+//@function: GetUserGrants
+//@description: 获取用户收到的和委托出去的授权
+//@param: userId uint
+//@return: received []system.SysUserAuthorityGrant, delegated []system.SysUserAuthorityGrant, err error
+
+func (grantService *AuthorityGrantService) GetUserGrants(userId uint) (received, delegated []system.SysUserAuthorityGrant, err error) {
+	if err = global.GVA_DB.Where("user_id = ?", userId).Order("id desc").Find(&received).Error; err != nil {
+		return
+	}
+	err = global.GVA_DB.Where("delegator_id = ?", userId).Order("id desc").Find(&delegated).Error
+	return received, delegated, err
+}
+
+//@function: ExpireGrants
+//@description: 定时任务 启用到达生效时间的授权 结束到期或委托人已失去角色的授权
+//@return: err error
+
+func (grantService *AuthorityGrantService) ExpireGrants() (err error) {
+	if global.GVA_DB == nil {
+		return nil
+	}
+	now := time.Now()
+	var pending []system.SysUserAuthorityGrant
+	if err = global.GVA_DB.Where("status = ? AND valid_from <= ?", system.AuthorityGrantPending, now).Find(&pending).Error; err != nil {
+		return err
+	}
+	for i := range pending {
+		if !pending[i].ValidUntil.After(now) {
+			err = grantService.end(&pending[i], system.AuthorityGrantExpired, "到期")
+		} else {
+			err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+				return grantService.activate(tx, &pending[i])
+			})
+		}
+		if err != nil {
+			global.GVA_LOG.Error("处理待生效授权失败!", zap.Uint("id", pending[i].ID), zap.Error(err))
+		}
+	}
+
+	var expired []system.SysUserAuthorityGrant
+	if err = global.GVA_DB.Where("status = ? AND valid_until <= ?", system.AuthorityGrantActive, now).Find(&expired).Error; err != nil {
+		return err
+	}
+	for i := range expired {
+		if err = grantService.end(&expired[i], system.AuthorityGrantExpired, "到期"); err != nil {
+			global.GVA_LOG.Error("结束到期授权失败!", zap.Uint("id", expired[i].ID), zap.Error(err))
+		}
+	}
+
+	// 委托人已不再拥有该角色(被移除或已删除)时 委托随之结束
+	var orphaned []system.SysUserAuthorityGrant
+	err = global.GVA_DB.Where("delegator_id <> 0 AND status IN ?", []string{system.AuthorityGrantPending, system.AuthorityGrantActive}).
+		Where("NOT EXISTS (SELECT 1 FROM sys_user_authority WHERE sys_user_authority.sys_user_id = sys_user_authority_grants.delegator_id AND sys_user_authority.sys_authority_authority_id = sys_user_authority_grants.authority_id)").
+		Find(&orphaned).Error
+	if err != nil {
+		return err
+	}
+	for i := range orphaned {
+		if err = grantService.end(&orphaned[i], system.AuthorityGrantRevoked, "委托人已失去该角色"); err != nil {
+			global.GVA_LOG.Error("结束委托失败!", zap.Uint("id", orphaned[i].ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// activate 授权生效 用户尚无该角色时写入 sys_user_authority 并记为由本授权写入
+func (grantService *AuthorityGrantService) activate(tx *gorm.DB, grant *system.SysUserAuthorityGrant) error {
+	err := tx.Where("sys_user_id = ? AND sys_authority_authority_id = ?", grant.UserId, grant.AuthorityId).First(&system.SysUserAuthority{}).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err = tx.Create(&system.SysUserAuthority{SysUserId: grant.UserId, SysAuthorityAuthorityId: grant.AuthorityId}).Error; err != nil {
+			return err
+		}
+		grant.Applied = true
+	} else if err != nil {
+		return err
+	}
+	grant.Status = system.AuthorityGrantActive
+	return tx.Model(grant).Updates(map[string]interface{}{"status": grant.Status, "applied": grant.Applied}).Error
+}
+
+// end 结束授权 如角色由本授权写入且没有其他生效中的授权 则移除该角色并吊销用户已签发的jwt
+func (grantService *AuthorityGrantService) end(grant *system.SysUserAuthorityGrant, status, reason string) error {
+	now := time.Now()
+	removed := false
+	// Updates 会回写 grant 需要提前记录
+	applied := grant.Applied
+	err := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(grant).Updates(map[string]interface{}{
+			"status":     status,
+			"ended_at":   now,
+			"end_reason": reason,
+			"applied":    false,
+		}).Error
+		if err != nil || !applied {
+			return err
+		}
+		// 同一角色还有其他生效中的授权时 由其接管
+		var other system.SysUserAuthorityGrant
+		err = tx.Where("id <> ? AND user_id = ? AND authority_id = ? AND status = ?", grant.ID, grant.UserId, grant.AuthorityId, system.AuthorityGrantActive).First(&other).Error
+		if err == nil {
+			return tx.Model(&other).Update("applied", true).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err = tx.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ? AND sys_authority_authority_id = ?", grant.UserId, grant.AuthorityId).Error; err != nil {
+			return err
+		}
+		removed = true
+		var user system.SysUser
+		if err = tx.Where("id = ?", grant.UserId).First(&user).Error; err != nil {
+			return err
+		}
+		if user.AuthorityId != grant.AuthorityId {
+			return nil
+		}
+		// 当前使用的角色被收回 切换到剩余的第一个角色 没有剩余角色时置0 不再匹配任何api权限
+		var next system.SysUserAuthority
+		var nextId uint
+		if tx.Where("sys_user_id = ?", grant.UserId).First(&next).Error == nil {
+			nextId = next.SysAuthorityAuthorityId
+		}
+		return tx.Model(&user).Update("authority_id", nextId).Error
+	})
+	if err != nil || !removed {
+		return err
+	}
+	var user system.SysUser
+	if err = global.GVA_DB.Where("id = ?", grant.UserId).First(&user).Error; err != nil {
+		return err
+	}
+	if err = JwtServiceApp.RevokeUserTokens(user.UUID.String(), user.Username, now); err != nil {
+		return err
+	}
+	// 失去角色后 由其发起的同角色委托一并结束
+	var delegated []system.SysUserAuthorityGrant
+	global.GVA_DB.Where("delegator_id = ? AND authority_id = ? AND status IN ?", grant.UserId, grant.AuthorityId, []string{system.AuthorityGrantPending, system.AuthorityGrantActive}).Find(&delegated)
+	for i := range delegated {
+		if err = grantService.end(&delegated[i], system.AuthorityGrantRevoked, "委托人已失去该角色"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reapplyActiveGrants 重新设置用户角色后 补回生效中授权对应的角色 使其仍按授权到期收回
+// 调用前 sys_user_authority 已按 authorityIds 重新写入 其中的角色视为管理员直接分配 授权到期时不再收回
+func (grantService *AuthorityGrantService) reapplyActiveGrants(tx *gorm.DB, userId uint, authorityIds []uint) error {
+	var grants []system.SysUserAuthorityGrant
+	if err := tx.Where("user_id = ? AND status = ?", userId, system.AuthorityGrantActive).Order("id").Find(&grants).Error; err != nil {
+		return err
+	}
+	assigned := make(map[uint]bool, len(authorityIds))
+	for _, id := range authorityIds {
+		assigned[id] = true
+	}
+	for i := range grants {
+		applied := false
+		// 同一角色有多个生效中的授权时 由第一个写入
+		if !assigned[grants[i].AuthorityId] {
+			if err := tx.Create(&system.SysUserAuthority{SysUserId: userId, SysAuthorityAuthorityId: grants[i].AuthorityId}).Error; err != nil {
+				return err
+			}
+			assigned[grants[i].AuthorityId] = true
+			applied = true
+		}
+		if grants[i].Applied == applied {
+			continue
+		}
+		if err := tx.Model(&grants[i]).Update("applied", applied).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
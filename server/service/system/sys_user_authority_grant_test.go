@@ -0,0 +1,212 @@
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupAuthorityGrantTest 角色 888、9528、100 用户 admin(1) alice(2) 只有 888 bob(3) 拥有 888 和 9528
+func setupAuthorityGrantTest(t *testing.T) *gorm.DB {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysUser{}, &system.SysUserAuthority{}, &system.SysUserAuthorityGrant{})
+	newTestCache(t)
+	oldJWT, oldSystem := global.GVA_CONFIG.JWT, global.GVA_CONFIG.System
+	global.GVA_CONFIG.JWT.ExpiresTime = "1h"
+	global.GVA_CONFIG.System.UseStrictAuth = false
+	global.GVA_CONFIG.System.UseMultipoint = false
+	t.Cleanup(func() { global.GVA_CONFIG.JWT, global.GVA_CONFIG.System = oldJWT, oldSystem })
+
+	for _, id := range []uint{888, 9528, 100} {
+		assert.Nil(t, db.Create(&system.SysAuthority{AuthorityId: id}).Error)
+	}
+	users := []struct {
+		name string
+		ids  []uint
+	}{{"admin", []uint{888}}, {"alice", []uint{888}}, {"bob", []uint{888, 9528}}}
+	for _, u := range users {
+		user := system.SysUser{UUID: uuid.New(), Username: u.name, AuthorityId: 888}
+		assert.Nil(t, db.Create(&user).Error)
+		for _, id := range u.ids {
+			assert.Nil(t, db.Create(&system.SysUserAuthority{SysUserId: user.ID, SysAuthorityAuthorityId: id}).Error)
+		}
+	}
+	return db
+}
+
+func grantFor(userId, authorityId uint) systemReq.CreateAuthorityGrant {
+	return systemReq.CreateAuthorityGrant{UserId: userId, AuthorityId: authorityId, ValidUntil: time.Now().Add(time.Hour), Reason: "值班"}
+}
+
+func reloadGrant(t *testing.T, db *gorm.DB, id uint) system.SysUserAuthorityGrant {
+	var grant system.SysUserAuthorityGrant
+	assert.Nil(t, db.First(&grant, id).Error)
+	return grant
+}
+
+func TestCreateGrant(t *testing.T) {
+	db := setupAuthorityGrantTest(t)
+
+	expired := grantFor(2, 9528)
+	expired.ValidUntil = time.Now().Add(-time.Second)
+	_, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, expired)
+	assert.EqualError(t, err, "失效时间必须晚于生效时间和当前时间")
+	_, err = AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(99, 9528))
+	assert.EqualError(t, err, "用户不存在")
+	_, err = AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(2, 1234))
+	assert.EqualError(t, err, "角色不存在")
+
+	// 立即生效 写入用户角色并记为由本授权写入
+	grant, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(2, 9528))
+	assert.Nil(t, err)
+	assert.Equal(t, system.AuthorityGrantActive, grant.Status)
+	assert.True(t, grant.Applied)
+	assert.Equal(t, uint(0), grant.DelegatorId)
+	assert.Equal(t, uint(1), grant.OperatorId)
+	assert.Equal(t, []uint{888, 9528}, userAuthorityIds(t, db, 2))
+
+	// 用户已有该角色 不记为由本授权写入
+	grant, err = AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(3, 9528))
+	assert.Nil(t, err)
+	assert.Equal(t, system.AuthorityGrantActive, grant.Status)
+	assert.False(t, grant.Applied)
+
+	// 未到生效时间
+	pending := grantFor(2, 100)
+	from := time.Now().Add(time.Hour)
+	pending.ValidFrom, pending.ValidUntil = &from, from.Add(time.Hour)
+	grant, err = AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, pending)
+	assert.Nil(t, err)
+	assert.Equal(t, system.AuthorityGrantPending, grant.Status)
+	assert.Equal(t, []uint{888, 9528}, userAuthorityIds(t, db, 2))
+}
+
+func TestDelegate(t *testing.T) {
+	db := setupAuthorityGrantTest(t)
+
+	noReason := grantFor(2, 9528)
+	noReason.Reason = " "
+	_, err := AuthorityGrantServiceApp.Delegate(context.Background(), 3, noReason)
+	assert.EqualError(t, err, "委托原因不能为空")
+	_, err = AuthorityGrantServiceApp.Delegate(context.Background(), 3, grantFor(3, 9528))
+	assert.EqualError(t, err, "不能委托给自己")
+	_, err = AuthorityGrantServiceApp.Delegate(context.Background(), 2, grantFor(1, 9528))
+	assert.EqualError(t, err, "您没有该角色,无法委托")
+
+	grant, err := AuthorityGrantServiceApp.Delegate(context.Background(), 3, grantFor(2, 9528))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), grant.DelegatorId)
+	assert.True(t, grant.Applied)
+	assert.Equal(t, []uint{888, 9528}, userAuthorityIds(t, db, 2))
+
+	// 委托得到的角色不能转授
+	_, err = AuthorityGrantServiceApp.Delegate(context.Background(), 2, grantFor(1, 9528))
+	assert.EqualError(t, err, "委托获得的角色不能再次委托")
+
+	// 只能撤销自己发起的委托
+	assert.EqualError(t, AuthorityGrantServiceApp.RevokeGrant(context.Background(), grant.ID, 2, ""), "授权不存在或已结束")
+	assert.Nil(t, AuthorityGrantServiceApp.RevokeGrant(context.Background(), grant.ID, 3, ""))
+	grant = reloadGrant(t, db, grant.ID)
+	assert.Equal(t, system.AuthorityGrantRevoked, grant.Status)
+	assert.Equal(t, "手动撤销", grant.EndReason)
+	assert.Equal(t, []uint{888}, userAuthorityIds(t, db, 2))
+}
+
+func TestExpireGrants(t *testing.T) {
+	db := setupAuthorityGrantTest(t)
+	var alice system.SysUser
+	assert.Nil(t, db.First(&alice, 2).Error)
+	before := issuedAt(alice.UUID, time.Now().Add(-time.Minute))
+
+	// 到期 且是用户当前使用的角色
+	expired, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(2, 9528))
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(&alice).Update("authority_id", 9528).Error)
+	assert.Nil(t, db.Model(&expired).Update("valid_until", time.Now().Add(-time.Second)).Error)
+	// 已到生效时间
+	pending := grantFor(2, 100)
+	from := time.Now().Add(time.Hour)
+	pending.ValidFrom, pending.ValidUntil = &from, from.Add(time.Hour)
+	started, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, pending)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(&started).Update("valid_from", time.Now().Add(-time.Second)).Error)
+	// 委托人已失去角色
+	orphaned, err := AuthorityGrantServiceApp.Delegate(context.Background(), 3, grantFor(1, 9528))
+	assert.Nil(t, err)
+	assert.Nil(t, db.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ? AND sys_authority_authority_id = ?", 3, 9528).Error)
+
+	assert.Nil(t, AuthorityGrantServiceApp.ExpireGrants())
+
+	expired = reloadGrant(t, db, expired.ID)
+	assert.Equal(t, system.AuthorityGrantExpired, expired.Status)
+	assert.False(t, expired.Applied)
+	assert.NotNil(t, expired.EndedAt)
+	assert.Equal(t, []uint{100, 888}, userAuthorityIds(t, db, 2))
+	assert.Nil(t, db.First(&alice, 2).Error)
+	assert.Contains(t, []uint{100, 888}, alice.AuthorityId)
+	assert.True(t, JwtServiceApp.IsRevoked(before))
+
+	started = reloadGrant(t, db, started.ID)
+	assert.Equal(t, system.AuthorityGrantActive, started.Status)
+	assert.True(t, started.Applied)
+
+	orphaned = reloadGrant(t, db, orphaned.ID)
+	assert.Equal(t, system.AuthorityGrantRevoked, orphaned.Status)
+	assert.Equal(t, "委托人已失去该角色", orphaned.EndReason)
+	assert.Equal(t, []uint{888}, userAuthorityIds(t, db, 1))
+
+	// 数据库尚未初始化时定时任务直接跳过
+	global.GVA_DB = nil
+	assert.Nil(t, AuthorityGrantServiceApp.ExpireGrants())
+}
+
+func TestEndGrant(t *testing.T) {
+	db := setupAuthorityGrantTest(t)
+	first, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(2, 9528))
+	assert.Nil(t, err)
+	second, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(2, 9528))
+	assert.Nil(t, err)
+	assert.False(t, second.Applied)
+	// alice 将管理员授予的角色委托给 admin
+	delegated, err := AuthorityGrantServiceApp.Delegate(context.Background(), 2, grantFor(1, 9528))
+	assert.Nil(t, err)
+
+	// 同一角色还有其他生效中的授权时 由其接管 角色保留
+	assert.Nil(t, AuthorityGrantServiceApp.RevokeGrant(context.Background(), first.ID, 0, "调岗"))
+	assert.Equal(t, "调岗", reloadGrant(t, db, first.ID).EndReason)
+	assert.True(t, reloadGrant(t, db, second.ID).Applied)
+	assert.Equal(t, []uint{888, 9528}, userAuthorityIds(t, db, 2))
+	assert.EqualError(t, AuthorityGrantServiceApp.RevokeGrant(context.Background(), first.ID, 0, ""), "授权不存在或已结束")
+
+	// 最后一个授权结束后收回角色 由其发起的委托一并结束
+	assert.Nil(t, AuthorityGrantServiceApp.RevokeGrant(context.Background(), second.ID, 0, ""))
+	assert.Equal(t, []uint{888}, userAuthorityIds(t, db, 2))
+	delegated = reloadGrant(t, db, delegated.ID)
+	assert.Equal(t, system.AuthorityGrantRevoked, delegated.Status)
+	assert.Equal(t, "委托人已失去该角色", delegated.EndReason)
+	assert.Equal(t, []uint{888}, userAuthorityIds(t, db, 1))
+}
+
+func TestReapplyActiveGrants(t *testing.T) {
+	db := setupAuthorityGrantTest(t)
+	temporary, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(2, 100))
+	assert.Nil(t, err)
+	assigned, err := AuthorityGrantServiceApp.CreateGrant(context.Background(), 888, 1, grantFor(2, 9528))
+	assert.Nil(t, err)
+
+	// 重新设置角色时 生效中授权的角色被补回 管理员直接分配的角色不再由授权写入
+	assert.Nil(t, UserServiceApp.SetUserAuthorities(context.Background(), 888, 2, []uint{888, 9528}))
+	assert.Equal(t, []uint{100, 888, 9528}, userAuthorityIds(t, db, 2))
+	assert.True(t, reloadGrant(t, db, temporary.ID).Applied)
+	assert.False(t, reloadGrant(t, db, assigned.ID).Applied)
+
+	assert.Nil(t, AuthorityGrantServiceApp.RevokeGrant(context.Background(), assigned.ID, 0, ""))
+	assert.Nil(t, AuthorityGrantServiceApp.RevokeGrant(context.Background(), temporary.ID, 0, ""))
+	assert.Equal(t, []uint{888, 9528}, userAuthorityIds(t, db, 2))
+}
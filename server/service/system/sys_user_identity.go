@@ -0,0 +1,49 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"gorm.io/gorm"
+)
+
+// mapGroupAuthorities 将外部用户组映射为本地角色ID 按配置顺序去重
+func mapGroupAuthorities(mapping []config.GroupAuthorityMapping, groups []string) []uint {
+	has := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		has[g] = struct{}{}
+	}
+	var ids []uint
+	seen := make(map[uint]struct{})
+	for _, m := range mapping {
+		if _, ok := has[m.Group]; !ok {
+			continue
+		}
+		if _, ok := seen[m.AuthorityId]; ok {
+			continue
+		}
+		seen[m.AuthorityId] = struct{}{}
+		ids = append(ids, m.AuthorityId)
+	}
+	return ids
+}
+
+// syncUserAuthorities 以外部来源为准覆盖用户的角色 当前角色不在其中时切换到第一个
+func syncUserAuthorities(tx *gorm.DB, user *system.SysUser, authorityIds []uint) error {
+	if err := tx.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ?", user.ID).Error; err != nil {
+		return err
+	}
+	useAuthority := make([]system.SysUserAuthority, 0, len(authorityIds))
+	current := false
+	for _, v := range authorityIds {
+		useAuthority = append(useAuthority, system.SysUserAuthority{SysUserId: user.ID, SysAuthorityAuthorityId: v})
+		current = current || v == user.AuthorityId
+	}
+	if err := tx.Create(&useAuthority).Error; err != nil {
+		return err
+	}
+	if current {
+		return nil
+	}
+	user.AuthorityId = authorityIds[0]
+	return tx.Model(&system.SysUser{}).Where("id = ?", user.ID).Update("authority_id", user.AuthorityId).Error
+}
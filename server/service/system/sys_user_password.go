@@ -0,0 +1,52 @@
+package system
+
+import (
+	"fmt"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"gorm.io/gorm"
+)
+
+// checkNewPassword 校验密码策略 并检查是否与最近使用过的密码重复
+func checkNewPassword(tx *gorm.DB, user system.SysUser, password string) error {
+	policy := global.GVA_CONFIG.PasswordPolicy
+	if err := utils.CheckPasswordPolicy(policy, user.Username, password); err != nil {
+		return err
+	}
+	if policy.HistoryCount <= 0 || user.ID == 0 {
+		return nil
+	}
+	if user.Password != "" && utils.BcryptCheck(password, user.Password) {
+		return fmt.Errorf("新密码不能与最近%d次使用过的密码相同", policy.HistoryCount)
+	}
+	var histories []system.SysUserPasswordHistory
+	err := tx.Where("user_id = ?", user.ID).Order("id desc").Limit(policy.HistoryCount).Find(&histories).Error
+	if err != nil {
+		return err
+	}
+	for i := range histories {
+		if utils.BcryptCheck(password, histories[i].Password) {
+			return fmt.Errorf("新密码不能与最近%d次使用过的密码相同", policy.HistoryCount)
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory 记录新的密码哈希 只保留策略要求的条数
+func recordPasswordHistory(tx *gorm.DB, userId uint, hash string) error {
+	count := global.GVA_CONFIG.PasswordPolicy.HistoryCount
+	if count <= 0 {
+		return nil
+	}
+	if err := tx.Create(&system.SysUserPasswordHistory{UserId: userId, Password: hash}).Error; err != nil {
+		return err
+	}
+	var keep []uint
+	err := tx.Model(&system.SysUserPasswordHistory{}).Where("user_id = ?", userId).Order("id desc").Limit(count).Pluck("id", &keep).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND id NOT IN ?", userId, keep).Delete(&system.SysUserPasswordHistory{}).Error
+}
@@ -0,0 +1,40 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginPasswordExpiry(t *testing.T) {
+	db := newTestDB(t, &system.SysAuthority{}, &system.SysUser{}, &system.SysUserAuthority{}, &system.UserLoginHistory{})
+	old := global.GVA_CONFIG.PasswordPolicy.ExpireDays
+	global.GVA_CONFIG.PasswordPolicy.ExpireDays = 90
+	t.Cleanup(func() { global.GVA_CONFIG.PasswordPolicy.ExpireDays = old })
+
+	created := time.Now().AddDate(-1, 0, 0)
+	legacy := system.SysUser{Username: "admin", Password: utils.BcryptHash("123456"), AuthorityId: 888}
+	legacy.CreatedAt = created
+	stale := time.Now().AddDate(0, 0, -91)
+	expired := system.SysUser{Username: "expired", Password: utils.BcryptHash("123456"), AuthorityId: 888, PasswordUpdatedAt: &stale}
+	assert.Nil(t, db.Create(&legacy).Error)
+	assert.Nil(t, db.Create(&expired).Error)
+
+	// 开启有效期前创建的账号不会立即过期 登录后开始计算
+	user, err := UserServiceApp.Login(&system.SysUser{Username: "admin", Password: "123456"})
+	assert.Nil(t, err)
+	assert.False(t, user.PasswordExpired)
+	var saved system.SysUser
+	assert.Nil(t, db.First(&saved, legacy.ID).Error)
+	if assert.NotNil(t, saved.PasswordUpdatedAt) {
+		assert.WithinDuration(t, time.Now(), *saved.PasswordUpdatedAt, time.Minute)
+	}
+
+	user, err = UserServiceApp.Login(&system.SysUser{Username: "expired", Password: "123456"})
+	assert.Nil(t, err)
+	assert.True(t, user.PasswordExpired)
+}
@@ -0,0 +1,376 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/event"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookMaxAttempts = 6                // 最多投递次数 含首次
+	webhookRetryBase   = 30 * time.Second // 首次重试间隔 之后每次翻倍
+	webhookRetryMax    = time.Hour        // 最大重试间隔
+	webhookTimeout     = 10 * time.Second // 单次请求超时
+	webhookBatch       = 100              // 定时任务每次处理的投递数量
+)
+
+// webhookClient 投递使用的客户端 未开启 webhook.allow-private-network 时拒绝连接内网地址
+var webhookClient = webhook.NewClient(webhookTimeout, func() bool {
+	return global.GVA_CONFIG.Webhook.AllowPrivateNetwork
+})
+
+var webhookEvents struct {
+	sync.RWMutex
+	names []string
+}
+
+type WebhookService struct{}
+
+var WebhookServiceApp = new(WebhookService)
+
+// WebhookEvent 将事件总线上的事件转发给 webhook 订阅 在 initialize.Event 中注册
+// 只转发本实例发布的事件 多实例部署时不会重复投递
+func WebhookEvent[T any](bus *event.Bus, topic event.Topic[T]) {
+	webhookEvents.Lock()
+	webhookEvents.names = append(webhookEvents.names, string(topic))
+	webhookEvents.Unlock()
+	event.Subscribe(bus, topic, func(ctx context.Context, payload T) error {
+		return WebhookServiceApp.Dispatch(ctx, string(topic), payload)
+	}, event.Async())
+}
+
+//@function: GetEvents
+//@description: 获取可订阅的事件
+//@return: []string
+
+func (webhookService *WebhookService) GetEvents() []string {
+	webhookEvents.RLock()
+	defer webhookEvents.RUnlock()
+	return append([]string{system.WebhookAllEvents}, webhookEvents.names...)
+}
+
+//@function: Dispatch
+//@description: 为订阅了该事件的启用中的 webhook 创建投递记录并立即投递 失败的由定时任务重试
+//@param: ctx context.Context, name string, payload any
+//@return: err error
+
+func (webhookService *WebhookService) Dispatch(ctx context.Context, name string, payload any) (err error) {
+	var hooks []system.SysWebhook
+	if err = global.GVA_DB.WithContext(ctx).Where("active = ?", true).Find(&hooks).Error; err != nil {
+		return err
+	}
+	var deliveries []system.SysWebhookDelivery
+	now := time.Now()
+	for i := range hooks {
+		if !hooks[i].Subscribed(name) {
+			continue
+		}
+		deliveryId := uuid.New().String()
+		body, err := json.Marshal(map[string]any{
+			"id":        deliveryId,
+			"event":     name,
+			"createdAt": now,
+			"data":      payload,
+		})
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, system.SysWebhookDelivery{
+			WebhookId:   hooks[i].ID,
+			DeliveryId:  deliveryId,
+			Event:       name,
+			Payload:     string(body),
+			Status:      system.WebhookDeliveryPending,
+			NextRetryAt: &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err = global.GVA_DB.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		return err
+	}
+	var errs []error
+	for i := range deliveries {
+		if _, err = webhookService.deliver(ctx, deliveries[i].ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+//@function: RetryDeliveries
+//@description: 投递到期的记录 由定时任务调用
+//@return: err error
+
+func (webhookService *WebhookService) RetryDeliveries() (err error) {
+	if global.GVA_DB == nil {
+		return nil
+	}
+	var ids []uint
+	err = global.GVA_DB.Model(&system.SysWebhookDelivery{}).
+		Where("status IN ? AND next_retry_at <= ?", webhookDueStatus, time.Now()).
+		Order("next_retry_at").Limit(webhookBatch).Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	for _, id := range ids {
+		if _, err = webhookService.deliver(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookDueStatus 可以投递的状态 投递中的记录超过租期未完成(如进程退出)时也会重新投递
+var webhookDueStatus = []string{system.WebhookDeliveryPending, system.WebhookDeliveryRetrying, system.WebhookDeliverySending}
+
+// deliver 投递一次 先通过条件更新领取记录 避免定时任务与即时投递或多实例间重复发送
+// 只有数据库错误会返回 err, 投递失败记录在投递记录中
+func (webhookService *WebhookService) deliver(ctx context.Context, id uint) (delivery system.SysWebhookDelivery, err error) {
+	db := global.GVA_DB.WithContext(ctx)
+	now := time.Now()
+	lease := now.Add(2 * webhookTimeout)
+	res := db.Model(&system.SysWebhookDelivery{}).
+		Where("id = ? AND status IN ? AND next_retry_at <= ?", id, webhookDueStatus, now).
+		Updates(map[string]any{"status": system.WebhookDeliverySending, "next_retry_at": lease})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return delivery, res.Error
+	}
+	if err = db.First(&delivery, id).Error; err != nil {
+		return delivery, err
+	}
+
+	var hook system.SysWebhook
+	err = db.Where("id = ?", delivery.WebhookId).Limit(1).Find(&hook).Error
+	if err != nil {
+		return delivery, err
+	}
+	delivery.Attempts++
+	if hook.ID == 0 || !hook.Active {
+		delivery.Status = system.WebhookDeliveryFailed
+		delivery.NextRetryAt = nil
+		delivery.Error = "webhook不存在或已停用"
+	} else {
+		result, sendErr := webhook.Send(ctx, webhookClient, webhook.Request{
+			Url:        hook.Url,
+			Secret:     hook.Secret,
+			Event:      delivery.Event,
+			DeliveryId: delivery.DeliveryId,
+			Body:       []byte(delivery.Payload),
+		})
+		delivery.ResponseCode = result.StatusCode
+		delivery.ResponseBody = result.Body
+		delivery.Duration = result.Duration.Milliseconds()
+		delivery.Error = ""
+		switch {
+		case sendErr == nil:
+			delivered := time.Now()
+			delivery.Status = system.WebhookDeliverySuccess
+			delivery.NextRetryAt = nil
+			delivery.DeliveredAt = &delivered
+		case delivery.Attempts >= webhookMaxAttempts:
+			delivery.Status = system.WebhookDeliveryFailed
+			delivery.NextRetryAt = nil
+			delivery.Error = sendErr.Error()
+		default:
+			next := time.Now().Add(webhook.Backoff(delivery.Attempts, webhookRetryBase, webhookRetryMax))
+			delivery.Status = system.WebhookDeliveryRetrying
+			delivery.NextRetryAt = &next
+			delivery.Error = sendErr.Error()
+		}
+	}
+	err = db.Model(&system.SysWebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]any{
+		"status":        delivery.Status,
+		"next_retry_at": delivery.NextRetryAt,
+		"attempts":      delivery.Attempts,
+		"response_code": delivery.ResponseCode,
+		"response_body": delivery.ResponseBody,
+		"error":         delivery.Error,
+		"duration":      delivery.Duration,
+		"delivered_at":  delivery.DeliveredAt,
+	}).Error
+	return delivery, err
+}
+
+//@function: Replay
+//@description: 重放投递记录 使用原请求内容和投递ID新建一条记录并立即投递
+//@param: ctx context.Context, id uint
+//@return: delivery system.SysWebhookDelivery, err error
+
+func (webhookService *WebhookService) Replay(ctx context.Context, id uint) (delivery system.SysWebhookDelivery, err error) {
+	var origin system.SysWebhookDelivery
+	if err = global.GVA_DB.WithContext(ctx).First(&origin, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return delivery, errors.New("投递记录不存在")
+		}
+		return delivery, err
+	}
+	if origin.Status != system.WebhookDeliveryFailed && origin.Status != system.WebhookDeliverySuccess {
+		return delivery, errors.New("投递尚未结束, 无需重放")
+	}
+	now := time.Now()
+	delivery = system.SysWebhookDelivery{
+		WebhookId:   origin.WebhookId,
+		DeliveryId:  origin.DeliveryId,
+		Event:       origin.Event,
+		Payload:     origin.Payload,
+		Status:      system.WebhookDeliveryPending,
+		NextRetryAt: &now,
+		ReplayOf:    origin.ID,
+	}
+	if err = global.GVA_DB.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return delivery, err
+	}
+	return webhookService.deliver(ctx, delivery.ID)
+}
+
+//@function: CreateWebhook
+//@description: 创建webhook 未填写密钥时自动生成
+//@param: w *system.SysWebhook
+//@return: err error
+
+func (webhookService *WebhookService) CreateWebhook(w *system.SysWebhook) (err error) {
+	if err = webhookService.check(w); err != nil {
+		return err
+	}
+	return global.GVA_DB.Create(w).Error
+}
+
+//@function: UpdateWebhook
+//@description: 更新webhook
+//@param: w system.SysWebhook
+//@return: err error
+
+func (webhookService *WebhookService) UpdateWebhook(w system.SysWebhook) (err error) {
+	if err = webhookService.check(&w); err != nil {
+		return err
+	}
+	res := global.GVA_DB.Model(&system.SysWebhook{}).Where("id = ?", w.ID).
+		Select("name", "url", "secret", "events", "active", "remark").
+		Updates(map[string]any{"name": w.Name, "url": w.Url, "secret": w.Secret, "events": w.Events, "active": w.Active, "remark": w.Remark})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("webhook不存在")
+	}
+	return nil
+}
+
+//@function: DeleteWebhook
+//@description: 删除webhook 保留投递记录 未完成的投递不再发送
+//@param: id uint
+//@return: err error
+
+func (webhookService *WebhookService) DeleteWebhook(id uint) (err error) {
+	return global.GVA_DB.Delete(&system.SysWebhook{}, "id = ?", id).Error
+}
+
+//@function: GetWebhook
+//@description: 获取webhook
+//@param: id uint
+//@return: w system.SysWebhook, err error
+
+func (webhookService *WebhookService) GetWebhook(id uint) (w system.SysWebhook, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&w).Error
+	return
+}
+
+//@function: GetWebhookList
+//@description: 分页获取webhook列表
+//@param: info systemReq.WebhookSearch
+//@return: list []system.SysWebhook, total int64, err error
+
+func (webhookService *WebhookService) GetWebhookList(info systemReq.WebhookSearch) (list []system.SysWebhook, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysWebhook{})
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if info.Active != nil {
+		db = db.Where("active = ?", *info.Active)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+//@function: GetDeliveryList
+//@description: 分页获取webhook投递记录
+//@param: info systemReq.WebhookDeliverySearch
+//@return: list []system.SysWebhookDelivery, total int64, err error
+
+func (webhookService *WebhookService) GetDeliveryList(info systemReq.WebhookDeliverySearch) (list []system.SysWebhookDelivery, total int64, err error) {
+	db := global.GVA_DB.Model(&system.SysWebhookDelivery{})
+	if info.WebhookId != 0 {
+		db = db.Where("webhook_id = ?", info.WebhookId)
+	}
+	if info.Event != "" {
+		db = db.Where("event = ?", info.Event)
+	}
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// check 校验回调地址和订阅的事件
+func (webhookService *WebhookService) check(w *system.SysWebhook) error {
+	if w.Name == "" {
+		return errors.New("名称不能为空")
+	}
+	u, err := url.Parse(w.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("回调地址必须是http或https地址")
+	}
+	if !global.GVA_CONFIG.Webhook.AllowPrivateNetwork {
+		// 投递时还会校验实际连接的地址 这里提前拒绝明显指向内网的地址
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		defer cancel()
+		if err = webhook.CheckHost(ctx, u.Hostname()); err != nil {
+			if errors.Is(err, webhook.ErrPrivateAddress) {
+				return err
+			}
+			return errors.New("回调地址无法解析: " + err.Error())
+		}
+	}
+	if len(w.Events) == 0 {
+		return errors.New("请选择订阅的事件")
+	}
+	events := webhookService.GetEvents()
+	for _, e := range w.Events {
+		known := false
+		for _, name := range events {
+			if e == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return errors.New("不支持的事件: " + e)
+		}
+	}
+	if w.Secret == "" {
+		w.Secret = utils.SecureRandomString(32)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package system
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/webhook"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// webhookReceiver 记录收到的投递ID 按 status 返回响应码
+type webhookReceiver struct {
+	sync.Mutex
+	status     int
+	deliveries []string
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+	r.deliveries = append(r.deliveries, req.Header.Get(webhook.HeaderDelivery))
+	w.WriteHeader(r.status)
+}
+
+func (r *webhookReceiver) received() []string {
+	r.Lock()
+	defer r.Unlock()
+	return append([]string(nil), r.deliveries...)
+}
+
+func (r *webhookReceiver) respond(status int) {
+	r.Lock()
+	r.status = status
+	r.Unlock()
+}
+
+func setupWebhookTest(t *testing.T, allowPrivate bool) (*gorm.DB, *webhookReceiver, *httptest.Server) {
+	db := newTestDB(t, &system.SysWebhook{}, &system.SysWebhookDelivery{})
+	old := global.GVA_CONFIG.Webhook
+	global.GVA_CONFIG.Webhook.AllowPrivateNetwork = allowPrivate
+	t.Cleanup(func() { global.GVA_CONFIG.Webhook = old })
+	receiver := &webhookReceiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	return db, receiver, server
+}
+
+func TestWebhookRetryAndReplay(t *testing.T) {
+	db, receiver, server := setupWebhookTest(t, true)
+	hook := system.SysWebhook{Name: "test", Url: server.URL, Events: []string{system.WebhookAllEvents}, Active: true}
+	assert.Nil(t, WebhookServiceApp.CreateWebhook(&hook))
+
+	// 首次投递失败后按退避时间安排重试
+	assert.Nil(t, WebhookServiceApp.Dispatch(context.Background(), "user.created", map[string]any{"id": 1}))
+	var delivery system.SysWebhookDelivery
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		delivery = system.SysWebhookDelivery{}
+		assert.Nil(t, db.First(&delivery).Error)
+		assert.Equal(t, attempt, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+		if attempt == webhookMaxAttempts {
+			break
+		}
+		assert.Equal(t, system.WebhookDeliveryRetrying, delivery.Status)
+		if assert.NotNil(t, delivery.NextRetryAt) {
+			wait := time.Until(*delivery.NextRetryAt)
+			backoff := webhook.Backoff(attempt, webhookRetryBase, webhookRetryMax)
+			assert.InDelta(t, backoff.Seconds(), wait.Seconds(), 5)
+		}
+		// 未到期的记录不会投递
+		assert.Nil(t, WebhookServiceApp.RetryDeliveries())
+		assert.Len(t, receiver.received(), attempt)
+
+		assert.Nil(t, db.Model(&delivery).Update("next_retry_at", time.Now().Add(-time.Second)).Error)
+		assert.Nil(t, WebhookServiceApp.RetryDeliveries())
+	}
+
+	// 超过最大投递次数后不再重试
+	assert.Equal(t, system.WebhookDeliveryFailed, delivery.Status)
+	assert.Nil(t, delivery.NextRetryAt)
+	assert.Equal(t, "响应状态码 500", delivery.Error)
+	received := receiver.received()
+	assert.Len(t, received, webhookMaxAttempts)
+	for _, id := range received {
+		assert.Equal(t, delivery.DeliveryId, id)
+	}
+
+	// 重放新建记录 投递ID不变
+	receiver.respond(http.StatusOK)
+	replay, err := WebhookServiceApp.Replay(context.Background(), delivery.ID)
+	assert.Nil(t, err)
+	assert.NotEqual(t, delivery.ID, replay.ID)
+	assert.Equal(t, delivery.ID, replay.ReplayOf)
+	assert.Equal(t, delivery.DeliveryId, replay.DeliveryId)
+	assert.Equal(t, system.WebhookDeliverySuccess, replay.Status)
+	assert.Equal(t, 1, replay.Attempts)
+	assert.NotNil(t, replay.DeliveredAt)
+	received = receiver.received()
+	assert.Len(t, received, webhookMaxAttempts+1)
+	assert.Equal(t, delivery.DeliveryId, received[len(received)-1])
+
+	_, err = WebhookServiceApp.Replay(context.Background(), replay.ID+1)
+	assert.EqualError(t, err, "投递记录不存在")
+
+	// 数据库尚未初始化时定时任务直接跳过
+	global.GVA_DB = nil
+	assert.Nil(t, WebhookServiceApp.RetryDeliveries())
+}
+
+func TestWebhookPrivateNetwork(t *testing.T) {
+	db, receiver, server := setupWebhookTest(t, false)
+	receiver.respond(http.StatusOK)
+
+	for _, u := range []string{server.URL, "http://169.254.169.254/latest/meta-data", "http://localhost:8888/hook"} {
+		err := WebhookServiceApp.CreateWebhook(&system.SysWebhook{Name: "test", Url: u, Events: []string{system.WebhookAllEvents}, Active: true})
+		assert.ErrorIs(t, err, webhook.ErrPrivateAddress, u)
+	}
+
+	// 关闭 allow-private-network 前保存的内网地址 投递时同样拒绝 不会读取到响应
+	hook := system.SysWebhook{Name: "test", Url: server.URL, Events: []string{system.WebhookAllEvents}, Active: true}
+	assert.Nil(t, db.Create(&hook).Error)
+	assert.Nil(t, WebhookServiceApp.Dispatch(context.Background(), "user.created", map[string]any{"id": 1}))
+	var delivery system.SysWebhookDelivery
+	assert.Nil(t, db.First(&delivery).Error)
+	assert.Equal(t, system.WebhookDeliveryRetrying, delivery.Status)
+	assert.Contains(t, delivery.Error, webhook.ErrPrivateAddress.Error())
+	assert.Empty(t, delivery.ResponseBody)
+	assert.Empty(t, receiver.received())
+}
@@ -78,6 +78,9 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 
 		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/updateCasbin", Description: "更改角色api权限"},
 		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/getPolicyPathByAuthorityId", Description: "获取权限列表"},
+		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/simulateCasbin", Description: "模拟权限判定"},
+		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/diffCasbin", Description: "预览角色api权限差异"},
+		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/migrateRoleInheritance", Description: "迁移角色继承权限"},
 
 		{ApiGroup: "菜单", Method: "POST", Path: "/menu/addBaseMenu", Description: "新增菜单"},
 		{ApiGroup: "菜单", Method: "POST", Path: "/menu/getMenu", Description: "获取菜单树(必选)"},
@@ -114,14 +117,22 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getTables", Description: "获取数据库表"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/createTemp", Description: "自动化代码"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/preview", Description: "预览自动化代码"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/updateTemp", Description: "修改已生成结构的字段"},
+		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getOpenAPISchemas", Description: "获取OpenAPI文档中的Schema"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/openAPI", Description: "从OpenAPI文档生成代码"},
 		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getColumn", Description: "获取所选table的所有字段"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/installPlugin", Description: "安装插件"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/pubPlug", Description: "打包插件"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/installPluginPackage", Description: "安装插件包"},
+		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getInstalledPlugins", Description: "获取已安装的插件"},
+		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getPluginStatus", Description: "获取插件运行状态"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/uninstallPlugin", Description: "卸载插件"},
 
 		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/createPackage", Description: "配置模板"},
 		{ApiGroup: "模板配置", Method: "GET", Path: "/autoCode/getTemplates", Description: "获取模板文件"},
 		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/getPackage", Description: "获取所有模板"},
 		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/delPackage", Description: "删除模板"},
+		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/previewPack", Description: "预览自定义模板包"},
 
 		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/getMeta", Description: "获取meta信息"},
 		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/rollback", Description: "回滚自动生成代码"},
@@ -158,6 +169,9 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "按钮权限", Method: "POST", Path: "/authorityBtn/getAuthorityBtn", Description: "获取已有按钮权限"},
 		{ApiGroup: "按钮权限", Method: "POST", Path: "/authorityBtn/canRemoveAuthorityBtn", Description: "删除按钮"},
 
+		{ApiGroup: "字段权限", Method: "POST", Path: "/authorityField/setAuthorityField", Description: "设置字段权限"},
+		{ApiGroup: "字段权限", Method: "POST", Path: "/authorityField/getAuthorityField", Description: "获取字段权限"},
+
 		{ApiGroup: "导出模板", Method: "POST", Path: "/sysExportTemplate/createSysExportTemplate", Description: "新增导出模板"},
 		{ApiGroup: "导出模板", Method: "DELETE", Path: "/sysExportTemplate/deleteSysExportTemplate", Description: "删除导出模板"},
 		{ApiGroup: "导出模板", Method: "DELETE", Path: "/sysExportTemplate/deleteSysExportTemplateByIds", Description: "批量删除导出模板"},
@@ -185,6 +199,83 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "媒体库分类", Method: "GET", Path: "/attachmentCategory/getCategoryList", Description: "分类列表"},
 		{ApiGroup: "媒体库分类", Method: "POST", Path: "/attachmentCategory/addCategory", Description: "添加/编辑分类"},
 		{ApiGroup: "媒体库分类", Method: "POST", Path: "/attachmentCategory/deleteCategory", Description: "删除分类"},
+
+		{ApiGroup: "LDAP", Method: "GET", Path: "/ldap/syncPreview", Description: "预览LDAP同步差异"},
+		{ApiGroup: "LDAP", Method: "POST", Path: "/ldap/sync", Description: "执行LDAP同步"},
+
+		{ApiGroup: "访问令牌", Method: "POST", Path: "/apiToken/createApiToken", Description: "创建个人访问令牌"},
+		{ApiGroup: "访问令牌", Method: "GET", Path: "/apiToken/getApiTokenList", Description: "获取个人访问令牌列表"},
+		{ApiGroup: "访问令牌", Method: "POST", Path: "/apiToken/revokeApiToken", Description: "吊销个人访问令牌"},
+		{ApiGroup: "访问令牌", Method: "POST", Path: "/apiToken/adminCreateApiToken", Description: "为用户创建访问令牌(管理员)"},
+		{ApiGroup: "访问令牌", Method: "GET", Path: "/apiToken/adminGetApiTokenList", Description: "获取全部访问令牌(管理员)"},
+		{ApiGroup: "访问令牌", Method: "POST", Path: "/apiToken/adminRevokeApiToken", Description: "吊销访问令牌(管理员)"},
+
+		{ApiGroup: "数据权限", Method: "POST", Path: "/dataScope/setDataScope", Description: "设置行级数据范围"},
+		{ApiGroup: "数据权限", Method: "POST", Path: "/dataScope/deleteDataScope", Description: "删除行级数据范围"},
+		{ApiGroup: "数据权限", Method: "GET", Path: "/dataScope/getDataScopes", Description: "获取角色数据范围规则"},
+
+		{ApiGroup: "部门管理", Method: "POST", Path: "/department/createDepartment", Description: "创建部门"},
+		{ApiGroup: "部门管理", Method: "PUT", Path: "/department/updateDepartment", Description: "更新部门"},
+		{ApiGroup: "部门管理", Method: "DELETE", Path: "/department/deleteDepartment", Description: "删除部门"},
+		{ApiGroup: "部门管理", Method: "POST", Path: "/department/moveDepartment", Description: "移动部门"},
+		{ApiGroup: "部门管理", Method: "POST", Path: "/department/mergeDepartment", Description: "合并部门"},
+		{ApiGroup: "部门管理", Method: "GET", Path: "/department/getDepartmentTree", Description: "获取部门树"},
+		{ApiGroup: "部门管理", Method: "GET", Path: "/department/getDepartmentUsers", Description: "获取部门成员"},
+		{ApiGroup: "部门管理", Method: "GET", Path: "/department/getUserDepartments", Description: "获取用户所属部门"},
+		{ApiGroup: "部门管理", Method: "POST", Path: "/department/setUserDepartments", Description: "设置用户部门"},
+		{ApiGroup: "部门管理", Method: "POST", Path: "/department/setDepartmentLeaders", Description: "设置部门负责人"},
+
+		{ApiGroup: "限时授权", Method: "POST", Path: "/authorityGrant/createAuthorityGrant", Description: "授予限时角色"},
+		{ApiGroup: "限时授权", Method: "POST", Path: "/authorityGrant/delegateAuthority", Description: "委托角色"},
+		{ApiGroup: "限时授权", Method: "POST", Path: "/authorityGrant/revokeAuthorityGrant", Description: "撤销授权"},
+		{ApiGroup: "限时授权", Method: "POST", Path: "/authorityGrant/revokeDelegation", Description: "撤销自己发起的委托"},
+		{ApiGroup: "限时授权", Method: "GET", Path: "/authorityGrant/getAuthorityGrantList", Description: "获取限时授权列表"},
+		{ApiGroup: "限时授权", Method: "GET", Path: "/authorityGrant/getMyAuthorityGrants", Description: "获取我的授权与委托"},
+
+		{ApiGroup: "权限配置迁移", Method: "GET", Path: "/rbac/exportRbac", Description: "导出权限配置包"},
+		{ApiGroup: "权限配置迁移", Method: "POST", Path: "/rbac/previewRbacImport", Description: "预览权限配置包导入差异"},
+		{ApiGroup: "权限配置迁移", Method: "POST", Path: "/rbac/importRbac", Description: "导入权限配置包"},
+
+		{ApiGroup: "租户管理", Method: "POST", Path: "/tenant/createTenant", Description: "创建租户"},
+		{ApiGroup: "租户管理", Method: "PUT", Path: "/tenant/updateTenant", Description: "更新租户"},
+		{ApiGroup: "租户管理", Method: "DELETE", Path: "/tenant/deleteTenant", Description: "删除租户"},
+		{ApiGroup: "租户管理", Method: "GET", Path: "/tenant/findTenant", Description: "获取租户"},
+		{ApiGroup: "租户管理", Method: "GET", Path: "/tenant/getTenantList", Description: "分页获取租户列表"},
+
+		{ApiGroup: "审批流程", Method: "POST", Path: "/approval/createFlow", Description: "创建审批流程"},
+		{ApiGroup: "审批流程", Method: "PUT", Path: "/approval/updateFlow", Description: "更新审批流程"},
+		{ApiGroup: "审批流程", Method: "DELETE", Path: "/approval/deleteFlow", Description: "删除审批流程"},
+		{ApiGroup: "审批流程", Method: "GET", Path: "/approval/findFlow", Description: "获取审批流程"},
+		{ApiGroup: "审批流程", Method: "GET", Path: "/approval/getFlowList", Description: "分页获取审批流程列表"},
+		{ApiGroup: "审批流程", Method: "GET", Path: "/approval/getActions", Description: "获取可配置审批流程的操作"},
+		{ApiGroup: "审批流程", Method: "POST", Path: "/approval/approve", Description: "同意审批"},
+		{ApiGroup: "审批流程", Method: "POST", Path: "/approval/reject", Description: "驳回审批"},
+		{ApiGroup: "审批流程", Method: "POST", Path: "/approval/cancel", Description: "撤回审批单"},
+		{ApiGroup: "审批流程", Method: "GET", Path: "/approval/findRequest", Description: "获取审批单详情"},
+		{ApiGroup: "审批流程", Method: "GET", Path: "/approval/getRequestList", Description: "分页获取审批单列表"},
+		{ApiGroup: "审批流程", Method: "GET", Path: "/approval/getMyRequests", Description: "获取我提交的审批单"},
+		{ApiGroup: "审批流程", Method: "GET", Path: "/approval/getMyTasks", Description: "获取待我审批的审批单"},
+
+		{ApiGroup: "站内信", Method: "GET", Path: "/notification/stream", Description: "订阅实时站内信"},
+		{ApiGroup: "站内信", Method: "GET", Path: "/notification/getMyNotifications", Description: "获取我的站内信"},
+		{ApiGroup: "站内信", Method: "GET", Path: "/notification/getUnread", Description: "获取未读站内信数量"},
+		{ApiGroup: "站内信", Method: "POST", Path: "/notification/markRead", Description: "标记站内信已读"},
+		{ApiGroup: "站内信", Method: "DELETE", Path: "/notification/deleteMyNotifications", Description: "删除我的站内信"},
+		{ApiGroup: "站内信", Method: "POST", Path: "/notification/send", Description: "发送站内信"},
+		{ApiGroup: "站内信", Method: "POST", Path: "/notification/createTemplate", Description: "创建站内信模板"},
+		{ApiGroup: "站内信", Method: "PUT", Path: "/notification/updateTemplate", Description: "更新站内信模板"},
+		{ApiGroup: "站内信", Method: "DELETE", Path: "/notification/deleteTemplate", Description: "删除站内信模板"},
+		{ApiGroup: "站内信", Method: "GET", Path: "/notification/findTemplate", Description: "获取站内信模板"},
+		{ApiGroup: "站内信", Method: "GET", Path: "/notification/getTemplateList", Description: "分页获取站内信模板列表"},
+
+		{ApiGroup: "webhook", Method: "POST", Path: "/webhook/createWebhook", Description: "创建webhook"},
+		{ApiGroup: "webhook", Method: "PUT", Path: "/webhook/updateWebhook", Description: "更新webhook"},
+		{ApiGroup: "webhook", Method: "DELETE", Path: "/webhook/deleteWebhook", Description: "删除webhook"},
+		{ApiGroup: "webhook", Method: "GET", Path: "/webhook/findWebhook", Description: "获取webhook"},
+		{ApiGroup: "webhook", Method: "GET", Path: "/webhook/getWebhookList", Description: "分页获取webhook列表"},
+		{ApiGroup: "webhook", Method: "GET", Path: "/webhook/getWebhookEvents", Description: "获取可订阅的事件"},
+		{ApiGroup: "webhook", Method: "GET", Path: "/webhook/getDeliveryList", Description: "分页获取webhook投递记录"},
+		{ApiGroup: "webhook", Method: "POST", Path: "/webhook/replayDelivery", Description: "重放webhook投递"},
 	}
 	if err := db.Create(&entities).Error; err != nil {
 		return ctx, errors.Wrap(err, sysModel.SysApi{}.TableName()+"表数据初始化失败!")
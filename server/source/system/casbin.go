@@ -100,6 +100,9 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 
 		{Ptype: "p", V0: "888", V1: "/casbin/updateCasbin", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/casbin/getPolicyPathByAuthorityId", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/casbin/simulateCasbin", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/casbin/diffCasbin", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/casbin/migrateRoleInheritance", V2: "POST"},
 
 		{Ptype: "p", V0: "888", V1: "/jwt/jsonInBlacklist", V2: "POST"},
 
@@ -120,15 +123,23 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/autoCode/getColumn", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/rollback", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/createTemp", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/updateTemp", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/getOpenAPISchemas", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/openAPI", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/delSysHistory", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/getSysHistory", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/createPackage", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/getTemplates", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/getPackage", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/delPackage", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/previewPack", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/createPlug", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/installPlugin", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/pubPlug", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/installPluginPackage", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/getInstalledPlugins", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/getPluginStatus", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/uninstallPlugin", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/addFunc", V2: "POST"},
 
 		{Ptype: "p", V0: "888", V1: "/sysDictionaryDetail/findSysDictionaryDetail", V2: "GET"},
@@ -160,6 +171,8 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/authorityBtn/setAuthorityBtn", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/authorityBtn/getAuthorityBtn", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/authorityBtn/canRemoveAuthorityBtn", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authorityField/setAuthorityField", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authorityField/getAuthorityField", V2: "POST"},
 
 		{Ptype: "p", V0: "888", V1: "/sysExportTemplate/createSysExportTemplate", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/sysExportTemplate/deleteSysExportTemplate", V2: "DELETE"},
@@ -189,6 +202,82 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/attachmentCategory/addCategory", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/attachmentCategory/deleteCategory", V2: "POST"},
 
+		{Ptype: "p", V0: "888", V1: "/ldap/syncPreview", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/ldap/sync", V2: "POST"},
+
+		{Ptype: "p", V0: "888", V1: "/apiToken/createApiToken", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/apiToken/getApiTokenList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/apiToken/revokeApiToken", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/apiToken/adminCreateApiToken", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/apiToken/adminGetApiTokenList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/apiToken/adminRevokeApiToken", V2: "POST"},
+
+		{Ptype: "p", V0: "888", V1: "/dataScope/setDataScope", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dataScope/deleteDataScope", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dataScope/getDataScopes", V2: "GET"},
+
+		{Ptype: "p", V0: "888", V1: "/department/createDepartment", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/department/updateDepartment", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/department/deleteDepartment", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/department/moveDepartment", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/department/mergeDepartment", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/department/getDepartmentTree", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/department/getDepartmentUsers", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/department/getUserDepartments", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/department/setUserDepartments", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/department/setDepartmentLeaders", V2: "POST"},
+
+		{Ptype: "p", V0: "888", V1: "/authorityGrant/createAuthorityGrant", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authorityGrant/delegateAuthority", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authorityGrant/revokeAuthorityGrant", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authorityGrant/revokeDelegation", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authorityGrant/getAuthorityGrantList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/authorityGrant/getMyAuthorityGrants", V2: "GET"},
+
+		{Ptype: "p", V0: "888", V1: "/rbac/exportRbac", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/rbac/previewRbacImport", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/rbac/importRbac", V2: "POST"},
+
+		{Ptype: "p", V0: "888", V1: "/tenant/createTenant", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/tenant/updateTenant", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/tenant/deleteTenant", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/tenant/findTenant", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/tenant/getTenantList", V2: "GET"},
+
+		{Ptype: "p", V0: "888", V1: "/approval/createFlow", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/approval/updateFlow", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/approval/deleteFlow", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/approval/findFlow", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/approval/getFlowList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/approval/getActions", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/approval/approve", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/approval/reject", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/approval/cancel", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/approval/findRequest", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/approval/getRequestList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/approval/getMyRequests", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/approval/getMyTasks", V2: "GET"},
+
+		{Ptype: "p", V0: "888", V1: "/notification/stream", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/notification/getMyNotifications", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/notification/getUnread", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/notification/markRead", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/notification/deleteMyNotifications", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/notification/send", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/notification/createTemplate", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/notification/updateTemplate", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/notification/deleteTemplate", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/notification/findTemplate", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/notification/getTemplateList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/webhook/createWebhook", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/webhook/updateWebhook", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/webhook/deleteWebhook", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/webhook/findWebhook", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/webhook/getWebhookList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/webhook/getWebhookEvents", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/webhook/getDeliveryList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/webhook/replayDelivery", V2: "POST"},
+
 		{Ptype: "p", V0: "8881", V1: "/user/admin_register", V2: "POST"},
 		{Ptype: "p", V0: "8881", V1: "/api/createApi", V2: "POST"},
 		{Ptype: "p", V0: "8881", V1: "/api/getApiList", V2: "POST"},
@@ -0,0 +1,289 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// structField 结构体字段在源码中的位置
+// text 包含字段上方的文档注释和行尾注释, def 只包含字段名、类型和标签
+type structField struct {
+	key              string
+	text, def        string
+	start, end       int
+	defStart, defEnd int
+}
+
+type structInfo struct {
+	fields []structField
+	byKey  map[string]structField
+	body   int // 结构体第一个字段可插入的位置
+}
+
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// MergeStructFields 以三方合并的方式把生成代码中结构体字段的变化应用到已有文件
+// base 为按旧配置生成的代码, target 为按新配置生成的代码, current 为磁盘上可能被手动修改过的代码
+// 只处理 target 中定义的结构体字段和 import, 手写的字段、方法和注释保持不变;
+// 字段在 current 中被手动修改过且与 target 不一致时保留手写内容并记为冲突
+func MergeStructFields(current, base, target []byte) (merged []byte, conflicts []string, err error) {
+	baseFile, baseStructs, err := parseStructs(base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析旧生成代码失败: %w", err)
+	}
+	targetFile, targetStructs, err := parseStructs(target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析新生成代码失败: %w", err)
+	}
+	_, currentStructs, err := parseStructs(current)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析现有代码失败: %w", err)
+	}
+
+	var edits []textEdit
+	names := make([]string, 0, len(targetStructs))
+	for name := range targetStructs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := targetStructs[name]
+		c, ok := currentStructs[name]
+		if !ok {
+			conflicts = append(conflicts, fmt.Sprintf("结构体 %s 不存在, 未更新", name))
+			continue
+		}
+		b := baseStructs[name]
+		if b == nil {
+			b = &structInfo{byKey: map[string]structField{}}
+		}
+		for _, bf := range b.fields {
+			if _, keep := t.byKey[bf.key]; keep {
+				continue
+			}
+			cf, exists := c.byKey[bf.key]
+			if !exists {
+				continue
+			}
+			if sameField(cf.def, bf.def) {
+				edits = append(edits, textEdit{start: cf.start, end: cf.end})
+			} else {
+				conflicts = append(conflicts, fmt.Sprintf("%s.%s 已手动修改, 未删除", name, bf.key))
+			}
+		}
+		anchor := c.body
+		for _, tf := range t.fields {
+			cf, exists := c.byKey[tf.key]
+			bf, inBase := b.byKey[tf.key]
+			if exists {
+				switch {
+				case sameField(cf.def, tf.def):
+				case inBase && sameField(cf.def, bf.def):
+					// 只替换定义 保留手写的注释
+					edits = append(edits, textEdit{start: cf.defStart, end: cf.defEnd, text: tf.def})
+				default:
+					conflicts = append(conflicts, fmt.Sprintf("%s.%s 已手动修改, 保留现有定义", name, tf.key))
+				}
+				anchor = cf.end
+				continue
+			}
+			if inBase && sameField(bf.def, tf.def) {
+				// 字段已被手动删除 且本次未修改该字段
+				continue
+			}
+			edits = append(edits, textEdit{start: anchor, end: anchor, text: tf.text})
+		}
+	}
+	merged = applyEdits(current, edits)
+
+	merged, err = mergeImports(merged, importsOf(baseFile), importsOf(targetFile))
+	if err != nil {
+		return nil, conflicts, err
+	}
+	merged, err = format.Source(merged)
+	return merged, conflicts, err
+}
+
+func parseStructs(src []byte) (*ast.File, map[string]*structInfo, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	structs := make(map[string]*structInfo)
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok || st.Fields == nil {
+			return false
+		}
+		info := &structInfo{byKey: make(map[string]structField), body: lineEnd(src, fset.Position(st.Fields.Opening).Offset)}
+		for _, field := range st.Fields.List {
+			begin := field.Pos()
+			if field.Doc != nil {
+				begin = field.Doc.Pos()
+			}
+			finish := field.End()
+			if field.Comment != nil {
+				finish = field.Comment.End()
+			}
+			f := structField{
+				start:    lineStart(src, fset.Position(begin).Offset),
+				end:      lineEnd(src, fset.Position(finish).Offset),
+				defStart: fset.Position(field.Pos()).Offset,
+				defEnd:   fset.Position(field.End()).Offset,
+			}
+			if f.start < info.body {
+				// 字段与花括号在同一行 无法按行合并
+				return false
+			}
+			f.text, f.def = string(src[f.start:f.end]), string(src[f.defStart:f.defEnd])
+			if len(field.Names) > 0 {
+				f.key = field.Names[0].Name
+			} else {
+				f.key = string(src[fset.Position(field.Type.Pos()).Offset:fset.Position(field.Type.End()).Offset])
+			}
+			info.fields = append(info.fields, f)
+			info.byKey[f.key] = f
+		}
+		structs[spec.Name.Name] = info
+		return false
+	})
+	return file, structs, nil
+}
+
+// sameField 忽略对齐空白比较字段定义
+func sameField(a, b string) bool {
+	return strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ")
+}
+
+func lineStart(src []byte, offset int) int {
+	for offset > 0 && src[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// lineEnd 返回offset所在行换行符之后的位置
+func lineEnd(src []byte, offset int) int {
+	for offset < len(src) && src[offset] != '\n' {
+		offset++
+	}
+	if offset < len(src) {
+		offset++
+	}
+	return offset
+}
+
+// applyEdits 从后向前应用修改 同一位置先删除再插入
+func applyEdits(src []byte, edits []textEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+	// 同一位置的多个插入按原顺序写入
+	out := append([]byte(nil), src...)
+	for i := 0; i < len(edits); {
+		j := i
+		var text strings.Builder
+		for j < len(edits) && edits[j].start == edits[i].start && edits[j].end == edits[i].end {
+			text.WriteString(edits[j].text)
+			j++
+		}
+		e := edits[i]
+		out = append(out[:e.start], append([]byte(text.String()), out[e.end:]...)...)
+		i = j
+	}
+	return out
+}
+
+func importsOf(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		imports[p] = importName(spec, p)
+	}
+	return imports
+}
+
+func importName(spec *ast.ImportSpec, p string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	return path.Base(p)
+}
+
+// mergeImports 补充新生成代码需要的 import, 移除生成代码引入但已不再使用的 import
+func mergeImports(src []byte, base, target map[string]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+	existing := importsOf(file)
+	var edits []textEdit
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		_, generated := base[p]
+		if _, ok := target[p]; ok {
+			generated = true
+		}
+		if generated && !used[importName(spec, p)] {
+			start := lineStart(src, fset.Position(spec.Pos()).Offset)
+			edits = append(edits, textEdit{start: start, end: lineEnd(src, fset.Position(spec.End()).Offset)})
+		}
+	}
+	var add []string
+	for p, name := range target {
+		if _, ok := existing[p]; ok || !used[name] {
+			continue
+		}
+		if name == path.Base(p) {
+			add = append(add, strconv.Quote(p))
+		} else {
+			add = append(add, name+" "+strconv.Quote(p))
+		}
+	}
+	if len(add) > 0 {
+		sort.Strings(add)
+		var decl *ast.GenDecl
+		for _, d := range file.Decls {
+			if g, ok := d.(*ast.GenDecl); ok && g.Tok == token.IMPORT && g.Lparen.IsValid() {
+				decl = g
+				break
+			}
+		}
+		if decl != nil {
+			at := lineEnd(src, fset.Position(decl.Lparen).Offset)
+			edits = append(edits, textEdit{start: at, end: at, text: "\t" + strings.Join(add, "\n\t") + "\n"})
+		} else {
+			at := lineEnd(src, fset.Position(file.Name.End()).Offset)
+			edits = append(edits, textEdit{start: at, end: at, text: "\nimport (\n\t" + strings.Join(add, "\n\t") + "\n)\n"})
+		}
+	}
+	return applyEdits(src, edits), nil
+}
@@ -0,0 +1,91 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeStructFields(t *testing.T) {
+	base := `package example
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"time"
+)
+
+type Book struct {
+	global.GVA_MODEL
+	Name  *string ` + "`json:\"name\" gorm:\"column:name;\"`" + `
+	Price *int ` + "`json:\"price\" gorm:\"column:price;\"`" + `
+	Published *time.Time ` + "`json:\"published\" gorm:\"column:published;\"`" + `
+	Remark *string ` + "`json:\"remark\" gorm:\"column:remark;\"`" + `
+}
+`
+	target := `package example
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"gorm.io/datatypes"
+)
+
+type Book struct {
+	global.GVA_MODEL
+	Name  *string ` + "`json:\"name\" gorm:\"column:name;size:64;\"`" + `
+	Price *int ` + "`json:\"price\" gorm:\"column:price;\"`" + `
+	Tags  datatypes.JSON ` + "`json:\"tags\" gorm:\"column:tags;\"`" + `
+	Remark *string ` + "`json:\"remark\" gorm:\"column:remark;comment:备注;\"`" + `
+}
+`
+	current := `package example
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"time"
+)
+
+// Book 手写注释
+type Book struct {
+	global.GVA_MODEL
+	// 书名
+	Name  *string ` + "`json:\"name\" gorm:\"column:name;\"`" + `
+	Price *int ` + "`json:\"price\" gorm:\"column:price;\"`" + `
+	Published *time.Time ` + "`json:\"published\" gorm:\"column:published;\"`" + `
+	Remark *string ` + "`json:\"remark\" gorm:\"column:remark;size:255;\"`" + `
+	Extra  string // 手写字段
+}
+
+func (Book) TableName() string {
+	return "books"
+}
+`
+	merged, conflicts, err := MergeStructFields([]byte(current), []byte(base), []byte(target))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(merged)
+	for _, want := range []string{
+		"// Book 手写注释",
+		"// 书名",
+		"gorm:\"column:name;size:64;\"",
+		"Tags   datatypes.JSON",
+		"\"gorm.io/datatypes\"",
+		"gorm:\"column:remark;size:255;\"",
+		"Extra  string         // 手写字段",
+		"func (Book) TableName() string",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("merged code missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"Published", "\"time\""} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("merged code should not contain %q:\n%s", unwanted, got)
+		}
+	}
+	if strings.Index(got, "Price") > strings.Index(got, "Tags") {
+		t.Errorf("new field should follow Price:\n%s", got)
+	}
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0], "Book.Remark") {
+		t.Errorf("unexpected conflicts: %v", conflicts)
+	}
+}
@@ -10,14 +10,15 @@ import (
 // PackageInitializeGorm 包初始化gorm
 type PackageInitializeGorm struct {
 	Base
-	Type         Type   // 类型
-	Path         string // 文件路径
-	ImportPath   string // 导包路径
-	Business     string // 业务库 gva => gva, 不要传"gva"
-	StructName   string // 结构体名称
-	PackageName  string // 包名
-	RelativePath string // 相对路径
-	IsNew        bool   // 是否使用new关键字 true: new(PackageName.StructName) false: &PackageName.StructName{}
+	Type         Type     // 类型
+	Path         string   // 文件路径
+	ImportPath   string   // 导包路径
+	Business     string   // 业务库 gva => gva, 不要传"gva"
+	StructName   string   // 结构体名称
+	Children     []string // 随结构体一起迁移的子表结构体名称
+	PackageName  string   // 包名
+	RelativePath string   // 相对路径
+	IsNew        bool     // 是否使用new关键字 true: new(PackageName.StructName) false: &PackageName.StructName{}
 }
 
 func (a *PackageInitializeGorm) Parse(filename string, writer io.Writer) (file *ast.File, err error) {
@@ -68,7 +69,7 @@ func (a *PackageInitializeGorm) Rollback(file *ast.File) error {
 					if x, identok := selector.X.(*ast.Ident); identok {
 						if x.Name == a.PackageName {
 							packageNameNum++
-							if selector.Sel.Name == a.StructName {
+							if a.isStruct(selector.Sel.Name) {
 								callExpr.Args = append(callExpr.Args[:i], callExpr.Args[i+1:]...)
 								i--
 							}
@@ -80,7 +81,7 @@ func (a *PackageInitializeGorm) Rollback(file *ast.File) error {
 		return true
 	})
 
-	if packageNameNum == 1 {
+	if packageNameNum == 1+len(a.Children) {
 		_ = NewImport(a.ImportPath).Rollback(file)
 	}
 	return nil
@@ -119,17 +120,32 @@ func (a *PackageInitializeGorm) Injection(file *ast.File) error {
 		}
 
 		// 添加结构体参数
-		callExpr.Args = append(callExpr.Args, &ast.CompositeLit{
-			Type: &ast.SelectorExpr{
-				X:   ast.NewIdent(a.PackageName),
-				Sel: ast.NewIdent(a.StructName),
-			},
-		})
+		for _, name := range append([]string{a.StructName}, a.Children...) {
+			callExpr.Args = append(callExpr.Args, &ast.CompositeLit{
+				Type: &ast.SelectorExpr{
+					X:   ast.NewIdent(a.PackageName),
+					Sel: ast.NewIdent(name),
+				},
+			})
+		}
 		return true
 	})
 	return nil
 }
 
+// isStruct 是否为本次注入的结构体或其子表
+func (a *PackageInitializeGorm) isStruct(name string) bool {
+	if name == a.StructName {
+		return true
+	}
+	for _, child := range a.Children {
+		if name == child {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *PackageInitializeGorm) Format(filename string, writer io.Writer, file *ast.File) error {
 	if filename == "" {
 		filename = a.Path
@@ -7,13 +7,14 @@ import (
 
 type PluginInitializeGorm struct {
 	Base
-	Type         Type   // 类型
-	Path         string // 文件路径
-	ImportPath   string // 导包路径
-	RelativePath string // 相对路径
-	StructName   string // 结构体名称
-	PackageName  string // 包名
-	IsNew        bool   // 是否使用new关键字 true: new(PackageName.StructName) false: &PackageName.StructName{}
+	Type         Type     // 类型
+	Path         string   // 文件路径
+	ImportPath   string   // 导包路径
+	RelativePath string   // 相对路径
+	StructName   string   // 结构体名称
+	Children     []string // 随结构体一起迁移的子表结构体名称
+	PackageName  string   // 包名
+	IsNew        bool     // 是否使用new关键字 true: new(PackageName.StructName) false: &PackageName.StructName{}
 }
 
 func (a *PluginInitializeGorm) Parse(filename string, writer io.Writer) (file *ast.File, err error) {
@@ -41,12 +42,12 @@ func (a *PluginInitializeGorm) Rollback(file *ast.File) error {
 		if !seok || selExpr.Sel.Name != "AutoMigrate" {
 			return true
 		}
-		if len(callExpr.Args) <= 1 {
+		if len(callExpr.Args) <= 1+len(a.Children) {
 			needRollBackImport = true
 		}
 		// 删除指定的参数
-		for i, arg := range callExpr.Args {
-			compLit, cok := arg.(*ast.CompositeLit)
+		for i := 0; i < len(callExpr.Args); i++ {
+			compLit, cok := callExpr.Args[i].(*ast.CompositeLit)
 			if !cok {
 				continue
 			}
@@ -57,10 +58,10 @@ func (a *PluginInitializeGorm) Rollback(file *ast.File) error {
 			}
 
 			ident, idok := cselExpr.X.(*ast.Ident)
-			if idok && ident.Name == a.PackageName && cselExpr.Sel.Name == a.StructName {
+			if idok && ident.Name == a.PackageName && a.isStruct(cselExpr.Sel.Name) {
 				// 删除参数
 				callExpr.Args = append(callExpr.Args[:i], callExpr.Args[i+1:]...)
-				break
+				i--
 			}
 		}
 
@@ -92,17 +93,31 @@ func (a *PluginInitializeGorm) Injection(file *ast.File) error {
 		return true
 	})
 
-	arg := &ast.CompositeLit{
-		Type: &ast.SelectorExpr{
-			X:   &ast.Ident{Name: a.PackageName},
-			Sel: &ast.Ident{Name: a.StructName},
-		},
+	for _, name := range append([]string{a.StructName}, a.Children...) {
+		arg := &ast.CompositeLit{
+			Type: &ast.SelectorExpr{
+				X:   &ast.Ident{Name: a.PackageName},
+				Sel: &ast.Ident{Name: name},
+			},
+		}
+		call.Args = append(call.Args, arg)
 	}
-
-	call.Args = append(call.Args, arg)
 	return nil
 }
 
+// isStruct 是否为本次注入的结构体或其子表
+func (a *PluginInitializeGorm) isStruct(name string) bool {
+	if name == a.StructName {
+		return true
+	}
+	for _, child := range a.Children {
+		if name == child {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *PluginInitializeGorm) Format(filename string, writer io.Writer, file *ast.File) error {
 	if filename == "" {
 		filename = a.Path
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"io"
+	"strings"
 )
 
 type PluginInitializeV2 struct {
@@ -31,7 +32,7 @@ func (a *PluginInitializeV2) Parse(filename string, writer io.Writer) (file *ast
 }
 
 func (a *PluginInitializeV2) Injection(file *ast.File) error {
-	if !CheckImport(file, a.ImportPath) {
+	if !CheckImport(file, strings.Trim(a.ImportPath, `"`)) {
 		NewImport(a.ImportPath).Injection(file)
 		funcDecl := FindFunction(file, "bizPluginV2")
 		stmt := CreateStmt(fmt.Sprintf("PluginInitV2(engine, %s.Plugin)", a.PackageName))
@@ -41,7 +42,41 @@ func (a *PluginInitializeV2) Injection(file *ast.File) error {
 }
 
 func (a *PluginInitializeV2) Rollback(file *ast.File) error {
-	return nil
+	if !CheckImport(file, strings.Trim(a.ImportPath, `"`)) {
+		return nil
+	}
+	funcDecl := FindFunction(file, "bizPluginV2")
+	if funcDecl != nil {
+		// 删除 PluginInitV2(engine, PackageName.Plugin) 语句
+		for i := 0; i < len(funcDecl.Body.List); i++ {
+			exprStmt, ok := funcDecl.Body.List[i].(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+			callExpr, ok := exprStmt.X.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if ident, ok := callExpr.Fun.(*ast.Ident); !ok || ident.Name != "PluginInitV2" {
+				continue
+			}
+			for j := 1; j < len(callExpr.Args); j++ {
+				selExpr, ok := callExpr.Args[j].(*ast.SelectorExpr)
+				if !ok {
+					continue
+				}
+				if x, ok := selExpr.X.(*ast.Ident); ok && x.Name == a.PackageName && selExpr.Sel.Name == "Plugin" {
+					callExpr.Args = append(callExpr.Args[:j], callExpr.Args[j+1:]...)
+					j--
+				}
+			}
+			if len(callExpr.Args) <= 1 {
+				funcDecl.Body.List = append(funcDecl.Body.List[:i], funcDecl.Body.List[i+1:]...)
+				i--
+			}
+		}
+	}
+	return NewImport(a.ImportPath).Rollback(file)
 }
 
 func (a *PluginInitializeV2) Format(filename string, writer io.Writer, file *ast.File) error {
@@ -0,0 +1,149 @@
+package ast
+
+import (
+	"go/ast"
+	"go/parser"
+	"io"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// StructInjection 自定义模板包声明的注入点, 向同一文件的结构体注入字段
+type StructInjection struct {
+	Base
+	Type         Type          // 类型
+	Path         string        // 文件路径
+	RelativePath string        // 相对路径
+	Imports      []string      // 导包路径
+	Fields       []StructField // 注入的字段
+}
+
+// StructField 注入到结构体的字段 Name 为空时为匿名字段
+type StructField struct {
+	Struct string // 结构体名称
+	Name   string // 字段名称
+	Type   string // 字段类型
+}
+
+func (a *StructInjection) Parse(filename string, writer io.Writer) (file *ast.File, err error) {
+	if filename == "" {
+		if a.RelativePath == "" {
+			filename = a.Path
+			a.RelativePath = a.Base.RelativePath(a.Path)
+			return a.Base.Parse(filename, writer)
+		}
+		a.Path = a.Base.AbsolutePath(a.RelativePath)
+		filename = a.Path
+	}
+	return a.Base.Parse(filename, writer)
+}
+
+func (a *StructInjection) Rollback(file *ast.File) error {
+	for _, field := range a.Fields {
+		structType := findStruct(file, field.Struct)
+		if structType == nil {
+			continue
+		}
+		for i, f := range structType.Fields.List {
+			if matchField(f, field) {
+				structType.Fields.List = append(structType.Fields.List[:i], structType.Fields.List[i+1:]...)
+				break
+			}
+		}
+	}
+	// 只移除不再使用的导包
+	used := selectorNames(file)
+	for _, importPath := range a.Imports {
+		p, _ := strconv.Unquote(importPath)
+		if !used[path.Base(p)] {
+			_ = NewImport(importPath).Rollback(file)
+		}
+	}
+	return nil
+}
+
+func (a *StructInjection) Injection(file *ast.File) error {
+	for _, field := range a.Fields {
+		structType := findStruct(file, field.Struct)
+		if structType == nil {
+			return errors.Errorf("[filepath:%s]结构体%s不存在!", a.Path, field.Struct)
+		}
+		exists := false
+		for _, f := range structType.Fields.List {
+			if matchField(f, field) {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+		expr, err := parser.ParseExpr(field.Type)
+		if err != nil {
+			return errors.Wrapf(err, "[type:%s]解析字段类型失败!", field.Type)
+		}
+		injected := &ast.Field{Type: expr}
+		if field.Name != "" {
+			injected.Names = []*ast.Ident{{Name: field.Name}}
+		}
+		structType.Fields.List = append(structType.Fields.List, injected)
+	}
+	for _, importPath := range a.Imports {
+		_ = NewImport(importPath).Injection(file)
+	}
+	return nil
+}
+
+func (a *StructInjection) Format(filename string, writer io.Writer, file *ast.File) error {
+	if filename == "" {
+		filename = a.Path
+	}
+	return a.Base.Format(filename, writer, file)
+}
+
+func findStruct(file *ast.File, name string) (structType *ast.StructType) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return structType == nil
+		}
+		structType, _ = spec.Type.(*ast.StructType)
+		return false
+	})
+	return structType
+}
+
+// matchField 按字段名匹配, 匿名字段按类型匹配
+func matchField(f *ast.Field, field StructField) bool {
+	if field.Name != "" {
+		return len(f.Names) == 1 && f.Names[0].Name == field.Name
+	}
+	return len(f.Names) == 0 && exprString(f.Type) == field.Type
+}
+
+func exprString(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return exprString(x.X) + "." + x.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(x.X)
+	}
+	return ""
+}
+
+func selectorNames(file *ast.File) map[string]bool {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
@@ -0,0 +1,435 @@
+package autocode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"gopkg.in/yaml.v3"
+)
+
+const openAPISchemaRef = "#/components/schemas/"
+
+// OpenAPI OpenAPI 3 文档中代码生成需要的部分
+type OpenAPI struct {
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Components struct {
+		Schemas OpenAPISchemas `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// OpenAPISchema OpenAPI Schema 对象, x-gva-* 为生成器扩展字段
+type OpenAPISchema struct {
+	Ref              string           `yaml:"$ref"`
+	Type             OpenAPIType      `yaml:"type"`
+	Format           string           `yaml:"format"`
+	Title            string           `yaml:"title"`
+	Description      string           `yaml:"description"`
+	Enum             []interface{}    `yaml:"enum"`
+	Items            *OpenAPISchema   `yaml:"items"`
+	Properties       OpenAPISchemas   `yaml:"properties"`
+	Required         []string         `yaml:"required"`
+	AllOf            []*OpenAPISchema `yaml:"allOf"`
+	OneOf            []*OpenAPISchema `yaml:"oneOf"`
+	AnyOf            []*OpenAPISchema `yaml:"anyOf"`
+	MaxLength        *int             `yaml:"maxLength"`
+	Default          interface{}      `yaml:"default"`
+	ReadOnly         bool             `yaml:"readOnly"`
+	GvaType          string           `yaml:"x-gva-type"`          // 指定生成的字段类型
+	GvaLabel         string           `yaml:"x-gva-label"`         // 关联字段展示的列
+	EnumDescriptions []string         `yaml:"x-enum-descriptions"` // 枚举值对应的字典展示值
+	EnumVarNames     []string         `yaml:"x-enum-varnames"`
+}
+
+// NamedOpenAPISchema 带名称的Schema
+type NamedOpenAPISchema struct {
+	Name   string
+	Schema *OpenAPISchema
+}
+
+// OpenAPISchemas 保持文档中定义顺序的Schema集合
+type OpenAPISchemas []NamedOpenAPISchema
+
+func (s *OpenAPISchemas) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("第%d行: 应为对象", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		schema := new(OpenAPISchema)
+		if err := node.Content[i+1].Decode(schema); err != nil {
+			return err
+		}
+		*s = append(*s, NamedOpenAPISchema{Name: node.Content[i].Value, Schema: schema})
+	}
+	return nil
+}
+
+// Get 按名称查找Schema
+func (s OpenAPISchemas) Get(name string) *OpenAPISchema {
+	for i := range s {
+		if s[i].Name == name {
+			return s[i].Schema
+		}
+	}
+	return nil
+}
+
+// OpenAPIType OpenAPI 3.1 中 type 可以是数组
+type OpenAPIType []string
+
+func (t *OpenAPIType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = OpenAPIType{node.Value}
+		return nil
+	}
+	var types []string
+	if err := node.Decode(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// String 返回忽略 null 后的类型
+func (t OpenAPIType) String() string {
+	for _, v := range t {
+		if v != "null" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ParseOpenAPI 解析 OpenAPI 3 文档, 支持 yaml 和 json
+func ParseOpenAPI(data []byte) (*OpenAPI, error) {
+	var doc OpenAPI
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析OpenAPI文档失败: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3") {
+		return nil, fmt.Errorf("仅支持OpenAPI 3文档, 当前版本: %q", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// resolve 解析本地引用并合并 allOf, 返回引用的Schema名称
+func (doc *OpenAPI) resolve(schema *OpenAPISchema) (*OpenAPISchema, string, error) {
+	if schema == nil {
+		return &OpenAPISchema{}, "", nil
+	}
+	seen := make(map[string]bool)
+	name := ""
+	for schema.Ref != "" {
+		if !strings.HasPrefix(schema.Ref, openAPISchemaRef) {
+			return nil, "", fmt.Errorf("不支持的引用 %s, 仅支持 %s*", schema.Ref, openAPISchemaRef)
+		}
+		name = strings.TrimPrefix(schema.Ref, openAPISchemaRef)
+		if seen[name] {
+			return nil, "", fmt.Errorf("循环引用 %s", schema.Ref)
+		}
+		seen[name] = true
+		target := doc.Components.Schemas.Get(name)
+		if target == nil {
+			return nil, "", fmt.Errorf("引用的Schema %s 不存在", name)
+		}
+		schema = target
+	}
+	if len(schema.AllOf) == 0 {
+		return schema, name, nil
+	}
+	// 只有一个引用时视为对该Schema的引用 其余情况合并为一个对象
+	if len(schema.AllOf) == 1 && schema.AllOf[0].Ref != "" {
+		target, refName, err := doc.resolve(schema.AllOf[0])
+		if err != nil {
+			return nil, "", err
+		}
+		merged := *target
+		mergeSchemaMeta(&merged, schema)
+		return &merged, refName, nil
+	}
+	merged := *schema
+	merged.AllOf, merged.Properties, merged.Required = nil, nil, nil
+	for _, part := range append(schema.AllOf, &OpenAPISchema{Properties: schema.Properties, Required: schema.Required}) {
+		resolved, _, err := doc.resolve(part)
+		if err != nil {
+			return nil, "", err
+		}
+		if merged.Type.String() == "" {
+			merged.Type = resolved.Type
+		}
+		for _, p := range resolved.Properties {
+			if merged.Properties.Get(p.Name) == nil {
+				merged.Properties = append(merged.Properties, p)
+			}
+		}
+		merged.Required = append(merged.Required, resolved.Required...)
+	}
+	return &merged, name, nil
+}
+
+// mergeSchemaMeta 引用处的说明信息优先于被引用的Schema
+func mergeSchemaMeta(dst, src *OpenAPISchema) {
+	if src.Title != "" {
+		dst.Title = src.Title
+	}
+	if src.Description != "" {
+		dst.Description = src.Description
+	}
+	if src.GvaLabel != "" {
+		dst.GvaLabel = src.GvaLabel
+	}
+	if src.GvaType != "" {
+		dst.GvaType = src.GvaType
+	}
+	if src.ReadOnly {
+		dst.ReadOnly = true
+	}
+}
+
+// OpenAPIResult Schema转换结果
+type OpenAPIResult struct {
+	AutoCode     systemReq.AutoCode
+	Dictionaries []model.SysDictionary // 枚举对应的字典
+	Warnings     []string              // 无法准确转换的字段
+}
+
+// gvaModelColumns 使用gva默认Model时由 GVA_MODEL 提供的列
+var gvaModelColumns = map[string]bool{"id": true, "created_at": true, "updated_at": true, "deleted_at": true, "tenant_id": true}
+
+// ConvertOpenAPISchema 将 components.schemas 中的Schema转换为代码生成配置
+func (doc *OpenAPI) ConvertOpenAPISchema(name string) (*OpenAPIResult, error) {
+	raw := doc.Components.Schemas.Get(name)
+	if raw == nil {
+		return nil, fmt.Errorf("Schema %s 不存在", name)
+	}
+	schema, _, err := doc.resolve(raw)
+	if err != nil {
+		return nil, err
+	}
+	if t := schema.Type.String(); (t != "" && t != "object") || len(schema.Properties) == 0 {
+		return nil, fmt.Errorf("Schema %s 不是包含属性的对象", name)
+	}
+	structName := openAPIStructName(name)
+	camel := lowerFirst(structName)
+	result := &OpenAPIResult{AutoCode: systemReq.AutoCode{
+		StructName:      structName,
+		TableName:       snakeCase(structName),
+		PackageName:     camel,
+		Abbreviation:    camel,
+		HumpPackageName: snakeCase(camel),
+		Description:     firstNonEmpty(schema.Title, schema.Description, name+"表"),
+		GvaModel:        true,
+		AutoMigrate:     true,
+	}}
+	required := make(map[string]bool, len(schema.Required))
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+	dicts := make(map[string]bool)
+	for _, p := range schema.Properties {
+		if gvaModelColumns[snakeCase(p.Name)] {
+			continue
+		}
+		field, dict, warning := doc.convertProperty(structName, p.Name, p.Schema)
+		if warning != "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s.%s: %s", name, p.Name, warning))
+		}
+		field.Require = required[p.Name]
+		result.AutoCode.Fields = append(result.AutoCode.Fields, field)
+		if dict != nil && !dicts[dict.Type] {
+			dicts[dict.Type] = true
+			result.Dictionaries = append(result.Dictionaries, *dict)
+		}
+	}
+	return result, nil
+}
+
+func (doc *OpenAPI) convertProperty(structName, name string, raw *OpenAPISchema) (field *systemReq.AutoCodeField, dict *model.SysDictionary, warning string) {
+	field = &systemReq.AutoCodeField{
+		FieldName:  openAPIStructName(name),
+		FieldJson:  name,
+		ColumnName: snakeCase(name),
+		Form:       true,
+		Table:      true,
+		Desc:       true,
+		Clearable:  true,
+	}
+	schema, refName, err := doc.resolve(raw)
+	if err != nil {
+		field.FieldType, field.FieldDesc = "json", name
+		return field, nil, err.Error() + ", 已按json处理"
+	}
+	field.FieldDesc = firstNonEmpty(schema.Title, schema.Description, name)
+	field.Comment = schema.Description
+	field.Form = !schema.ReadOnly
+
+	switch {
+	case len(schema.OneOf) > 0 || len(schema.AnyOf) > 0:
+		field.FieldType, warning = "json", "不支持oneOf/anyOf, 已按json处理"
+	case len(schema.Enum) > 0:
+		dict = openAPIDictionary(structName, name, refName, schema)
+		field.FieldType, field.DictType = "string", dict.Type
+		if t := schema.Type.String(); t == "integer" || t == "number" {
+			field.FieldType = "int"
+		}
+	case refName != "" && schema.Type.String() != "array" && len(schema.Properties) > 0:
+		field.FieldType = "int"
+		field.DataSource = openAPIDataSource(refName, schema, 1)
+	case schema.Type.String() == "array":
+		field.FieldType = "array"
+		items, itemName, err := doc.resolve(schema.Items)
+		if err != nil {
+			field.FieldType, warning = "json", err.Error()+", 已按json处理"
+		} else if itemName != "" && len(items.Properties) > 0 {
+			field.DataSource = openAPIDataSource(itemName, items, 2)
+		} else if t := items.Type.String(); t == "object" || t == "array" || t == "" {
+			field.FieldType = "json"
+		}
+	default:
+		field.FieldType = openAPIFieldType(schema)
+		if field.FieldType == "string" && schema.MaxLength != nil {
+			field.DataTypeLong = strconv.Itoa(*schema.MaxLength)
+		}
+	}
+	if schema.GvaType != "" {
+		field.FieldType = schema.GvaType
+	}
+	switch field.FieldType {
+	case "int", "float64", "bool":
+		if schema.Default != nil {
+			field.DefaultValue = fmt.Sprint(schema.Default)
+		}
+	}
+	return field, dict, warning
+}
+
+func openAPIFieldType(schema *OpenAPISchema) string {
+	switch schema.Type.String() {
+	case "string":
+		switch schema.Format {
+		case "date", "date-time":
+			return "time.Time"
+		case "binary":
+			return "file"
+		}
+		return "string"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+	return "json"
+}
+
+// openAPIDictionary 枚举转换为字典 引用的枚举Schema使用Schema名称作为字典类型以便复用
+func openAPIDictionary(structName, name, refName string, schema *OpenAPISchema) *model.SysDictionary {
+	dictType := snakeCase(structName) + "_" + snakeCase(name)
+	if refName != "" {
+		dictType = snakeCase(refName)
+	}
+	status := true
+	dict := &model.SysDictionary{
+		Name:   firstNonEmpty(schema.Title, schema.Description, name),
+		Type:   dictType,
+		Status: &status,
+		Desc:   schema.Description,
+	}
+	for i, v := range schema.Enum {
+		value := fmt.Sprint(v)
+		label := value
+		if i < len(schema.EnumDescriptions) && schema.EnumDescriptions[i] != "" {
+			label = schema.EnumDescriptions[i]
+		} else if i < len(schema.EnumVarNames) && schema.EnumVarNames[i] != "" {
+			label = schema.EnumVarNames[i]
+		}
+		dict.SysDictionaryDetails = append(dict.SysDictionaryDetails, model.SysDictionaryDetail{
+			Label:  label,
+			Value:  value,
+			Status: &status,
+			Sort:   i + 1,
+		})
+	}
+	return dict
+}
+
+// openAPIDataSource 引用其他Schema的字段转换为数据源 关联到该Schema生成的表
+func openAPIDataSource(refName string, schema *OpenAPISchema, association int) *systemReq.DataSource {
+	label := schema.GvaLabel
+	for _, candidate := range []string{"name", "title", "label"} {
+		if label == "" && schema.Properties.Get(candidate) != nil {
+			label = candidate
+		}
+	}
+	for _, p := range schema.Properties {
+		if label == "" && p.Schema.Type.String() == "string" {
+			label = p.Name
+		}
+	}
+	return &systemReq.DataSource{
+		Table:        snakeCase(openAPIStructName(refName)),
+		Label:        snakeCase(firstNonEmpty(label, "id")),
+		Value:        "id",
+		Association:  association,
+		HasDeletedAt: true,
+	}
+}
+
+// openAPIStructName 将 user_profile、user-profile 等名称转换为 UserProfile
+func openAPIStructName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// snakeCase 将驼峰命名转换为下划线命名 连续大写视为一个单词
+func snakeCase(name string) string {
+	runes := []rune(openAPIStructName(name))
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
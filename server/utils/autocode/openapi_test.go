@@ -0,0 +1,130 @@
+package autocode
+
+import (
+	"strings"
+	"testing"
+)
+
+const testOpenAPI = `
+openapi: 3.0.3
+info:
+  title: 图书
+  version: 1.0.0
+components:
+  schemas:
+    Author:
+      type: object
+      properties:
+        id:
+          type: integer
+        fullName:
+          type: string
+    BookStatus:
+      type: integer
+      title: 状态
+      enum: [1, 2]
+      x-enum-descriptions: [上架, 下架]
+    Tag:
+      type: object
+      x-gva-label: tag_name
+      properties:
+        tagName:
+          type: string
+    Book:
+      title: 图书
+      required: [title]
+      allOf:
+        - type: object
+          properties:
+            createdAt:
+              type: string
+              format: date-time
+        - type: object
+          properties:
+            title:
+              type: string
+              description: 书名
+              maxLength: 64
+            price:
+              type: number
+              default: 0
+            publishedAt:
+              type: string
+              format: date
+            status:
+              $ref: '#/components/schemas/BookStatus'
+            format:
+              type: string
+              enum: [paper, ebook]
+            author:
+              allOf:
+                - $ref: '#/components/schemas/Author'
+              title: 作者
+            tags:
+              type: array
+              items:
+                $ref: '#/components/schemas/Tag'
+            keywords:
+              type: array
+              items:
+                type: string
+            extra:
+              oneOf:
+                - type: string
+                - type: integer
+`
+
+func TestConvertOpenAPISchema(t *testing.T) {
+	doc, err := ParseOpenAPI([]byte(testOpenAPI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := doc.ConvertOpenAPISchema("Book")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := result.AutoCode
+	if info.StructName != "Book" || info.TableName != "book" || info.Description != "图书" || !info.GvaModel {
+		t.Errorf("unexpected struct info: %+v", info)
+	}
+	fields := make(map[string]string)
+	var names []string
+	for _, f := range info.Fields {
+		names = append(names, f.FieldName)
+		fields[f.FieldName] = f.FieldType
+	}
+	if got := strings.Join(names, ","); got != "Title,Price,PublishedAt,Status,Format,Author,Tags,Keywords,Extra" {
+		t.Errorf("unexpected fields: %s", got)
+	}
+	want := map[string]string{
+		"Title": "string", "Price": "float64", "PublishedAt": "time.Time", "Status": "int",
+		"Format": "string", "Author": "int", "Tags": "array", "Keywords": "array", "Extra": "json",
+	}
+	for name, typ := range want {
+		if fields[name] != typ {
+			t.Errorf("%s: want type %s, got %s", name, typ, fields[name])
+		}
+	}
+	title, author, tags := info.Fields[0], info.Fields[5], info.Fields[6]
+	if !title.Require || title.DataTypeLong != "64" || title.ColumnName != "title" || title.FieldDesc != "书名" {
+		t.Errorf("unexpected title field: %+v", title)
+	}
+	if info.Fields[1].DefaultValue != "0" || info.Fields[2].ColumnName != "published_at" {
+		t.Errorf("unexpected price/publishedAt fields: %+v %+v", info.Fields[1], info.Fields[2])
+	}
+	if ds := author.DataSource; ds == nil || ds.Table != "author" || ds.Label != "full_name" || ds.Association != 1 || author.FieldDesc != "作者" {
+		t.Errorf("unexpected author data source: %+v", ds)
+	}
+	if ds := tags.DataSource; ds == nil || ds.Table != "tag" || ds.Label != "tag_name" || ds.Association != 2 {
+		t.Errorf("unexpected tags data source: %+v", ds)
+	}
+	if len(result.Dictionaries) != 2 || result.Dictionaries[0].Type != "book_status" || result.Dictionaries[1].Type != "book_format" {
+		t.Fatalf("unexpected dictionaries: %+v", result.Dictionaries)
+	}
+	if d := result.Dictionaries[0].SysDictionaryDetails; len(d) != 2 || d[0].Label != "上架" || d[1].Value != "2" {
+		t.Errorf("unexpected dictionary details: %+v", d)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "Book.extra") {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+}
@@ -11,13 +11,16 @@ import (
 // GetTemplateFuncMap 返回模板函数映射，用于在模板中使用
 func GetTemplateFuncMap() template.FuncMap {
 	return template.FuncMap{
-		"GenerateField":            GenerateField,
-		"GenerateSearchConditions": GenerateSearchConditions,
-		"GenerateSearchFormItem":   GenerateSearchFormItem,
-		"GenerateTableColumn":      GenerateTableColumn,
-		"GenerateFormItem":         GenerateFormItem,
-		"GenerateDescriptionItem":  GenerateDescriptionItem,
-		"GenerateDefaultFormValue": GenerateDefaultFormValue,
+		"GenerateField":                 GenerateField,
+		"GenerateSearchConditions":      GenerateSearchConditions,
+		"GenerateChildSearchConditions": GenerateChildSearchConditions,
+		"GenerateSearchFormItem":        GenerateSearchFormItem,
+		"GenerateTableColumn":           GenerateTableColumn,
+		"GenerateFormItem":              GenerateFormItem,
+		"GenerateDescriptionItem":       GenerateDescriptionItem,
+		"GenerateDefaultFormValue":      GenerateDefaultFormValue,
+		"GenerateTestValue":             GenerateTestValue,
+		"GenerateTestKey":               GenerateTestKey,
 	}
 }
 
@@ -121,13 +124,13 @@ func GenerateSearchConditions(fields []*systemReq.AutoCodeField) string {
 				if field.FieldSearchType == "LIKE" {
 					condition = fmt.Sprintf(`
     if info.%s != "" {
-        db = db.Where("%s LIKE ?", "%%"+ *info.%s+"%%")
+        db = db.Where("%s LIKE ?", "%%"+ info.%s+"%%")
     }`,
 						field.FieldName, field.ColumnName, field.FieldName)
 				} else {
 					condition = fmt.Sprintf(`
     if info.%s != "" {
-        db = db.Where("%s %s ?", *info.%s)
+        db = db.Where("%s %s ?", info.%s)
     }`,
 						field.FieldName, field.ColumnName, field.FieldSearchType, field.FieldName)
 				}
@@ -175,6 +178,29 @@ func GenerateSearchConditions(fields []*systemReq.AutoCodeField) string {
 	return strings.Join(conditions, "")
 }
 
+// GenerateChildSearchConditions 子表搜索条件 使用子查询筛选主表记录
+// model 为模型所在包名 db 为数据库语句
+func GenerateChildSearchConditions(child *systemReq.AutoCodeChild, model, db string) string {
+	prefix := fmt.Sprintf(`%s.Model(&%s.%s{}).Select("%s").Where(`, db, model, child.StructName, child.ForeignColumn)
+	suffix := ")"
+	if child.Association == systemReq.ChildMany2Many {
+		prefix = fmt.Sprintf(`%s.Table("%s").Select("%s").Where("%s IN (?)", %s.Model(&%s.%s{}).Select("id").Where(`,
+			db, child.JoinTable, child.ForeignColumn, child.ReferenceColumn, db, model, child.StructName)
+		suffix = "))"
+	}
+	// 复用主表的搜索条件 把条件语句放入子查询
+	lines := strings.Split(GenerateSearchConditions(child.SearchFields()), "\n")
+	for i, line := range lines {
+		where := strings.Index(line, "db = db.Where(")
+		if where == -1 {
+			continue
+		}
+		condition := strings.TrimSuffix(line[where+len("db = db.Where("):], ")")
+		lines[i] = line[:where] + `db = db.Where("id IN (?)", ` + prefix + condition + suffix + ")"
+	}
+	return strings.Join(lines, "\n")
+}
+
 // 格式化前端搜索条件
 func GenerateSearchFormItem(field systemReq.AutoCodeField) string {
 	// 开始构建表单项
@@ -672,3 +698,42 @@ func GenerateDefaultFormValue(field systemReq.AutoCodeField) string {
 	// 返回格式化后的默认值字符串
 	return fmt.Sprintf(`%s: %s,`, field.FieldJson, defaultValue)
 }
+
+// GenerateTestValue 生成测试用例中字段的示例值 seq 区分同一测试中的多条记录
+func GenerateTestValue(field systemReq.AutoCodeField, seq int) string {
+	switch field.FieldType {
+	case "string":
+		return fmt.Sprintf(`utils.Pointer("%s-%d")`, field.FieldJson, seq)
+	case "richtext":
+		return fmt.Sprintf(`utils.Pointer("<p>%s-%d</p>")`, field.FieldJson, seq)
+	case "int":
+		return fmt.Sprintf(`utils.Pointer(%d)`, seq)
+	case "float64":
+		return fmt.Sprintf(`utils.Pointer(%d.5)`, seq)
+	case "bool":
+		return fmt.Sprintf(`utils.Pointer(%t)`, seq%2 == 1)
+	case "time.Time":
+		return fmt.Sprintf(`utils.Pointer(time.Date(2024, 1, %d, 0, 0, 0, 0, time.Local))`, seq)
+	case "enum":
+		values := strings.Split(field.DataTypeLong, ",")
+		value := strings.Trim(strings.TrimSpace(values[(seq-1)%len(values)]), `'"`)
+		return fmt.Sprintf(`%q`, value)
+	case "picture", "video":
+		return fmt.Sprintf(`"uploads/file/%s-%d"`, field.FieldJson, seq)
+	case "file", "pictures", "array":
+		return "[]byte(`[]`)"
+	case "json":
+		return "[]byte(`{}`)"
+	default:
+		return fmt.Sprintf(`new(%s)`, field.FieldType)
+	}
+}
+
+// GenerateTestKey 生成测试用例中把自定义主键转为接口参数的语句 name 为结构体变量名
+func GenerateTestKey(field systemReq.AutoCodeField, name string) string {
+	value := name + "." + field.FieldName
+	if slices.Contains([]string{"enum", "picture", "video"}, field.FieldType) {
+		return value
+	}
+	return fmt.Sprintf(`fmt.Sprint(*%s)`, value)
+}
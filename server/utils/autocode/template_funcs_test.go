@@ -0,0 +1,157 @@
+package autocode
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
+// testTemplates 生成单元测试和接口测试的模板 以及被测试的service模板
+var testTemplates = []string{
+	"package/server/service/service.go.tpl",
+	"plugin/server/service/service.go.tpl",
+	"package/server/service/service_test.go.tpl",
+	"package/server/router/router_test.go.tpl",
+	"plugin/server/service/service_test.go.tpl",
+	"plugin/server/router/router_test.go.tpl",
+}
+
+func testField(name, fieldType string) *systemReq.AutoCodeField {
+	return &systemReq.AutoCodeField{
+		FieldName:  name,
+		FieldType:  fieldType,
+		FieldJson:  strings.ToLower(name[:1]) + name[1:],
+		ColumnName: strings.ToLower(name),
+	}
+}
+
+// renderTemplate 执行resource下的模板
+func renderTemplate(t *testing.T, name string, info *systemReq.AutoCode) string {
+	t.Helper()
+	path := filepath.Join("..", "..", "resource", name)
+	tmpl, err := template.New(filepath.Base(path)).Funcs(GetTemplateFuncMap()).ParseFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var builder strings.Builder
+	if err = tmpl.Execute(&builder, info); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return builder.String()
+}
+
+func TestGenerateTestTemplates(t *testing.T) {
+	code := testField("Code", "string")
+	code.PrimaryKey = true
+	level := testField("Level", "enum")
+	level.PrimaryKey = true
+	level.DataTypeLong = "'low','high'"
+	status := testField("Status", "enum")
+	status.DataTypeLong = "'on','off'"
+	sku := testField("Sku", "string")
+	sku.FieldSearchType = "="
+	tagName := testField("TagName", "string")
+	tagName.FieldSearchType = "LIKE"
+
+	tests := []struct {
+		name string
+		info systemReq.AutoCode
+		want []string
+	}{
+		{
+			name: "GvaModel",
+			info: systemReq.AutoCode{
+				GvaModel: true, AutoCreateResource: true, BusinessDB: "biz",
+				Fields: []*systemReq.AutoCodeField{
+					testField("Title", "string"), testField("Content", "richtext"), testField("Count", "int"),
+					testField("Price", "float64"), testField("Enable", "bool"), testField("Cover", "picture"),
+					testField("Attachments", "file"), testField("Extra", "json"),
+				},
+			},
+			want: []string{`Title: utils.Pointer("title-1")`, `got.Price = utils.Pointer(3.5)`, `Enable: utils.Pointer(false)`, `Extra: []byte(` + "`{}`" + `)`, "strconv.FormatUint"},
+		},
+		{
+			name: "自定义主键",
+			info: systemReq.AutoCode{Fields: []*systemReq.AutoCodeField{code, testField("Name", "string"), testField("Sort", "int")}},
+			want: []string{`Code: utils.Pointer("code-2")`, "return fmt.Sprint(*item.Code)"},
+		},
+		{
+			name: "枚举",
+			info: systemReq.AutoCode{Fields: []*systemReq.AutoCodeField{level, status}},
+			want: []string{`Level: "high"`, `Status: "on"`, "return item.Level", `strings.HasPrefix(string(field.DataType), "enum")`},
+		},
+		{
+			name: "时间",
+			info: systemReq.AutoCode{GvaModel: true, Fields: []*systemReq.AutoCodeField{testField("StartAt", "time.Time")}},
+			want: []string{`"time"`, "StartAt: utils.Pointer(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))"},
+		},
+		{
+			name: "树形",
+			info: systemReq.AutoCode{GvaModel: true, IsTree: true, TreeJson: "name", Fields: []*systemReq.AutoCodeField{testField("Name", "string")}},
+			want: []string{"list, err := service.GetBookInfoList(ctx)"},
+		},
+		{
+			name: "子表",
+			info: systemReq.AutoCode{
+				GvaModel: true, TableName: "book",
+				Fields: []*systemReq.AutoCodeField{testField("Title", "string")},
+				Children: []*systemReq.AutoCodeChild{
+					{StructName: "BookItem", TableName: "book_item", Description: "明细", FieldName: "Items", Association: systemReq.ChildHasMany,
+						Fields: []*systemReq.AutoCodeField{sku, testField("Quantity", "int"), testField("ShippedAt", "time.Time")}},
+					{StructName: "BookTag", TableName: "book_tag", Description: "标签", FieldName: "Tags", Association: systemReq.ChildMany2Many,
+						Fields: []*systemReq.AutoCodeField{tagName}},
+				},
+			},
+			want: []string{
+				// 主表和子表在同一个事务中创建和更新
+				"err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {",
+				"tx = tx.Session(&gorm.Session{FullSaveAssociations: true})",
+				`tx.Model(&book).Association("Items").Unscoped().Replace(book.Items)`,
+				`tx.Model(&book).Association("Tags").Replace(book.Tags)`,
+				`clause.Table{Name: "book_book_tag"}, clause.Column{Name: "book_id"}`,
+				// 查询时预加载子表 按子表字段搜索
+				`.Preload("Items").Preload("Tags")`,
+				`global.GVA_DB.Model(&example.BookItem{}).Select("book_id").Where("sku = ?"`,
+				`global.GVA_DB.Table("book_book_tag").Select("book_id").Where("book_tag_id IN (?)", global.GVA_DB.Model(&example.BookTag{}).Select("id").Where("tagname LIKE ?"`,
+				// 测试用例创建和更新子表 并校验预加载
+				`Items: []example.BookItem{`,
+				`got.Tags = []model.BookTag{`,
+				"ShippedAt: utils.Pointer(time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local))",
+				`t.Fatalf("更新后图书应有1条明细, 实际为%d", len(updated.Items))`,
+				`&model.Book{}, &model.BookItem{}, &model.BookTag{}, &system.SysOperationRecord{}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			info.Package, info.StructName, info.Abbreviation, info.Description = "example", "Book", "book", "图书"
+			if err := info.Pretreatment(); err != nil {
+				t.Fatal(err)
+			}
+			info.Module = "example.com/server"
+			var rendered []string
+			for _, name := range testTemplates {
+				src := renderTemplate(t, name, &info)
+				if _, err := parser.ParseFile(token.NewFileSet(), name, src, parser.AllErrors); err != nil {
+					t.Fatalf("%s 生成的代码无法解析: %v\n%s", name, err, src)
+				}
+				rendered = append(rendered, src)
+			}
+			all := strings.Join(rendered, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(all, want) {
+					t.Errorf("生成的代码缺少 %s", want)
+				}
+			}
+			if strings.Contains(all, `"time"`) != info.HasTimer {
+				t.Errorf("time包的导入与HasTimer=%v不一致", info.HasTimer)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package autocode
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplatePackManifest 模板包清单文件名, 位于 resource/{模板包}/ 下
+const TemplatePackManifest = "manifest.yaml"
+
+// TemplatePack 自定义模板包
+// 继承内置的 package 或 plugin 模板, 可以替换、排除内置模板, 增加新的生成文件、注入点、模板函数和分层约束
+type TemplatePack struct {
+	Name        string                  `json:"name" yaml:"name"`
+	Description string                  `json:"description" yaml:"description"`
+	Extends     string                  `json:"extends" yaml:"extends"`       // 继承的内置模板 package 或 plugin
+	Exclude     []string                `json:"exclude" yaml:"exclude"`       // 不生成的内置模板, 相对于内置模板目录
+	Files       []TemplatePackFile      `json:"files" yaml:"files"`           // 新增或替换的模板
+	Injections  []TemplatePackInjection `json:"injections" yaml:"injections"` // 生成后向已有文件注入的结构体字段
+	Funcs       map[string]string       `json:"funcs" yaml:"funcs"`           // 模板函数, 值为以参数为 . 渲染的模板
+	Rules       []TemplatePackRule      `json:"rules" yaml:"rules"`           // 生成代码需要遵守的分层约束
+	Dir         string                  `json:"-" yaml:"-"`                   // 模板包目录
+}
+
+// TemplatePackFile 模板包中的模板文件
+type TemplatePackFile struct {
+	Template string `json:"template" yaml:"template"` // 模板文件, 相对于模板包目录
+	Output   string `json:"output" yaml:"output"`     // 生成路径, 相对于AutoCode.Root, 支持模板语法
+	Override string `json:"override" yaml:"override"` // 替换的内置模板, 相对于内置模板目录, 为空时为新增文件
+	Package  bool   `json:"package" yaml:"package"`   // 是否只在创建包时生成
+}
+
+// TemplatePackInjection 向已有文件的结构体注入字段, 各属性均支持模板语法
+type TemplatePackInjection struct {
+	Path      string `json:"path" yaml:"path"`           // 注入的文件, 相对于AutoCode.Root
+	Import    string `json:"import" yaml:"import"`       // 字段类型需要的导包路径
+	Struct    string `json:"struct" yaml:"struct"`       // 注入到的结构体
+	FieldName string `json:"fieldName" yaml:"fieldName"` // 字段名, 为空时为匿名字段
+	FieldType string `json:"fieldType" yaml:"fieldType"` // 字段类型
+}
+
+// TemplatePackRule 分层约束, 匹配路径的go文件不能导入指定的包
+type TemplatePackRule struct {
+	Path        string   `json:"path" yaml:"path"`               // 生成文件路径, 相对于AutoCode.Root, 支持 path.Match 语法, 以 /** 结尾时匹配目录下所有文件
+	DenyImports []string `json:"denyImports" yaml:"denyImports"` // 禁止导入的包前缀, 支持模板语法
+	Message     string   `json:"message" yaml:"message"`         // 违反约束时的提示
+}
+
+// TemplatePackData 渲染模板包路径时的数据
+type TemplatePackData struct {
+	systemReq.AutoCode
+	Server string // 后端目录 相对于AutoCode.Root
+	Web    string // 前端目录 相对于AutoCode.Root
+}
+
+// LoadTemplatePack 读取模板包目录下的清单, 目录下没有清单时返回 nil
+func LoadTemplatePack(dir string) (*TemplatePack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, TemplatePackManifest))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取模板包清单失败: %w", err)
+	}
+	pack := &TemplatePack{Dir: dir}
+	if err = yaml.Unmarshal(data, pack); err != nil {
+		return nil, fmt.Errorf("解析模板包清单[%s]失败: %w", filepath.Join(dir, TemplatePackManifest), err)
+	}
+	if pack.Name == "" {
+		pack.Name = filepath.Base(dir)
+	}
+	switch pack.Extends {
+	case "package", "plugin":
+	default:
+		return nil, fmt.Errorf("模板包[%s]的extends只能为package或plugin", pack.Name)
+	}
+	return pack, nil
+}
+
+// Validate 检查清单引用的文件和语法, 返回全部问题
+// baseDir 为继承的内置模板目录
+func (p *TemplatePack) Validate(baseDir string) (problems []string) {
+	overrides := make(map[string]bool)
+	for i, f := range p.Files {
+		prefix := fmt.Sprintf("files[%d]", i)
+		if err := p.checkTemplate(f.Template); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.template: %v", prefix, err))
+		}
+		if f.Override != "" {
+			if overrides[f.Override] {
+				problems = append(problems, fmt.Sprintf("%s.override: 重复替换内置模板 %s", prefix, f.Override))
+			}
+			overrides[f.Override] = true
+			if _, err := os.Stat(filepath.Join(baseDir, filepath.FromSlash(f.Override))); err != nil {
+				problems = append(problems, fmt.Sprintf("%s.override: 内置模板 %s 不存在", prefix, f.Override))
+			}
+			continue
+		}
+		if f.Output == "" {
+			problems = append(problems, prefix+".output: 新增的模板必须指定生成路径")
+		} else if _, err := template.New("output").Parse(f.Output); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.output: %v", prefix, err))
+		}
+	}
+	for i, pattern := range p.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("exclude[%d]: %v", i, err))
+		}
+	}
+	for i, inj := range p.Injections {
+		prefix := fmt.Sprintf("injections[%d]", i)
+		if inj.Path == "" || inj.Struct == "" || inj.FieldType == "" {
+			problems = append(problems, prefix+": path、struct、fieldType不能为空")
+		}
+		for _, value := range []string{inj.Path, inj.Import, inj.Struct, inj.FieldName, inj.FieldType} {
+			if _, err := template.New("injection").Parse(value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", prefix, err))
+			}
+		}
+	}
+	if _, err := p.FuncMap(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for i, rule := range p.Rules {
+		if _, err := path.Match(strings.TrimSuffix(rule.Path, "/**"), ""); err != nil || rule.Path == "" {
+			problems = append(problems, fmt.Sprintf("rules[%d].path: 路径不合法", i))
+		}
+	}
+	return problems
+}
+
+// checkTemplate 模板文件必须位于模板包目录下且以 .tpl 结尾
+func (p *TemplatePack) checkTemplate(name string) error {
+	if filepath.Ext(name) != ".tpl" {
+		return fmt.Errorf("%s 非法模版后缀", name)
+	}
+	file := p.TemplatePath(name)
+	if rel, err := filepath.Rel(p.Dir, file); err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("%s 不在模板包目录下", name)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("%s 不存在", name)
+	}
+	return nil
+}
+
+// TemplatePath 模板文件的绝对路径
+func (p *TemplatePack) TemplatePath(name string) string {
+	return filepath.Join(p.Dir, filepath.FromSlash(name))
+}
+
+// Excluded 内置模板是否被排除, name 为相对于内置模板目录的路径
+func (p *TemplatePack) Excluded(name string) bool {
+	for _, pattern := range p.Exclude {
+		if matchPath(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Override 替换内置模板的模板包文件
+func (p *TemplatePack) Override(name string) (TemplatePackFile, bool) {
+	for _, f := range p.Files {
+		if f.Override == name {
+			return f, true
+		}
+	}
+	return TemplatePackFile{}, false
+}
+
+// FuncMap 内置模板函数与模板包函数
+func (p *TemplatePack) FuncMap() (template.FuncMap, error) {
+	funcs := GetTemplateFuncMap()
+	if p == nil || len(p.Funcs) == 0 {
+		return funcs, nil
+	}
+	base := GetTemplateFuncMap()
+	names := make([]string, 0, len(p.Funcs))
+	for name := range p.Funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := base[name]; ok {
+			return nil, fmt.Errorf("funcs.%s: 与内置模板函数重名", name)
+		}
+		tpl, err := template.New(name).Funcs(base).Parse(p.Funcs[name])
+		if err != nil {
+			return nil, fmt.Errorf("funcs.%s: %w", name, err)
+		}
+		// 单个参数时 . 为该参数, 多个参数时 . 为参数列表
+		funcs[name] = func(args ...interface{}) (string, error) {
+			var data interface{} = args
+			if len(args) == 1 {
+				data = args[0]
+			}
+			var builder strings.Builder
+			err := tpl.Execute(&builder, data)
+			return builder.String(), err
+		}
+	}
+	return funcs, nil
+}
+
+// Render 渲染清单中的路径等配置
+func (p *TemplatePack) Render(text string, data TemplatePackData) (string, error) {
+	tpl, err := template.New("manifest").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	if err = tpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// CheckRules 检查生成的go文件是否违反分层约束, files 的键为相对于AutoCode.Root的路径
+func (p *TemplatePack) CheckRules(data TemplatePackData, files map[string]string) (violations []string) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, rule := range p.Rules {
+		deny := make([]string, 0, len(rule.DenyImports))
+		for _, d := range rule.DenyImports {
+			rendered, err := p.Render(d, data)
+			if err != nil {
+				violations = append(violations, fmt.Sprintf("rules %s: %v", rule.Path, err))
+				continue
+			}
+			deny = append(deny, rendered)
+		}
+		for _, name := range names {
+			if path.Ext(name) != ".go" || !matchPath(rule.Path, name) {
+				continue
+			}
+			file, err := parser.ParseFile(token.NewFileSet(), name, files[name], parser.ImportsOnly)
+			if err != nil {
+				violations = append(violations, fmt.Sprintf("%s: 解析失败 %v", name, err))
+				continue
+			}
+			for _, spec := range file.Imports {
+				imported, _ := strconv.Unquote(spec.Path.Value)
+				for _, d := range deny {
+					if imported == d || strings.HasPrefix(imported, strings.TrimSuffix(d, "/")+"/") {
+						violation := fmt.Sprintf("%s: 不能导入 %s", name, imported)
+						if rule.Message != "" {
+							violation += " (" + rule.Message + ")"
+						}
+						violations = append(violations, violation)
+					}
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// matchPath 使用 path.Match 匹配, 以 /** 结尾时匹配目录下的所有文件
+func matchPath(pattern, name string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		for d := path.Dir(name); d != "." && d != "/"; d = path.Dir(d) {
+			if matched, _ := path.Match(dir, d); matched {
+				return true
+			}
+		}
+		return false
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
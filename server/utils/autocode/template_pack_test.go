@@ -0,0 +1,55 @@
+package autocode
+
+import (
+	"strings"
+	"testing"
+
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
+func TestTemplatePack(t *testing.T) {
+	pack := &TemplatePack{
+		Exclude: []string{"web/view/form.vue.tpl", "server/router/**"},
+		Funcs:   map[string]string{"repo": `{{ printf "%sRepository" . }}`, "join": `{{ index . 0 }}-{{ index . 1 }}`},
+		Rules: []TemplatePackRule{{
+			Path:        "server/service/**",
+			DenyImports: []string{"gorm.io/gorm", "{{.Module}}/api"},
+			Message:     "service层不能依赖gorm和api",
+		}},
+	}
+	for name, want := range map[string]bool{
+		"web/view/form.vue.tpl":        true,
+		"web/view/table.vue.tpl":       false,
+		"server/router/router.go.tpl":  true,
+		"server/service/enter.go.tpl":  false,
+		"server/router2/router.go.tpl": false,
+	} {
+		if got := pack.Excluded(name); got != want {
+			t.Errorf("Excluded(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	funcs, err := pack.FuncMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := funcs["repo"].(func(...interface{}) (string, error))("Book"); got != "BookRepository" {
+		t.Errorf("repo = %q", got)
+	}
+	if got, _ := funcs["join"].(func(...interface{}) (string, error))("a", "b"); got != "a-b" {
+		t.Errorf("join = %q", got)
+	}
+	if _, err = (&TemplatePack{Funcs: map[string]string{"GenerateField": "x"}}).FuncMap(); err == nil {
+		t.Error("funcs overriding built-in helpers should fail")
+	}
+
+	data := TemplatePackData{AutoCode: systemReq.AutoCode{Module: "example.com/server"}}
+	violations := pack.CheckRules(data, map[string]string{
+		"server/service/example/book.go":    "package example\n\nimport (\n\t\"gorm.io/gorm\"\n\t\"example.com/server/api/v1/example\"\n\t\"example.com/server/apis\"\n)\n",
+		"server/repository/example/book.go": "package example\n\nimport \"gorm.io/gorm\"\n",
+		"server/service/example/readme.txt": "import \"gorm.io/gorm\"",
+	})
+	if len(violations) != 2 || !strings.Contains(violations[0], "gorm.io/gorm") || !strings.Contains(violations[1], "api/v1/example") {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"net"
 	"time"
 
@@ -137,12 +138,36 @@ func GetUserName(c *gin.Context) string {
 func LoginToken(user system.Login) (token string, claims systemReq.CustomClaims, err error) {
 	j := NewJWT()
 	claims = j.CreateClaims(systemReq.BaseClaims{
-		UUID:        user.GetUUID(),
-		ID:          user.GetUserId(),
-		NickName:    user.GetNickname(),
-		Username:    user.GetUsername(),
-		AuthorityId: user.GetAuthorityId(),
+		UUID:            user.GetUUID(),
+		ID:              user.GetUserId(),
+		NickName:        user.GetNickname(),
+		Username:        user.GetUsername(),
+		AuthorityId:     user.GetAuthorityId(),
+		PasswordExpired: user.GetPasswordExpired(),
+		TenantId:        user.GetTenantId(),
 	})
 	token, err = j.CreateToken(claims)
 	return
 }
+
+type claimsContextKey struct{}
+
+// SetClaims 将claims写入gin上下文 同时写入请求上下文 供只接收 context.Context 的 service 层使用
+func SetClaims(c *gin.Context, claims *systemReq.CustomClaims) {
+	c.Set("claims", claims)
+	c.Request = c.Request.WithContext(ContextWithClaims(c.Request.Context(), claims))
+}
+
+// ContextWithClaims 返回携带claims的context
+func ContextWithClaims(ctx context.Context, claims *systemReq.CustomClaims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// GetClaimsFromContext 从context中获取claims 非请求上下文(如定时任务)返回nil
+func GetClaimsFromContext(ctx context.Context) *systemReq.CustomClaims {
+	if ctx == nil {
+		return nil
+	}
+	claims, _ := ctx.Value(claimsContextKey{}).(*systemReq.CustomClaims)
+	return claims
+}
@@ -0,0 +1,235 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Topic 事件主题, T 为事件内容类型, 订阅与发布时由编译器校验类型
+type Topic[T any] string
+
+// Handler 事件处理函数
+type Handler[T any] func(ctx context.Context, payload T) error
+
+// Transport 跨实例转发事件, 多实例部署时使用
+type Transport interface {
+	// Publish 转发本实例发布的事件
+	Publish(ctx context.Context, name string, payload []byte) error
+	// Listen 阻塞接收其他实例发布的事件, ctx 结束时返回
+	Listen(ctx context.Context, fn func(name string, payload []byte)) error
+}
+
+type subscription struct {
+	id     uint64
+	async  bool
+	remote bool
+	handle func(ctx context.Context, payload any) error
+	decode func(data []byte) (any, error)
+}
+
+type SubscribeOption func(*subscription)
+
+// Async 异步执行, 不阻塞发布方, 处理失败只会交给 OnError
+func Async() SubscribeOption {
+	return func(s *subscription) {
+		s.async = true
+	}
+}
+
+// Remote 同时接收其他实例通过 Transport 转发的事件, 默认只接收本实例发布的事件
+func Remote() SubscribeOption {
+	return func(s *subscription) {
+		s.remote = true
+	}
+}
+
+// Bus 进程内事件总线
+type Bus struct {
+	mu        sync.RWMutex
+	seq       uint64
+	subs      map[string][]*subscription
+	transport Transport
+	local     map[string]bool
+	cancel    context.CancelFunc
+	listening sync.WaitGroup
+	running   sync.WaitGroup
+	onError   func(name string, err error)
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[string][]*subscription), local: make(map[string]bool)}
+}
+
+// Local 标记主题只在本实例内分发, 配置了 Transport 时也不转发给其他实例
+// 用于内容较大或包含敏感数据、只需由发布实例处理的事件
+func Local[T any](b *Bus, topic Topic[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.local[string(topic)] = true
+}
+
+// OnError 设置异步订阅及远程事件处理失败时的回调
+func (b *Bus) OnError(fn func(name string, err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onError = fn
+}
+
+// Use 设置跨实例转发并开始接收其他实例的事件
+func (b *Bus) Use(transport Transport) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.mu.Lock()
+	b.transport = transport
+	b.cancel = cancel
+	b.mu.Unlock()
+	b.listening.Add(1)
+	go func() {
+		defer b.listening.Done()
+		err := transport.Listen(ctx, func(name string, payload []byte) {
+			b.receive(ctx, name, payload)
+		})
+		if err != nil && ctx.Err() == nil {
+			b.report("", fmt.Errorf("接收远程事件失败: %w", err))
+		}
+	}()
+}
+
+// Close 停止接收远程事件并等待异步订阅处理完成
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	cancel := b.cancel
+	b.transport, b.cancel = nil, nil
+	b.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		b.listening.Wait()
+	}
+	done := make(chan struct{})
+	go func() {
+		b.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe 订阅事件, 返回取消订阅的函数
+func Subscribe[T any](b *Bus, topic Topic[T], handler Handler[T], opts ...SubscribeOption) (unsubscribe func()) {
+	s := &subscription{
+		handle: func(ctx context.Context, payload any) error {
+			return handler(ctx, payload.(T))
+		},
+		decode: func(data []byte) (any, error) {
+			var payload T
+			err := json.Unmarshal(data, &payload)
+			return payload, err
+		},
+	}
+	for i := range opts {
+		opts[i](s)
+	}
+	name := string(topic)
+	b.mu.Lock()
+	b.seq++
+	s.id = b.seq
+	b.subs[name] = append(b.subs[name], s)
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[name]
+		for i := range subs {
+			if subs[i].id == s.id {
+				b.subs[name] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish 发布事件, 同步订阅按订阅顺序执行并返回其错误, 配置了 Transport 时同时转发给其他实例, Local 主题除外
+func Publish[T any](ctx context.Context, b *Bus, topic Topic[T], payload T) error {
+	name := string(topic)
+	err := b.dispatch(ctx, name, payload, false)
+	b.mu.RLock()
+	transport, local := b.transport, b.local[name]
+	b.mu.RUnlock()
+	if transport == nil || local {
+		return err
+	}
+	data, e := json.Marshal(payload)
+	if e == nil {
+		e = transport.Publish(ctx, name, data)
+	}
+	if e != nil {
+		err = errors.Join(err, fmt.Errorf("转发事件 %s 失败: %w", name, e))
+	}
+	return err
+}
+
+func (b *Bus) receive(ctx context.Context, name string, data []byte) {
+	if err := b.dispatch(ctx, name, data, true); err != nil {
+		b.report(name, err)
+	}
+}
+
+// dispatch 分发事件, remote 为 true 时 payload 为其他实例转发的 json
+func (b *Bus) dispatch(ctx context.Context, name string, payload any, remote bool) error {
+	b.mu.RLock()
+	subs := append([]*subscription(nil), b.subs[name]...)
+	b.mu.RUnlock()
+	var errs []error
+	for _, s := range subs {
+		if remote && !s.remote {
+			continue
+		}
+		value := payload
+		if remote {
+			var err error
+			if value, err = s.decode(payload.([]byte)); err != nil {
+				errs = append(errs, fmt.Errorf("解析事件 %s 失败: %w", name, err))
+				continue
+			}
+		}
+		if s.async {
+			b.running.Add(1)
+			go func(s *subscription, value any) {
+				defer b.running.Done()
+				if err := call(context.WithoutCancel(ctx), s, value); err != nil {
+					b.report(name, err)
+				}
+			}(s, value)
+			continue
+		}
+		if err := call(ctx, s, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// call 执行订阅, 订阅方 panic 时转换为错误避免影响发布方
+func call(ctx context.Context, s *subscription, payload any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return s.handle(ctx, payload)
+}
+
+func (b *Bus) report(name string, err error) {
+	b.mu.RLock()
+	fn := b.onError
+	b.mu.RUnlock()
+	if fn != nil {
+		fn(name, err)
+	}
+}
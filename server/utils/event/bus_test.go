@@ -0,0 +1,200 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userCreated struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+var topicUserCreated = Topic[userCreated]("user.created")
+
+// memoryTransport 模拟多个实例共享的 stream
+type memoryTransport struct {
+	origin string
+	hub    *memoryHub
+}
+
+type memoryHub struct {
+	mu        sync.Mutex
+	listeners map[string]chan [2]string
+}
+
+func (h *memoryHub) join(origin string) *memoryTransport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listeners == nil {
+		h.listeners = make(map[string]chan [2]string)
+	}
+	h.listeners[origin] = make(chan [2]string, 10)
+	return &memoryTransport{origin: origin, hub: h}
+}
+
+func (m *memoryTransport) Publish(_ context.Context, name string, payload []byte) error {
+	m.hub.mu.Lock()
+	defer m.hub.mu.Unlock()
+	for origin, ch := range m.hub.listeners {
+		if origin != m.origin {
+			ch <- [2]string{name, string(payload)}
+		}
+	}
+	return nil
+}
+
+func (m *memoryTransport) Listen(ctx context.Context, fn func(name string, payload []byte)) error {
+	m.hub.mu.Lock()
+	ch := m.hub.listeners[m.origin]
+	m.hub.mu.Unlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			fn(msg[0], []byte(msg[1]))
+		}
+	}
+}
+
+func TestBusSync(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	unsubscribe := Subscribe(bus, topicUserCreated, func(ctx context.Context, payload userCreated) error {
+		got = append(got, "first:"+payload.Name)
+		return nil
+	})
+	Subscribe(bus, topicUserCreated, func(ctx context.Context, payload userCreated) error {
+		got = append(got, "second:"+payload.Name)
+		return errors.New("second failed")
+	})
+	Subscribe(bus, topicUserCreated, func(ctx context.Context, payload userCreated) error {
+		panic("third panic")
+	})
+
+	err := Publish(context.Background(), bus, topicUserCreated, userCreated{ID: 1, Name: "a"})
+	assert.ErrorContains(t, err, "second failed")
+	assert.ErrorContains(t, err, "third panic")
+	assert.Equal(t, []string{"first:a", "second:a"}, got)
+
+	unsubscribe()
+	got = nil
+	_ = Publish(context.Background(), bus, topicUserCreated, userCreated{ID: 2, Name: "b"})
+	assert.Equal(t, []string{"second:b"}, got)
+	assert.Nil(t, Publish(context.Background(), bus, Topic[int]("nobody"), 1))
+}
+
+func TestBusAsync(t *testing.T) {
+	bus := NewBus()
+	var mu sync.Mutex
+	var failed []string
+	bus.OnError(func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, name+":"+err.Error())
+	})
+	release := make(chan struct{})
+	done := make(chan uint, 1)
+	Subscribe(bus, topicUserCreated, func(ctx context.Context, payload userCreated) error {
+		<-release
+		done <- payload.ID
+		return errors.New("async failed")
+	}, Async())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.Nil(t, Publish(ctx, bus, topicUserCreated, userCreated{ID: 3}))
+	// 发布方的 ctx 结束不影响异步订阅
+	cancel()
+
+	timeout, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	assert.ErrorIs(t, bus.Close(timeout), context.DeadlineExceeded)
+
+	close(release)
+	assert.Nil(t, bus.Close(context.Background()))
+	assert.Equal(t, uint(3), <-done)
+	assert.Equal(t, []string{"user.created:async failed"}, failed)
+}
+
+func TestBusRemote(t *testing.T) {
+	hub := &memoryHub{}
+	a, b := NewBus(), NewBus()
+	a.Use(hub.join("a"))
+	b.Use(hub.join("b"))
+	defer a.Close(context.Background())
+	defer b.Close(context.Background())
+
+	local := make(chan userCreated, 1)
+	remote := make(chan userCreated, 2)
+	Subscribe(b, topicUserCreated, func(ctx context.Context, payload userCreated) error {
+		local <- payload
+		return nil
+	})
+	Subscribe(b, topicUserCreated, func(ctx context.Context, payload userCreated) error {
+		remote <- payload
+		return nil
+	}, Remote())
+	// 发布方只收到一次本地事件, 不会再收到 transport 转发回来的同一事件
+	publisher := make(chan userCreated, 2)
+	Subscribe(a, topicUserCreated, func(ctx context.Context, payload userCreated) error {
+		publisher <- payload
+		return nil
+	}, Remote())
+
+	assert.Nil(t, Publish(context.Background(), a, topicUserCreated, userCreated{ID: 4, Name: "remote"}))
+	select {
+	case payload := <-remote:
+		assert.Equal(t, userCreated{ID: 4, Name: "remote"}, payload)
+	case <-time.After(time.Second):
+		t.Fatal("remote event not received")
+	}
+	assert.Len(t, local, 0)
+	assert.Len(t, publisher, 1)
+}
+
+// recordTransport 记录转发的事件
+type recordTransport struct {
+	mu        sync.Mutex
+	published []string
+}
+
+func (r *recordTransport) Publish(_ context.Context, name string, _ []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.published = append(r.published, name)
+	return nil
+}
+
+func (r *recordTransport) Listen(ctx context.Context, _ func(name string, payload []byte)) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestBusLocal(t *testing.T) {
+	bus := NewBus()
+	transport := &recordTransport{}
+	bus.Use(transport)
+	defer bus.Close(context.Background())
+
+	topicRecorded := Topic[userCreated]("operation.recorded")
+	Local(bus, topicRecorded)
+	var got []uint
+	Subscribe(bus, topicRecorded, func(ctx context.Context, payload userCreated) error {
+		got = append(got, payload.ID)
+		return nil
+	}, Remote())
+
+	// 本实例订阅照常收到 但不转发给其他实例
+	assert.Nil(t, Publish(context.Background(), bus, topicRecorded, userCreated{ID: 1}))
+	assert.Nil(t, Publish(context.Background(), bus, topicUserCreated, userCreated{ID: 2}))
+	assert.Equal(t, []uint{1}, got)
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	assert.Equal(t, []string{"user.created"}, transport.published)
+}
@@ -0,0 +1,72 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStream 基于 Redis Streams 的跨实例事件转发
+// 所有实例写入同一个 stream, 各自从启动时的位置读取, 并忽略自己发布的事件
+type RedisStream struct {
+	client redis.UniversalClient
+	stream string
+	maxLen int64
+	origin string
+}
+
+func NewRedisStream(client redis.UniversalClient, stream string, maxLen int64) *RedisStream {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &RedisStream{client: client, stream: stream, maxLen: maxLen, origin: hex.EncodeToString(id)}
+}
+
+func (r *RedisStream) Publish(ctx context.Context, name string, payload []byte) error {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		MaxLen: r.maxLen,
+		Approx: true,
+		Values: map[string]any{"name": name, "origin": r.origin, "payload": payload},
+	}).Err()
+}
+
+func (r *RedisStream) Listen(ctx context.Context, fn func(name string, payload []byte)) error {
+	lastID := "$"
+	for {
+		streams, err := r.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{r.stream, lastID},
+			Count:   100,
+			Block:   5 * time.Second,
+		}).Result()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			// redis 暂时不可用时稍后重试
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				lastID = message.ID
+				if origin, _ := message.Values["origin"].(string); origin == r.origin {
+					continue
+				}
+				name, _ := message.Values["name"].(string)
+				payload, _ := message.Values["payload"].(string)
+				fn(name, []byte(payload))
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 字段权限动作
+const (
+	FieldHide = "hide" // 隐藏字段
+	FieldMask = "mask" // 脱敏显示
+)
+
+// MaskString 脱敏字符串 邮箱保留首字符和域名 其余保留首尾各约三分之一
+func MaskString(s string) string {
+	if at := strings.LastIndex(s, "@"); at > 0 {
+		local := []rune(s[:at])
+		return string(local[0]) + strings.Repeat("*", len(local)-1) + s[at:]
+	}
+	r := []rune(s)
+	keep := len(r) / 3
+	if keep == 0 {
+		return strings.Repeat("*", len(r))
+	}
+	return string(r[:keep]) + strings.Repeat("*", len(r)-2*keep) + string(r[len(r)-keep:])
+}
+
+// MaskValue 脱敏任意值 字符串和 json.Number 等 Stringer 按字符串脱敏 其余显示为 ***
+func MaskValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return MaskString(val)
+	case []byte:
+		return MaskString(string(val))
+	case fmt.Stringer:
+		return MaskString(val.String())
+	}
+	return "***"
+}
+
+// MaskJSON 按字段权限处理JSON 任意层级中名称匹配的字段都会被隐藏或脱敏 非JSON内容原样返回
+// rules 为 字段名 -> FieldHide/FieldMask
+func MaskJSON(body []byte, rules map[string]string) []byte {
+	if len(rules) == 0 || len(body) == 0 {
+		return body
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+	if !maskJSONValue(v, rules) {
+		return body
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func maskJSONValue(v interface{}, rules map[string]string) (changed bool) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			switch rules[k] {
+			case FieldHide:
+				delete(val, k)
+				changed = true
+				continue
+			case FieldMask:
+				val[k] = MaskValue(item)
+				changed = true
+				continue
+			}
+			if maskJSONValue(item, rules) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if maskJSONValue(item, rules) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
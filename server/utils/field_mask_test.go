@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMaskString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: ""},
+		{in: "ab", want: "**"},
+		{in: "13800138000", want: "138*****000"},
+		{in: "admin@example.com", want: "a****@example.com"},
+	}
+	for _, tt := range tests {
+		if got := MaskString(tt.in); got != tt.want {
+			t.Errorf("MaskString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMaskJSON(t *testing.T) {
+	body := []byte(`{"code":0,"data":{"list":[{"ID":1,"userName":"admin","phone":"13800138000","email":"admin@example.com","authority":{"authorityId":888}}],"total":1},"msg":"获取成功"}`)
+	out := MaskJSON(body, map[string]string{"phone": FieldMask, "email": FieldHide, "authorityId": FieldMask})
+
+	var res struct {
+		Data struct {
+			List []map[string]interface{} `json:"list"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out, &res); err != nil {
+		t.Fatal(err)
+	}
+	user := res.Data.List[0]
+	if user["phone"] != "138*****000" {
+		t.Errorf("phone = %v", user["phone"])
+	}
+	if _, ok := user["email"]; ok {
+		t.Errorf("email 应被隐藏")
+	}
+	if user["authority"].(map[string]interface{})["authorityId"] != "8*8" {
+		t.Errorf("authorityId = %v", user["authority"])
+	}
+	if user["ID"].(float64) != 1 {
+		t.Errorf("未配置的字段不应被修改")
+	}
+
+	if got := MaskJSON([]byte("not json"), map[string]string{"phone": FieldHide}); string(got) != "not json" {
+		t.Errorf("非JSON内容应原样返回")
+	}
+}
@@ -2,6 +2,9 @@ package utils
 
 import (
 	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -29,3 +32,20 @@ func MD5V(str []byte, b ...byte) string {
 	h.Write(str)
 	return hex.EncodeToString(h.Sum(b))
 }
+
+//@function: SHA256V
+//@description: sha256摘要 用于令牌等高熵随机串的存储
+//@param: str []byte
+//@return: string
+
+func SHA256V(str []byte) string {
+	h := sha256.Sum256(str)
+	return hex.EncodeToString(h[:])
+}
+
+// SecureRandomString 使用 crypto/rand 生成n字节熵的url安全随机串 用于令牌、OIDC state/nonce/code_verifier 等安全场景
+func SecureRandomString(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
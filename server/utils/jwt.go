@@ -37,6 +37,7 @@ func (j *JWT) CreateClaims(baseClaims request.BaseClaims) request.CustomClaims {
 		RegisteredClaims: jwt.RegisteredClaims{
 			Audience:  jwt.ClaimStrings{"GVA"},                   // 受众
 			NotBefore: jwt.NewNumericDate(time.Now().Add(-1000)), // 签名生效时间
+			IssuedAt:  jwt.NewNumericDate(time.Now()),            // 签发时间 用于判断是否早于会话吊销时间
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ep)),    // 过期时间 7天  配置文件
 			Issuer:    global.GVA_CONFIG.JWT.Issuer,              // 签名的发行者
 		},
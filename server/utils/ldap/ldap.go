@@ -0,0 +1,189 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+var (
+	ErrUserNotFound    = errors.New("目录中不存在该用户")
+	ErrInvalidPassword = errors.New("目录账号密码错误")
+)
+
+// Entry 目录中的用户
+type Entry struct {
+	DN       string   `json:"dn"`
+	Username string   `json:"username"`
+	NickName string   `json:"nickName"`
+	Email    string   `json:"email"`
+	Phone    string   `json:"phone"`
+	Groups   []string `json:"groups"`
+}
+
+type Client struct {
+	config config.Ldap
+}
+
+func NewClient(cfg config.Ldap) *Client {
+	if cfg.UsernameAttr == "" {
+		cfg.UsernameAttr = "uid"
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(&(objectClass=person)(" + cfg.UsernameAttr + "=%s))"
+	}
+	if cfg.GroupNameAttr == "" {
+		cfg.GroupNameAttr = "cn"
+	}
+	if cfg.GroupBaseDN == "" {
+		cfg.GroupBaseDN = cfg.BaseDN
+	}
+	return &Client{config: cfg}
+}
+
+// dial 建立连接并使用服务账号绑定
+func (c *Client) dial() (*goldap.Conn, error) {
+	u, err := url.Parse(c.config.URL)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: c.config.InsecureSkipVerify}
+	conn, err := goldap.DialURL(c.config.URL, goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	if c.config.StartTLS && u.Scheme == "ldap" {
+		if err = conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.config.BindDN != "" {
+		err = conn.Bind(c.config.BindDN, c.config.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ldap服务账号绑定失败: %w", err)
+	}
+	return conn, nil
+}
+
+// Authenticate 查找用户并使用其DN和密码绑定 成功后返回用户信息
+func (c *Client) Authenticate(username, password string) (*Entry, error) {
+	if password == "" {
+		// 空密码会被部分服务器视为匿名绑定而直接成功
+		return nil, ErrInvalidPassword
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	entries, err := c.search(conn, fmt.Sprintf(c.config.UserFilter, goldap.EscapeFilter(username)), 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrUserNotFound
+	}
+	if len(entries) > 1 {
+		return nil, fmt.Errorf("用户名 %s 在目录中不唯一", username)
+	}
+	entry := entries[0]
+	if err = conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidPassword
+		}
+		return nil, err
+	}
+	// 使用服务账号查询用户组 部分目录不允许普通用户读取组信息
+	if c.config.BindDN != "" {
+		if err = conn.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+			return nil, err
+		}
+	}
+	if entry.Groups, err = c.groups(conn, entry.DN); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Users 获取目录中的全部用户及其所属组 用于同步
+func (c *Client) Users() ([]Entry, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	entries, err := c.search(conn, fmt.Sprintf(c.config.UserFilter, "*"), 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Groups, err = c.groups(conn, entries[i].DN); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func (c *Client) search(conn *goldap.Conn, filter string, sizeLimit int) ([]Entry, error) {
+	attrs := []string{c.config.UsernameAttr}
+	for _, a := range []string{c.config.NickNameAttr, c.config.EmailAttr, c.config.PhoneAttr} {
+		if a != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	req := goldap.NewSearchRequest(c.config.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, sizeLimit, 0, false, filter, attrs, nil)
+	res, err := conn.SearchWithPaging(req, 500)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(res.Entries))
+	for _, e := range res.Entries {
+		if entry, ok := c.entry(e); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// entry 按配置的属性映射目录条目 缺少用户名属性的条目忽略
+func (c *Client) entry(e *goldap.Entry) (Entry, bool) {
+	username := e.GetAttributeValue(c.config.UsernameAttr)
+	if username == "" {
+		return Entry{}, false
+	}
+	return Entry{
+		DN:       e.DN,
+		Username: username,
+		NickName: e.GetAttributeValue(c.config.NickNameAttr),
+		Email:    e.GetAttributeValue(c.config.EmailAttr),
+		Phone:    e.GetAttributeValue(c.config.PhoneAttr),
+	}, true
+}
+
+func (c *Client) groups(conn *goldap.Conn, userDN string) ([]string, error) {
+	if c.config.GroupFilter == "" {
+		return nil, nil
+	}
+	req := goldap.NewSearchRequest(c.config.GroupBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(c.config.GroupFilter, goldap.EscapeFilter(userDN)), []string{c.config.GroupNameAttr}, nil)
+	res, err := conn.SearchWithPaging(req, 500)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(res.Entries))
+	for _, e := range res.Entries {
+		if name := e.GetAttributeValue(c.config.GroupNameAttr); name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
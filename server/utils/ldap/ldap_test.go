@@ -0,0 +1,56 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient(config.Ldap{BaseDN: "ou=people,dc=example,dc=com"})
+	assert.Equal(t, "uid", c.config.UsernameAttr)
+	assert.Equal(t, "(&(objectClass=person)(uid=%s))", c.config.UserFilter)
+	assert.Equal(t, "cn", c.config.GroupNameAttr)
+	assert.Equal(t, "ou=people,dc=example,dc=com", c.config.GroupBaseDN)
+
+	c = NewClient(config.Ldap{UsernameAttr: "sAMAccountName", GroupBaseDN: "ou=groups,dc=example,dc=com"})
+	assert.Equal(t, "(&(objectClass=person)(sAMAccountName=%s))", c.config.UserFilter)
+	assert.Equal(t, "ou=groups,dc=example,dc=com", c.config.GroupBaseDN)
+}
+
+func TestEntryAttributeMapping(t *testing.T) {
+	c := NewClient(config.Ldap{
+		UsernameAttr: "sAMAccountName",
+		NickNameAttr: "displayName",
+		EmailAttr:    "mail",
+		PhoneAttr:    "telephoneNumber",
+	})
+	entry, ok := c.entry(goldap.NewEntry("cn=Alice,ou=people,dc=example,dc=com", map[string][]string{
+		"sAMAccountName":  {"alice"},
+		"displayName":     {"Alice Liddell"},
+		"mail":            {"alice@example.com", "alice2@example.com"},
+		"telephoneNumber": {"10086"},
+		"uid":             {"ignored"},
+	}))
+	assert.True(t, ok)
+	assert.Equal(t, Entry{
+		DN:       "cn=Alice,ou=people,dc=example,dc=com",
+		Username: "alice",
+		NickName: "Alice Liddell",
+		Email:    "alice@example.com",
+		Phone:    "10086",
+	}, entry)
+
+	// 未配置的属性留空
+	c = NewClient(config.Ldap{})
+	entry, ok = c.entry(goldap.NewEntry("uid=bob,dc=example,dc=com", map[string][]string{"uid": {"bob"}, "mail": {"bob@example.com"}}))
+	assert.True(t, ok)
+	assert.Equal(t, "bob", entry.Username)
+	assert.Empty(t, entry.Email)
+
+	// 缺少用户名属性的条目忽略
+	_, ok = c.entry(goldap.NewEntry("cn=service,dc=example,dc=com", map[string][]string{"cn": {"service"}}))
+	assert.False(t, ok)
+}
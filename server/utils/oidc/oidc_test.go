@@ -0,0 +1,126 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIdP 本地模拟的身份提供方 只实现授权码+PKCE登录所需的端点
+type mockIdP struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	m := &mockIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Discovery{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JwksURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "test",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("code") != "good-code" || CodeChallenge(r.PostForm.Get("code_verifier")) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   m.URL,
+			"aud":   "gva",
+			"sub":   "user-1",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": m.nonce,
+		}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		signed, _ := token.SignedString(key)
+		_ = json.NewEncoder(w).Encode(Token{AccessToken: "at", TokenType: "Bearer", IDToken: signed})
+	})
+	m.Server = httptest.NewServer(mux)
+	return m
+}
+
+func TestProvider_LoginFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	defer idp.Close()
+	idp.claims = jwt.MapClaims{"preferred_username": "alice", "groups": []string{"admins", "dev"}}
+
+	ctx := context.Background()
+	p, err := NewProvider(ctx, config.Oidc{Issuer: idp.URL, ClientID: "gva", RedirectURL: "http://localhost/callback"}, nil)
+	assert.Nil(t, err)
+
+	state, nonce, verifier := utils.SecureRandomString(16), utils.SecureRandomString(16), utils.SecureRandomString(32)
+	u, err := url.Parse(p.AuthCodeURL(state, nonce, verifier))
+	assert.Nil(t, err)
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	assert.Equal(t, state, u.Query().Get("state"))
+	idp.challenge = u.Query().Get("code_challenge")
+	idp.nonce = u.Query().Get("nonce")
+
+	_, err = p.Exchange(ctx, "good-code", "wrong-verifier")
+	assert.NotNil(t, err)
+
+	token, err := p.Exchange(ctx, "good-code", verifier)
+	assert.Nil(t, err)
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, nonce)
+	assert.Nil(t, err)
+	assert.Equal(t, "user-1", claims.Subject())
+	assert.Equal(t, "alice", claims.String("preferred_username"))
+	assert.Equal(t, []string{"admins", "dev"}, claims.Strings("groups"))
+
+	_, err = p.VerifyIDToken(ctx, token.IDToken, "other-nonce")
+	assert.ErrorIs(t, err, ErrNonce)
+}
+
+func TestProvider_VerifyIDTokenRejectsAudience(t *testing.T) {
+	idp := newMockIdP(t)
+	defer idp.Close()
+
+	ctx := context.Background()
+	p, err := NewProvider(ctx, config.Oidc{Issuer: idp.URL, ClientID: "another-client"}, nil)
+	assert.Nil(t, err)
+
+	verifier := utils.SecureRandomString(32)
+	idp.challenge = CodeChallenge(verifier)
+	token, err := p.Exchange(ctx, "good-code", verifier)
+	assert.Nil(t, err)
+	_, err = p.VerifyIDToken(ctx, token.IDToken, "")
+	assert.NotNil(t, err)
+}
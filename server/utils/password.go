@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+)
+
+var ErrPasswordInDictionary = errors.New("密码过于常见或已出现在泄露密码库中,请更换")
+
+// CheckPasswordPolicy 按密码策略校验明文密码 返回第一条不满足的规则
+func CheckPasswordPolicy(policy config.PasswordPolicy, username, password string) error {
+	if password == "" {
+		return errors.New("密码不能为空")
+	}
+	if n := len([]rune(password)); policy.MinLength > 0 && n < policy.MinLength {
+		return fmt.Errorf("密码长度不能少于%d位", policy.MinLength)
+	}
+	var upper, lower, digit, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			special = true
+		}
+	}
+	if policy.RequireUpper && !upper {
+		return errors.New("密码必须包含大写字母")
+	}
+	if policy.RequireLower && !lower {
+		return errors.New("密码必须包含小写字母")
+	}
+	if policy.RequireDigit && !digit {
+		return errors.New("密码必须包含数字")
+	}
+	if policy.RequireSpecial && !special {
+		return errors.New("密码必须包含特殊字符")
+	}
+	if policy.DisallowUsername && len(username) >= 3 && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return errors.New("密码不能包含用户名")
+	}
+	if policy.DictionaryFile != "" {
+		words, err := loadPasswordDictionary(policy.DictionaryFile)
+		if err != nil {
+			return fmt.Errorf("加载密码字典失败: %w", err)
+		}
+		if _, ok := words[strings.ToLower(password)]; ok {
+			return ErrPasswordInDictionary
+		}
+	}
+	return nil
+}
+
+type passwordDictionary struct {
+	modTime time.Time
+	words   map[string]struct{}
+}
+
+var (
+	passwordDictLock sync.RWMutex
+	passwordDicts    = make(map[string]passwordDictionary)
+)
+
+// loadPasswordDictionary 读取字典文件并按修改时间缓存 文件更新后自动重新加载
+func loadPasswordDictionary(path string) (map[string]struct{}, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	passwordDictLock.RLock()
+	dict, ok := passwordDicts[path]
+	passwordDictLock.RUnlock()
+	if ok && dict.modTime.Equal(info.ModTime()) {
+		return dict.words, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	words := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words[strings.ToLower(word)] = struct{}{}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	passwordDictLock.Lock()
+	passwordDicts[path] = passwordDictionary{modTime: info.ModTime(), words: words}
+	passwordDictLock.Unlock()
+	return words, nil
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	dict := filepath.Join(t.TempDir(), "dictionary.txt")
+	if err := os.WriteFile(dict, []byte("# 常见弱口令\nPassword123\nqwerty123\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy := config.PasswordPolicy{
+		MinLength:        8,
+		RequireUpper:     true,
+		RequireLower:     true,
+		RequireDigit:     true,
+		RequireSpecial:   true,
+		DisallowUsername: true,
+		DictionaryFile:   dict,
+	}
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "空密码", password: "", wantErr: true},
+		{name: "长度不足", password: "Ab1!", wantErr: true},
+		{name: "缺少大写", password: "abcdef1!", wantErr: true},
+		{name: "缺少小写", password: "ABCDEF1!", wantErr: true},
+		{name: "缺少数字", password: "Abcdefg!", wantErr: true},
+		{name: "缺少特殊字符", password: "Abcdefg1", wantErr: true},
+		{name: "包含用户名", password: "Admin@2024", wantErr: true},
+		{name: "符合策略", password: "Gva#2024safe", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPasswordPolicy(policy, "admin", tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPasswordPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	dictOnly := config.PasswordPolicy{DictionaryFile: dict}
+	if err := CheckPasswordPolicy(dictOnly, "", "PASSWORD123"); err != ErrPasswordInDictionary {
+		t.Errorf("CheckPasswordPolicy() error = %v, want %v", err, ErrPasswordInDictionary)
+	}
+}
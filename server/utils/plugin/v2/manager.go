@@ -0,0 +1,244 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type State string
+
+const (
+	StatePending State = "pending" // 已添加, 未启动
+	StateRunning State = "running" // 启动完成
+	StateStopped State = "stopped" // 已停止
+	StateFailed  State = "failed"  // 启动失败
+)
+
+// Status 插件运行状态
+type Status struct {
+	Name         string     `json:"name"`
+	Dependencies []string   `json:"dependencies"`
+	Order        int        `json:"order"` // 启动顺序, 从1开始, 0表示未能排序
+	State        State      `json:"state"`
+	Stage        string     `json:"stage"` // 最后执行的阶段
+	Healthy      bool       `json:"healthy"`
+	Error        string     `json:"error"`
+	StartedAt    *time.Time `json:"startedAt"`
+}
+
+type entry struct {
+	plugin Plugin
+	status Status
+}
+
+// Manager v2插件管理 按依赖顺序启动插件, 按逆序停止并记录各插件状态
+type Manager struct {
+	mu      sync.RWMutex
+	entries []*entry
+	started []*entry
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Add 添加插件, 在 Setup 时统一排序启动
+func (m *Manager) Add(plugins ...Plugin) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range plugins {
+		e := &entry{plugin: plugins[i], status: Status{Name: nameOf(plugins[i]), State: StatePending}}
+		if d, ok := plugins[i].(Dependent); ok {
+			e.status.Dependencies = d.Dependencies()
+		}
+		m.entries = append(m.entries, e)
+	}
+}
+
+// Setup 按依赖拓扑顺序执行 Init -> Migrate -> Register -> Start
+// 某个插件失败不影响其他插件, 但依赖它的插件不会启动, 返回所有失败原因
+func (m *Manager) Setup(ctx context.Context, engine *gin.Engine) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	order := m.sort()
+	for i, e := range order {
+		e.status.Order = i + 1
+	}
+	for _, e := range m.entries {
+		if e.status.State == StatePending && e.status.Order == 0 {
+			err := m.fail(e, "sort", errors.New("存在循环依赖或插件名称重复"))
+			errs = append(errs, fmt.Errorf("插件 %s: %w", e.status.Name, err))
+		}
+	}
+	for _, e := range order {
+		if e.status.State != StatePending {
+			continue
+		}
+		if err := m.start(ctx, engine, e); err != nil {
+			errs = append(errs, fmt.Errorf("插件 %s: %w", e.status.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) start(ctx context.Context, engine *gin.Engine, e *entry) error {
+	for _, dep := range e.status.Dependencies {
+		if d := m.find(dep); d == nil || d.status.State != StateRunning {
+			return m.fail(e, "dependency", fmt.Errorf("依赖插件 %s 未就绪", dep))
+		}
+	}
+	lifecycle, ok := e.plugin.(Lifecycle)
+	if ok {
+		if err := m.call(e, "init", func() error { return lifecycle.Init(ctx) }); err != nil {
+			return err
+		}
+		if err := m.call(e, "migrate", func() error { return lifecycle.Migrate(ctx) }); err != nil {
+			return err
+		}
+	}
+	if err := m.call(e, "register", func() error { e.plugin.Register(engine); return nil }); err != nil {
+		return err
+	}
+	if ok {
+		if err := m.call(e, "start", func() error { return lifecycle.Start(ctx) }); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	e.status.State = StateRunning
+	e.status.StartedAt = &now
+	m.started = append(m.started, e)
+	return nil
+}
+
+// Stop 按启动的逆序停止插件
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for i := len(m.started) - 1; i >= 0; i-- {
+		e := m.started[i]
+		if lifecycle, ok := e.plugin.(Lifecycle); ok {
+			if err := m.call(e, "stop", func() error { return lifecycle.Stop(ctx) }); err != nil {
+				errs = append(errs, fmt.Errorf("插件 %s: %w", e.status.Name, err))
+				continue
+			}
+		}
+		e.status.State = StateStopped
+	}
+	m.started = nil
+	return errors.Join(errs...)
+}
+
+// Statuses 获取所有插件状态, 运行中且实现了 HealthChecker 的插件会执行健康检查
+func (m *Manager) Statuses(ctx context.Context) []Status {
+	m.mu.RLock()
+	statuses := make([]Status, len(m.entries))
+	checkers := make(map[int]HealthChecker)
+	for i, e := range m.entries {
+		statuses[i] = e.status
+		statuses[i].Healthy = e.status.State == StateRunning
+		if checker, ok := e.plugin.(HealthChecker); ok && statuses[i].Healthy {
+			checkers[i] = checker
+		}
+	}
+	m.mu.RUnlock()
+	for i, checker := range checkers {
+		if err := checker.Health(ctx); err != nil {
+			statuses[i].Healthy = false
+			statuses[i].Error = err.Error()
+		}
+	}
+	return statuses
+}
+
+// sort 稳定的拓扑排序, 同一层级保持添加顺序, 重名及循环依赖的插件不在结果中
+func (m *Manager) sort() []*entry {
+	names := make(map[string]*entry, len(m.entries))
+	var nodes []*entry
+	for _, e := range m.entries {
+		if _, ok := names[e.status.Name]; ok {
+			continue
+		}
+		names[e.status.Name] = e
+		nodes = append(nodes, e)
+	}
+	done := make(map[*entry]bool, len(nodes))
+	order := make([]*entry, 0, len(nodes))
+	for len(order) < len(nodes) {
+		progressed := false
+		for _, e := range nodes {
+			if done[e] {
+				continue
+			}
+			ready := true
+			for _, dep := range e.status.Dependencies {
+				// 缺失的依赖不参与排序, 启动时再报错
+				if d, ok := names[dep]; ok && !done[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				done[e] = true
+				order = append(order, e)
+				progressed = true
+				break
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	return order
+}
+
+func (m *Manager) find(name string) *entry {
+	for _, e := range m.entries {
+		if e.status.Name == name && e.status.Order != 0 {
+			return e
+		}
+	}
+	return nil
+}
+
+// call 执行生命周期阶段, 插件 panic 时转换为错误避免影响主程序
+func (m *Manager) call(e *entry, stage string, fn func() error) (err error) {
+	e.status.Stage = stage
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+		if err != nil {
+			err = m.fail(e, stage, err)
+		}
+	}()
+	return fn()
+}
+
+func (m *Manager) fail(e *entry, stage string, err error) error {
+	e.status.Stage = stage
+	e.status.State = StateFailed
+	e.status.Error = err.Error()
+	return fmt.Errorf("%s: %w", stage, err)
+}
+
+// nameOf 插件名称, 未实现 Dependent 时取插件所在包名
+func nameOf(p Plugin) string {
+	if d, ok := p.(Dependent); ok {
+		return d.Name()
+	}
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return path.Base(t.PkgPath())
+}
@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPlugin struct {
+	name    string
+	deps    []string
+	events  *[]string
+	failAt  string
+	healthy error
+}
+
+func (p *mockPlugin) Name() string           { return p.name }
+func (p *mockPlugin) Dependencies() []string { return p.deps }
+
+func (p *mockPlugin) record(stage string) error {
+	*p.events = append(*p.events, p.name+"."+stage)
+	if p.failAt == stage {
+		return errors.New(stage + " failed")
+	}
+	return nil
+}
+
+func (p *mockPlugin) Init(context.Context) error    { return p.record("init") }
+func (p *mockPlugin) Migrate(context.Context) error { return p.record("migrate") }
+func (p *mockPlugin) Register(*gin.Engine) {
+	if p.record("register") != nil {
+		panic("register failed")
+	}
+}
+func (p *mockPlugin) Start(context.Context) error  { return p.record("start") }
+func (p *mockPlugin) Stop(context.Context) error   { return p.record("stop") }
+func (p *mockPlugin) Health(context.Context) error { return p.healthy }
+
+type simplePlugin struct{}
+
+func (simplePlugin) Register(*gin.Engine) {}
+
+func TestManagerOrder(t *testing.T) {
+	var events []string
+	m := NewManager()
+	m.Add(
+		&mockPlugin{name: "c", deps: []string{"b"}, events: &events},
+		&mockPlugin{name: "b", deps: []string{"a"}, events: &events},
+		&mockPlugin{name: "a", events: &events},
+		simplePlugin{},
+	)
+	assert.Nil(t, m.Setup(context.Background(), gin.New()))
+	assert.Equal(t, []string{
+		"a.init", "a.migrate", "a.register", "a.start",
+		"b.init", "b.migrate", "b.register", "b.start",
+		"c.init", "c.migrate", "c.register", "c.start",
+	}, events)
+
+	statuses := m.Statuses(context.Background())
+	assert.Equal(t, "v2", statuses[3].Name)
+	for _, s := range statuses {
+		assert.Equal(t, StateRunning, s.State)
+		assert.True(t, s.Healthy)
+	}
+
+	events = nil
+	assert.Nil(t, m.Stop(context.Background()))
+	assert.Equal(t, []string{"c.stop", "b.stop", "a.stop"}, events)
+	assert.Equal(t, StateStopped, m.Statuses(context.Background())[0].State)
+}
+
+func TestManagerFailure(t *testing.T) {
+	var events []string
+	m := NewManager()
+	m.Add(
+		&mockPlugin{name: "a", events: &events, failAt: "register"},
+		&mockPlugin{name: "b", deps: []string{"a"}, events: &events},
+		&mockPlugin{name: "c", deps: []string{"missing"}, events: &events},
+		&mockPlugin{name: "d", deps: []string{"e"}, events: &events},
+		&mockPlugin{name: "e", deps: []string{"d"}, events: &events},
+		&mockPlugin{name: "f", events: &events, healthy: errors.New("down")},
+	)
+	err := m.Setup(context.Background(), gin.New())
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"a.init", "a.migrate", "a.register", "f.init", "f.migrate", "f.register", "f.start"}, events)
+
+	statuses := m.Statuses(context.Background())
+	expect := map[string]string{"a": "register", "b": "dependency", "c": "dependency", "d": "sort", "e": "sort"}
+	for _, s := range statuses[:5] {
+		assert.Equal(t, StateFailed, s.State, s.Name)
+		assert.Equal(t, expect[s.Name], s.Stage, s.Name)
+		assert.False(t, s.Healthy)
+	}
+	assert.Equal(t, StateRunning, statuses[5].State)
+	assert.False(t, statuses[5].Healthy)
+	assert.Equal(t, "down", statuses[5].Error)
+}
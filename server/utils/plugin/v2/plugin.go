@@ -1,6 +1,8 @@
 package plugin
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,3 +11,29 @@ type Plugin interface {
 	// Register 注册路由
 	Register(group *gin.Engine)
 }
+
+// Lifecycle 插件生命周期扩展接口, 可选实现
+// 启动顺序为 Init -> Migrate -> Register -> Start, 服务退出时按依赖的逆序调用 Stop
+type Lifecycle interface {
+	Plugin
+	// Init 初始化配置、api、菜单等
+	Init(ctx context.Context) error
+	// Migrate 迁移插件数据表
+	Migrate(ctx context.Context) error
+	// Start 启动后台任务, 在路由注册完成后调用
+	Start(ctx context.Context) error
+	// Stop 优雅停止, ctx 超时后应尽快返回
+	Stop(ctx context.Context) error
+}
+
+// Dependent 声明插件名称及其依赖的插件, 可选实现
+// 未实现时插件名称取插件所在包名
+type Dependent interface {
+	Name() string
+	Dependencies() []string
+}
+
+// HealthChecker 插件健康检查, 可选实现
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantScope 当前请求所处的租户范围
+type TenantScope struct {
+	Id  uint // 租户ID 0为平台
+	All bool // 超级管理员未指定租户时可访问全部租户数据
+}
+
+type tenantContextKey struct{}
+
+// SetTenant 将租户范围写入gin上下文 同时写入请求上下文
+func SetTenant(c *gin.Context, scope TenantScope) {
+	c.Set("tenant", scope)
+	c.Request = c.Request.WithContext(ContextWithTenant(c.Request.Context(), scope))
+}
+
+// ContextWithTenant 返回携带租户范围的context
+func ContextWithTenant(ctx context.Context, scope TenantScope) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, scope)
+}
+
+// GetTenantFromContext 从context中获取租户范围 未经过租户中间件(如定时任务)时 ok 为false
+func GetTenantFromContext(ctx context.Context) (scope TenantScope, ok bool) {
+	if ctx == nil {
+		return
+	}
+	scope, ok = ctx.Value(tenantContextKey{}).(TenantScope)
+	return
+}
+
+// GetTenantId 从Gin的Context中获取当前租户ID 未开启多租户或可访问全部租户时为0
+func GetTenantId(c *gin.Context) uint {
+	if v, ok := c.Get("tenant"); ok {
+		if scope := v.(TenantScope); !scope.All {
+			return scope.Id
+		}
+	}
+	return 0
+}
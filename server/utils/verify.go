@@ -10,10 +10,14 @@ var (
 	PageInfoVerify         = Rules{"Page": {NotEmpty()}, "PageSize": {NotEmpty()}}
 	CustomerVerify         = Rules{"CustomerName": {NotEmpty()}, "CustomerPhoneData": {NotEmpty()}}
 	AutoCodeVerify         = Rules{"Abbreviation": {NotEmpty()}, "StructName": {NotEmpty()}, "PackageName": {NotEmpty()}}
+	AutoCodeOpenAPIVerify  = Rules{"Path": {NotEmpty()}, "Schema": {NotEmpty()}}
 	AutoPackageVerify      = Rules{"PackageName": {NotEmpty()}}
+	TemplatePackVerify     = Rules{"Template": {NotEmpty()}}
 	AuthorityVerify        = Rules{"AuthorityId": {NotEmpty()}, "AuthorityName": {NotEmpty()}}
 	AuthorityIdVerify      = Rules{"AuthorityId": {NotEmpty()}}
 	OldAuthorityVerify     = Rules{"OldAuthorityId": {NotEmpty()}}
 	ChangePasswordVerify   = Rules{"Password": {NotEmpty()}, "NewPassword": {NotEmpty()}}
 	SetUserAuthorityVerify = Rules{"AuthorityId": {NotEmpty()}}
+	ForgotPasswordVerify   = Rules{"Account": {NotEmpty()}}
+	ResetByTokenVerify     = Rules{"Token": {NotEmpty()}, "NewPassword": {NotEmpty()}}
 )
@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-GVA-Event"     // 事件名称
+	HeaderDelivery  = "X-GVA-Delivery"  // 投递ID 重试和重放时不变 接收方可据此去重
+	HeaderTimestamp = "X-GVA-Timestamp" // 签名时间 unix秒
+	HeaderSignature = "X-GVA-Signature" // sha256=签名
+)
+
+// maxResponseBody 记录的响应内容长度上限
+const maxResponseBody = 2048
+
+// Request 一次投递
+type Request struct {
+	Url        string
+	Secret     string
+	Event      string
+	DeliveryId string
+	Body       []byte
+}
+
+// Result 投递结果 请求未发出时 StatusCode 为 0
+type Result struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Sign 计算签名 hex(hmac_sha256(secret, timestamp + "." + body))
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 接收方校验签名 tolerance 为允许的时间偏差 防止重放攻击 为0时不校验时间
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.New("签名时间无效")
+	}
+	if tolerance > 0 {
+		if diff := time.Since(time.Unix(timestamp, 0)); diff > tolerance || diff < -tolerance {
+			return errors.New("签名已过期")
+		}
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return errors.New("签名不匹配")
+	}
+	return nil
+}
+
+// Send 发送一次回调 响应码为2xx时视为成功
+func Send(ctx context.Context, client *http.Client, r Request) (result Result, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.Url, bytes.NewReader(r.Body))
+	if err != nil {
+		return result, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gin-vue-admin-webhook")
+	req.Header.Set(HeaderEvent, r.Event)
+	req.Header.Set(HeaderDelivery, r.DeliveryId)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(r.Secret, timestamp, r.Body))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Duration = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result.StatusCode = resp.StatusCode
+	result.Body = string(body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("响应状态码 %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// Backoff 第 attempt 次失败后的重试间隔 base*2^(attempt-1) 不超过 max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	wait := base
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+	if wait > max {
+		return max
+	}
+	return wait
+}
+
+// ErrPrivateAddress 回调地址指向回环、内网等非公网地址
+var ErrPrivateAddress = errors.New("回调地址不能指向内网地址")
+
+// privatePrefixes 标准库未覆盖的非公网网段
+var privatePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"), // 运营商级 NAT
+	netip.MustParsePrefix("198.18.0.0/15"), // 基准测试
+}
+
+// IsPrivateIP 是否为回环、内网、链路本地(含云厂商元数据地址)、未指定或组播地址
+func IsPrivateIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range privatePrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHost 解析回调地址的主机名 任一地址为非公网地址时返回 ErrPrivateAddress
+func CheckHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if IsPrivateIP(addr) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+		}
+	}
+	return nil
+}
+
+// NewClient 创建投递使用的客户端 allowPrivate 返回 false 时拒绝连接非公网地址
+// 在建立连接时校验实际连接的 IP, 重定向和 DNS 重绑定同样受限; 不使用环境变量中的代理
+func NewClient(timeout time.Duration, allowPrivate func() bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivate() {
+				return nil
+			}
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if IsPrivateIP(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
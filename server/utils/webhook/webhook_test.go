@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	var received http.Header
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		receivedBody, _ = io.ReadAll(r.Body)
+		if err := Verify("secret", r.Header, receivedBody, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(err.Error()))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer receiver.Close()
+
+	body := []byte(`{"event":"user.created","data":{"id":1}}`)
+	result, err := Send(context.Background(), receiver.Client(), Request{
+		Url: receiver.URL, Secret: "secret", Event: "user.created", DeliveryId: "d-1", Body: body,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "ok", result.Body)
+	assert.Equal(t, body, receivedBody)
+	assert.Equal(t, "user.created", received.Get(HeaderEvent))
+	assert.Equal(t, "d-1", received.Get(HeaderDelivery))
+	assert.Equal(t, "application/json", received.Get("Content-Type"))
+
+	// 密钥不一致时接收方拒绝, 非2xx视为失败
+	result, err = Send(context.Background(), receiver.Client(), Request{
+		Url: receiver.URL, Secret: "wrong", Event: "user.created", DeliveryId: "d-2", Body: body,
+	})
+	assert.ErrorContains(t, err, "401")
+	assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	assert.Equal(t, "签名不匹配", result.Body)
+}
+
+func TestSendUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := receiver.URL
+	receiver.Close()
+	result, err := Send(context.Background(), http.DefaultClient, Request{Url: url, Secret: "secret", Body: []byte("{}")})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, result.StatusCode)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	header := http.Header{}
+	old := time.Now().Add(-10 * time.Minute).Unix()
+	header.Set(HeaderTimestamp, strconv.FormatInt(old, 10))
+	header.Set(HeaderSignature, Sign("secret", old, body))
+
+	assert.Nil(t, Verify("secret", header, body, 0))
+	assert.EqualError(t, Verify("secret", header, body, 5*time.Minute), "签名已过期")
+	assert.EqualError(t, Verify("secret", header, []byte(`{"a":2}`), 0), "签名不匹配")
+	header.Del(HeaderTimestamp)
+	assert.EqualError(t, Verify("secret", header, body, 0), "签名时间无效")
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+	assert.Equal(t, 30*time.Second, Backoff(0, base, max))
+	assert.Equal(t, 30*time.Second, Backoff(1, base, max))
+	assert.Equal(t, time.Minute, Backoff(2, base, max))
+	assert.Equal(t, 8*time.Minute, Backoff(5, base, max))
+	assert.Equal(t, max, Backoff(6, base, max))
+	assert.Equal(t, max, Backoff(100, base, max))
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "100.64.0.1", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "224.0.0.1"} {
+		assert.True(t, IsPrivateIP(netip.MustParseAddr(ip)), ip)
+	}
+	for _, ip := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		assert.False(t, IsPrivateIP(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func TestNewClientPrivateNetwork(t *testing.T) {
+	var hits int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer receiver.Close()
+
+	allow := false
+	client := NewClient(time.Second, func() bool { return allow })
+	result, err := Send(context.Background(), client, Request{Url: receiver.URL, Secret: "secret", Body: []byte("{}")})
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+	assert.Equal(t, 0, result.StatusCode)
+	assert.Equal(t, 0, hits)
+
+	allow = true
+	result, err = Send(context.Background(), client, Request{Url: receiver.URL, Secret: "secret", Body: []byte("{}")})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, 1, hits)
+}
+
+func TestCheckHost(t *testing.T) {
+	assert.ErrorIs(t, CheckHost(context.Background(), "127.0.0.1"), ErrPrivateAddress)
+	assert.ErrorIs(t, CheckHost(context.Background(), "localhost"), ErrPrivateAddress)
+	assert.Nil(t, CheckHost(context.Background(), "8.8.8.8"))
+}
@@ -139,6 +139,28 @@ export const pubPlug = (params) => {
   })
 }
 
+export const getInstalledPlugins = () => {
+  return service({
+    url: '/autoCode/getInstalledPlugins',
+    method: 'get'
+  })
+}
+
+export const getPluginStatus = () => {
+  return service({
+    url: '/autoCode/getPluginStatus',
+    method: 'get'
+  })
+}
+
+export const uninstallPlugin = (data) => {
+  return service({
+    url: '/autoCode/uninstallPlugin',
+    method: 'post',
+    data
+  })
+}
+
 export const llmAuto = (data) => {
   return service({
     url: '/autoCode/llmAuto',